@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"encoding/json"
 	"strconv"
 
 	"github.com/camh-/jobber/job"
@@ -9,6 +10,21 @@ import (
 // ProcSelfArgMaker returns a command line to run the job in a container.
 // This runs ourself as "/proc/self/exe rc ..."
 func ProcSelfArgMaker(jd job.JobDescription) (cmd string, args []string) {
+	return BinaryArgMaker("/proc/self/exe")(jd)
+}
+
+// BinaryArgMaker returns an ArgMaker for command lines that run jobs in a
+// container with the program at path, as "path rc ...". The program must
+// implement the hidden `jobber rc` command, which it may do with a smaller
+// build of jobber, such as a static one, than the server.
+func BinaryArgMaker(path string) job.ArgMaker {
+	return func(jd job.JobDescription) (string, []string) {
+		return path, append([]string{"jobber", "rc"}, rcArgs(jd)...)
+	}
+}
+
+// rcArgs returns the arguments to the `jobber rc` command to run the job.
+func rcArgs(jd job.JobDescription) []string {
 	argv := []string{"--id", jd.ID}
 
 	r := jd.Spec.Resources
@@ -19,21 +35,71 @@ func ProcSelfArgMaker(jd job.JobDescription) (cmd string, args []string) {
 	if jd.Spec.IsolateNetwork {
 		argv = append(argv, "--isolate-network")
 	}
+	if jd.Spec.IsolateUsers {
+		argv = append(argv, "--isolate-users")
+	}
+	if jd.Spec.MountDev {
+		argv = append(argv, "--mount-dev")
+	}
+	if jd.Spec.Nice != 0 {
+		// Use the --flag=value form so a negative value is not parsed
+		// as a flag.
+		argv = append(argv, "--nice="+strconv.Itoa(jd.Spec.Nice))
+	}
+	if jd.Spec.IONice.Class != job.IOClassNone {
+		argv = append(argv, "--ionice", jd.Spec.IONice.String())
+	}
+	if jd.Spec.Umask.Set {
+		argv = append(argv, "--umask", jd.Spec.Umask.String())
+	}
+	if jd.Spec.Workdir != "" {
+		argv = append(argv, "--workdir", jd.Spec.Workdir)
+	}
+	for _, m := range jd.Spec.Mounts {
+		argv = append(argv, "--mount", m.String())
+	}
+	if jd.Spec.RunAsUser != 0 {
+		argv = append(argv, "--user", strconv.FormatUint(uint64(jd.Spec.RunAsUser), 10))
+	}
+	if jd.Spec.RunAsGroup != 0 {
+		argv = append(argv, "--group", strconv.FormatUint(uint64(jd.Spec.RunAsGroup), 10))
+	}
+	if jd.Spec.TraceSyscalls {
+		argv = append(argv, "--trace-syscalls")
+	}
 	if r.MaxProcesses != 0 {
 		argv = append(argv, "--max-processes", strconv.FormatUint(uint64(r.MaxProcesses), 10))
 	}
 	if r.Memory != 0 {
-		argv = append(argv, "--memory", strconv.FormatUint(r.Memory, 10))
+		argv = append(argv, "--memory", strconv.FormatUint(uint64(r.Memory), 10))
 	}
 	if r.CPU != 0 {
 		argv = append(argv, "--cpu", strconv.FormatUint(uint64(r.CPU), 10))
 	}
+	if r.CpuBurst != 0 {
+		argv = append(argv, "--cpu-burst", strconv.FormatUint(uint64(r.CpuBurst), 10))
+	}
+	if r.CpuSetMems != "" {
+		argv = append(argv, "--cpuset-mems", r.CpuSetMems)
+	}
 	for _, iolim := range r.IO {
 		argv = append(argv, "--io", iolim.String())
 	}
+	if jd.Spec.SetupTimeout > 0 {
+		argv = append(argv, "--setup-timeout", jd.Spec.SetupTimeout.String())
+	}
 
-	argv = append(argv, "--", jd.Spec.Command)
-	argv = append(argv, jd.Spec.Args...)
+	for _, command := range jd.Spec.Commands {
+		// Each command of a sequence is passed as a JSON array so its
+		// arguments are kept separate.
+		b, _ := json.Marshal(command) // can't fail for a []string
+		argv = append(argv, "--sequence", string(b))
+	}
+
+	if jd.Spec.Command != "" {
+		argv = append(argv, "--", jd.Spec.Command)
+		argv = append(argv, jd.Spec.Args...)
+	}
 
-	return "/proc/self/exe", append([]string{"jobber", "rc"}, argv...)
+	return argv
 }
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/camh-/jobber/job"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcSelfArgMaker(t *testing.T) {
+	tests := map[string]struct {
+		spec     job.JobSpec
+		expected []string
+	}{
+		"command only": {
+			spec:     job.JobSpec{Command: "/bin/true"},
+			expected: []string{"--", "/bin/true"},
+		},
+		"mount dev": {
+			spec:     job.JobSpec{Command: "/bin/ls", Args: []string{"/dev"}, Root: "/srv/root", MountDev: true},
+			expected: []string{"--root", "/srv/root", "--mount-dev", "--", "/bin/ls", "/dev"},
+		},
+		"run as": {
+			spec:     job.JobSpec{Command: "/bin/id", RunAsUser: 1000, RunAsGroup: 100},
+			expected: []string{"--user", "1000", "--group", "100", "--", "/bin/id"},
+		},
+		"isolate users": {
+			spec:     job.JobSpec{Command: "/bin/true", IsolateNetwork: true, IsolateUsers: true},
+			expected: []string{"--isolate-network", "--isolate-users", "--", "/bin/true"},
+		},
+		"nice": {
+			spec:     job.JobSpec{Command: "/bin/true", Nice: 10},
+			expected: []string{"--nice=10", "--", "/bin/true"},
+		},
+		"negative nice": {
+			spec:     job.JobSpec{Command: "/bin/true", Nice: -5},
+			expected: []string{"--nice=-5", "--", "/bin/true"},
+		},
+		"ionice": {
+			spec:     job.JobSpec{Command: "/bin/true", IONice: job.IONice{Class: job.IOClassBestEffort, Priority: 6}},
+			expected: []string{"--ionice", "best-effort:6", "--", "/bin/true"},
+		},
+		"umask": {
+			spec:     job.JobSpec{Command: "/bin/true", Umask: job.Umask{Mask: 022, Set: true}},
+			expected: []string{"--umask", "0022", "--", "/bin/true"},
+		},
+		"zero umask": {
+			spec:     job.JobSpec{Command: "/bin/true", Umask: job.Umask{Set: true}},
+			expected: []string{"--umask", "0000", "--", "/bin/true"},
+		},
+		"cpuset mems": {
+			spec: job.JobSpec{
+				Command:   "/bin/true",
+				Resources: job.ResourceLimits{Memory: 1 << 30, CpuSetMems: "0-1,3"},
+			},
+			expected: []string{"--memory", "1073741824", "--cpuset-mems", "0-1,3", "--", "/bin/true"},
+		},
+		"cpu burst": {
+			spec: job.JobSpec{
+				Command:   "/bin/true",
+				Resources: job.ResourceLimits{CPU: 500, CpuBurst: 100000},
+			},
+			expected: []string{"--cpu", "500", "--cpu-burst", "100000", "--", "/bin/true"},
+		},
+		"setup timeout": {
+			spec:     job.JobSpec{Command: "/bin/true", SetupTimeout: 15 * time.Second},
+			expected: []string{"--setup-timeout", "15s", "--", "/bin/true"},
+		},
+		"trace syscalls": {
+			spec:     job.JobSpec{Command: "/bin/true", TraceSyscalls: true},
+			expected: []string{"--trace-syscalls", "--", "/bin/true"},
+		},
+		"command sequence": {
+			spec: job.JobSpec{
+				Commands: [][]string{{"/bin/make"}, {"/bin/make", "install", "PREFIX=/opt/a b,c"}},
+			},
+			expected: []string{
+				"--sequence", `["/bin/make"]`,
+				"--sequence", `["/bin/make","install","PREFIX=/opt/a b,c"]`,
+			},
+		},
+		"workdir and mounts": {
+			spec: job.JobSpec{
+				Command: "/bin/make",
+				Root:    "/srv/root",
+				Workdir: "/workspace",
+				Mounts: []job.BindMount{
+					{Source: "/home/eve/src", Target: "/workspace"},
+					{Source: "/etc/resolv.conf", Target: "/etc/resolv.conf", ReadOnly: true},
+				},
+			},
+			expected: []string{
+				"--root", "/srv/root", "--workdir", "/workspace",
+				"--mount", "/home/eve/src:/workspace", "--mount", "/etc/resolv.conf:/etc/resolv.conf:ro",
+				"--", "/bin/make",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			jd := job.JobDescription{ID: "job-1", Spec: tc.spec}
+			cmd, args := ProcSelfArgMaker(jd)
+			require.Equal(t, "/proc/self/exe", cmd)
+			expected := append([]string{"jobber", "rc", "--id", "job-1"}, tc.expected...)
+			require.Equal(t, expected, args)
+
+			// The args should parse back into the same job spec.
+			rc := parseRunContainer(t, args[1:])
+			require.Equal(t, jd.ID, rc.ID)
+			require.Equal(t, tc.spec, rc.JobSpec)
+		})
+	}
+}
+
+func TestBinaryArgMaker(t *testing.T) {
+	jd := job.JobDescription{ID: "job-1", Spec: job.JobSpec{Command: "/bin/true"}}
+	cmd, args := BinaryArgMaker("/usr/libexec/jobber-init")(jd)
+	require.Equal(t, "/usr/libexec/jobber-init", cmd)
+	require.Equal(t, []string{"jobber", "rc", "--id", "job-1", "--", "/bin/true"}, args)
+
+	// The args are the same as when running ourself.
+	_, selfArgs := ProcSelfArgMaker(jd)
+	require.Equal(t, selfArgs, args)
+}
+
+// parseRunContainer parses args as the `jobber rc` command line.
+func parseRunContainer(t *testing.T, args []string) CmdRunContainer {
+	t.Helper()
+	var cli struct {
+		Rc CmdRunContainer `cmd:""`
+	}
+	parser, err := kong.New(&cli)
+	require.NoError(t, err)
+	_, err = parser.Parse(args)
+	require.NoError(t, err)
+	return cli.Rc
+}
@@ -1,16 +1,30 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"os/signal"
+	"strconv"
 	"text/tabwriter"
 	"time"
 
 	"github.com/camh-/jobber/job"
 	pb "github.com/camh-/jobber/pb"
+	"github.com/camh-/jobber/service"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // client is a struct intended to be embedded in each of the client kong
@@ -23,16 +37,34 @@ type clientCmd struct {
 	TLSKey  string `name:"tls-key" default:"certs/user.key" help:"TLS user key"`
 	CACert  string `name:"ca-cert" default:"certs/ca.crt" help:"CA for authenticating server"`
 
-	conn   *grpc.ClientConn
-	output io.Writer
+	conn      *grpc.ClientConn
+	output    io.Writer
+	errOutput io.Writer
+	// clock returns the current time, for the running time of jobs. If
+	// nil, time.Now is used.
+	clock func() time.Time
 }
 
 // CmdRun is a kong struct describing the flags and arguments for the
 // `jobber run` subcommand.
 type CmdRun struct {
 	clientCmd
-	Detach       bool `short:"d" help:"Detach from output when running" xor:"ts"`
-	NoTimestamps bool `short:"T" help:"Do not output timestamps on lines" xor:"ts"`
+	Detach        bool   `short:"d" help:"Detach from output when running" xor:"ts"`
+	NoTimestamps  bool   `short:"T" help:"Do not output timestamps on lines" xor:"ts"`
+	TSPrecision   string `name:"ts-precision" enum:"s,ms,us,ns" default:"ns" help:"Precision of timestamps on lines (s,ms,us,ns)"`
+	BindCwd       bool   `help:"Run the job in the current directory, bind mounted at /workspace if the job has its own root. The server must be on the same host"`
+	SpecFile      string `name:"spec-file" type:"existingfile" help:"JSON file of the job spec, overriding any of the job flags and arguments it sets. Use \"commands\" to run a sequence of commands"`
+	ExplainCgroup bool   `name:"explain-cgroup" help:"Show the cgroup writes the server would make for the job's resource limits instead of running it"`
+	Wait          bool   `short:"w" help:"Exit with the job's exit code once it completes: 128 plus the signal number if it was killed or stopped, 124 if it timed out, or 127 if it never started"`
+
+	job.JobSpec
+}
+
+// CmdExecSync is a kong struct describing the flags and arguments for the
+// `jobber exec-sync` subcommand.
+type CmdExecSync struct {
+	clientCmd
+	Deadline time.Duration `help:"Give up waiting for the job after this long. The server stops the job in time to return its result"`
 
 	job.JobSpec
 }
@@ -42,43 +74,148 @@ type CmdRun struct {
 type CmdStop struct {
 	clientCmd
 	Cleanup bool   `short:"c" help:"Remove job from jobber server after stopping. Can be used on already stopped job"`
-	JobID   string `arg:"" help:"ID of job to stop"`
+	Command string `help:"Stop all your running jobs whose command is exactly this, instead of a job by ID. With --cleanup, completed jobs are removed too"`
+	All     bool   `short:"a" help:"With --command, stop all users' jobs (admin only)"`
+	JobID   string `arg:"" optional:"" help:"ID of job to stop"`
+}
+
+// CmdCancel is a kong struct describing the flags and arguments for the
+// `jobber cancel` subcommand.
+type CmdCancel struct {
+	clientCmd
+	AllMine    bool   `name:"all-mine" help:"Cancel all your queued jobs instead of a job by ID. Requires --queued-only"`
+	QueuedOnly bool   `name:"queued-only" help:"Acknowledge that only queued jobs are cancelled by --all-mine, leaving your running jobs to be stopped with jobber stop"`
+	JobID      string `arg:"" optional:"" help:"ID of queued job to cancel"`
 }
 
 // CmdStatus is a kong struct describing the flags and arguments for the
 // `jobber status` subcommand.
 type CmdStatus struct {
 	clientCmd
-	JobID string `arg:"" help:"ID of job to get status of"`
+	Wide   bool   `short:"w" help:"Also show the job's pid, the most processes it had at once and the address of the client that started it"`
+	Output string `short:"o" enum:"text,json" default:"text" help:"Output format (text,json). json is an array of job objects with all the fields of --wide"`
+	JobID  string `arg:"" help:"ID of job to get status of"`
+}
+
+// CmdExists is a kong struct describing the flags and arguments for the
+// `jobber exists` subcommand.
+type CmdExists struct {
+	clientCmd
+	JobID string `arg:"" help:"ID of job to check for"`
+}
+
+// CmdStatsHistory is a kong struct describing the flags and arguments for
+// the `jobber stats-history` subcommand.
+type CmdStatsHistory struct {
+	clientCmd
+	JobID string `arg:"" help:"ID of job to show the resource usage history of. It must have been run with --sample-resources"`
+}
+
+// CmdHealth is a kong struct describing the flags and arguments for the
+// hidden `jobber health` subcommand.
+type CmdHealth struct {
+	clientCmd
+	Service string `help:"Service to check the health of, such as JobExecutor (default: the server as a whole)"`
+}
+
+// CmdInspect is a kong struct describing the flags and arguments for the
+// `jobber inspect` subcommand.
+type CmdInspect struct {
+	clientCmd
+	Output string `short:"o" enum:"json" default:"json" help:"Output format (json)"`
+	JobID  string `arg:"" help:"ID of job to inspect"`
 }
 
 // CmdList is a kong struct describing the flags and arguments for the
 // `jobber list` subcommand.
 type CmdList struct {
 	clientCmd
-	All       bool `short:"a" help:"List all user's jobs"`
-	Completed bool `short:"c" help:"List completed as well as running jobs"`
+	All       bool   `short:"a" help:"List all user's jobs"`
+	Completed bool   `short:"c" help:"List completed as well as running jobs"`
+	Command   string `help:"List only jobs whose command is exactly this"`
+	Output    string `short:"o" enum:"text,json" default:"text" help:"Output format (text,json). json is an array of job objects"`
+
+	RecentlyFailed time.Duration `help:"List only jobs that failed (exited non-zero, were killed or never started) and completed within this long, such as 1h. Implies --completed"`
 }
 
 // CmdLogs is a kong struct describing the flags and arguments for the
 // `jobber logs` subcommand.
 type CmdLogs struct {
 	clientCmd
-	Follow       bool   `short:"f" help:"Stream logs continuously as they are produced"`
-	NoTimestamps bool   `short:"T" help:"Do not output timestamps on lines"`
-	JobID        string `arg:"" help:"ID of job to fetch logs from"`
+	Follow       bool          `short:"f" help:"Stream logs continuously as they are produced"`
+	NoTimestamps bool          `short:"T" help:"Do not output timestamps on lines"`
+	TSPrecision  string        `name:"ts-precision" enum:"s,ms,us,ns" default:"ns" help:"Precision of timestamps on lines (s,ms,us,ns)"`
+	Format       string        `enum:"plain,syslog" default:"plain" help:"Format of log lines (plain,syslog). syslog is RFC5424 with the job ID as the app-name"`
+	Resume       bool          `short:"r" help:"Start after the last line shown by a previous --resume of this job, and remember where this one stops (including on Ctrl-C)"`
+	PositionFile string        `name:"position-file" type:"path" help:"File storing log positions for --resume (default: jobber/log-positions.json in the user cache directory)"`
+	AllMine      bool          `name:"all-mine" help:"Fetch the logs of all your running jobs, each line prefixed with its job ID. With --follow, jobs started later are picked up too"`
+	PollInterval time.Duration `default:"1s" help:"How often --all-mine --follow checks for newly started jobs"`
+	LineNumbers  bool          `short:"n" name:"line-numbers" help:"Prefix each line with its index in the job's output, as used to resume or page through the logs"`
+	Stream       string        `enum:"stdout,stderr,both" default:"both" help:"Output stream of the job to show lines from (stdout,stderr,both)"`
+	FromTime     time.Time     `name:"from-time" help:"Show only lines timestamped at or after this time (RFC3339)"`
+	ToTime       time.Time     `name:"to-time" help:"Show only lines timestamped at or before this time (RFC3339)"`
+	Tail         uint32        `help:"Show only the last N lines output so far, then any later lines with --follow (0 for all)"`
+	Output       string        `short:"o" enum:"text,json" default:"text" help:"Output format (text,json). json is a JSON object per line with the line's index, timestamp, stream and text"`
+	JobID        string        `arg:"" optional:"" help:"ID of job to fetch logs from"`
+}
+
+// CmdWatch is a kong struct describing the flags and arguments for the
+// `jobber watch` subcommand.
+type CmdWatch struct {
+	clientCmd
+	TSPrecision string `name:"ts-precision" enum:"s,ms,us,ns" default:"ns" help:"Precision of timestamps on lines (s,ms,us,ns)"`
+	JobID       string `arg:"" help:"ID of job to watch"`
 }
 
 type CmdShutdown struct {
 	clientCmd
 }
 
+// CmdDrain is a kong struct describing the flags and arguments for the
+// `jobber drain` subcommand.
+type CmdDrain struct {
+	clientCmd
+	Wait         bool          `short:"w" help:"Wait until the server has drained, showing the jobs remaining as they complete"`
+	PollInterval time.Duration `default:"5s" help:"How often --wait checks the progress of the drain"`
+}
+
+// CmdDrainStatus is a kong struct describing the flags and arguments for
+// the `jobber drain-status` subcommand.
+type CmdDrainStatus struct {
+	clientCmd
+}
+
+// CmdChown is a kong struct describing the flags and arguments for the
+// `jobber chown` subcommand.
+type CmdChown struct {
+	clientCmd
+	JobID string `arg:"" help:"ID of job to change the owner of"`
+	Owner string `arg:"" optional:"" help:"New owner of the job. If not given, the job is left without an owner for only admins to manage"`
+}
+
+// CmdDebug is a kong struct grouping the `jobber debug` subcommands, which
+// show internal server state for admins.
+type CmdDebug struct {
+	Feeder CmdDebugFeeder `cmd:"" help:"Show the state of a job's log feeder"`
+}
+
+// CmdDebugFeeder is a kong struct describing the flags and arguments for
+// the `jobber debug feeder` subcommand.
+type CmdDebugFeeder struct {
+	clientCmd
+	JobID string `arg:"" help:"ID of job to show log feeder of"`
+}
+
 func (c *clientCmd) connect() (pb.JobExecutorClient, error) {
 	creds, err := mTLSCreds(c.TLSCert, c.TLSKey, c.CACert)
 	if err != nil {
 		return nil, err
 	}
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(serverErrorUnaryInterceptor),
+		grpc.WithStreamInterceptor(serverErrorStreamInterceptor),
+	}
 	cc, err := grpc.Dial(c.Address, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot dial %s: %w", c.Address, err)
@@ -88,6 +225,59 @@ func (c *clientCmd) connect() (pb.JobExecutorClient, error) {
 	return pb.NewJobExecutorClient(cc), nil
 }
 
+// serverError is an error status returned by the jobber server. It prints
+// as the server's message alone, such as "greeting-01234567: unknown job",
+// rather than with the gRPC code as "rpc error: code = NotFound desc = ...".
+// The status is still available with status.FromError.
+type serverError struct {
+	st *status.Status
+}
+
+func (e serverError) Error() string { return e.st.Message() }
+
+func (e serverError) GRPCStatus() *status.Status { return e.st }
+
+// newServerError returns err as a serverError if it is a gRPC status error,
+// otherwise err unchanged.
+func newServerError(err error) error {
+	if st, ok := status.FromError(err); ok && st.Code() != codes.OK {
+		return serverError{st: st}
+	}
+	return err
+}
+
+// serverErrorUnaryInterceptor returns the errors of unary calls as
+// serverErrors.
+func serverErrorUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return newServerError(invoker(ctx, method, req, reply, cc, opts...))
+}
+
+// serverErrorStreamInterceptor returns the errors of streaming calls, and of
+// receiving on their streams, as serverErrors.
+func serverErrorStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, newServerError(err)
+	}
+	return serverErrorStream{stream}, nil
+}
+
+type serverErrorStream struct {
+	grpc.ClientStream
+}
+
+func (s serverErrorStream) RecvMsg(m interface{}) error {
+	return newServerError(s.ClientStream.RecvMsg(m))
+}
+
+// ExitCode is returned by a command that has output all it has to, to exit
+// with the code without the error being printed.
+type ExitCode int
+
+func (e ExitCode) Error() string {
+	return fmt.Sprintf("exit code %d", int(e))
+}
+
 func (c *clientCmd) writer() io.Writer {
 	if c.output != nil {
 		return c.output
@@ -95,6 +285,20 @@ func (c *clientCmd) writer() io.Writer {
 	return os.Stdout
 }
 
+func (c *clientCmd) errWriter() io.Writer {
+	if c.errOutput != nil {
+		return c.errOutput
+	}
+	return os.Stderr
+}
+
+func (c *clientCmd) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return time.Now()
+}
+
 func (c *clientCmd) Close() error {
 	return c.conn.Close()
 }
@@ -103,7 +307,9 @@ func (c *clientCmd) Close() error {
 // command line arguments into a `RunRequest` message and calls the
 // `JobExecutor.Run()` method. If the detach flag is not specified, it
 // calls the `JobExecutor.Logs()` method after a successful run to stream
-// back the logs of the run command.
+// back the logs of the run command, and with the wait flag, returns the
+// job's exit code as an ExitCode. Any warnings returned by the server are
+// written to stderr after the job ID.
 //
 // It is called by kong after parsing the command line.
 func (cmd *CmdRun) Run() error {
@@ -113,47 +319,171 @@ func (cmd *CmdRun) Run() error {
 	}
 	defer cmd.Close()
 
-	var iolims []*pb.DiskIOLimit
-	for _, iolim := range cmd.Resources.IO {
-		pblim := &pb.DiskIOLimit{
-			Device:    iolim.Device,
-			ReadBps:   iolim.ReadBPS,
-			WriteBps:  iolim.WriteBPS,
-			ReadIops:  iolim.ReadIOPS,
-			WriteIops: iolim.WriteIOPS,
-		}
-		iolims = append(iolims, pblim)
-	}
-
-	req := pb.RunRequest{
-		Spec: &pb.JobSpec{
-			Command:        cmd.Command,
-			Arguments:      cmd.Args,
-			RootDir:        cmd.Root,
-			IsolateNetwork: cmd.IsolateNetwork,
-			Resources: &pb.Resources{
-				MaxProcesses: cmd.Resources.MaxProcesses,
-				MilliCpu:     cmd.Resources.CPU,
-				Memory:       cmd.Resources.Memory,
-				IoLimits:     iolims,
-			},
-		},
+	spec := cmd.JobSpec
+	if cmd.SpecFile != "" {
+		if err := readSpecFile(cmd.SpecFile, &spec); err != nil {
+			return err
+		}
+	}
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	if cmd.Detach && spec.StopOnClientDisconnect {
+		return errors.New("--stop-on-client-disconnect cannot be used with --detach")
+	}
+	if cmd.BindCwd {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		bindCwd(&spec, cwd)
 	}
 
+	req := pb.RunRequest{Spec: service.NewJobSpecPB(spec), ExplainCgroup: cmd.ExplainCgroup}
+
 	resp, err := cl.Run(context.Background(), &req)
 	if err != nil {
 		return err
 	}
 
+	if cmd.ExplainCgroup {
+		for _, w := range resp.GetCgroupWrites() {
+			fmt.Fprintf(cmd.writer(), "%s=%s\n", w.GetFile(), w.GetValue())
+		}
+		for _, warning := range resp.GetWarnings() {
+			fmt.Fprintln(cmd.errWriter(), "warning:", warning)
+		}
+		return nil
+	}
+
 	fmt.Fprintln(cmd.writer(), "job id:", string(resp.GetJobId()))
+	for _, warning := range resp.GetWarnings() {
+		fmt.Fprintln(cmd.errWriter(), "warning:", warning)
+	}
 
 	if !cmd.Detach {
-		return getLogs(cmd.writer(), cl, resp.GetJobId(), true /* follow */, !cmd.NoTimestamps)
+		format := plainFormat(timestampFormat(!cmd.NoTimestamps, cmd.TSPrecision))
+		// The job's stdout and stderr go to ours, as if it ran here.
+		out := logOutput{stdout: cmd.writer(), stderr: cmd.errWriter()}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		// Only this stream stops a job run to stop on disconnect, not
+		// others following its logs.
+		logsReq := pb.LogsRequest{JobId: resp.GetJobId(), Follow: true, StopJobOnDisconnect: spec.StopOnClientDisconnect}
+		exit, _, err := streamLogs(ctx, out, cl, &logsReq, false /* lineNumbers */, format)
+		if ctx.Err() != nil {
+			fmt.Fprintln(cmd.errWriter(), "detached from job", string(resp.GetJobId()))
+			if cmd.Wait {
+				// The job has not completed, so it has no exit code
+				// to exit with. Exit as if interrupted by SIGINT.
+				return ExitCode(interruptedExitCode)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if exit == nil && cmd.Wait {
+			// The stream should end with the exit status, but make
+			// sure of it rather than exit with the wrong code.
+			statusResp, err := cl.Status(context.Background(), &pb.StatusRequest{JobId: resp.GetJobId()})
+			if err != nil {
+				return err
+			}
+			exit = statusResp.GetStatus().GetExit()
+			if exit == nil {
+				return fmt.Errorf("job %s: output ended before the job completed", resp.GetJobId())
+			}
+		}
+		if exit != nil {
+			fmt.Fprintln(cmd.errWriter(), "job", exit.GetReason())
+		}
+		if cmd.Wait {
+			return jobExitCode(exit)
+		}
+	}
+
+	return nil
+}
+
+// Exit codes of `jobber run --wait` other than the exit code of the job:
+// timedOutExitCode for a job that timed out, as timeout(1) uses, and
+// interruptedExitCode when the client is interrupted before the job
+// completes, as a shell uses for a command interrupted by SIGINT.
+const (
+	timedOutExitCode    = 124
+	interruptedExitCode = 130
+)
+
+// jobExitCode returns the error for `jobber run --wait` to exit with for a
+// job that ended with exit: nil if it exited with code 0, otherwise an
+// ExitCode of its exit code, which is already 128 plus the signal number for
+// a job killed by a signal (such as by `jobber stop`) and 127 for a job that
+// never started, or timedOutExitCode for a job that timed out.
+func jobExitCode(exit *pb.ExitStatus) error {
+	code := int(exit.GetExitCode())
+	if exit.GetTimedOut() {
+		code = timedOutExitCode
+	}
+	if code == 0 {
+		return nil
 	}
+	return ExitCode(code)
+}
+
+// Validate validates the flags and the job spec given on the command line.
+// If a spec file is given, the spec is validated after reading the file
+// instead. It is called by kong after parsing the command line.
+func (cmd *CmdRun) Validate() error {
+	if cmd.Wait && (cmd.Detach || cmd.ExplainCgroup) {
+		return errors.New("--wait cannot be used with --detach or --explain-cgroup")
+	}
+	if cmd.SpecFile != "" {
+		return nil
+	}
+	return cmd.JobSpec.Validate()
+}
 
+// readSpecFile reads a JSON job spec from filename into spec. Fields in the
+// file replace those already in spec. The field names are those of
+// job.JobSpec, matched case-insensitively, and fields such as mounts and io
+// limits are strings in the same format as their command line flags:
+//
+//	{
+//	  "commands": [["/bin/make"], ["/bin/make", "install"]],
+//	  "root": "/srv/build",
+//	  "mounts": ["/home/eve/src:/src:ro"],
+//	  "resources": {"memory": 1073741824}
+//	}
+func readSpecFile(filename string, spec *job.JobSpec) error {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(spec); err != nil {
+		return fmt.Errorf("could not parse spec file %s: %w", filename, err)
+	}
 	return nil
 }
 
+// workspaceDir is where the current directory is mounted in a job with
+// `jobber run --bind-cwd`.
+const workspaceDir = "/workspace"
+
+// bindCwd sets up spec to run in the directory cwd. If the job has its own
+// root directory, cwd is bind mounted at workspaceDir within it, otherwise
+// cwd is already visible to the job and is used as is.
+func bindCwd(spec *job.JobSpec, cwd string) {
+	if spec.Root == "" {
+		spec.Workdir = cwd
+		return
+	}
+	spec.Mounts = append(spec.Mounts, job.BindMount{Source: cwd, Target: workspaceDir})
+	spec.Workdir = workspaceDir
+}
+
 // Run is the entrypoint for the `jobber stop` cli command. It packages the
 // command line arguments into a `StopRequest` message and calls the
 // `JobExecutor.Stop()` method.
@@ -166,6 +496,10 @@ func (cmd *CmdStop) Run() error {
 	}
 	defer cmd.Close()
 
+	if cmd.Command != "" {
+		return cmd.stopByCommand(cl)
+	}
+
 	req := pb.StopRequest{
 		JobId:   []byte(cmd.JobID),
 		Cleanup: cmd.Cleanup,
@@ -175,6 +509,160 @@ func (cmd *CmdStop) Run() error {
 	return err
 }
 
+// Validate checks that either a job ID or --command is given. It is called
+// by kong after parsing the command line.
+func (cmd *CmdStop) Validate() error {
+	switch {
+	case cmd.Command != "" && cmd.JobID != "":
+		return errors.New("a job ID cannot be given with --command")
+	case cmd.Command == "" && cmd.JobID == "":
+		return errors.New("a job ID or --command is required")
+	case cmd.All && cmd.Command == "":
+		return errors.New("--all can only be used with --command")
+	}
+	return nil
+}
+
+// stopByCommand stops each job with the command given by --command,
+// printing the ID of each job stopped. A job that fails to stop does not
+// prevent the rest from being stopped.
+func (cmd *CmdStop) stopByCommand(cl pb.JobExecutorClient) error {
+	ctx := context.Background()
+	listReq := pb.ListRequest{AllJobs: cmd.All, Completed: cmd.Cleanup, Command: cmd.Command}
+	resp, err := cl.List(ctx, &listReq)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, js := range resp.GetJobs() {
+		req := pb.StopRequest{JobId: js.GetJobId(), Cleanup: cmd.Cleanup}
+		if _, err := cl.Stop(ctx, &req); err != nil {
+			fmt.Fprintf(cmd.errWriter(), "could not stop %s: %v\n", js.GetJobId(), err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(cmd.writer(), "stopped %s\n", js.GetJobId())
+	}
+	if failed > 0 {
+		return fmt.Errorf("could not stop %d of %d jobs", failed, len(resp.GetJobs()))
+	}
+	return nil
+}
+
+// Run is the entrypoint for the `jobber cancel` cli command. It calls the
+// `JobExecutor.Cancel()` method for the job given, or for each of the
+// user's queued jobs with --all-mine.
+//
+// It is called by kong after parsing the command line.
+func (cmd *CmdCancel) Run() error {
+	cl, err := cmd.connect()
+	if err != nil {
+		return err
+	}
+	defer cmd.Close()
+
+	if cmd.AllMine {
+		return cmd.cancelAllMine(cl)
+	}
+	_, err = cl.Cancel(context.Background(), &pb.CancelRequest{JobId: []byte(cmd.JobID)})
+	return err
+}
+
+// Validate checks that either a job ID or --all-mine with --queued-only is
+// given. It is called by kong after parsing the command line.
+func (cmd *CmdCancel) Validate() error {
+	switch {
+	case cmd.AllMine && cmd.JobID != "":
+		return errors.New("a job ID cannot be given with --all-mine")
+	case !cmd.AllMine && cmd.JobID == "":
+		return errors.New("a job ID or --all-mine is required")
+	case cmd.AllMine && !cmd.QueuedOnly:
+		return errors.New("--all-mine requires --queued-only")
+	}
+	return nil
+}
+
+// cancelAllMine cancels each of the user's queued jobs, printing the ID of
+// each job cancelled. A job that starts before it is cancelled is left
+// running, and does not prevent the rest from being cancelled.
+func (cmd *CmdCancel) cancelAllMine(cl pb.JobExecutorClient) error {
+	ctx := context.Background()
+	resp, err := cl.List(ctx, &pb.ListRequest{})
+	if err != nil {
+		return err
+	}
+
+	queued, failed := 0, 0
+	for _, js := range resp.GetJobs() {
+		if js.GetState() != pb.JobStatus_JOBSTATE_QUEUED {
+			continue
+		}
+		queued++
+		if _, err := cl.Cancel(ctx, &pb.CancelRequest{JobId: js.GetJobId()}); err != nil {
+			fmt.Fprintf(cmd.errWriter(), "could not cancel %s: %v\n", js.GetJobId(), err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(cmd.writer(), "cancelled %s\n", js.GetJobId())
+	}
+	if failed > 0 {
+		return fmt.Errorf("could not cancel %d of %d jobs", failed, queued)
+	}
+	return nil
+}
+
+// Run is the entrypoint for the `jobber exec-sync` cli command. It packages
+// the command line arguments into a `RunSyncRequest` message and calls the
+// `JobExecutor.RunSync()` method, which returns once the job has completed.
+// The job's output is written to stdout and how it terminated to stderr.
+//
+// It is called by kong after parsing the command line.
+func (cmd *CmdExecSync) Run() error {
+	cl, err := cmd.connect()
+	if err != nil {
+		return err
+	}
+	defer cmd.Close()
+
+	// The job's --timeout is the RunSync timeout, which the server limits
+	// to its maximum and defaults to it if not given.
+	spec := cmd.JobSpec
+	spec.Timeout = 0
+	req := pb.RunSyncRequest{Spec: service.NewJobSpecPB(spec)}
+	if cmd.JobSpec.Timeout > 0 {
+		req.Timeout = durationpb.New(cmd.JobSpec.Timeout)
+	}
+	ctx := context.Background()
+	if cmd.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cmd.Deadline)
+		defer cancel()
+	}
+	resp, err := cl.RunSync(ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range resp.GetWarnings() {
+		fmt.Fprintln(cmd.errWriter(), "warning:", warning)
+	}
+	if _, err := cmd.writer().Write(resp.GetOutput()); err != nil {
+		return err
+	}
+	if resp.GetOutputTruncated() {
+		fmt.Fprintln(cmd.errWriter(), "warning: output truncated at the server's maximum size")
+	}
+	if resp.GetTimedOut() {
+		fmt.Fprintln(cmd.errWriter(), "job timed out")
+	}
+	if resp.GetDeadlineExceeded() {
+		fmt.Fprintln(cmd.errWriter(), "job stopped at the client deadline")
+	}
+	fmt.Fprintln(cmd.errWriter(), "job", resp.GetExit().GetReason())
+	return nil
+}
+
 // Run is the entrypoint for the `jobber status` cli command. It packages the
 // command line arguments into a `StatusRequest` message and calls the
 // `JobExecutor.Status()` method.
@@ -196,7 +684,152 @@ func (cmd *CmdStatus) Run() error {
 		return err
 	}
 
-	return printStatus(cmd.writer(), resp.GetStatus())
+	return statusFormatFor(cmd.Output)(cmd.writer(), cmd.Wide, cmd.now(), resp.GetStatus())
+}
+
+// Run is the entrypoint for the `jobber exists` cli command. It calls the
+// `JobExecutor.Exists()` method and outputs nothing, returning ExitCode 1
+// if the job does not exist so that scripts can test for it.
+//
+// It is called by kong after parsing the command line.
+func (cmd *CmdExists) Run() error {
+	cl, err := cmd.connect()
+	if err != nil {
+		return err
+	}
+	defer cmd.Close()
+
+	resp, err := cl.Exists(context.Background(), &pb.ExistsRequest{JobId: []byte(cmd.JobID)})
+	if err != nil {
+		return err
+	}
+	if !resp.GetExists() {
+		return ExitCode(1)
+	}
+	return nil
+}
+
+// Run is the entrypoint for the `jobber stats-history` cli command. It
+// calls the `JobExecutor.ResourceHistory()` method and prints each sample
+// of the job's resource usage on a line, oldest first.
+//
+// It is called by kong after parsing the command line.
+func (cmd *CmdStatsHistory) Run() error {
+	cl, err := cmd.connect()
+	if err != nil {
+		return err
+	}
+	defer cmd.Close()
+
+	req := pb.ResourceHistoryRequest{JobId: []byte(cmd.JobID)}
+	resp, err := cl.ResourceHistory(context.Background(), &req)
+	if err != nil {
+		return err
+	}
+
+	if n := resp.GetDropped(); n > 0 {
+		fmt.Fprintf(cmd.errWriter(), "%d earlier samples dropped\n", n)
+	}
+	tw := tabwriter.NewWriter(cmd.writer(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tMEM\tCPU TIME\tPROCS")
+	for _, s := range resp.GetSamples() {
+		cpu := time.Duration(s.GetCpuTimeUs()) * time.Microsecond
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", s.GetTime().AsTime().Format(time.Stamp),
+			formatBytes(s.GetMemoryBytes()), cpu.Round(time.Millisecond), s.GetProcesses())
+	}
+	return tw.Flush()
+}
+
+// Run is the entrypoint for the `jobber health` cli command. It calls the
+// standard gRPC `Health.Check()` method and prints the serving status,
+// returning ExitCode(1) if it is not SERVING so it can be used as a probe.
+//
+// It is called by kong after parsing the command line.
+func (cmd *CmdHealth) Run() error {
+	if _, err := cmd.connect(); err != nil {
+		return err
+	}
+	defer cmd.Close()
+
+	req := healthpb.HealthCheckRequest{Service: cmd.Service}
+	resp, err := healthpb.NewHealthClient(cmd.conn).Check(context.Background(), &req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.writer(), resp.GetStatus())
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return ExitCode(1)
+	}
+	return nil
+}
+
+// Run is the entrypoint for the `jobber inspect` cli command. It calls the
+// `JobExecutor.Status()` method and prints all of the job's status,
+// including its full spec, as JSON.
+//
+// It is called by kong after parsing the command line.
+func (cmd *CmdInspect) Run() error {
+	cl, err := cmd.connect()
+	if err != nil {
+		return err
+	}
+	defer cmd.Close()
+
+	req := pb.StatusRequest{
+		JobId: []byte(cmd.JobID),
+	}
+
+	resp, err := cl.Status(context.Background(), &req)
+	if err != nil {
+		return err
+	}
+
+	b, err := inspectJSON(resp.GetStatus())
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cmd.writer(), string(b))
+	return err
+}
+
+// jobInspect is the JSON output of `jobber inspect`.
+type jobInspect struct {
+	ID        string          `json:"id"`
+	User      string          `json:"user"`
+	State     string          `json:"state"`
+	StartTime time.Time       `json:"start_time"`
+	Pid       uint32          `json:"pid,omitempty"`
+	Exit      *exitInspect    `json:"exit,omitempty"`
+	Spec      json.RawMessage `json:"spec"`
+}
+
+type exitInspect struct {
+	Code   uint32 `json:"code"`
+	Signal uint32 `json:"signal,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// inspectJSON formats a job status as indented JSON. The job's spec is
+// included with all its fields, even those not set, so the output shows
+// exactly what the job was run with.
+func inspectJSON(status *pb.JobStatus) ([]byte, error) {
+	opts := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+	spec, err := opts.Marshal(status.GetSpec())
+	if err != nil {
+		return nil, err
+	}
+	ji := jobInspect{
+		ID:        string(status.GetJobId()),
+		User:      status.GetUser(),
+		State:     jobState(status.GetState()),
+		StartTime: status.GetStartTime().AsTime(),
+		Pid:       status.GetPid(),
+		Spec:      spec,
+	}
+	if exit := status.GetExit(); exit != nil {
+		ji.Exit = &exitInspect{Code: exit.GetExitCode(), Signal: exit.GetSignal(), Reason: exit.GetReason()}
+	}
+	return json.MarshalIndent(ji, "", "  ")
 }
 
 // Run is the entrypoint for the `jobber list` cli command. It packages the
@@ -211,13 +844,16 @@ func (cmd *CmdList) Run() error {
 	}
 	defer cmd.Close()
 
-	req := pb.ListRequest{AllJobs: cmd.All, Completed: cmd.Completed}
+	req := pb.ListRequest{AllJobs: cmd.All, Completed: cmd.Completed, Command: cmd.Command}
+	if cmd.RecentlyFailed > 0 {
+		req.RecentlyFailed = durationpb.New(cmd.RecentlyFailed)
+	}
 	resp, err := cl.List(context.Background(), &req)
 	if err != nil {
 		return err
 	}
 
-	return printStatus(cmd.writer(), resp.GetJobs()...)
+	return statusFormatFor(cmd.Output)(cmd.writer(), false /* wide */, cmd.now(), resp.GetJobs()...)
 }
 
 // Run is the entrypoint for the `jobber logs` cli command. It packages the
@@ -232,7 +868,124 @@ func (cmd *CmdLogs) Run() error {
 	}
 	defer cmd.Close()
 
-	return getLogs(cmd.writer(), cl, []byte(cmd.JobID), cmd.Follow, !cmd.NoTimestamps)
+	if cmd.AllMine {
+		return cmd.allMineLogs(cl)
+	}
+
+	format := plainFormat(timestampFormat(!cmd.NoTimestamps, cmd.TSPrecision))
+	if cmd.Format == "syslog" {
+		// The job's owner is included in each syslog message.
+		req := pb.StatusRequest{JobId: []byte(cmd.JobID)}
+		resp, err := cl.Status(context.Background(), &req)
+		if err != nil {
+			return err
+		}
+		host, _, err := net.SplitHostPort(cmd.Address)
+		if err != nil {
+			host = cmd.Address
+		}
+		format = syslogFormat(host, cmd.JobID, resp.GetStatus().GetUser())
+	}
+	if f, ok := logFormats[cmd.Output]; ok {
+		format = f
+	}
+	if cmd.Resume {
+		return cmd.resumeLogs(cl, format)
+	}
+	req := pb.LogsRequest{JobId: []byte(cmd.JobID), Follow: cmd.Follow, TailLines: cmd.Tail}
+	if !cmd.FromTime.IsZero() {
+		req.FromTime = timestamppb.New(cmd.FromTime)
+	}
+	if !cmd.ToTime.IsZero() {
+		req.ToTime = timestamppb.New(cmd.ToTime)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	_, _, err = streamLogs(ctx, selectStreams(cmd.writer(), cmd.Stream), cl, &req, cmd.LineNumbers, format)
+	if ctx.Err() != nil {
+		// Cancelling the stream detaches from the job's logs on the
+		// server at once, rather than when it notices we have gone.
+		fmt.Fprintln(cmd.errWriter(), "detached from job", cmd.JobID)
+		return nil
+	}
+	return err
+}
+
+// Validate checks that either a job ID or --all-mine is given. It is called
+// by kong after parsing the command line.
+func (cmd *CmdLogs) Validate() error {
+	switch {
+	case cmd.AllMine && cmd.JobID != "":
+		return errors.New("a job ID cannot be given with --all-mine")
+	case cmd.AllMine && cmd.Resume:
+		return errors.New("--resume cannot be used with --all-mine")
+	case !cmd.AllMine && cmd.JobID == "":
+		return errors.New("a job ID or --all-mine is required")
+	case cmd.LineNumbers && cmd.AllMine:
+		return errors.New("--line-numbers cannot be used with --all-mine")
+	case cmd.LineNumbers && cmd.Format == "syslog":
+		return errors.New("--line-numbers cannot be used with --format syslog")
+	case logFormats[cmd.Output] != nil && cmd.AllMine:
+		return fmt.Errorf("--output %s cannot be used with --all-mine", cmd.Output)
+	case logFormats[cmd.Output] != nil && cmd.Format == "syslog":
+		return fmt.Errorf("--output %s cannot be used with --format syslog", cmd.Output)
+	case logFormats[cmd.Output] != nil && cmd.LineNumbers:
+		return fmt.Errorf("--output %s cannot be used with --line-numbers; lines include their index", cmd.Output)
+	case cmd.Tail > 0 && cmd.AllMine:
+		return errors.New("--tail cannot be used with --all-mine")
+	case cmd.Tail > 0 && cmd.Resume:
+		return errors.New("--tail cannot be used with --resume")
+	case cmd.Tail > 0 && (!cmd.FromTime.IsZero() || !cmd.ToTime.IsZero()):
+		return errors.New("--tail cannot be used with --from-time or --to-time")
+	}
+	if !cmd.FromTime.IsZero() || !cmd.ToTime.IsZero() {
+		switch {
+		case cmd.Follow:
+			return errors.New("--from-time and --to-time cannot be used with --follow")
+		case cmd.AllMine:
+			return errors.New("--from-time and --to-time cannot be used with --all-mine")
+		case cmd.Resume:
+			return errors.New("--from-time and --to-time cannot be used with --resume")
+		case !cmd.FromTime.IsZero() && !cmd.ToTime.IsZero() && cmd.ToTime.Before(cmd.FromTime):
+			return errors.New("--to-time cannot be before --from-time")
+		}
+	}
+	return nil
+}
+
+// resumeLogs streams the job's logs starting after the last line shown by
+// a previous resume, and records the position it stops at. Interrupting it
+// is not an error, as that is how a follow is detached from.
+func (cmd *CmdLogs) resumeLogs(cl pb.JobExecutorClient, format logFormat) error {
+	filename := cmd.PositionFile
+	if filename == "" {
+		var err error
+		if filename, err = defaultPositionFile(); err != nil {
+			return err
+		}
+	}
+	store := positionStore{filename: filename}
+	start, err := store.get(cmd.Address, cmd.JobID)
+	if err != nil {
+		return fmt.Errorf("could not read log position: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	req := pb.LogsRequest{JobId: []byte(cmd.JobID), Follow: cmd.Follow, StartLine: start}
+	_, next, err := streamLogs(ctx, selectStreams(cmd.writer(), cmd.Stream), cl, &req, cmd.LineNumbers, format)
+	if ctx.Err() != nil {
+		err = nil
+	}
+	// The position is left as it is if no line was received, such as
+	// when the server could not be reached.
+	if next == start {
+		return err
+	}
+	if saveErr := store.set(cmd.Address, cmd.JobID, next); saveErr != nil && err == nil {
+		err = fmt.Errorf("could not save log position: %w", saveErr)
+	}
+	return err
 }
 
 func (cmd *CmdShutdown) Run() error {
@@ -250,11 +1003,154 @@ func (cmd *CmdShutdown) Run() error {
 	return nil
 }
 
+// Run is the entrypoint for the `jobber drain` cli command. It asks the
+// server to drain and shows the jobs remaining, and with --wait, polls the
+// progress of the drain until the server is drained or interrupted.
+//
+// It is called by kong after parsing the command line.
+func (cmd *CmdDrain) Run() error {
+	cl, err := cmd.connect()
+	if err != nil {
+		return err
+	}
+	defer cmd.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	resp, err := cl.Drain(ctx, &pb.DrainRequest{})
+	if err != nil {
+		return err
+	}
+	w := cmd.writer()
+	progress := resp.GetProgress()
+	printDrainProgress(w, progress)
+	if !cmd.Wait {
+		return nil
+	}
+
+	ticker := time.NewTicker(cmd.PollInterval)
+	defer ticker.Stop()
+	for !progress.GetDrained() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+		resp, err := cl.DrainStatus(ctx, &pb.DrainStatusRequest{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		// Only show progress as it changes.
+		if !proto.Equal(resp.GetProgress(), progress) {
+			printDrainProgress(w, resp.GetProgress())
+		}
+		progress = resp.GetProgress()
+	}
+	return nil
+}
+
+// Run is the entrypoint for the `jobber drain-status` cli command. It shows
+// the progress of draining the server.
+//
+// It is called by kong after parsing the command line.
+func (cmd *CmdDrainStatus) Run() error {
+	cl, err := cmd.connect()
+	if err != nil {
+		return err
+	}
+	defer cmd.Close()
+
+	resp, err := cl.DrainStatus(context.Background(), &pb.DrainStatusRequest{})
+	if err != nil {
+		return err
+	}
+	printDrainProgress(cmd.writer(), resp.GetProgress())
+	return nil
+}
+
+// printDrainProgress writes a line describing the progress of draining a
+// server to w.
+func printDrainProgress(w io.Writer, p *pb.DrainProgress) {
+	switch {
+	case p.GetDrained():
+		fmt.Fprintln(w, "drained: no jobs remaining, ready to shut down")
+	case p.GetDraining():
+		fmt.Fprintf(w, "draining: %d running and %d queued jobs remaining\n", p.GetRunningJobs(), p.GetQueuedJobs())
+	default:
+		fmt.Fprintf(w, "not draining: %d running and %d queued jobs\n", p.GetRunningJobs(), p.GetQueuedJobs())
+	}
+}
+
+// Run is the entrypoint for the `jobber chown` cli command. It packages the
+// command line arguments into a `ChownRequest` message and calls the
+// `JobExecutor.Chown()` method.
+//
+// It is called by kong after parsing the command line.
+func (cmd *CmdChown) Run() error {
+	cl, err := cmd.connect()
+	if err != nil {
+		return err
+	}
+	defer cmd.Close()
+
+	req := pb.ChownRequest{JobId: []byte(cmd.JobID), Owner: cmd.Owner}
+	resp, err := cl.Chown(context.Background(), &req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.writer(), "%s: owner changed from %q to %q\n", cmd.JobID, resp.GetPreviousOwner(), cmd.Owner)
+	return nil
+}
+
+// Run is the entrypoint for the `jobber debug feeder` cli command. It
+// packages the command line arguments into a `DebugFeederRequest` message
+// and calls the `JobExecutor.DebugFeeder()` method.
+//
+// It is called by kong after parsing the command line.
+func (cmd *CmdDebugFeeder) Run() error {
+	cl, err := cmd.connect()
+	if err != nil {
+		return err
+	}
+	defer cmd.Close()
+
+	req := pb.DebugFeederRequest{JobId: []byte(cmd.JobID)}
+	resp, err := cl.DebugFeeder(context.Background(), &req)
+	if err != nil {
+		return err
+	}
+
+	infeed := "open"
+	if resp.GetInfeedClosed() {
+		infeed = "closed"
+	}
+	w := cmd.writer()
+	fmt.Fprintf(w, "buffered lines: %d\ninfeed: %s\n", resp.GetBufferLen(), infeed)
+	if n := resp.GetDropped(); n > 0 {
+		fmt.Fprintf(w, "dropped lines: %d\n", n)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OUTFEED\tPOSITION\tLAG\tFOLLOW\tWAITING")
+	for i, o := range resp.GetOutfeeds() {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%t\t%t\n", i, o.GetPosition(), o.GetLag(), o.GetFollow(), o.GetWaiting())
+	}
+	return tw.Flush()
+}
+
 // printStatus formats the JobStatuses passed to it and writes them to the
-// given io.Writer. It writes one job status per line, with a header.
-func printStatus(w io.Writer, statuses ...*pb.JobStatus) error {
+// given io.Writer. It writes one job status per line, with a header. The
+// duration of a running job is how long it has been running until now.
+func printStatus(w io.Writer, wide bool, now time.Time, statuses ...*pb.JobStatus) error {
 	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(tw, "JOB ID\tSTART TIME\tUSER\tSTATUS")
+	fmt.Fprint(tw, "JOB ID\tSTART TIME\tUSER\tSTATUS\tDURATION")
+	if wide {
+		fmt.Fprint(tw, "\tEND TIME\tPID\tPEAK PROCS\tMEM\tPEAK MEM\tCPU TIME\tCLIENT")
+	}
+	fmt.Fprintln(tw)
 
 	for _, status := range statuses {
 		state := "unknown"
@@ -263,46 +1159,288 @@ func printStatus(w io.Writer, statuses ...*pb.JobStatus) error {
 			state = "running"
 		case pb.JobStatus_JOBSTATE_COMPLETED:
 			state = fmt.Sprintf("exited (%d)", status.GetExitCode())
+			if status.GetExit().GetTimedOut() {
+				state = "timed out"
+			}
+		case pb.JobStatus_JOBSTATE_QUEUED:
+			state = "queued"
+		case pb.JobStatus_JOBSTATE_CANCELLED:
+			state = "cancelled"
 		}
 
 		ts := status.GetStartTime().AsTime().Format(time.Stamp)
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", status.GetJobId(), ts, status.GetUser(), state)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s", status.GetJobId(), ts, status.GetUser(), state, jobDuration(status, now))
+		if wide {
+			end := "-"
+			if status.CompletionTime != nil {
+				end = status.GetCompletionTime().AsTime().Format(time.Stamp)
+			}
+			peak := "-"
+			if n := status.GetPeakProcesses(); n > 0 {
+				peak = strconv.FormatUint(uint64(n), 10)
+			}
+			cpu := time.Duration(status.GetCpuTimeUs()) * time.Microsecond
+			client := "-"
+			if status.GetClient() != "" {
+				client = status.GetClient()
+			}
+			fmt.Fprintf(tw, "\t%s\t%d\t%s\t%s\t%s\t%s\t%s", end, status.GetPid(), peak,
+				formatBytes(status.GetMemoryBytes()), formatBytes(status.GetPeakMemoryBytes()), cpu.Round(time.Millisecond), client)
+		}
+		fmt.Fprintln(tw)
 	}
 	return tw.Flush()
 }
 
-// getLogs performs a `JobExecutor.Logs()` method call for a job and writes
-// the logs streamed back to the given io.Writer. If follow is true, it will
-// continue to stream logs while the job continues to run. If showTimestamp
-// is true the log timestamp is printed before each log line.
-func getLogs(w io.Writer, cl pb.JobExecutorClient, id []byte, follow bool, showTimestamp bool) error {
-	logsReq := pb.LogsRequest{
-		JobId:  id,
-		Follow: follow,
+// formatBytes formats a number of bytes with a binary unit, such as 1.5Mi,
+// or as "-" if it is zero, as it is when not known.
+func formatBytes(n uint64) string {
+	if n == 0 {
+		return "-"
+	}
+	units := []string{"", "Ki", "Mi", "Gi", "Ti"}
+	f, i := float64(n), 0
+	for f >= 1024 && i < len(units)-1 {
+		f /= 1024
+		i++
+	}
+	if i == 0 {
+		return strconv.FormatUint(n, 10)
+	}
+	return strconv.FormatFloat(f, 'f', 1, 64) + units[i]
+}
+
+// jobDuration returns how long a completed job ran for, or how long a
+// running job has been running until now, to the second. It is "-" for a
+// queued job, or a completed job without a completion time.
+func jobDuration(status *pb.JobStatus, now time.Time) string {
+	d, ok := jobRunTime(status, now)
+	if !ok {
+		return "-"
+	}
+	return d.String()
+}
+
+// jobRunTime returns the duration of a job as for jobDuration, and false
+// if it does not have one.
+func jobRunTime(status *pb.JobStatus, now time.Time) (time.Duration, bool) {
+	var end time.Time
+	switch {
+	case status.GetState() == pb.JobStatus_JOBSTATE_RUNNING:
+		end = now
+	case status.GetState() == pb.JobStatus_JOBSTATE_COMPLETED && status.CompletionTime != nil:
+		end = status.GetCompletionTime().AsTime()
+	default:
+		return 0, false
+	}
+	return end.Sub(status.GetStartTime().AsTime()).Round(time.Second), true
+}
+
+// jobState returns a job state as a lowercase word.
+func jobState(state pb.JobStatus_JobState) string {
+	switch state {
+	case pb.JobStatus_JOBSTATE_RUNNING:
+		return "running"
+	case pb.JobStatus_JOBSTATE_COMPLETED:
+		return "completed"
+	case pb.JobStatus_JOBSTATE_QUEUED:
+		return "queued"
+	case pb.JobStatus_JOBSTATE_CANCELLED:
+		return "cancelled"
+	}
+	return "unknown"
+}
+
+// timestampFormat returns the time layout for printing log timestamps with
+// the given precision (s, ms, us or ns). An empty layout is returned if
+// timestamps are not to be shown. Sub-second digits are not trimmed so that
+// timestamps line up in the output.
+func timestampFormat(show bool, precision string) string {
+	if !show {
+		return ""
+	}
+	switch precision {
+	case "s":
+		return time.RFC3339
+	case "ms":
+		return "2006-01-02T15:04:05.000Z07:00"
+	case "us":
+		return "2006-01-02T15:04:05.000000Z07:00"
+	default:
+		return "2006-01-02T15:04:05.000000000Z07:00"
+	}
+}
+
+// logLine is a line of a job's output as given to a logFormat. A line
+// saying how many earlier lines were dropped from the server's log buffer
+// has dropped set, and no index.
+type logLine struct {
+	index     int64
+	timestamp time.Time
+	stream    pb.Stream
+	line      []byte
+	dropped   int64
+}
+
+// logFormat writes a log line to w.
+type logFormat func(w io.Writer, l logLine)
+
+// lineJoiner joins the chunks of lines longer than the server's maximum
+// line size, which it sends as partial lines, so that each line is
+// formatted whole. The chunks of a line on one stream may be interleaved
+// with lines on the other.
+type lineJoiner struct {
+	// pending are the lines of each stream whose last chunk has not
+	// arrived yet.
+	pending map[pb.Stream]logLine
+}
+
+// join returns the line that chunk l completes and true, or false if l is
+// a partial chunk, in which case it is held until the rest of its line
+// arrives. A joined line has the index and timestamp of its first chunk.
+func (j *lineJoiner) join(l logLine, partial bool) (logLine, bool) {
+	if p, ok := j.pending[l.stream]; ok {
+		p.line = append(p.line, l.line...)
+		l = p
 	}
-	stream, err := cl.Logs(context.Background(), &logsReq)
+	if partial {
+		if j.pending == nil {
+			j.pending = make(map[pb.Stream]logLine)
+		}
+		j.pending[l.stream] = l
+		return logLine{}, false
+	}
+	delete(j.pending, l.stream)
+	return l, true
+}
+
+// flush returns the lines held whose last chunk has not arrived, stdout
+// first, and forgets them.
+func (j *lineJoiner) flush() []logLine {
+	var lines []logLine
+	for _, stream := range []pb.Stream{pb.Stream_STREAM_STDOUT, pb.Stream_STREAM_STDERR} {
+		if l, ok := j.pending[stream]; ok {
+			lines = append(lines, l)
+		}
+	}
+	j.pending = nil
+	return lines
+}
+
+// plainFormat returns a logFormat that writes log lines as they are. If
+// tsFormat is not empty, the log timestamp is formatted with it and printed
+// before each log line.
+func plainFormat(tsFormat string) logFormat {
+	return func(w io.Writer, l logLine) {
+		line := l.line
+		showTimestamp := tsFormat != ""
+		if showTimestamp {
+			fmt.Fprint(w, l.timestamp.Format(tsFormat), " ")
+		}
+		fmt.Fprint(w, string(line))
+		if l := len(line); showTimestamp && l > 0 && line[l-1] != '\n' {
+			// Add a newline on lines without a newline only if we are
+			// prefixing timestamps.
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// logOutput is where the lines of each of a job's output streams are
+// written. The lines of a stream without a writer are skipped.
+type logOutput struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// selectStreams returns a logOutput that writes the lines of the streams
+// selected by stream (stdout, stderr or both) to w.
+func selectStreams(w io.Writer, stream string) logOutput {
+	var out logOutput
+	if stream != "stderr" {
+		out.stdout = w
+	}
+	if stream != "stdout" {
+		out.stderr = w
+	}
+	return out
+}
+
+// writer returns the writer for the lines of stream, or nil if they are
+// skipped.
+func (o logOutput) writer(stream pb.Stream) io.Writer {
+	if stream == pb.Stream_STREAM_STDERR {
+		return o.stderr
+	}
+	return o.stdout
+}
+
+// streamLogs performs the `JobExecutor.Logs()` method call req and writes
+// the logs streamed back to out in the given format, prefixed with their
+// index if lineNumbers is true. A followed stream continues while the job
+// runs or until ctx is cancelled. It returns the job's exit status if it
+// has completed and the index after the last line received, including any
+// skipped as their stream is not written, or req's start line if no line
+// was received. The index is returned even if there is an error, so the
+// caller can resume streaming after the last line received.
+func streamLogs(ctx context.Context, out logOutput, cl pb.JobExecutorClient, req *pb.LogsRequest, lineNumbers bool, format logFormat) (*pb.ExitStatus, int64, error) {
+	stream, err := cl.Logs(ctx, req)
 	if err != nil {
-		return err
+		return nil, req.GetStartLine(), err
 	}
 
+	var exit *pb.ExitStatus
+	next := req.GetStartLine()
+	var joiner lineJoiner
+	write := func(l logLine) {
+		w := out.writer(l.stream)
+		if lineNumbers {
+			fmt.Fprintf(w, "%d ", l.index)
+		}
+		format(w, l)
+	}
+	// Lines cut short by the end of the stream are written as far as
+	// they got.
+	flush := func() {
+		for _, l := range joiner.flush() {
+			write(l)
+		}
+	}
 	for {
 		resp, err := stream.Recv()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return err
+			flush()
+			return nil, next, err
 		}
-		if showTimestamp {
-			fmt.Print(resp.Timestamp.AsTime().Format(time.RFC3339), " ")
+		if resp.Exit != nil {
+			exit = resp.Exit
+			continue
 		}
-		fmt.Fprint(w, string(resp.Line))
-		if l := len(resp.Line); showTimestamp && l > 0 && resp.Line[l-1] != '\n' {
-			// Add a newline on lines without a newline only if we are
-			// prefixing timestamps.
-			fmt.Fprintln(w)
+		// Lines dropped from the log buffer are skipped, so the index
+		// of the next line is not the number of lines received.
+		next = resp.GetIndex() + 1
+		w := out.writer(resp.GetStream())
+		if w == nil {
+			continue
+		}
+		l := logLine{timestamp: resp.Timestamp.AsTime(), stream: resp.GetStream()}
+		if n := resp.GetDropped(); n > 0 {
+			// The rest of a line being joined may have been dropped.
+			flush()
+			marker := l
+			marker.line = []byte(fmt.Sprintf("[jobber] %d earlier lines dropped from the log buffer\n", n))
+			marker.dropped = n
+			format(w, marker)
+		}
+		l.index, l.line = resp.GetIndex(), resp.Line
+		if l, ok := joiner.join(l, resp.GetPartial()); ok {
+			write(l)
 		}
 	}
 
-	return nil
+	flush()
+	return exit, next, nil
 }
@@ -2,14 +2,25 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/camh-/jobber/job"
+	pb "github.com/camh-/jobber/pb"
 	"github.com/camh-/jobber/service"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func newClientCmd(address string, output io.Writer) clientCmd {
@@ -19,6 +30,8 @@ func newClientCmd(address string, output io.Writer) clientCmd {
 		TLSCert: "testdata/user.crt",
 		TLSKey:  "testdata/user.key",
 		CACert:  "testdata/ca.crt",
+		// Two minutes after the first fake job started.
+		clock: func() time.Time { return time.Unix(1653654364, 0) },
 	}
 }
 func TestClientAgainstFakeService(t *testing.T) {
@@ -37,23 +50,25 @@ func TestClientAgainstFakeService(t *testing.T) {
 	defer grpcServer.Stop()
 
 	t.Run("run greeting", func(t *testing.T) {
-		w := &bytes.Buffer{}
+		w, errw := &bytes.Buffer{}, &bytes.Buffer{}
 		cmd := CmdRun{
 			clientCmd:    newClientCmd(address, w),
 			NoTimestamps: true,
 			JobSpec:      job.JobSpec{Command: "greeting"},
 		}
+		cmd.errOutput = errw
 		err := cmd.Run()
 		require.NoError(t, err)
+		// The job's stderr line goes to stderr.
 		expected := `job id: greeting-01234567
 Hello world
-Goodbye world
 `
 		require.Equal(t, expected, w.String())
+		require.Equal(t, "Goodbye world\n", errw.String())
 	})
 
 	t.Run("run jack beanstalk", func(t *testing.T) {
-		w := &bytes.Buffer{}
+		w, errw := &bytes.Buffer{}, &bytes.Buffer{}
 		cmd := CmdRun{
 			clientCmd:    newClientCmd(address, w),
 			NoTimestamps: true,
@@ -62,6 +77,7 @@ Goodbye world
 				Args:    []string{"beanstalk"},
 			},
 		}
+		cmd.errOutput = errw
 		err := cmd.Run()
 		require.NoError(t, err)
 		expected := `job id: jack-01234568
@@ -71,6 +87,63 @@ fo
 fum
 `
 		require.Equal(t, expected, w.String())
+		require.Equal(t, "job exited with code 1\n", errw.String())
+	})
+
+	t.Run("run --wait jack beanstalk", func(t *testing.T) {
+		w, errw := &bytes.Buffer{}, &bytes.Buffer{}
+		cmd := CmdRun{
+			clientCmd:    newClientCmd(address, w),
+			NoTimestamps: true,
+			Wait:         true,
+			JobSpec: job.JobSpec{
+				Command: "jack",
+				Args:    []string{"beanstalk"},
+			},
+		}
+		cmd.errOutput = errw
+		require.NoError(t, cmd.Validate())
+		err := cmd.Run()
+		require.Equal(t, ExitCode(1), err)
+		require.Equal(t, "job exited with code 1\n", errw.String())
+
+		cmd.Detach = true
+		require.Error(t, cmd.Validate())
+	})
+
+	t.Run("run greedy warnings", func(t *testing.T) {
+		w, errw := &bytes.Buffer{}, &bytes.Buffer{}
+		cmd := CmdRun{
+			clientCmd: newClientCmd(address, w),
+			Detach:    true,
+			JobSpec:   job.JobSpec{Command: "greedy"},
+		}
+		cmd.errOutput = errw
+		err := cmd.Run()
+		require.NoError(t, err)
+		require.Equal(t, "job id: greedy-0123456a\n", w.String())
+		expected := "warning: cpu limit of 64000 mCPU exceeds the 8 CPUs available; clamped to 8000 mCPU\n"
+		require.Equal(t, expected, errw.String())
+	})
+
+	t.Run("run explain cgroup", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdRun{
+			clientCmd:     newClientCmd(address, w),
+			ExplainCgroup: true,
+			JobSpec: job.JobSpec{
+				Command: "greeting",
+				Resources: job.ResourceLimits{
+					MaxProcesses: 100,
+					Memory:       1 << 20,
+					CPU:          250,
+				},
+			},
+		}
+		err := cmd.Run()
+		require.NoError(t, err)
+		expected := "pids.max=100\nmemory.max=1048576\ncpu.max=250000 1000000\n"
+		require.Equal(t, expected, w.String())
 	})
 
 	t.Run("run invalid-command", func(t *testing.T) {
@@ -83,6 +156,30 @@ fum
 		require.Error(t, err)
 	})
 
+	t.Run("exec-sync greeting", func(t *testing.T) {
+		w, errw := &bytes.Buffer{}, &bytes.Buffer{}
+		cmd := CmdExecSync{
+			clientCmd: newClientCmd(address, w),
+			JobSpec:   job.JobSpec{Command: "greeting"},
+		}
+		cmd.errOutput = errw
+		require.NoError(t, cmd.Run())
+		require.Equal(t, "Hello world\nGoodbye world\n", w.String())
+		require.Equal(t, "job exited with code 0\n", errw.String())
+	})
+
+	t.Run("exec-sync jack beanstalk", func(t *testing.T) {
+		w, errw := &bytes.Buffer{}, &bytes.Buffer{}
+		cmd := CmdExecSync{
+			clientCmd: newClientCmd(address, w),
+			JobSpec:   job.JobSpec{Command: "jack", Args: []string{"beanstalk"}},
+		}
+		cmd.errOutput = errw
+		require.NoError(t, cmd.Run())
+		require.Equal(t, "fee\nfi\nfo\nfum\n", w.String())
+		require.Equal(t, "job exited with code 1\n", errw.String())
+	})
+
 	t.Run("stop greeting-01234567", func(t *testing.T) {
 		cmd := CmdStop{
 			clientCmd: newClientCmd(address, io.Discard),
@@ -101,6 +198,64 @@ fum
 		require.Error(t, err)
 	})
 
+	t.Run("stop by command", func(t *testing.T) {
+		tests := map[string]struct {
+			cmd      CmdStop
+			expected string
+		}{
+			"own jobs":       {cmd: CmdStop{Command: "/usr/bin/red"}},
+			"all users":      {cmd: CmdStop{Command: "/usr/bin/red", All: true}, expected: "stopped red-01234569\n"},
+			"completed":      {cmd: CmdStop{Command: "jack", All: true}},
+			"cleanup":        {cmd: CmdStop{Command: "jack", All: true, Cleanup: true}, expected: "stopped jack-01234568\n"},
+			"not exact":      {cmd: CmdStop{Command: "red", All: true}},
+			"other commands": {cmd: CmdStop{Command: "/usr/bin/blue", All: true}},
+		}
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				w := &bytes.Buffer{}
+				cmd := tc.cmd
+				cmd.clientCmd = newClientCmd(address, w)
+				require.NoError(t, cmd.Validate())
+				require.NoError(t, cmd.Run())
+				require.Equal(t, tc.expected, w.String())
+			})
+		}
+	})
+
+	t.Run("stop validation", func(t *testing.T) {
+		require.Error(t, (&CmdStop{}).Validate())
+		require.Error(t, (&CmdStop{JobID: "red-01234569", Command: "/usr/bin/red"}).Validate())
+		require.Error(t, (&CmdStop{JobID: "red-01234569", All: true}).Validate())
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		tests := map[string]codes.Code{
+			"greeting-01234567": codes.FailedPrecondition, // running
+			"invalid-job-id":    codes.NotFound,
+		}
+		for id, expected := range tests {
+			cmd := CmdCancel{clientCmd: newClientCmd(address, io.Discard), JobID: id}
+			require.NoError(t, cmd.Validate())
+			err := cmd.Run()
+			require.Equal(t, expected, status.Code(err), id)
+		}
+	})
+
+	t.Run("cancel all mine", func(t *testing.T) {
+		// None of the fake jobs are queued, so there is nothing to cancel.
+		w := &bytes.Buffer{}
+		cmd := CmdCancel{clientCmd: newClientCmd(address, w), AllMine: true, QueuedOnly: true}
+		require.NoError(t, cmd.Validate())
+		require.NoError(t, cmd.Run())
+		require.Empty(t, w.String())
+	})
+
+	t.Run("cancel validation", func(t *testing.T) {
+		require.Error(t, (&CmdCancel{}).Validate())
+		require.Error(t, (&CmdCancel{AllMine: true}).Validate())
+		require.Error(t, (&CmdCancel{AllMine: true, QueuedOnly: true, JobID: "greeting-01234567"}).Validate())
+	})
+
 	t.Run("status greeting-01234567", func(t *testing.T) {
 		w := &bytes.Buffer{}
 		cmd := CmdStatus{
@@ -109,8 +264,23 @@ fum
 		}
 		err := cmd.Run()
 		require.NoError(t, err)
-		expected := `JOB ID             START TIME       USER  STATUS
-greeting-01234567  May 27 12:24:04  eve   running
+		expected := `JOB ID             START TIME       USER  STATUS   DURATION
+greeting-01234567  May 27 12:24:04  eve   running  2m0s
+`
+		require.Equal(t, expected, w.String())
+	})
+
+	t.Run("status wide", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdStatus{
+			clientCmd: newClientCmd(address, w),
+			Wide:      true,
+			JobID:     "jack-01234568",
+		}
+		err := cmd.Run()
+		require.NoError(t, err)
+		expected := `JOB ID         START TIME       USER     STATUS      DURATION  END TIME         PID   PEAK PROCS  MEM  PEAK MEM  CPU TIME  CLIENT
+jack-01234568  May 27 12:24:05  mallory  exited (1)  1m0s      May 27 12:25:05  4242  3           -    768.0Ki   1.5s      192.0.2.7:51234
 `
 		require.Equal(t, expected, w.String())
 	})
@@ -121,7 +291,90 @@ greeting-01234567  May 27 12:24:04  eve   running
 			JobID:     "invalid-job-id",
 		}
 		err := cmd.Run()
-		require.Error(t, err)
+		require.EqualError(t, err, "invalid-job-id: unknown job")
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		tests := map[string]error{
+			"greeting-01234567": nil,         // owned by eve
+			"jack-01234568":     ExitCode(1), // owned by mallory
+			"invalid-job-id":    ExitCode(1),
+		}
+		for id, expected := range tests {
+			w := &bytes.Buffer{}
+			cmd := CmdExists{clientCmd: newClientCmd(address, w), JobID: id}
+			err := cmd.Run()
+			require.Equal(t, expected, err, id)
+			require.Empty(t, w.String())
+		}
+	})
+
+	t.Run("stats-history jack-01234568", func(t *testing.T) {
+		w, errw := &bytes.Buffer{}, &bytes.Buffer{}
+		cmd := CmdStatsHistory{clientCmd: newClientCmd(address, w), JobID: "jack-01234568"}
+		cmd.errOutput = errw
+		err := cmd.Run()
+		require.NoError(t, err)
+		expected := `TIME             MEM      CPU TIME  PROCS
+May 27 12:24:15  256.0Ki  200ms     1
+May 27 12:24:25  768.0Ki  900ms     3
+May 27 12:24:35  512.0Ki  1.5s      2
+`
+		require.Equal(t, expected, w.String())
+		require.Equal(t, "1 earlier samples dropped\n", errw.String())
+	})
+
+	t.Run("stats-history not sampled", func(t *testing.T) {
+		cmd := CmdStatsHistory{clientCmd: newClientCmd(address, io.Discard), JobID: "greeting-01234567"}
+		err := cmd.Run()
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("inspect jack-01234568", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdInspect{
+			clientCmd: newClientCmd(address, w),
+			Output:    "json",
+			JobID:     "jack-01234568",
+		}
+		err := cmd.Run()
+		require.NoError(t, err)
+
+		var got map[string]any
+		require.NoError(t, json.Unmarshal(w.Bytes(), &got))
+		require.Equal(t, "jack-01234568", got["id"])
+		require.Equal(t, "mallory", got["user"])
+		require.Equal(t, "completed", got["state"])
+		require.Equal(t, "2022-05-27T12:24:05Z", got["start_time"])
+		require.Equal(t, float64(4242), got["pid"])
+		require.Equal(t, map[string]any{"code": float64(1), "reason": "exited with code 1"}, got["exit"])
+
+		spec := got["spec"].(map[string]any)
+		require.Equal(t, "jack", spec["command"])
+		require.Equal(t, []any{"beanstalk"}, spec["arguments"])
+		require.Equal(t, "/srv/giant", spec["root_dir"])
+		require.Equal(t, float64(5), spec["nice"])
+		require.Equal(t, false, spec["isolate_network"]) // unset fields are included
+		resources := spec["resources"].(map[string]any)
+		require.Equal(t, float64(500), resources["milli_cpu"])
+		require.Equal(t, "1048576", resources["memory"]) // 64-bit ints are strings in protojson
+	})
+
+	t.Run("logs page", func(t *testing.T) {
+		cmd := newClientCmd(address, io.Discard)
+		cl, err := cmd.connect()
+		require.NoError(t, err)
+		defer cmd.Close()
+
+		req := pb.LogsPageRequest{JobId: []byte("jack-01234568"), StartLine: 1, EndLine: 3}
+		page, err := cl.LogsPage(context.Background(), &req)
+		require.NoError(t, err)
+		require.Equal(t, int64(4), page.GetTotalLines())
+		require.Len(t, page.GetLines(), 2)
+		require.Equal(t, "fi\n", string(page.GetLines()[0].GetLine()))
+		require.Equal(t, "fo\n", string(page.GetLines()[1].GetLine()))
+		require.Equal(t, int64(3), page.GetEndLine())
 	})
 
 	t.Run("list", func(t *testing.T) {
@@ -131,8 +384,8 @@ greeting-01234567  May 27 12:24:04  eve   running
 		}
 		err := cmd.Run()
 		require.NoError(t, err)
-		expected := `JOB ID             START TIME       USER  STATUS
-greeting-01234567  May 27 12:24:04  eve   running
+		expected := `JOB ID             START TIME       USER  STATUS   DURATION
+greeting-01234567  May 27 12:24:04  eve   running  2m0s
 `
 		require.Equal(t, expected, w.String())
 	})
@@ -145,9 +398,9 @@ greeting-01234567  May 27 12:24:04  eve   running
 		}
 		err := cmd.Run()
 		require.NoError(t, err)
-		expected := `JOB ID             START TIME       USER     STATUS
-greeting-01234567  May 27 12:24:04  eve      running
-red-01234569       May 27 12:24:06  mallory  running
+		expected := `JOB ID             START TIME       USER     STATUS   DURATION
+greeting-01234567  May 27 12:24:04  eve      running  2m0s
+red-01234569       May 27 12:24:06  mallory  running  1m58s
 `
 		require.Equal(t, expected, w.String())
 	})
@@ -161,10 +414,103 @@ red-01234569       May 27 12:24:06  mallory  running
 		}
 		err := cmd.Run()
 		require.NoError(t, err)
-		expected := `JOB ID             START TIME       USER     STATUS
-greeting-01234567  May 27 12:24:04  eve      running
-jack-01234568      May 27 12:24:05  mallory  exited (1)
-red-01234569       May 27 12:24:06  mallory  running
+		expected := `JOB ID             START TIME       USER     STATUS      DURATION
+greeting-01234567  May 27 12:24:04  eve      running     2m0s
+jack-01234568      May 27 12:24:05  mallory  exited (1)  1m0s
+red-01234569       May 27 12:24:06  mallory  running     1m58s
+`
+		require.Equal(t, expected, w.String())
+	})
+
+	t.Run("list all json", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdList{
+			clientCmd: newClientCmd(address, w),
+			All:       true,
+			Completed: true,
+			Output:    "json",
+		}
+		err := cmd.Run()
+		require.NoError(t, err)
+		expected := `[
+  {
+    "id": "greeting-01234567",
+    "user": "eve",
+    "state": "running",
+    "start_time": "2022-05-27T12:24:04Z",
+    "duration_seconds": 120
+  },
+  {
+    "id": "jack-01234568",
+    "user": "mallory",
+    "state": "completed",
+    "start_time": "2022-05-27T12:24:05Z",
+    "end_time": "2022-05-27T12:25:05Z",
+    "duration_seconds": 60,
+    "pid": 4242,
+    "exit": {
+      "code": 1,
+      "reason": "exited with code 1"
+    },
+    "peak_processes": 3,
+    "peak_memory_bytes": 786432,
+    "cpu_time_us": 1500000,
+    "client": "192.0.2.7:51234"
+  },
+  {
+    "id": "red-01234569",
+    "user": "mallory",
+    "state": "running",
+    "start_time": "2022-05-27T12:24:06Z",
+    "duration_seconds": 118
+  }
+]
+`
+		require.Equal(t, expected, w.String())
+	})
+
+	t.Run("list none json", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdList{
+			clientCmd: newClientCmd(address, w),
+			Command:   "no-such-command",
+			Output:    "json",
+		}
+		require.NoError(t, cmd.Run())
+		require.Equal(t, "[]\n", w.String())
+	})
+
+	t.Run("list recently failed", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdList{
+			clientCmd: newClientCmd(address, w),
+			All:       true,
+			// The fake jobs completed in 2022.
+			RecentlyFailed: 100 * 365 * 24 * time.Hour,
+		}
+		require.NoError(t, cmd.Run())
+		expected := `JOB ID         START TIME       USER     STATUS      DURATION
+jack-01234568  May 27 12:24:05  mallory  exited (1)  1m0s
+`
+		require.Equal(t, expected, w.String())
+
+		w.Reset()
+		cmd.RecentlyFailed = time.Hour
+		require.NoError(t, cmd.Run())
+		require.Equal(t, "JOB ID  START TIME  USER  STATUS  DURATION\n", w.String())
+	})
+
+	t.Run("list by command", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdList{
+			clientCmd: newClientCmd(address, w),
+			All:       true,
+			Completed: true,
+			Command:   "/usr/bin/red",
+		}
+		require.NoError(t, cmd.Run())
+		expected := `JOB ID        START TIME       USER     STATUS   DURATION
+red-01234569  May 27 12:24:06  mallory  running  1m58s
 `
 		require.Equal(t, expected, w.String())
 	})
@@ -184,13 +530,322 @@ Goodbye world
 		require.Equal(t, expected, w.String())
 	})
 
+	t.Run("logs greeting-01234567 json", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdLogs{
+			clientCmd: newClientCmd(address, w),
+			JobID:     "greeting-01234567",
+			Stream:    "both",
+			Output:    "json",
+		}
+		err := cmd.Run()
+		require.NoError(t, err)
+		expected := `{"index":0,"timestamp":"2022-05-27T12:24:04Z","stream":"stdout","line":"Hello world"}
+{"index":1,"timestamp":"2022-05-27T12:24:04.00025Z","stream":"stderr","line":"Goodbye world"}
+`
+		require.Equal(t, expected, w.String())
+
+		require.Error(t, (&CmdLogs{AllMine: true, Output: "json"}).Validate())
+		require.Error(t, (&CmdLogs{JobID: "greeting-01234567", Format: "syslog", Output: "json"}).Validate())
+		require.Error(t, (&CmdLogs{JobID: "greeting-01234567", LineNumbers: true, Output: "json"}).Validate())
+	})
+
+	t.Run("logs greeting-01234567 by stream", func(t *testing.T) {
+		for stream, expected := range map[string]string{
+			"stdout": "0 Hello world\n",
+			"stderr": "1 Goodbye world\n",
+			"both":   "0 Hello world\n1 Goodbye world\n",
+		} {
+			w := &bytes.Buffer{}
+			cmd := CmdLogs{
+				clientCmd:    newClientCmd(address, w),
+				JobID:        "greeting-01234567",
+				NoTimestamps: true,
+				LineNumbers:  true,
+				Stream:       stream,
+			}
+			require.NoError(t, cmd.Run())
+			require.Equal(t, expected, w.String(), stream)
+		}
+	})
+
+	t.Run("logs greeting-01234567 tail", func(t *testing.T) {
+		for tail, expected := range map[uint32]string{
+			1: "1 Goodbye world\n",
+			2: "0 Hello world\n1 Goodbye world\n",
+			5: "0 Hello world\n1 Goodbye world\n",
+		} {
+			w := &bytes.Buffer{}
+			cmd := CmdLogs{
+				clientCmd:    newClientCmd(address, w),
+				JobID:        "greeting-01234567",
+				NoTimestamps: true,
+				LineNumbers:  true,
+				Tail:         tail,
+			}
+			require.NoError(t, cmd.Validate())
+			require.NoError(t, cmd.Run())
+			require.Equal(t, expected, w.String(), tail)
+		}
+		require.Error(t, (&CmdLogs{JobID: "greeting-01234567", Tail: 1, Resume: true}).Validate())
+		require.Error(t, (&CmdLogs{AllMine: true, Tail: 1}).Validate())
+	})
+
+	t.Run("logs greeting-01234567 time window", func(t *testing.T) {
+		start := time.Date(2022, 5, 27, 12, 24, 4, 0, time.UTC)
+		tests := map[string]struct {
+			from, to time.Time
+			expected string
+		}{
+			"first line":  {to: start.Add(100 * time.Microsecond), expected: "0 Hello world\n"},
+			"second line": {from: start.Add(100 * time.Microsecond), expected: "1 Goodbye world\n"},
+			"both lines":  {from: start, to: start.Add(250 * time.Microsecond), expected: "0 Hello world\n1 Goodbye world\n"},
+			"before logs": {from: start.Add(-time.Hour), to: start.Add(-time.Minute), expected: ""},
+			"after logs":  {from: start.Add(time.Minute), to: start.Add(time.Hour), expected: ""},
+		}
+		for name, tc := range tests {
+			w := &bytes.Buffer{}
+			cmd := CmdLogs{
+				clientCmd:    newClientCmd(address, w),
+				JobID:        "greeting-01234567",
+				NoTimestamps: true,
+				LineNumbers:  true,
+				FromTime:     tc.from,
+				ToTime:       tc.to,
+			}
+			require.NoError(t, cmd.Validate(), name)
+			require.NoError(t, cmd.Run(), name)
+			require.Equal(t, tc.expected, w.String(), name)
+		}
+
+		now := time.Now()
+		require.Error(t, (&CmdLogs{JobID: "greeting-01234567", FromTime: now, Follow: true}).Validate())
+		require.Error(t, (&CmdLogs{AllMine: true, ToTime: now}).Validate())
+		require.Error(t, (&CmdLogs{JobID: "greeting-01234567", FromTime: now, ToTime: now.Add(-time.Second)}).Validate())
+	})
+
+	t.Run("logs greeting-01234567 timestamps", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdLogs{
+			clientCmd: newClientCmd(address, w),
+			JobID:     "greeting-01234567",
+		}
+		err := cmd.Run()
+		require.NoError(t, err)
+		expected := `2022-05-27T12:24:04.000000000Z Hello world
+2022-05-27T12:24:04.000250000Z Goodbye world
+`
+		require.Equal(t, expected, w.String())
+	})
+
+	t.Run("logs greeting-01234567 ms timestamps", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdLogs{
+			clientCmd:   newClientCmd(address, w),
+			JobID:       "greeting-01234567",
+			TSPrecision: "ms",
+		}
+		err := cmd.Run()
+		require.NoError(t, err)
+		expected := `2022-05-27T12:24:04.000Z Hello world
+2022-05-27T12:24:04.000Z Goodbye world
+`
+		require.Equal(t, expected, w.String())
+	})
+
+	t.Run("logs greeting-01234567 syslog", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdLogs{
+			clientCmd: newClientCmd(address, w),
+			JobID:     "greeting-01234567",
+			Format:    "syslog",
+		}
+		err := cmd.Run()
+		require.NoError(t, err)
+		expected := `<14>1 2022-05-27T12:24:04.000000Z 127.0.0.1 greeting-01234567 - - [jobber@32473 user="eve"] Hello world
+<14>1 2022-05-27T12:24:04.000250Z 127.0.0.1 greeting-01234567 - - [jobber@32473 user="eve"] Goodbye world
+`
+		require.Equal(t, expected, w.String())
+	})
+
+	t.Run("logs all mine", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdLogs{
+			clientCmd:    newClientCmd(address, w),
+			AllMine:      true,
+			NoTimestamps: true,
+		}
+		require.NoError(t, cmd.Run())
+		expected := "greeting-01234567: Hello world\ngreeting-01234567: Goodbye world\n"
+		require.Equal(t, expected, w.String())
+	})
+
+	t.Run("logs resume jack-01234568", func(t *testing.T) {
+		positionFile := filepath.Join(t.TempDir(), "positions.json")
+		store := positionStore{filename: positionFile}
+		require.NoError(t, store.set(address, "jack-01234568", 2))
+
+		w := &bytes.Buffer{}
+		cmd := CmdLogs{
+			clientCmd:    newClientCmd(address, w),
+			JobID:        "jack-01234568",
+			NoTimestamps: true,
+			Resume:       true,
+			PositionFile: positionFile,
+		}
+		require.NoError(t, cmd.Run())
+		require.Equal(t, "fo\nfum\n", w.String())
+		pos, err := store.get(address, "jack-01234568")
+		require.NoError(t, err)
+		require.Equal(t, int64(4), pos)
+
+		// Resuming again shows nothing new.
+		w.Reset()
+		require.NoError(t, cmd.Run())
+		require.Equal(t, "", w.String())
+	})
+
+	t.Run("logs resume red-01234569 after dropped lines", func(t *testing.T) {
+		positionFile := filepath.Join(t.TempDir(), "positions.json")
+		store := positionStore{filename: positionFile}
+
+		w := &bytes.Buffer{}
+		cmd := CmdLogs{
+			clientCmd:    newClientCmd(address, w),
+			JobID:        "red-01234569",
+			NoTimestamps: true,
+			Resume:       true,
+			PositionFile: positionFile,
+		}
+		require.NoError(t, cmd.Run())
+		require.Equal(t, "[jobber] 1 earlier lines dropped from the log buffer\ntoo cold\njust right\n", w.String())
+		pos, err := store.get(address, "red-01234569")
+		require.NoError(t, err)
+		require.Equal(t, int64(3), pos)
+	})
+
+	t.Run("logs resume jack-01234568 line numbers", func(t *testing.T) {
+		positionFile := filepath.Join(t.TempDir(), "positions.json")
+		store := positionStore{filename: positionFile}
+		require.NoError(t, store.set(address, "jack-01234568", 2))
+
+		w := &bytes.Buffer{}
+		cmd := CmdLogs{
+			clientCmd:    newClientCmd(address, w),
+			JobID:        "jack-01234568",
+			NoTimestamps: true,
+			Resume:       true,
+			PositionFile: positionFile,
+			LineNumbers:  true,
+		}
+		require.NoError(t, cmd.Run())
+		require.Equal(t, "2 fo\n3 fum\n", w.String())
+	})
+
+	t.Run("logs exit status jack-01234568", func(t *testing.T) {
+		cmd := newClientCmd(address, io.Discard)
+		cl, err := cmd.connect()
+		require.NoError(t, err)
+		defer cmd.Close()
+
+		w := &bytes.Buffer{}
+		req := pb.LogsRequest{JobId: []byte("jack-01234568")}
+		exit, next, err := streamLogs(context.Background(), selectStreams(w, "both"), cl, &req, false /* lineNumbers */, plainFormat(""))
+		require.NoError(t, err)
+		require.Equal(t, "fee\nfi\nfo\nfum\n", w.String())
+		require.Equal(t, int64(4), next)
+		require.Equal(t, uint32(1), exit.GetExitCode())
+		require.Equal(t, uint32(0), exit.GetSignal())
+		require.Equal(t, "exited with code 1", exit.GetReason())
+	})
+
+	t.Run("logs exit status running greeting-01234567", func(t *testing.T) {
+		cmd := newClientCmd(address, io.Discard)
+		cl, err := cmd.connect()
+		require.NoError(t, err)
+		defer cmd.Close()
+
+		req := pb.LogsRequest{JobId: []byte("greeting-01234567")}
+		exit, _, err := streamLogs(context.Background(), selectStreams(io.Discard, "both"), cl, &req, false /* lineNumbers */, plainFormat(""))
+		require.NoError(t, err)
+		require.Nil(t, exit)
+	})
+
+	t.Run("debug feeder greeting-01234567", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdDebugFeeder{
+			clientCmd: newClientCmd(address, w),
+			JobID:     "greeting-01234567",
+		}
+		err := cmd.Run()
+		require.NoError(t, err)
+		expected := `buffered lines: 2
+infeed: open
+OUTFEED  POSITION  LAG  FOLLOW  WAITING
+0        2         0    true    true
+1        1         1    false   false
+`
+		require.Equal(t, expected, w.String())
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdDrain{clientCmd: newClientCmd(address, w)}
+		require.NoError(t, cmd.Run())
+		require.Equal(t, "draining: 1 running and 0 queued jobs remaining\n", w.String())
+	})
+
+	t.Run("drain --wait", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdDrain{clientCmd: newClientCmd(address, w), Wait: true, PollInterval: time.Millisecond}
+		require.NoError(t, cmd.Run())
+		expected := `draining: 1 running and 0 queued jobs remaining
+drained: no jobs remaining, ready to shut down
+`
+		require.Equal(t, expected, w.String())
+	})
+
+	t.Run("watch jack-01234568", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdWatch{clientCmd: newClientCmd(address, w), TSPrecision: "us", JobID: "jack-01234568"}
+		require.NoError(t, cmd.Run())
+		expected := `2022-05-27T12:24:05.000000Z [jobber] job started by mallory as pid 4242
+2022-05-27T12:24:05.000000Z fee
+2022-05-27T12:24:05.000250Z fi
+2022-05-27T12:24:05.000500Z fo
+2022-05-27T12:24:05.000750Z fum
+2022-05-27T12:25:05.000000Z [jobber] job exited with code 1
+`
+		require.Equal(t, expected, w.String())
+	})
+
+	t.Run("drain-status", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdDrainStatus{clientCmd: newClientCmd(address, w)}
+		require.NoError(t, cmd.Run())
+		require.Equal(t, "drained: no jobs remaining, ready to shut down\n", w.String())
+	})
+
+	t.Run("chown jack-01234568", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		cmd := CmdChown{
+			clientCmd: newClientCmd(address, w),
+			JobID:     "jack-01234568",
+			Owner:     "eve",
+		}
+		err := cmd.Run()
+		require.NoError(t, err)
+		require.Equal(t, "jack-01234568: owner changed from \"mallory\" to \"eve\"\n", w.String())
+	})
+
 	t.Run("logs invalid-job-id", func(t *testing.T) {
 		cmd := CmdLogs{
-			clientCmd: clientCmd{Address: address, output: io.Discard},
+			clientCmd: newClientCmd(address, io.Discard),
 			JobID:     "invalid-job-id",
 		}
 		err := cmd.Run()
-		require.Error(t, err)
+		require.EqualError(t, err, "invalid-job-id: unknown job")
+		require.Equal(t, codes.NotFound, status.Code(err))
 	})
 
 	t.Run("invalid client cert CA", func(t *testing.T) {
@@ -210,6 +865,68 @@ Goodbye world
 
 }
 
+// failingLogsClient is a client whose Logs calls fail as if the server
+// could not be reached.
+type failingLogsClient struct {
+	pb.JobExecutorClient
+}
+
+func (failingLogsClient) Logs(context.Context, *pb.LogsRequest, ...grpc.CallOption) (pb.JobExecutor_LogsClient, error) {
+	return nil, status.Error(codes.Unavailable, "connection refused")
+}
+
+func TestResumeLogsFailedCall(t *testing.T) {
+	positionFile := filepath.Join(t.TempDir(), "positions.json")
+	store := positionStore{filename: positionFile}
+	require.NoError(t, store.set("localhost:8080", "jack-01234568", 2))
+
+	cmd := CmdLogs{
+		clientCmd:    clientCmd{Address: "localhost:8080", output: io.Discard},
+		JobID:        "jack-01234568",
+		Resume:       true,
+		PositionFile: positionFile,
+	}
+	err := cmd.resumeLogs(failingLogsClient{}, plainFormat(""))
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	pos, err := store.get("localhost:8080", "jack-01234568")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), pos)
+}
+
+func TestJobExitCode(t *testing.T) {
+	tests := map[string]struct {
+		exit *pb.ExitStatus
+		want error
+	}{
+		"success":     {exit: &pb.ExitStatus{ExitCode: 0}},
+		"failure":     {exit: &pb.ExitStatus{ExitCode: 3}, want: ExitCode(3)},
+		"stopped":     {exit: &pb.ExitStatus{ExitCode: 137, Signal: 9}, want: ExitCode(137)},
+		"timed out":   {exit: &pb.ExitStatus{ExitCode: 137, Signal: 9, TimedOut: true}, want: ExitCode(timedOutExitCode)},
+		"not started": {exit: &pb.ExitStatus{ExitCode: 127}, want: ExitCode(127)},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, jobExitCode(tc.exit))
+		})
+	}
+}
+
+func TestBindCwd(t *testing.T) {
+	spec := job.JobSpec{Command: "/bin/make"}
+	bindCwd(&spec, "/home/eve/src")
+	require.Equal(t, job.JobSpec{Command: "/bin/make", Workdir: "/home/eve/src"}, spec)
+
+	spec = job.JobSpec{Command: "/bin/make", Root: "/srv/root"}
+	bindCwd(&spec, "/home/eve/src")
+	expected := job.JobSpec{
+		Command: "/bin/make",
+		Root:    "/srv/root",
+		Workdir: "/workspace",
+		Mounts:  []job.BindMount{{Source: "/home/eve/src", Target: "/workspace"}},
+	}
+	require.Equal(t, expected, spec)
+}
+
 func TestBadServerCerts(t *testing.T) {
 	creds, err := mTLSCreds("testdata/badserver.crt", "testdata/badserver.key", "testdata/ca.crt")
 	require.NoError(t, err)
@@ -236,3 +953,145 @@ func TestBadServerCerts(t *testing.T) {
 		require.ErrorContains(t, err, "x509: certificate signed by unknown authority")
 	})
 }
+
+func TestHealth(t *testing.T) {
+	creds, err := mTLSCreds("testdata/server.crt", "testdata/server.key", "testdata/ca.crt")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer(grpc.Creds(creds))
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	healthServer.SetServingStatus("JobExecutor", healthpb.HealthCheckResponse_SERVING)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	address := lis.Addr().String()
+	go grpcServer.Serve(lis) //nolint:errcheck
+	defer grpcServer.Stop()
+
+	w := &bytes.Buffer{}
+	cmd := CmdHealth{clientCmd: newClientCmd(address, w), Service: "JobExecutor"}
+	require.NoError(t, cmd.Run())
+	require.Equal(t, "SERVING\n", w.String())
+
+	// As when the server is shut down.
+	healthServer.Shutdown()
+	w.Reset()
+	require.Equal(t, ExitCode(1), cmd.Run())
+	require.Equal(t, "NOT_SERVING\n", w.String())
+
+	cmd.Service = "unknown"
+	err = cmd.Run()
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestReadSpecFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "spec.json")
+	content := `{
+  "commands": [["/bin/make"], ["/bin/make", "install"]],
+  "root": "/srv/build",
+  "mounts": ["/home/eve/src:/src:ro"],
+  "ionice": "idle",
+  "resources": {"memory": 1073741824}
+}`
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+
+	// Fields in the file override those already set.
+	spec := job.JobSpec{Root: "/srv/other", Nice: 5}
+	require.NoError(t, readSpecFile(filename, &spec))
+	expected := job.JobSpec{
+		Commands:  [][]string{{"/bin/make"}, {"/bin/make", "install"}},
+		Root:      "/srv/build",
+		Nice:      5,
+		Mounts:    []job.BindMount{{Source: "/home/eve/src", Target: "/src", ReadOnly: true}},
+		IONice:    job.IONice{Class: job.IOClassIdle},
+		Resources: job.ResourceLimits{Memory: 1 << 30},
+	}
+	require.Equal(t, expected, spec)
+
+	require.NoError(t, os.WriteFile(filename, []byte(`{"comand": "/bin/true"}`), 0644))
+	require.ErrorContains(t, readSpecFile(filename, &spec), `unknown field "comand"`)
+}
+
+func TestFormatBytes(t *testing.T) {
+	require.Equal(t, "-", formatBytes(0))
+	require.Equal(t, "1023", formatBytes(1023))
+	require.Equal(t, "1.0Ki", formatBytes(1024))
+	require.Equal(t, "1.5Mi", formatBytes(3<<19))
+	require.Equal(t, "2.0Gi", formatBytes(2<<30))
+}
+
+func TestLineJoiner(t *testing.T) {
+	start := time.Date(2022, 5, 27, 12, 0, 0, 0, time.UTC)
+	chunk := func(index int64, stream pb.Stream, line string) logLine {
+		return logLine{index: index, timestamp: start.Add(time.Duration(index) * time.Second), stream: stream, line: []byte(line)}
+	}
+	var j lineJoiner
+
+	_, ok := j.join(chunk(0, pb.Stream_STREAM_STDOUT, "hello "), true)
+	require.False(t, ok)
+	// A whole line on the other stream is not held back.
+	l, ok := j.join(chunk(1, pb.Stream_STREAM_STDERR, "oops\n"), false)
+	require.True(t, ok)
+	require.Equal(t, chunk(1, pb.Stream_STREAM_STDERR, "oops\n"), l)
+	_, ok = j.join(chunk(2, pb.Stream_STREAM_STDOUT, "wide "), true)
+	require.False(t, ok)
+	l, ok = j.join(chunk(3, pb.Stream_STREAM_STDOUT, "world\n"), false)
+	require.True(t, ok)
+	require.Equal(t, chunk(0, pb.Stream_STREAM_STDOUT, "hello wide world\n"), l)
+	require.Empty(t, j.flush())
+
+	_, ok = j.join(chunk(4, pb.Stream_STREAM_STDERR, "cut "), true)
+	require.False(t, ok)
+	_, ok = j.join(chunk(5, pb.Stream_STREAM_STDOUT, "short"), true)
+	require.False(t, ok)
+	require.Equal(t, []logLine{
+		chunk(5, pb.Stream_STREAM_STDOUT, "short"),
+		chunk(4, pb.Stream_STREAM_STDERR, "cut "),
+	}, j.flush())
+	require.Empty(t, j.flush())
+}
+
+func TestJobDuration(t *testing.T) {
+	start := time.Date(2022, 5, 27, 12, 0, 0, 0, time.UTC)
+	now := start.Add(90 * time.Minute)
+	tests := map[string]struct {
+		status *pb.JobStatus
+		want   string
+	}{
+		"running": {
+			status: &pb.JobStatus{State: pb.JobStatus_JOBSTATE_RUNNING, StartTime: timestamppb.New(start)},
+			want:   "1h30m0s",
+		},
+		"completed": {
+			status: &pb.JobStatus{
+				State:          pb.JobStatus_JOBSTATE_COMPLETED,
+				StartTime:      timestamppb.New(start),
+				CompletionTime: timestamppb.New(start.Add(2*time.Minute + 3*time.Second)),
+			},
+			want: "2m3s",
+		},
+		"completed rounded to the second": {
+			status: &pb.JobStatus{
+				State:          pb.JobStatus_JOBSTATE_COMPLETED,
+				StartTime:      timestamppb.New(start),
+				CompletionTime: timestamppb.New(start.Add(1600 * time.Millisecond)),
+			},
+			want: "2s",
+		},
+		"completed without completion time": {
+			status: &pb.JobStatus{State: pb.JobStatus_JOBSTATE_COMPLETED, StartTime: timestamppb.New(start)},
+			want:   "-",
+		},
+		"queued": {
+			status: &pb.JobStatus{State: pb.JobStatus_JOBSTATE_QUEUED, StartTime: timestamppb.New(start)},
+			want:   "-",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, jobDuration(tc.status, now))
+		})
+	}
+}
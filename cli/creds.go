@@ -7,6 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/camh-/jobber/job"
 	"google.golang.org/grpc/credentials"
@@ -19,54 +23,292 @@ var (
 	ErrNoTLSInfo    = fmt.Errorf("%w: no TLSInfo auth info", ErrAuthFailed)
 	ErrNoClientCert = fmt.Errorf("%w: no client certificate in auth info", ErrAuthFailed)
 	ErrNoCNInCert   = fmt.Errorf("%w: no CN in client certificate", ErrAuthFailed)
+	ErrNoSpiffeID   = fmt.Errorf("%w: no SPIFFE ID URI SAN in client certificate", ErrAuthFailed)
 )
 
+// IdentitySource is the part of a client certificate that a user is
+// identified by.
+type IdentitySource string
+
+const (
+	// IdentityCN identifies users by the Common Name (CN) of their
+	// certificate's subject.
+	IdentityCN IdentitySource = "cn"
+	// IdentitySpiffe identifies users by the SPIFFE ID of a SPIFFE X.509
+	// SVID: the first URI Subject Alternative Name (SAN) of their
+	// certificate with the spiffe scheme, such as
+	// spiffe://example.org/ns/prod/sa/builder.
+	IdentitySpiffe IdentitySource = "spiffe"
+)
+
+// user returns the identity of the user cert was issued to.
+func (s IdentitySource) user(cert *x509.Certificate) (string, error) {
+	if s == IdentitySpiffe {
+		for _, u := range cert.URIs {
+			if u.Scheme == "spiffe" {
+				return u.String(), nil
+			}
+		}
+		return "", ErrNoSpiffeID
+	}
+	if cert.Subject.CommonName == "" {
+		return "", ErrNoCNInCert
+	}
+	return cert.Subject.CommonName, nil
+}
+
 func mTLSCreds(certFile, keyFile, caFile string) (credentials.TransportCredentials, error) {
 	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
 	if err != nil {
 		return nil, err
 	}
-
-	caCert, err := ioutil.ReadFile(caFile)
+	cfg, err := mTLSConfig(caFile)
 	if err != nil {
 		return nil, err
 	}
-	caCertPool := x509.NewCertPool()
-	if !caCertPool.AppendCertsFromPEM(caCert) {
-		return nil, fmt.Errorf("could not load ca certs from %s", caFile)
+	cfg.Certificates = []tls.Certificate{cert}
+	return credentials.NewTLS(cfg), nil
+}
+
+// mTLSConfig returns a TLS config that requires and verifies peer certs
+// against the CA certs in caFile, without any certs of its own.
+func mTLSConfig(caFile string) (*tls.Config, error) {
+	caCertPool, err := loadCertPool(caFile)
+	if err != nil {
+		return nil, err
 	}
 
 	cfg := &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		RootCAs:      caCertPool, // make it work on both client and server
-		ClientCAs:    caCertPool, // make it work on both client and server
-		ClientAuth:   tls.RequireAndVerifyClientCert,
-		MinVersion:   tls.VersionTLS13,
+		RootCAs:    caCertPool, // make it work on both client and server
+		ClientCAs:  caCertPool, // make it work on both client and server
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		MinVersion: tls.VersionTLS13,
 		// cipher suites are not configurable with TLS13
 	}
-	return credentials.NewTLS(cfg), nil
+	return cfg, nil
+}
+
+// loadCertPool returns a pool of the PEM encoded certs in the file at path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("could not load ca certs from %s", path)
+	}
+	return pool, nil
+}
+
+// reloadingTLS is a server's TLS cert and key, and the CA certs it verifies
+// the certs of clients against, loaded from files that are reloaded when
+// they change, so that renewed certs are used for new connections without
+// restarting the server. Existing connections are not affected.
+type reloadingTLS struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	// stamp identifies the versions of the files cert and clientCAs were
+	// loaded from.
+	stamp string
+}
+
+// newReloadingTLS returns a reloadingTLS for the given files, failing if
+// they cannot be loaded.
+func newReloadingTLS(certFile, keyFile, caFile string) (*reloadingTLS, error) {
+	rt := &reloadingTLS{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if _, err := rt.reload(); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// config returns a server TLS config using the current cert, that requires
+// client certs verified against the current CA certs.
+func (rt *reloadingTLS) config() *tls.Config {
+	return &tls.Config{
+		GetCertificate: rt.getCertificate,
+		// Client certs are verified by verifyClientCert rather than
+		// against a fixed ClientCAs pool, so the CA certs can change.
+		ClientAuth:            tls.RequireAnyClientCert,
+		VerifyPeerCertificate: rt.verifyClientCert,
+		MinVersion:            tls.VersionTLS13,
+	}
+}
+
+// getCertificate is a tls.Config.GetCertificate callback that returns the
+// cert, first reloading the files if they have changed. If reloading fails,
+// such as when only one of the cert and key has been replaced so far, the
+// previous cert is returned and reloading is tried again next time.
+func (rt *reloadingTLS) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	_, _ = rt.reload()
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.cert, nil
+}
+
+// verifyClientCert is a tls.Config.VerifyPeerCertificate callback that
+// verifies the client's cert chain against the current CA certs, as
+// tls.RequireAndVerifyClientCert would against a fixed pool. The CA certs
+// are reloaded with the server cert in getCertificate, which is called
+// earlier in the same handshake.
+func (rt *reloadingTLS) verifyClientCert(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return ErrNoClientCert
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("could not parse client certificate: %w", err)
+		}
+		certs[i] = cert
+	}
+	rt.mu.Lock()
+	roots := rt.clientCAs
+	rt.mu.Unlock()
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(opts)
+	return err
 }
 
+// reload loads the cert, key and CA certs if their files have changed
+// since they were last loaded, returning whether they were. If any of them
+// cannot be loaded, none are changed.
+func (rt *reloadingTLS) reload() (bool, error) {
+	stamp, err := fileStamp(rt.certFile, rt.keyFile, rt.caFile)
+	if err != nil {
+		return false, err
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if stamp == rt.stamp {
+		return false, nil
+	}
+	cert, err := tls.LoadX509KeyPair(rt.certFile, rt.keyFile)
+	if err != nil {
+		return false, err
+	}
+	clientCAs, err := loadCertPool(rt.caFile)
+	if err != nil {
+		return false, err
+	}
+	rt.cert, rt.clientCAs, rt.stamp = &cert, clientCAs, stamp
+	return true, nil
+}
+
+// fileStamp returns a string that changes when any of the given files is
+// modified or replaced.
+func fileStamp(filenames ...string) (string, error) {
+	var stamp string
+	for _, filename := range filenames {
+		fi, err := os.Stat(filename)
+		if err != nil {
+			return "", err
+		}
+		stamp += fmt.Sprintf("%s:%d:%s;", filename, fi.Size(), fi.ModTime().Format(time.RFC3339Nano))
+	}
+	return stamp, nil
+}
+
+// CNToUser authenticates the user of a request as the CN of their client
+// certificate, adding the user and the OUs of the certificate as their
+// groups to the returned context.
 func CNToUser(ctx context.Context) (context.Context, error) {
+	ctx, _, err := certToUser(ctx, IdentityCN)
+	return ctx, err
+}
+
+// CertToUserWithAdminOUs returns an auth func that authenticates users as
+// CNToUser does, but identified by the given source in their client
+// certificate, and also marks users as admins if their client certificate
+// has any of the given organizational units (OUs).
+func CertToUserWithAdminOUs(source IdentitySource, adminOUs []string) func(context.Context) (context.Context, error) {
+	return func(ctx context.Context) (context.Context, error) {
+		ctx, cert, err := certToUser(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		return addAdminForOUs(ctx, cert, adminOUs), nil
+	}
+}
+
+// HTTPCertToUserWithAdminOUs returns an auth func for HTTP requests made
+// over mTLS that authenticates users as CertToUserWithAdminOUs does.
+func HTTPCertToUserWithAdminOUs(source IdentitySource, adminOUs []string) func(*http.Request) (context.Context, error) {
+	return func(r *http.Request) (context.Context, error) {
+		if r.TLS == nil {
+			return nil, ErrNoTLSInfo
+		}
+		ctx, cert, err := peerCertToUser(r.Context(), r.TLS.PeerCertificates, source)
+		if err != nil {
+			return nil, err
+		}
+		return addAdminForOUs(ctx, cert, adminOUs), nil
+	}
+}
+
+// addAdminForOUs marks the user of ctx as an admin if cert has any of the
+// given organizational units (OUs).
+func addAdminForOUs(ctx context.Context, cert *x509.Certificate, adminOUs []string) context.Context {
+	for _, ou := range cert.Subject.OrganizationalUnit {
+		for _, adminOU := range adminOUs {
+			if ou == adminOU {
+				return job.AddAdminToContext(ctx)
+			}
+		}
+	}
+	return ctx
+}
+
+// certToUser adds the identity from source of the client certificate of the
+// request to the context as the user, returning the new context and the
+// certificate.
+func certToUser(ctx context.Context, source IdentitySource) (context.Context, *x509.Certificate, error) {
 	p, ok := peer.FromContext(ctx)
 	if !ok {
-		return nil, ErrNoPeer
+		return nil, nil, ErrNoPeer
 	}
 
 	authinfo, ok := p.AuthInfo.(credentials.TLSInfo)
 	if !ok {
-		return nil, ErrNoTLSInfo
+		return nil, nil, ErrNoTLSInfo
 	}
 
-	if len(authinfo.State.PeerCertificates) == 0 {
-		return nil, ErrNoClientCert
+	return peerCertToUser(ctx, authinfo.State.PeerCertificates, source)
+}
+
+// peerCertToUser adds the identity from source of the first of the verified
+// peer certificates of a connection, the leaf of its chain, to the context
+// as the user, and its organizational units (OUs) as the user's groups,
+// returning the new context and the certificate.
+func peerCertToUser(ctx context.Context, certs []*x509.Certificate, source IdentitySource) (context.Context, *x509.Certificate, error) {
+	if len(certs) == 0 {
+		return nil, nil, ErrNoClientCert
 	}
 
-	cert := authinfo.State.PeerCertificates[0]
-	cn := cert.Subject.CommonName
-	if cn == "" {
-		return nil, ErrNoCNInCert
+	cert := certs[0]
+	user, err := source.user(cert)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return job.AddUserToContext(ctx, cn), nil
+	ctx = job.AddUserToContext(ctx, user)
+	if ous := cert.Subject.OrganizationalUnit; len(ous) > 0 {
+		ctx = job.AddGroupsToContext(ctx, ous)
+	}
+	return ctx, cert, nil
 }
@@ -0,0 +1,317 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/camh-/jobber/job"
+	pb "github.com/camh-/jobber/pb"
+	"github.com/camh-/jobber/service"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// peerContext returns a context for a request from a client with a cert
+// with the given subject.
+func peerContext(subject pkix.Name) context.Context {
+	cert := &x509.Certificate{Subject: subject}
+	authInfo := credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}}
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: authInfo})
+}
+
+func TestCertToUserWithAdminOUs(t *testing.T) {
+	tests := map[string]struct {
+		subject pkix.Name
+		admin   bool
+	}{
+		"no OU":        {subject: pkix.Name{CommonName: "eve"}},
+		"other OU":     {subject: pkix.Name{CommonName: "eve", OrganizationalUnit: []string{"dev"}}},
+		"admin OU":     {subject: pkix.Name{CommonName: "eve", OrganizationalUnit: []string{"ops"}}, admin: true},
+		"second OU":    {subject: pkix.Name{CommonName: "eve", OrganizationalUnit: []string{"dev", "sre"}}, admin: true},
+		"OU substring": {subject: pkix.Name{CommonName: "eve", OrganizationalUnit: []string{"devops"}}},
+	}
+
+	authFunc := CertToUserWithAdminOUs(IdentityCN, []string{"ops", "sre"})
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, err := authFunc(peerContext(tc.subject))
+			require.NoError(t, err)
+			user, ok := job.GetUserFromContext(ctx)
+			require.True(t, ok)
+			require.Equal(t, "eve", user)
+			groups, _ := job.GetGroupsFromContext(ctx)
+			require.Equal(t, tc.subject.OrganizationalUnit, groups)
+
+			// Only admins can get feeder stats, so use that to check
+			// whether the user was made an admin.
+			tracker := job.NewTracker(nil, nil)
+			_, err = tracker.FeederStats(ctx, "no-such-job")
+			if tc.admin {
+				require.ErrorIs(t, err, job.ErrUnknown)
+			} else {
+				require.ErrorIs(t, err, job.ErrUnauthorized)
+			}
+		})
+	}
+
+	_, err := authFunc(peerContext(pkix.Name{OrganizationalUnit: []string{"ops"}}))
+	require.ErrorIs(t, err, ErrNoCNInCert)
+}
+
+func TestHTTPCertToUserWithAdminOUs(t *testing.T) {
+	authFunc := HTTPCertToUserWithAdminOUs(IdentityCN, []string{"ops"})
+	request := func(certs ...*x509.Certificate) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/jobs/sleep-01234567/logs", nil)
+		r.TLS = &tls.ConnectionState{PeerCertificates: certs}
+		return r
+	}
+
+	ctx, err := authFunc(request(&x509.Certificate{Subject: pkix.Name{CommonName: "eve", OrganizationalUnit: []string{"ops"}}}))
+	require.NoError(t, err)
+	user, ok := job.GetUserFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "eve", user)
+	_, err = job.NewTracker(nil, nil).FeederStats(ctx, "no-such-job")
+	require.ErrorIs(t, err, job.ErrUnknown, "user is not an admin")
+
+	_, err = authFunc(request())
+	require.ErrorIs(t, err, ErrNoClientCert)
+	_, err = authFunc(httptest.NewRequest(http.MethodGet, "/jobs/sleep-01234567/logs", nil))
+	require.ErrorIs(t, err, ErrNoTLSInfo)
+}
+
+func TestSpiffeIdentity(t *testing.T) {
+	spiffeID, err := url.Parse("spiffe://example.org/ns/prod/sa/builder")
+	require.NoError(t, err)
+	other, err := url.Parse("https://example.org/builder")
+	require.NoError(t, err)
+	certContext := func(cert *x509.Certificate) context.Context {
+		authInfo := credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}}
+		return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: authInfo})
+	}
+
+	authFunc := CertToUserWithAdminOUs(IdentitySpiffe, []string{"ops"})
+	ctx, err := authFunc(certContext(&x509.Certificate{
+		Subject: pkix.Name{CommonName: "builder", OrganizationalUnit: []string{"ops"}},
+		URIs:    []*url.URL{other, spiffeID},
+	}))
+	require.NoError(t, err)
+	user, ok := job.GetUserFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "spiffe://example.org/ns/prod/sa/builder", user)
+	_, err = job.NewTracker(nil, nil).FeederStats(ctx, "no-such-job")
+	require.ErrorIs(t, err, job.ErrUnknown, "user is not an admin")
+
+	// The CN is not used in place of a missing SPIFFE ID.
+	_, err = authFunc(certContext(&x509.Certificate{Subject: pkix.Name{CommonName: "builder"}, URIs: []*url.URL{other}}))
+	require.ErrorIs(t, err, ErrNoSpiffeID)
+	require.ErrorIs(t, err, ErrAuthFailed)
+
+	// The CN is the default, even if there is a SPIFFE ID.
+	ctx, err = CNToUser(certContext(&x509.Certificate{Subject: pkix.Name{CommonName: "builder"}, URIs: []*url.URL{spiffeID}}))
+	require.NoError(t, err)
+	user, _ = job.GetUserFromContext(ctx)
+	require.Equal(t, "builder", user)
+
+	httpAuthFunc := HTTPCertToUserWithAdminOUs(IdentitySpiffe, nil)
+	r := httptest.NewRequest(http.MethodGet, "/jobs/sleep-01234567/logs", nil)
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{URIs: []*url.URL{spiffeID}}}}
+	ctx, err = httpAuthFunc(r)
+	require.NoError(t, err)
+	user, _ = job.GetUserFromContext(ctx)
+	require.Equal(t, "spiffe://example.org/ns/prod/sa/builder", user)
+}
+
+func TestReloadingTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	caFile := filepath.Join(dir, "ca.crt")
+	copyFile := func(dst string, srcs ...string) {
+		t.Helper()
+		var b []byte
+		for _, src := range srcs {
+			sb, err := os.ReadFile(src)
+			require.NoError(t, err)
+			b = append(b, sb...)
+		}
+		// Write and rename, as cert renewal tools do.
+		require.NoError(t, os.WriteFile(dst+".tmp", b, 0600))
+		require.NoError(t, os.Rename(dst+".tmp", dst))
+	}
+	copyFile(certFile, "testdata/server.crt")
+	copyFile(keyFile, "testdata/server.key")
+	copyFile(caFile, "testdata/ca.crt")
+
+	rt, err := newReloadingTLS(certFile, keyFile, caFile)
+	require.NoError(t, err)
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", rt.config())
+	require.NoError(t, err)
+	defer lis.Close()
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			// Echo, so connections can be checked to still work.
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	loadCert := func(name string) tls.Certificate {
+		t.Helper()
+		cert, err := tls.LoadX509KeyPair("testdata/"+name+".crt", "testdata/"+name+".key")
+		require.NoError(t, err)
+		return cert
+	}
+	userCert, badUserCert := loadCert("user"), loadCert("baduser")
+	// dial connects to the server with clientCert, checking the server
+	// accepts it with an echo. The server cert is not verified as the
+	// replacement is from another CA.
+	dial := func(clientCert tls.Certificate) (*tls.Conn, error) {
+		t.Helper()
+		conn, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{
+			Certificates:       []tls.Certificate{clientCert},
+			InsecureSkipVerify: true, //nolint:gosec
+			MinVersion:         tls.VersionTLS13,
+		})
+		require.NoError(t, err)
+		// With TLS 1.3, a rejected client cert fails the first read.
+		if err := echo(conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+	serverCN := func() string {
+		t.Helper()
+		conn, err := dial(userCert)
+		require.NoError(t, err)
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+	}
+
+	existing, err := dial(userCert)
+	require.NoError(t, err)
+	defer existing.Close()
+	require.Equal(t, "server", serverCN())
+	_, err = dial(badUserCert)
+	require.Error(t, err, "client cert from unknown CA")
+
+	// A cert without its matching key is not used yet.
+	copyFile(certFile, "testdata/badserver.crt")
+	require.Equal(t, "server", serverCN())
+
+	copyFile(keyFile, "testdata/badserver.key")
+	require.Equal(t, "badserver", serverCN())
+
+	// Trusting another CA lets its users connect, while the connection
+	// made before any of the files changed still works with the old cert.
+	copyFile(caFile, "testdata/ca.crt", "testdata/badca.crt")
+	conn, err := dial(badUserCert)
+	require.NoError(t, err)
+	conn.Close()
+	require.NoError(t, echo(existing))
+	require.Equal(t, "server", existing.ConnectionState().PeerCertificates[0].Subject.CommonName)
+
+	// Bad CA certs are not loaded, keeping the previous ones.
+	require.NoError(t, os.WriteFile(caFile, []byte("not a cert"), 0600))
+	reloaded, err := rt.reload()
+	require.Error(t, err)
+	require.False(t, reloaded)
+	conn, err = dial(badUserCert)
+	require.NoError(t, err)
+	conn.Close()
+
+	copyFile(caFile, "testdata/ca.crt")
+	reloaded, err = rt.reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+	_, err = dial(badUserCert)
+	require.Error(t, err, "client cert from CA no longer trusted")
+	reloaded, err = rt.reload()
+	require.NoError(t, err)
+	require.False(t, reloaded)
+}
+
+// echo writes to conn and checks the same is read back.
+func echo(conn net.Conn) error {
+	msg := []byte("ping")
+	if _, err := conn.Write(msg); err != nil {
+		return err
+	}
+	b := make([]byte, len(msg))
+	if _, err := io.ReadFull(conn, b); err != nil {
+		return err
+	}
+	if !bytes.Equal(b, msg) {
+		return fmt.Errorf("echoed %q, not %q", b, msg)
+	}
+	return nil
+}
+
+func TestGRPCWebClientCertAuth(t *testing.T) {
+	authFunc := CertToUserWithAdminOUs(IdentityCN, nil)
+	gs := grpc.NewServer(grpc.UnaryInterceptor(grpc_auth.UnaryServerInterceptor(authFunc)))
+	service.NewJobExecutor(nil, nil, nil).RegisterWith(gs)
+	rt, err := newReloadingTLS("testdata/server.crt", "testdata/server.key", "testdata/ca.crt")
+	require.NoError(t, err)
+	cfg := rt.config()
+	// Serve with cfg directly: httptest's StartTLS would serve its own cert.
+	srv := httptest.NewUnstartedServer(service.GRPCWebHandler(gs, nil))
+	srv.Listener = tls.NewListener(srv.Listener, cfg)
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.Start()
+	defer srv.Close()
+	url := strings.Replace(srv.URL, "http:", "https:", 1)
+
+	clientCfg, err := mTLSConfig("testdata/ca.crt")
+	require.NoError(t, err)
+	status := func(certs ...tls.Certificate) (string, error) {
+		cfg := clientCfg.Clone()
+		cfg.Certificates = certs
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+		msg, err := proto.Marshal(&pb.StatusRequest{JobId: []byte("no-such-job")})
+		require.NoError(t, err)
+		body := append([]byte{0, 0, 0, 0, byte(len(msg))}, msg...)
+		resp, err := client.Post(url+"/JobExecutor/Status", "application/grpc-web+proto", bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		return string(b), err
+	}
+
+	// The user of the client cert reaches the service, so the job is
+	// unknown rather than the request unauthorized.
+	clientCert, err := tls.LoadX509KeyPair("testdata/user.crt", "testdata/user.key")
+	require.NoError(t, err)
+	resp, err := status(clientCert)
+	require.NoError(t, err)
+	require.Contains(t, resp, "grpc-status: 5\r\n")
+
+	_, err = status()
+	require.Error(t, err, "no client cert")
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Lifetimes of the certs generated by `jobber gen-certs`. They match the
+// certs the Makefile generates with certstrap.
+const (
+	genCALifetime     = 10 * 365 * 24 * time.Hour
+	genServerLifetime = 90 * 24 * time.Hour
+	genUserLifetime   = 7 * 24 * time.Hour
+)
+
+var ErrInvalidUserName = errors.New("invalid user name")
+
+// CmdGenCerts is a kong struct describing the flags and arguments for the
+// `jobber gen-certs` subcommand.
+type CmdGenCerts struct {
+	Dir         string   `short:"o" default:"certs" type:"path" help:"Directory to write the certs and keys to"`
+	ServerHosts []string `name:"server-host" default:"localhost,127.0.0.1" help:"Host names and IP addresses the server cert is valid for"`
+	Users       []string `name:"user" required:"" help:"Users to generate client certs for. The first is also written as the default user.crt and user.key"`
+}
+
+// Run is the entrypoint for the `jobber gen-certs` cli command. It
+// generates a CA, a server cert signed by it and a client cert for each
+// user, with the user name as the CN. Existing files are not overwritten.
+func (cmd *CmdGenCerts) Run() error {
+	for _, user := range cmd.Users {
+		// "user" is the name of the default user cert, and "ca" and
+		// "server" would overwrite those certs.
+		if user == "" || user == "ca" || user == "server" || user == "user" || filepath.Base(user) != user {
+			return fmt.Errorf("%w: %q", ErrInvalidUserName, user)
+		}
+	}
+	if err := os.MkdirAll(cmd.Dir, 0755); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	caTemplate := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             now,
+		NotAfter:              now.Add(genCALifetime),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caCert, caKey, err := cmd.genCert("ca", caTemplate, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	serverTemplate := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "server"},
+		NotBefore:   now,
+		NotAfter:    now.Add(genServerLifetime),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range cmd.ServerHosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+		}
+	}
+	if _, _, err := cmd.genCert("server", serverTemplate, caCert, caKey); err != nil {
+		return err
+	}
+
+	for _, user := range cmd.Users {
+		userTemplate := &x509.Certificate{
+			Subject:     pkix.Name{CommonName: user},
+			NotBefore:   now,
+			NotAfter:    now.Add(genUserLifetime),
+			KeyUsage:    x509.KeyUsageDigitalSignature,
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		if _, _, err := cmd.genCert(user, userTemplate, caCert, caKey); err != nil {
+			return err
+		}
+	}
+
+	// Link the first user's cert as the default used by the client
+	// commands, as `make default-user-cert` does.
+	for _, ext := range []string{".crt", ".key"} {
+		link := filepath.Join(cmd.Dir, "user"+ext)
+		if err := os.Symlink(cmd.Users[0]+ext, link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// genCert generates a key and a cert from template, signed by parent and
+// parentKey, and writes them as PEM files named name.crt and name.key. If
+// parent is nil, the cert is self-signed. It returns the cert and key.
+func (cmd *CmdGenCerts) genCert(name string, template, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not generate %s key: %w", name, err)
+	}
+	template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not generate %s serial number: %w", name, err)
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create %s cert: %w", name, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := writePEM(filepath.Join(cmd.Dir, name+".key"), "PRIVATE KEY", keyDER, 0600); err != nil {
+		return nil, nil, err
+	}
+	if err := writePEM(filepath.Join(cmd.Dir, name+".crt"), "CERTIFICATE", der, 0644); err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// writePEM writes a PEM file with a single block, failing if the file
+// already exists.
+func writePEM(filename, blockType string, der []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if err := pem.Encode(f, &pem.Block{Type: blockType, Bytes: der}); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/camh-/jobber/job"
+	"github.com/camh-/jobber/service"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func readCert(t *testing.T, filename string) *x509.Certificate {
+	t.Helper()
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	block, _ := pem.Decode(b)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestGenCerts(t *testing.T) {
+	dir := t.TempDir()
+	cmd := CmdGenCerts{
+		Dir:         dir,
+		ServerHosts: []string{"localhost", "jobber.example.com", "127.0.0.1"},
+		Users:       []string{"alice", "bob"},
+	}
+	require.NoError(t, cmd.Run())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(readCert(t, filepath.Join(dir, "ca.crt")))
+
+	server := readCert(t, filepath.Join(dir, "server.crt"))
+	for _, host := range []string{"localhost", "jobber.example.com", "127.0.0.1"} {
+		_, err := server.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		require.NoError(t, err, host)
+	}
+	_, err := server.Verify(x509.VerifyOptions{DNSName: "other.example.com", Roots: roots})
+	require.Error(t, err)
+
+	for _, user := range []string{"alice", "bob", "user"} {
+		cert := readCert(t, filepath.Join(dir, user+".crt"))
+		opts := x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}
+		_, err := cert.Verify(opts)
+		require.NoError(t, err, user)
+	}
+	require.Equal(t, "alice", readCert(t, filepath.Join(dir, "user.crt")).Subject.CommonName)
+
+	info, err := os.Stat(filepath.Join(dir, "alice.key"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// The generated certs work for the server and client commands.
+	creds, err := mTLSCreds(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt"))
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer(grpc.Creds(creds))
+	service.NewFake().RegisterWith(grpcServer)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go grpcServer.Serve(lis) //nolint:errcheck
+	defer grpcServer.Stop()
+
+	w := &bytes.Buffer{}
+	run := CmdRun{
+		clientCmd: clientCmd{
+			Address: lis.Addr().String(),
+			output:  w,
+			TLSCert: filepath.Join(dir, "user.crt"),
+			TLSKey:  filepath.Join(dir, "user.key"),
+			CACert:  filepath.Join(dir, "ca.crt"),
+		},
+		NoTimestamps: true,
+		JobSpec:      job.JobSpec{Command: "greeting"},
+	}
+	require.NoError(t, run.Run())
+	require.Contains(t, w.String(), "Hello world\n")
+
+	// Existing certs are not overwritten.
+	require.ErrorIs(t, cmd.Run(), os.ErrExist)
+}
+
+func TestGenCertsInvalidUser(t *testing.T) {
+	for _, user := range []string{"", "ca", "server", "user", "../alice"} {
+		cmd := CmdGenCerts{Dir: t.TempDir(), Users: []string{"alice", user}}
+		require.ErrorIs(t, cmd.Run(), ErrInvalidUserName, user)
+	}
+}
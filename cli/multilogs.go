@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	pb "github.com/camh-/jobber/pb"
+)
+
+// allMineLogs writes the logs of all the user's running jobs, interleaved
+// as they arrive. If following, it runs until interrupted, which is not an
+// error.
+func (cmd *CmdLogs) allMineLogs(cl pb.JobExecutorClient) error {
+	tsFormat := timestampFormat(!cmd.NoTimestamps, cmd.TSPrecision)
+	host, _, err := net.SplitHostPort(cmd.Address)
+	if err != nil {
+		host = cmd.Address
+	}
+	jobFormat := func(status *pb.JobStatus) logFormat {
+		id := string(status.GetJobId())
+		if cmd.Format == "syslog" {
+			// The job ID is already the syslog app-name.
+			return syslogFormat(host, id, status.GetUser())
+		}
+		return prefixFormat(id+": ", plainFormat(tsFormat))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = followJobs(ctx, selectStreams(cmd.writer(), cmd.Stream), cl, cmd.Follow, cmd.PollInterval, jobFormat)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// followJobs streams the logs of the user's running jobs to out, each in the
+// format returned by jobFormat for the job. Lines from different jobs are
+// interleaved as they arrive, but each line is written whole.
+//
+// If follow is false, it returns once the logs recorded so far for each job
+// have been written. Otherwise it lists the user's jobs every pollInterval
+// to pick up jobs started since, and streams each job's logs until the job
+// completes. It returns when ctx is done, or on the first error streaming
+// logs.
+func followJobs(ctx context.Context, out logOutput, cl pb.JobExecutorClient, follow bool, pollInterval time.Duration, jobFormat func(*pb.JobStatus) logFormat) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex // serialises writes to out
+	errs := make(chan error, 1)
+	streaming := map[string]bool{}
+	streamNewJobs := func() error {
+		resp, err := cl.List(ctx, &pb.ListRequest{})
+		if err != nil {
+			return err
+		}
+		for _, status := range resp.GetJobs() {
+			id := string(status.GetJobId())
+			if streaming[id] {
+				// Completed jobs are kept so a job that completed
+				// after being listed is not streamed again.
+				continue
+			}
+			streaming[id] = true
+			format := lockedFormat(&mu, jobFormat(status))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := pb.LogsRequest{JobId: []byte(id), Follow: follow}
+				if _, _, err := streamLogs(ctx, out, cl, &req, false /* lineNumbers */, format); err != nil && ctx.Err() == nil {
+					select {
+					case errs <- fmt.Errorf("%s: %w", id, err):
+					default:
+					}
+				}
+			}()
+		}
+		return nil
+	}
+
+	if err := streamNewJobs(); err != nil {
+		return err
+	}
+	if !follow {
+		wg.Wait()
+		select {
+		case err := <-errs:
+			return err
+		default:
+			return nil
+		}
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := streamNewJobs(); err != nil && ctx.Err() == nil {
+				return err
+			}
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// prefixFormat returns a logFormat that writes prefix before each line
+// written by format.
+func prefixFormat(prefix string, format logFormat) logFormat {
+	return func(w io.Writer, l logLine) {
+		fmt.Fprint(w, prefix)
+		format(w, l)
+	}
+}
+
+// lockedFormat returns a logFormat that writes each line formatted by
+// format to w in a single write while holding mu, so lines from formats
+// sharing mu are not mixed together. A newline is added to lines without
+// one so the next line starts on a line of its own.
+func lockedFormat(mu *sync.Mutex, format logFormat) logFormat {
+	return func(w io.Writer, l logLine) {
+		var buf bytes.Buffer
+		format(&buf, l)
+		if b := buf.Bytes(); len(b) > 0 && b[len(b)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write(buf.Bytes())
+	}
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/camh-/jobber/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// liveJobExecutor is a JobExecutor whose running jobs and their output are
+// controlled by the test. A job's logs are the lines sent on its channel,
+// and it completes when the channel is closed.
+type liveJobExecutor struct {
+	pb.UnimplementedJobExecutorServer
+
+	mu   sync.Mutex
+	jobs map[string]chan string
+}
+
+func (s *liveJobExecutor) start(id string) chan string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := make(chan string)
+	s.jobs[id] = ch
+	return ch
+}
+
+func (s *liveJobExecutor) complete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.jobs[id])
+	delete(s.jobs, id)
+}
+
+func (s *liveJobExecutor) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := &pb.ListResponse{}
+	for id := range s.jobs {
+		resp.Jobs = append(resp.Jobs, &pb.JobStatus{JobId: []byte(id), State: pb.JobStatus_JOBSTATE_RUNNING})
+	}
+	return resp, nil
+}
+
+func (s *liveJobExecutor) Logs(req *pb.LogsRequest, stream pb.JobExecutor_LogsServer) error {
+	s.mu.Lock()
+	ch := s.jobs[string(req.GetJobId())]
+	s.mu.Unlock()
+	for {
+		select {
+		case line, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			resp := pb.LogsResponse{Line: []byte(line), Timestamp: timestamppb.Now()}
+			if err := stream.Send(&resp); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// lines returns the lines written so far, sorted as lines from different
+// jobs may be written in any order.
+func (b *syncBuffer) lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lines := strings.Split(strings.TrimSuffix(b.buf.String(), "\n"), "\n")
+	sort.Strings(lines)
+	return lines
+}
+
+func TestFollowJobs(t *testing.T) {
+	creds, err := mTLSCreds("testdata/server.crt", "testdata/server.key", "testdata/ca.crt")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer(grpc.Creds(creds))
+	svc := &liveJobExecutor{jobs: map[string]chan string{}}
+	pb.RegisterJobExecutorServer(grpcServer, svc)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go grpcServer.Serve(lis) //nolint:errcheck
+	defer grpcServer.Stop()
+
+	cmd := newClientCmd(lis.Addr().String(), nil)
+	cl, err := cmd.connect()
+	require.NoError(t, err)
+	defer cmd.Close()
+
+	a, b := svc.start("a"), svc.start("b")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &syncBuffer{}
+	done := make(chan error)
+	jobFormat := func(status *pb.JobStatus) logFormat {
+		return prefixFormat(string(status.GetJobId())+": ", plainFormat(""))
+	}
+	go func() {
+		done <- followJobs(ctx, selectStreams(w, "both"), cl, true /* follow */, 10*time.Millisecond, jobFormat)
+	}()
+
+	// Sends block until the job's logs are being streamed.
+	a <- "a1\n"
+	b <- "b1" // no newline; one is added so lines are not joined
+	c := svc.start("c")
+	c <- "c1\n"
+	svc.complete("a")
+	b <- "b2\n"
+	c <- "c2\n"
+
+	expected := []string{"a: a1", "b: b1", "b: b2", "c: c1", "c: c2"}
+	require.Eventually(t, func() bool {
+		return strings.Join(w.lines(), "\n") == strings.Join(expected, "\n")
+	}, 5*time.Second, 10*time.Millisecond, "got %q", w.lines())
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+
+	pb "github.com/camh-/jobber/pb"
+)
+
+// statusFormat writes the status of jobs to w. wide adds more detail for
+// formats that leave it out by default, and now is the time the duration
+// of running jobs is measured until.
+type statusFormat func(w io.Writer, wide bool, now time.Time, statuses ...*pb.JobStatus) error
+
+// statusFormats are the formats of job statuses by the name given with
+// --output.
+var statusFormats = map[string]statusFormat{
+	"text": printStatus,
+	"json": printStatusJSON,
+}
+
+// statusFormatFor returns the status format named name, or the text format
+// if there is none.
+func statusFormatFor(name string) statusFormat {
+	if f, ok := statusFormats[name]; ok {
+		return f
+	}
+	return printStatus
+}
+
+// logFormats are the formats of log lines by the name given with --output,
+// other than text, which depends on other flags.
+var logFormats = map[string]logFormat{
+	"json": jsonLogFormat,
+}
+
+// jobJSON is a job status as output by printStatusJSON.
+type jobJSON struct {
+	ID              string       `json:"id"`
+	User            string       `json:"user"`
+	State           string       `json:"state"`
+	StartTime       time.Time    `json:"start_time"`
+	EndTime         *time.Time   `json:"end_time,omitempty"`
+	DurationSeconds *float64     `json:"duration_seconds,omitempty"`
+	Pid             uint32       `json:"pid,omitempty"`
+	Exit            *exitInspect `json:"exit,omitempty"`
+	PeakProcesses   uint32       `json:"peak_processes,omitempty"`
+	MemoryBytes     uint64       `json:"memory_bytes,omitempty"`
+	PeakMemoryBytes uint64       `json:"peak_memory_bytes,omitempty"`
+	CPUTimeUs       uint64       `json:"cpu_time_us,omitempty"`
+	Client          string       `json:"client,omitempty"`
+}
+
+// printStatusJSON writes the job statuses to w as a JSON array of objects,
+// one for each job, with all the fields of the wide text format.
+func printStatusJSON(w io.Writer, _ bool, now time.Time, statuses ...*pb.JobStatus) error {
+	jobs := []jobJSON{}
+	for _, status := range statuses {
+		j := jobJSON{
+			ID:              string(status.GetJobId()),
+			User:            status.GetUser(),
+			State:           jobState(status.GetState()),
+			StartTime:       status.GetStartTime().AsTime().UTC(),
+			Pid:             status.GetPid(),
+			PeakProcesses:   status.GetPeakProcesses(),
+			MemoryBytes:     status.GetMemoryBytes(),
+			PeakMemoryBytes: status.GetPeakMemoryBytes(),
+			CPUTimeUs:       status.GetCpuTimeUs(),
+			Client:          status.GetClient(),
+		}
+		if status.CompletionTime != nil {
+			end := status.GetCompletionTime().AsTime().UTC()
+			j.EndTime = &end
+		}
+		if d, ok := jobRunTime(status, now); ok {
+			secs := d.Seconds()
+			j.DurationSeconds = &secs
+		}
+		if exit := status.GetExit(); exit != nil {
+			j.Exit = &exitInspect{Code: exit.GetExitCode(), Signal: exit.GetSignal(), Reason: exit.GetReason()}
+		}
+		jobs = append(jobs, j)
+	}
+	b, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// logJSON is a log line as output by jsonLogFormat. A line saying how many
+// lines were dropped has only the timestamp, stream and dropped.
+type logJSON struct {
+	Index     *int64    `json:"index,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Stream    string    `json:"stream"`
+	Line      *string   `json:"line,omitempty"`
+	Dropped   int64     `json:"dropped,omitempty"`
+}
+
+// jsonLogFormat writes each log line as a line of JSON (newline-delimited
+// JSON), without the line's trailing newline.
+func jsonLogFormat(w io.Writer, l logLine) {
+	stream := "stdout"
+	if l.stream == pb.Stream_STREAM_STDERR {
+		stream = "stderr"
+	}
+	lj := logJSON{Timestamp: l.timestamp.UTC(), Stream: stream, Dropped: l.dropped}
+	if l.dropped == 0 {
+		line := string(bytes.TrimSuffix(l.line, []byte("\n")))
+		lj.Index, lj.Line = &l.index, &line
+	}
+	b, err := json.Marshal(lj)
+	if err != nil {
+		return
+	}
+	_, _ = w.Write(append(b, '\n'))
+}
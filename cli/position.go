@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// positionStore records how many lines of each job's logs have been shown
+// by `jobber logs --resume`, so a later resume can continue from there. The
+// positions are kept in a JSON file, keyed by server address and job ID.
+type positionStore struct {
+	filename string
+}
+
+// defaultPositionFile returns the file positions are stored in when none is
+// given on the command line.
+func defaultPositionFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "jobber", "log-positions.json"), nil
+}
+
+func positionKey(server, jobID string) string {
+	return server + "/" + jobID
+}
+
+// get returns the number of lines of the job's logs already shown, or zero
+// if none have been.
+func (ps positionStore) get(server, jobID string) (int64, error) {
+	positions, err := ps.load()
+	if err != nil {
+		return 0, err
+	}
+	return positions[positionKey(server, jobID)], nil
+}
+
+// set records that pos lines of the job's logs have been shown.
+func (ps positionStore) set(server, jobID string, pos int64) error {
+	positions, err := ps.load()
+	if err != nil {
+		return err
+	}
+	positions[positionKey(server, jobID)] = pos
+
+	b, err := json.MarshalIndent(positions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ps.filename), 0700); err != nil {
+		return err
+	}
+	// Write to a temporary file and rename it so the positions of other
+	// jobs are not lost if we are interrupted while writing.
+	tmp := ps.filename + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, ps.filename)
+}
+
+func (ps positionStore) load() (map[string]int64, error) {
+	positions := map[string]int64{}
+	b, err := os.ReadFile(ps.filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return positions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &positions); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
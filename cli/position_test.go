@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionStore(t *testing.T) {
+	store := positionStore{filename: filepath.Join(t.TempDir(), "jobber", "positions.json")}
+
+	// Nothing has been shown before the file exists.
+	pos, err := store.get("localhost:8443", "job-1")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), pos)
+
+	require.NoError(t, store.set("localhost:8443", "job-1", 10))
+	require.NoError(t, store.set("localhost:8443", "job-2", 3))
+	require.NoError(t, store.set("example.com:8443", "job-1", 7))
+	require.NoError(t, store.set("localhost:8443", "job-1", 12))
+
+	for key, expected := range map[[2]string]int64{
+		{"localhost:8443", "job-1"}:   12,
+		{"localhost:8443", "job-2"}:   3,
+		{"example.com:8443", "job-1"}: 7,
+		{"example.com:8443", "job-2"}: 0,
+	} {
+		pos, err := store.get(key[0], key[1])
+		require.NoError(t, err)
+		require.Equal(t, expected, pos, key)
+	}
+
+	require.NoError(t, os.WriteFile(store.filename, []byte("not json"), 0600))
+	_, err = store.get("localhost:8443", "job-1")
+	require.Error(t, err)
+}
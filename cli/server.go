@@ -1,23 +1,89 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/camh-/jobber/job"
+	pb "github.com/camh-/jobber/pb"
 	"github.com/camh-/jobber/service"
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 // CmdServe is a kong struct describing the flags and arguments for the
 // `jobber serve` subcommand.
 type CmdServe struct {
-	Listen string   `short:"l" default:":8443" help:"TCP listen address"`
-	Admin  []string `help:"admin users with full privileges"`
+	Listen         string         `short:"l" default:":8443" help:"TCP listen address"`
+	Admin          []string       `help:"admin users with full privileges"`
+	AdminOU        []string       `name:"admin-ou" help:"organizational units (OUs) whose users have full privileges, in addition to --admin"`
+	Role           []string       `help:"role of a user, as user=role: viewer to view all jobs but run and stop none, operator to run jobs and manage their own (the default), or admin"`
+	IdentitySource IdentitySource `name:"identity-source" enum:"cn,spiffe" default:"cn" help:"what identifies users in their client certificates: the subject's common name (CN), or the SPIFFE ID in the URI SAN of a SPIFFE SVID (cn,spiffe)"`
+	GroupRole      []string       `name:"group-role" help:"role of the users whose client certificates have an organizational unit (OU), as ou=role; a user's own --role takes precedence unless either is admin"`
 
-	TLSCert string `name:"tls-cert" default:"certs/server.crt" help:"TLS server cert"`
+	MaxProcesses uint32       `help:"maximum number of processes a job may run (0 for no maximum)"`
+	MaxMemory    job.ByteSize `help:"maximum memory (bytes, or with a unit such as 4Gi) a job may use (0 for no maximum)"`
+	MaxCPU       job.MilliCPU `name:"max-cpu" help:"maximum CPU (milliCPU, or CPUs such as 2.0) a job may use (0 for no maximum)"`
+
+	CommandDefaults string `type:"existingfile" help:"JSON file of default resource limits by command name, such as {\"java\": {\"Memory\": \"2Gi\"}}; limits a job sets override them"`
+	CommandLimits   string `type:"existingfile" help:"JSON file of the most jobs running at once by command name, such as {\"backup\": 2}, across all users"`
+
+	MaxLogStreams         int           `help:"maximum number of log streams open at once across all jobs (0 for no maximum)"`
+	MaxConcurrentRequests int           `help:"maximum number of requests handled at once across all users; further requests are rejected. Log streams are limited by --max-log-streams instead (0 for no maximum)"`
+	MaxRunningJobs        int           `help:"maximum number of jobs running at once; further jobs are queued if they ask to be, otherwise rejected (0 for no maximum)"`
+	MaxCompletedPerUser   int           `help:"maximum number of completed jobs kept for each user; when a job completes, the user's oldest completed jobs beyond it are cleaned up (0 for no maximum)"`
+	MaxIOLimits           int           `name:"max-io-limits" default:"16" help:"maximum number of io limits a job may have"`
+	MaxArgs               int           `default:"4096" help:"maximum number of arguments a job may have, over all its commands for a command sequence"`
+	MaxLogLinesPerSec     uint32        `help:"maximum lines per second kept from the output of jobs that do not set their own limit (0 for no limit)"`
+	MaxLogBufferLines     int           `help:"maximum number of lines of each job's output kept in memory; the oldest are dropped beyond it (0 for no maximum)"`
+	MaxLogBufferBytes     job.ByteSize  `help:"maximum bytes of each job's output kept in memory; the oldest lines are dropped beyond it (0 for no maximum)"`
+	LogMemoryBudget       job.ByteSize  `help:"maximum bytes of the output of all jobs kept in memory; beyond it the oldest lines are dropped, first from jobs that have completed (0 for no maximum)"`
+	MaxLineBytes          job.ByteSize  `default:"512" help:"size at which lines of the output of jobs are split into multiple log lines, bounding the memory used to read a line"`
+	MaxSyncTimeout        time.Duration `default:"1m" help:"longest a job run with exec-sync may run"`
+	MaxSyncOutput         job.ByteSize  `default:"1Mi" help:"most output returned for a job run with exec-sync"`
+	SampleInterval        time.Duration `default:"10s" help:"how often the resource usage of jobs run with --sample-resources is recorded"`
+	MaxSamples            int           `default:"360" help:"most resource usage samples kept for each job; the oldest are dropped beyond it"`
+	ShutdownConcurrency   int           `default:"16" help:"maximum number of jobs stopped at once on shutdown"`
+	StopGracePeriod       time.Duration `default:"0s" help:"time a stopped job has to exit after SIGTERM before SIGKILL (0 to SIGKILL at once)"`
+	DrainTimeout          time.Duration `default:"30s" help:"longest to wait on shutdown, once jobs are stopped, for requests in flight such as log streams before closing them; a second SIGINT or SIGTERM closes them at once"`
+	CgroupCheckInterval   time.Duration `default:"30s" help:"how often to check that cgroups are healthy"`
+	SetupTimeout          time.Duration `default:"30s" help:"longest a job may take to set up its cgroup and namespaces before it fails to start (0 for no limit)"`
+	UserIDPrefix          bool          `name:"user-id-prefix" help:"namespace job ids by their owner, such as alice/greeting-01234567"`
+	ReservedNamePrefix    []string      `help:"prefix the names given to jobs with --name may not start with, such as jobber-"`
+	IDFormat              string        `name:"id-format" enum:"hex,ulid,uuid" default:"hex" help:"format of the random suffix of job ids: a short hex number, a time-sortable ULID or a UUID (hex,ulid,uuid)"`
+	RuntimeBinary         string        `type:"existingfile" help:"program to run jobs in their container with, which must implement jobber rc (default: the server's own binary)"`
+	SpoolDir              string        `type:"path" help:"directory to keep the output of jobs in on disk as well as in memory (default: memory only)"`
+	SpoolRotateSize       job.ByteSize  `default:"64Mi" help:"size at which a job's spooled output is rotated and compressed"`
+	SpoolMaxSize          job.ByteSize  `default:"1Gi" help:"most disk used by spooled output; the oldest rotated output is removed to stay within it (0 for no maximum)"`
+	SpoolKeyFile          string        `name:"spool-encryption-key-file" type:"existingfile" help:"file of AES keys to encrypt spooled output with, one key ID and base64 key per line. The first key encrypts new output; the others are kept to read output encrypted before keys were rotated (default: not encrypted)"`
+	StateDir              string        `type:"path" help:"directory to keep jobs and their output in so completed jobs survive a restart (default: memory only)"`
+	StateDirGroupReadable bool          `help:"make --state-dir and the jobs in it readable by the server's group, so a --read-replica running as another user in the group can serve them"`
+	ReadReplica           bool          `help:"serve the status, list and logs of the jobs another server keeps in --state-dir, read-only, without running any jobs"`
+	ReplicaRefresh        time.Duration `default:"1s" help:"how often a read replica reloads jobs from --state-dir"`
+	EventLog              string        `type:"path" help:"file to append a JSON line to for each job that starts, is stopped, completes or fails. It is reopened for each event, so it can be rotated by renaming it"`
+	Pushgateway           string        `help:"URL of a Prometheus Pushgateway to push the duration, exit code, peak memory and CPU time of each job to when it completes (default: none)"`
+	PushgatewayRetention  time.Duration `default:"1h" help:"how long the metrics pushed to --pushgateway for each job are kept before they are deleted (0 to keep them)"`
+	UserNSHostID          uint32        `name:"userns-host-id" default:"100000" help:"first host user and group ID that root in jobs isolating users is mapped to"`
+	UserNSSize            uint32        `name:"userns-size" default:"65536" help:"number of host user and group IDs mapped into jobs isolating users, from --userns-host-id"`
+	SSEListen             string        `name:"sse-listen" help:"TCP listen address of an HTTPS server streaming job logs as Server-Sent Events at /jobs/<id>/logs, with the same TLS and client cert auth as gRPC (default: none)"`
+	MetricsListen         string        `name:"metrics-listen" help:"TCP listen address of an HTTPS server serving the server's metrics, such as the number of log streams open, in the Prometheus text format at /metrics, with the same TLS and client cert auth as gRPC (default: none)"`
+	GRPCWebListen         string        `name:"grpc-web-listen" help:"TCP listen address of an HTTPS server serving the gRPC API as gRPC-Web for browsers, with the same TLS and client cert auth as gRPC (default: none)"`
+	GRPCWebOrigin         []string      `name:"grpc-web-origin" help:"origins of web UIs allowed to call the gRPC-Web server from browsers, such as https://jobber.example.com; * is not allowed"`
+	AllowUnprivileged     bool          `help:"start without the privileges needed to run jobs, warning instead. Jobs are refused while cgroups are unavailable and jobs needing other missing privileges fail"`
+
+	TLSCert string `name:"tls-cert" default:"certs/server.crt" help:"TLS server cert. It and the key are reloaded for new connections when they change"`
 	TLSKey  string `name:"tls-key" default:"certs/server.key" help:"TLS server key"`
 	CACert  string `name:"ca-cert" default:"certs/ca.crt" help:"CA for authenticating users"`
 }
@@ -35,15 +101,31 @@ type CmdRunJob struct {
 // up those namespaces and cgroups.
 type CmdRunContainer struct {
 	job.JobSpec
-	ID string `required:"" help:"job id"`
+	ID           string        `required:"" help:"job id"`
+	Sequence     []string      `sep:"none" help:"command of a sequence, as a JSON array of the command and its arguments"`
+	SetupTimeout time.Duration `help:"fail if setting up the job takes longer than this (0 for no limit)"`
 }
 
 // Run is the entrypoint for the `jobber serve` cli command. It starts a
 // grpc server and serves a fake implementation of the JobExecutor service.
 // gRPC server reflection is enabled on the gRPC server.
 func (cmd *CmdServe) Run() error {
-	if err := job.InitCgroups(); err != nil {
-		return err
+	// A read replica runs no jobs, so needs no privileges.
+	if !cmd.ReadReplica {
+		if err := job.CheckPrivileges(); err != nil {
+			if !cmd.AllowUnprivileged {
+				return fmt.Errorf("%w, or start with --allow-unprivileged", err)
+			}
+			fmt.Fprintln(os.Stderr, "warning:", err)
+		}
+		if err := job.InitCgroups(); err != nil {
+			if !cmd.AllowUnprivileged {
+				return err
+			}
+			// The cgroup health check keeps failing, so jobs are
+			// refused with the reason rather than failing to start.
+			fmt.Fprintln(os.Stderr, "warning:", err)
+		}
 	}
 
 	l, err := net.Listen("tcp", cmd.Listen)
@@ -51,29 +133,386 @@ func (cmd *CmdServe) Run() error {
 		return err
 	}
 
-	creds, err := mTLSCreds(cmd.TLSCert, cmd.TLSKey, cmd.CACert)
+	serverTLS, err := newReloadingTLS(cmd.TLSCert, cmd.TLSKey, cmd.CACert)
 	if err != nil {
 		return err
 	}
+	creds := credentials.NewTLS(serverTLS.config())
+	authFunc := CertToUserWithAdminOUs(cmd.IdentitySource, cmd.AdminOU)
 	grpcServer := grpc.NewServer(
 		grpc.Creds(creds),
-		grpc.UnaryInterceptor(grpc_auth.UnaryServerInterceptor(CNToUser)),
-		grpc.StreamInterceptor(grpc_auth.StreamServerInterceptor(CNToUser)),
+		grpc.ChainUnaryInterceptor(
+			service.NewConcurrencyLimiter(cmd.MaxConcurrentRequests).UnaryServerInterceptor(),
+			grpc_auth.UnaryServerInterceptor(authFunc),
+		),
+		grpc.StreamInterceptor(grpc_auth.StreamServerInterceptor(authFunc)),
 	)
 
+	// done is closed once running jobs have been stopped by a Shutdown
+	// request or a signal. Requests in flight, such as Logs streams of
+	// the stopped jobs, are then left up to --drain-timeout to complete
+	// before Run returns. force is closed by a second signal to return
+	// without waiting for them, or for jobs to stop.
 	done := make(chan struct{})
+	force := make(chan struct{})
+	stopped := make(chan struct{})
 	go func() {
-		<-done
-		grpcServer.GracefulStop()
+		defer close(stopped)
+		select {
+		case <-done:
+		case <-force:
+			grpcServer.Stop()
+			return
+		}
+		drained := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-force:
+			grpcServer.Stop()
+		case <-time.After(cmd.DrainTimeout):
+			// XXX Should log, but no logger yet
+			fmt.Fprintf(os.Stderr, "requests still in flight after %v, closing them\n", cmd.DrainTimeout)
+			grpcServer.Stop()
+		}
 	}()
 
-	jobberService := service.NewJobExecutor(done, ProcSelfArgMaker, cmd.Admin)
+	maxResources := job.ResourceLimits{
+		MaxProcesses: cmd.MaxProcesses,
+		Memory:       cmd.MaxMemory,
+		CPU:          cmd.MaxCPU,
+	}
+	var commandDefaults job.CommandDefaults
+	if cmd.CommandDefaults != "" {
+		if commandDefaults, err = job.LoadCommandDefaults(cmd.CommandDefaults); err != nil {
+			return err
+		}
+	}
+	var commandLimits job.CommandLimits
+	if cmd.CommandLimits != "" {
+		if commandLimits, err = job.LoadCommandLimits(cmd.CommandLimits); err != nil {
+			return err
+		}
+	}
+	var spool *job.Spool
+	if cmd.SpoolDir != "" {
+		spool, err = job.NewSpool(cmd.SpoolDir, int64(cmd.SpoolRotateSize), int64(cmd.SpoolMaxSize))
+		if err != nil {
+			return err
+		}
+		if cmd.SpoolKeyFile != "" {
+			keys, err := job.LoadSpoolKeys(cmd.SpoolKeyFile)
+			if err != nil {
+				return err
+			}
+			spool.Encrypt(keys)
+		}
+	}
+	var store job.Store
+	if cmd.StateDir != "" {
+		fileStore, err := job.NewFileStore(cmd.StateDir)
+		if err != nil {
+			return err
+		}
+		if cmd.StateDirGroupReadable {
+			if err := fileStore.ShareWithGroup(); err != nil {
+				return fmt.Errorf("could not share state directory: %w", err)
+			}
+		}
+		store = fileStore
+	}
+	storeOpt := job.WithStore(store)
+	if cmd.ReadReplica {
+		storeOpt = job.WithReadReplica(store, cmd.ReplicaRefresh)
+	}
+	var eventLog *job.EventLog
+	if cmd.EventLog != "" {
+		if eventLog, err = job.NewEventLog(cmd.EventLog); err != nil {
+			return err
+		}
+	}
+	var pushgateway *job.Pushgateway
+	if cmd.Pushgateway != "" {
+		if pushgateway, err = job.NewPushgateway(cmd.Pushgateway, cmd.PushgatewayRetention); err != nil {
+			return err
+		}
+	}
+	roles, err := parseRoles(cmd.Role)
+	if err != nil {
+		return err
+	}
+	groupRoles, err := parseRoles(cmd.GroupRole)
+	if err != nil {
+		return err
+	}
+	argMaker := ProcSelfArgMaker
+	if cmd.RuntimeBinary != "" {
+		argMaker = BinaryArgMaker(cmd.RuntimeBinary)
+	}
+	jobberService := service.NewJobExecutor(done, argMaker, cmd.Admin,
+		job.WithMaxResources(maxResources),
+		job.WithCommandDefaults(commandDefaults),
+		job.WithCommandLimits(commandLimits),
+		job.WithRoles(roles),
+		job.WithGroupRoles(groupRoles),
+		job.WithMaxRunningJobs(cmd.MaxRunningJobs),
+		job.WithMaxCompletedPerUser(cmd.MaxCompletedPerUser),
+		job.WithMaxIOLimits(cmd.MaxIOLimits),
+		job.WithMaxArgs(cmd.MaxArgs),
+		job.WithReservedNamePrefixes(cmd.ReservedNamePrefix),
+		job.WithDefaultMaxLogLinesPerSec(cmd.MaxLogLinesPerSec),
+		job.WithMaxLineBytes(int(cmd.MaxLineBytes)),
+		job.WithMaxLogBuffer(cmd.MaxLogBufferLines, int(cmd.MaxLogBufferBytes)),
+		job.WithLogMemoryBudget(int(cmd.LogMemoryBudget)),
+		job.WithSyncLimits(cmd.MaxSyncTimeout, int(cmd.MaxSyncOutput)),
+		job.WithResourceSampling(cmd.SampleInterval, cmd.MaxSamples),
+		job.WithShutdownConcurrency(cmd.ShutdownConcurrency),
+		job.WithStopGracePeriod(cmd.StopGracePeriod),
+		job.WithSetupTimeout(cmd.SetupTimeout),
+		job.WithUserIDPrefix(cmd.UserIDPrefix),
+		job.WithIDFormat(job.IDFormat(cmd.IDFormat)),
+		job.WithSpool(spool),
+		storeOpt,
+		job.WithEventLog(eventLog),
+		job.WithPushgateway(pushgateway),
+		job.WithUserNamespaceIDs(job.IDMapping{HostID: cmd.UserNSHostID, Size: cmd.UserNSSize}),
+	)
+	jobberService.SetMaxLogStreams(cmd.MaxLogStreams)
 	jobberService.RegisterWith(grpcServer)
 
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	go func() {
+		<-done
+		// Stop load balancers sending new requests while the server
+		// drains. Later cgroup checks cannot make it serving again.
+		healthServer.Shutdown()
+	}()
+	if cmd.ReadReplica {
+		healthServer.SetServingStatus(pb.JobExecutor_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	} else {
+		jobberService.MonitorCgroups(done, cmd.CgroupCheckInterval, healthServer)
+	}
+
 	reflection.Register(grpcServer)
 
-	// grpcServer takes ownership of l (net.Listen)
-	return grpcServer.Serve(l)
+	if cmd.SSEListen != "" {
+		if err := cmd.serveSSE(done, jobberService, serverTLS); err != nil {
+			return err
+		}
+	}
+	if cmd.MetricsListen != "" {
+		if err := cmd.serveMetrics(done, jobberService, serverTLS); err != nil {
+			return err
+		}
+	}
+	if cmd.GRPCWebListen != "" {
+		if err := cmd.serveGRPCWeb(done, grpcServer, serverTLS); err != nil {
+			return err
+		}
+	}
+
+	reloadTLSOnSIGHUP(done, serverTLS)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		select {
+		case sig := <-sigs:
+			// XXX Should log, but no logger yet
+			fmt.Fprintf(os.Stderr, "received %v, stopping jobs and shutting down\n", sig)
+			go shutdownServer(jobberService)
+		case <-done:
+		}
+		select {
+		case sig := <-sigs:
+			fmt.Fprintf(os.Stderr, "received %v again, shutting down now\n", sig)
+			close(force)
+		case <-stopped:
+		}
+	}()
+
+	// grpcServer takes ownership of l (net.Listen). Serve returns as soon
+	// as it stops accepting connections, so wait for it to stop.
+	if err := grpcServer.Serve(l); err != nil {
+		// Do not leave jobs running when the server fails.
+		shutdownServer(jobberService)
+		<-stopped
+		return err
+	}
+	<-stopped
+	return nil
+}
+
+// reloadTLSOnSIGHUP reloads the server's TLS cert, key and CA certs on
+// each SIGHUP until done is closed, reporting whether they were reloaded.
+// They are reloaded anyway when a new connection finds the files have
+// changed, but then a failure to load them is not reported.
+func reloadTLSOnSIGHUP(done <-chan struct{}, serverTLS *reloadingTLS) {
+	hups := make(chan os.Signal, 1)
+	signal.Notify(hups, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hups)
+		for {
+			select {
+			case <-hups:
+				// XXX Should log, but no logger yet
+				switch reloaded, err := serverTLS.reload(); {
+				case err != nil:
+					fmt.Fprintf(os.Stderr, "could not reload TLS cert, key and CA certs: %v\n", err)
+				case reloaded:
+					fmt.Fprintln(os.Stderr, "reloaded TLS cert, key and CA certs")
+				default:
+					fmt.Fprintln(os.Stderr, "TLS cert, key and CA certs unchanged")
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// shutdownServer stops the jobs of svc and then the server, reporting how
+// many jobs were stopped.
+func shutdownServer(svc *service.JobExecutor) {
+	n, err := svc.ShutdownServer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not stop jobs: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "stopped %d jobs\n", n)
+}
+
+// serveSSE starts an HTTPS server on the --sse-listen address streaming the
+// logs of jobs as Server-Sent Events, until done is closed.
+func (cmd *CmdServe) serveSSE(done <-chan struct{}, svc *service.JobExecutor, serverTLS *reloadingTLS) error {
+	l, err := net.Listen("tcp", cmd.SSEListen)
+	if err != nil {
+		return err
+	}
+	cfg := serverTLS.config()
+	mux := http.NewServeMux()
+	mux.Handle("/jobs/", svc.LogsHandler(HTTPCertToUserWithAdminOUs(cmd.IdentitySource, cmd.AdminOU)))
+	srv := &http.Server{Handler: mux, TLSConfig: cfg, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-done
+		// Followers would hold off a graceful shutdown indefinitely.
+		_ = srv.Close()
+	}()
+	go func() {
+		if err := srv.ServeTLS(l, "", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			// XXX Should log, but no logger yet
+			fmt.Fprintf(os.Stderr, "SSE server failed: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// serveMetrics starts an HTTPS server on the --metrics-listen address
+// serving the metrics of svc at /metrics, until done is closed. Any client
+// with a valid client certificate may read them, as they are of the server
+// as a whole rather than of any user's jobs.
+func (cmd *CmdServe) serveMetrics(done <-chan struct{}, svc *service.JobExecutor, serverTLS *reloadingTLS) error {
+	l, err := net.Listen("tcp", cmd.MetricsListen)
+	if err != nil {
+		return err
+	}
+	cfg := serverTLS.config()
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", svc.MetricsHandler())
+	srv := &http.Server{Handler: mux, TLSConfig: cfg, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-done
+		_ = srv.Close()
+	}()
+	go func() {
+		if err := srv.ServeTLS(l, "", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			// XXX Should log, but no logger yet
+			fmt.Fprintf(os.Stderr, "metrics server failed: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// serveGRPCWeb starts an HTTPS server on the --grpc-web-listen address
+// serving the services of gs as gRPC-Web, until done is closed.
+func (cmd *CmdServe) serveGRPCWeb(done <-chan struct{}, gs *grpc.Server, serverTLS *reloadingTLS) error {
+	l, err := net.Listen("tcp", cmd.GRPCWebListen)
+	if err != nil {
+		return err
+	}
+	cfg := serverTLS.config()
+	handler := service.GRPCWebHandler(gs, cmd.GRPCWebOrigin)
+	srv := &http.Server{Handler: handler, TLSConfig: cfg, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-done
+		// Log streams would hold off a graceful shutdown indefinitely.
+		_ = srv.Close()
+	}()
+	go func() {
+		if err := srv.ServeTLS(l, "", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			// XXX Should log, but no logger yet
+			fmt.Fprintf(os.Stderr, "gRPC-Web server failed: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// Validate checks that a read replica has a state directory to read jobs
+// from, and a group-readable state directory one to share, that jobs
+// isolating users are not mapped to root or the server's own IDs, that spool
+// encryption keys have a spool to encrypt and no state directory keeping
+// the same output unencrypted, that job IDs are only prefixed by users that
+// can be part of one, and that gRPC-Web origins are listed rather than
+// allowed by a wildcard. It is called by kong after parsing the command
+// line.
+func (cmd *CmdServe) Validate() error {
+	if cmd.ReadReplica && cmd.StateDir == "" {
+		return errors.New("--read-replica requires --state-dir")
+	}
+	if cmd.StateDirGroupReadable && cmd.StateDir == "" {
+		return errors.New("--state-dir-group-readable requires --state-dir")
+	}
+	userNSIDs := job.IDMapping{HostID: cmd.UserNSHostID, Size: cmd.UserNSSize}
+	if err := userNSIDs.Check(uint32(os.Getuid()), uint32(os.Getgid())); err != nil {
+		return fmt.Errorf("invalid --userns-host-id or --userns-size: %w", err)
+	}
+	if cmd.SpoolKeyFile != "" && cmd.SpoolDir == "" {
+		return errors.New("--spool-encryption-key-file requires --spool-dir")
+	}
+	if cmd.SpoolKeyFile != "" && cmd.StateDir != "" {
+		return errors.New("--spool-encryption-key-file cannot be used with --state-dir, which keeps the output of jobs unencrypted")
+	}
+	if cmd.UserIDPrefix && cmd.IdentitySource == IdentitySpiffe {
+		return errors.New("--user-id-prefix cannot be used with --identity-source spiffe, as SPIFFE IDs contain slashes")
+	}
+	for _, origin := range cmd.GRPCWebOrigin {
+		if origin == "*" {
+			return errors.New("--grpc-web-origin cannot be *, as any web page could then call the server with the user's client certificate")
+		}
+	}
+	return nil
+}
+
+// parseRoles parses the roles of users or groups given as name=role.
+func parseRoles(specs []string) (map[string]job.Role, error) {
+	roles := make(map[string]job.Role, len(specs))
+	for _, spec := range specs {
+		name, roleName, ok := strings.Cut(spec, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid role %q: must be name=role", spec)
+		}
+		role, err := job.ParseRole(roleName)
+		if err != nil {
+			return nil, fmt.Errorf("role of %s: %w", name, err)
+		}
+		roles[name] = role
+	}
+	return roles, nil
 }
 
 // CmdRunJob is an internal command for directly running a container. It is
@@ -87,12 +526,28 @@ func (cmd *CmdRunJob) Run() error {
 	if err := j.Start("owner"); err != nil {
 		return err
 	}
-	for l := range j.AttachOutfeed(true /* follow */, nil) {
+	for l := range j.AttachOutfeed(true /* follow */, 0, nil) {
 		fmt.Print(string(l.Line))
 	}
 	return j.Status.ExitError
 }
 
+// Validate decodes the --sequence flags into the job spec's command
+// sequence, sets its setup timeout and validates the job spec. It is called
+// by kong after parsing the command line.
+func (cmd *CmdRunContainer) Validate() error {
+	cmd.Commands = nil
+	for _, s := range cmd.Sequence {
+		var argv []string
+		if err := json.Unmarshal([]byte(s), &argv); err != nil {
+			return fmt.Errorf("could not parse sequence command %s: %w", s, err)
+		}
+		cmd.Commands = append(cmd.Commands, argv)
+	}
+	cmd.JobSpec.SetupTimeout = cmd.SetupTimeout
+	return cmd.JobSpec.Validate()
+}
+
 // CmdRunContainer implements `jobber rc` to execute part 2 of the
 // container running process - setting up the cgroup(s) and namespace(s)
 // and execing the job's command.
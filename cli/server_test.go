@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeValidate(t *testing.T) {
+	// The defaults of the user namespace flags, which kong sets.
+	const userNSHostID, userNSSize = 100000, 65536
+
+	cmd := CmdServe{UserNSHostID: userNSHostID, UserNSSize: userNSSize, GRPCWebOrigin: []string{"https://ui.example.com"}}
+	require.NoError(t, cmd.Validate())
+
+	cmd.GRPCWebOrigin = append(cmd.GRPCWebOrigin, "*")
+	require.Error(t, cmd.Validate())
+
+	cmd = CmdServe{UserNSHostID: userNSHostID, UserNSSize: userNSSize, SpoolDir: "spool", SpoolKeyFile: "keys"}
+	require.NoError(t, cmd.Validate())
+	cmd.StateDir = "state"
+	require.Error(t, cmd.Validate())
+
+	cmd = CmdServe{UserNSHostID: 0, UserNSSize: userNSSize}
+	require.ErrorContains(t, cmd.Validate(), "--userns-host-id")
+	cmd = CmdServe{UserNSHostID: userNSHostID, UserNSSize: 0}
+	require.ErrorContains(t, cmd.Validate(), "--userns-size")
+}
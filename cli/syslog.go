@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Syslog priority values for log lines. All job output is logged with the
+// user facility. Lines added by jobber itself (such as resource limit
+// markers) are logged with a higher severity than the job's own output.
+const (
+	syslogFacilityUser   = 1
+	syslogSeverityNotice = 5
+	syslogSeverityInfo   = 6
+)
+
+// syslogSDID is the structured data ID for the jobber parameters of a
+// syslog message. jobber does not have an IANA enterprise number so the
+// example number reserved for documentation is used.
+const syslogSDID = "jobber@32473"
+
+// syslogFormat returns a logFormat that writes each log line as an RFC5424
+// syslog message from hostname, with jobID as the app-name and the job's
+// owner as structured data.
+func syslogFormat(hostname, jobID, owner string) logFormat {
+	hostname = syslogHeaderField(hostname, 255)
+	appName := syslogHeaderField(jobID, 48)
+	sd := fmt.Sprintf(`[%s user="%s"]`, syslogSDID, syslogParamValue(owner))
+	return func(w io.Writer, l logLine) {
+		line := bytes.TrimSuffix(l.line, []byte("\n"))
+		severity := syslogSeverityInfo
+		if bytes.HasPrefix(line, []byte("[jobber]")) {
+			severity = syslogSeverityNotice
+		}
+		pri := syslogFacilityUser*8 + severity
+		ts := l.timestamp.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+		fmt.Fprintf(w, "<%d>1 %s %s %s - - %s %s\n", pri, ts, hostname, appName, sd, line)
+	}
+}
+
+// syslogHeaderField returns s as a valid syslog header field of at most
+// maxLen characters. Header fields are printable US-ASCII without spaces
+// and "-" if empty.
+func syslogHeaderField(s string, maxLen int) string {
+	if s == "" {
+		return "-"
+	}
+	s = strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+	return s
+}
+
+// syslogParamValue escapes s for use as a structured data parameter value.
+func syslogParamValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogFormat(t *testing.T) {
+	ts := time.Date(2022, 5, 27, 12, 24, 4, 123456789, time.FixedZone("AEST", 10*60*60))
+	tests := map[string]struct {
+		host, jobID, owner string
+		line               string
+		expected           string
+	}{
+		"output line": {
+			host: "jobber.example.com", jobID: "greeting-01234567", owner: "eve",
+			line:     "Hello world\n",
+			expected: `<14>1 2022-05-27T02:24:04.123456Z jobber.example.com greeting-01234567 - - [jobber@32473 user="eve"] Hello world` + "\n",
+		},
+		"no trailing newline": {
+			host: "jobber.example.com", jobID: "greeting-01234567", owner: "eve",
+			line:     "Goodbye",
+			expected: `<14>1 2022-05-27T02:24:04.123456Z jobber.example.com greeting-01234567 - - [jobber@32473 user="eve"] Goodbye` + "\n",
+		},
+		"jobber marker": {
+			host: "jobber.example.com", jobID: "hog-89abcdef", owner: "eve",
+			line:     "[jobber] memory limit reached, process throttled\n",
+			expected: `<13>1 2022-05-27T02:24:04.123456Z jobber.example.com hog-89abcdef - - [jobber@32473 user="eve"] [jobber] memory limit reached, process throttled` + "\n",
+		},
+		"sanitized fields": {
+			host: "", jobID: "my job-0123456789012345678901234567890123456789abcdef", owner: `"eve]\`,
+			line:     "x\n",
+			expected: `<14>1 2022-05-27T02:24:04.123456Z - my_job-0123456789012345678901234567890123456789a - - [jobber@32473 user="\"eve\]\\"] x` + "\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			syslogFormat(tc.host, tc.jobID, tc.owner)(w, logLine{timestamp: ts, line: []byte(tc.line)})
+			require.Equal(t, tc.expected, w.String())
+		})
+	}
+}
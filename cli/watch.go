@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	pb "github.com/camh-/jobber/pb"
+	"golang.org/x/exp/slices"
+)
+
+// Run follows the output of a job together with its lifecycle events until
+// the job completes. It runs until interrupted if the job does not
+// complete, which is not an error.
+func (cmd *CmdWatch) Run() error {
+	cl, err := cmd.connect()
+	if err != nil {
+		return err
+	}
+	defer cmd.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	format := plainFormat(timestampFormat(true, cmd.TSPrecision))
+	err = watchJob(ctx, cmd.writer(), cl, cmd.JobID, format)
+	if ctx.Err() != nil {
+		fmt.Fprintln(cmd.errWriter(), "detached from job", cmd.JobID)
+		return nil
+	}
+	return err
+}
+
+// watchJob writes the lifecycle events of the job identified by id merged
+// with its output to w, each in format, in chronological order. The events
+// come from the job's status: when it was queued or started, and when it
+// completed. Markers of the job hitting its resource limits are lines of
+// its output, so are already in order. It returns when the job completes
+// and the last of its output and its completion have been written.
+func watchJob(ctx context.Context, w io.Writer, cl pb.JobExecutorClient, id string, format logFormat) error {
+	getStatus := func() (*pb.JobStatus, error) {
+		resp, err := cl.Status(ctx, &pb.StatusRequest{JobId: []byte(id)})
+		return resp.GetStatus(), err
+	}
+	status, err := getStatus()
+	if err != nil {
+		return err
+	}
+	a := &activityWriter{w: w, format: format}
+	queued := status.GetState() == pb.JobStatus_JOBSTATE_QUEUED
+	if queued {
+		a.event(status.GetStartTime().AsTime(), "job queued by "+status.GetUser())
+	} else {
+		a.started(status)
+	}
+
+	stream, err := cl.Logs(ctx, &pb.LogsRequest{JobId: []byte(id), Follow: true})
+	if err != nil {
+		return err
+	}
+	var joiner lineJoiner
+	flush := func() {
+		for _, l := range joiner.flush() {
+			a.line(l)
+		}
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			flush()
+			return err
+		}
+		if queued {
+			// Following the logs of a queued job waits for it to
+			// start, so it has started (or been cancelled) by now.
+			if status, err = getStatus(); err != nil {
+				return err
+			}
+			if status.GetState() != pb.JobStatus_JOBSTATE_CANCELLED {
+				a.started(status)
+			}
+			queued = false
+		}
+		if resp.Exit != nil {
+			continue
+		}
+		l := logLine{timestamp: resp.Timestamp.AsTime(), stream: resp.GetStream()}
+		if n := resp.GetDropped(); n > 0 {
+			flush()
+			marker := l
+			marker.line = []byte(fmt.Sprintf("[jobber] %d earlier lines dropped from the log buffer\n", n))
+			marker.dropped = n
+			a.line(marker)
+		}
+		l.index, l.line = resp.GetIndex(), resp.Line
+		if l, ok := joiner.join(l, resp.GetPartial()); ok {
+			a.line(l)
+		}
+	}
+	flush()
+
+	if status, err = getStatus(); err != nil {
+		return err
+	}
+	if exit := status.GetExit(); exit != nil {
+		a.event(status.GetCompletionTime().AsTime(), "job "+exit.GetReason())
+	}
+	a.flush()
+	return nil
+}
+
+// activityWriter writes the activity of a job, its lifecycle events and
+// lines of output, in chronological order. Lines come in order from the
+// job's log stream while events are added as they become known, so each
+// event is held until a line later than it is written or the writer is
+// flushed. Events are written as lines of the job's output prefixed with
+// [jobber], like the markers the server adds.
+type activityWriter struct {
+	w      io.Writer
+	format logFormat
+	// events are the events not yet written, oldest first.
+	events []logLine
+}
+
+// event adds an event that happened at t, described by text.
+func (a *activityWriter) event(t time.Time, text string) {
+	l := logLine{timestamp: t, line: []byte("[jobber] " + text + "\n")}
+	i := sort.Search(len(a.events), func(i int) bool { return a.events[i].timestamp.After(t) })
+	a.events = slices.Insert(a.events, i, l)
+}
+
+// started adds the event of the job with status starting.
+func (a *activityWriter) started(status *pb.JobStatus) {
+	text := "job started by " + status.GetUser()
+	if pid := status.GetPid(); pid != 0 {
+		text += fmt.Sprintf(" as pid %d", pid)
+	}
+	a.event(status.GetStartTime().AsTime(), text)
+}
+
+// line writes l after the events that happened before or at the same time
+// as it.
+func (a *activityWriter) line(l logLine) {
+	for len(a.events) > 0 && !a.events[0].timestamp.After(l.timestamp) {
+		a.format(a.w, a.events[0])
+		a.events = a.events[1:]
+	}
+	a.format(a.w, l)
+}
+
+// flush writes the events not yet written.
+func (a *activityWriter) flush() {
+	for _, l := range a.events {
+		a.format(a.w, l)
+	}
+	a.events = nil
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityWriter(t *testing.T) {
+	start := time.Date(2022, 5, 27, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	w := &bytes.Buffer{}
+	a := &activityWriter{w: w, format: plainFormat("15:04:05.000")}
+
+	// Events are held until a later line is written, however early they
+	// are known.
+	a.event(at(0), "job started by eve as pid 42")
+	a.event(at(900), "job exited with code 137 after hitting its memory limit")
+	a.line(logLine{timestamp: at(0), line: []byte("loading\n")})
+	a.line(logLine{timestamp: at(200), line: []byte("allocating\n")})
+	a.line(logLine{timestamp: at(500), line: []byte("[jobber] memory limit exceeded, process killed by the OOM killer\n")})
+	a.line(logLine{timestamp: at(600), line: []byte("killed\n")})
+	a.flush()
+
+	expected := `12:00:00.000 [jobber] job started by eve as pid 42
+12:00:00.000 loading
+12:00:00.200 allocating
+12:00:00.500 [jobber] memory limit exceeded, process killed by the OOM killer
+12:00:00.600 killed
+12:00:00.900 [jobber] job exited with code 137 after hitting its memory limit
+`
+	require.Equal(t, expected, w.String())
+}
@@ -1,6 +1,6 @@
 module github.com/camh-/jobber
 
-go 1.18
+go 1.20
 
 require (
 	github.com/alecthomas/kong v0.5.0
@@ -0,0 +1,40 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrCgroupsUnavailable = errors.New("cgroups unavailable")
+
+// CheckCgroups checks that the jobber cgroup can be used to run jobs: that
+// it exists, has the controllers jobs need enabled for its children, and is
+// writable. It returns an error wrapping ErrCgroupsUnavailable describing
+// the first problem found.
+func CheckCgroups() error {
+	filename := filepath.Join(cgroupRoot, "cgroup.subtree_control")
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCgroupsUnavailable, err)
+	}
+
+	enabled := map[string]bool{}
+	for _, c := range strings.Fields(string(b)) {
+		enabled[c] = true
+	}
+	for _, c := range strings.Fields(cgroupControllers) {
+		if c = strings.TrimPrefix(c, "+"); !enabled[c] {
+			return fmt.Errorf("%w: %s controller not enabled", ErrCgroupsUnavailable, c)
+		}
+	}
+
+	// Writing the enabled controllers again changes nothing but fails if
+	// the cgroup filesystem has become read-only.
+	if err := writeFile(filename, []byte(cgroupControllers), 0700); err != nil {
+		return fmt.Errorf("%w: %v", ErrCgroupsUnavailable, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CommandDefaults are the default resource limits of jobs by the base name
+// of their command, such as "java", for commands known to need more (or
+// less) than other jobs.
+type CommandDefaults map[string]ResourceLimits
+
+// WithCommandDefaults sets the default resource limits of jobs by the base
+// name of their command. Limits a job sets override them, and they are
+// clamped to the tracker's maximum resources like any other.
+func WithCommandDefaults(defaults CommandDefaults) TrackerOption {
+	return func(t *Tracker) {
+		t.commandDefaults = defaults
+	}
+}
+
+// LoadCommandDefaults reads CommandDefaults from the JSON file at path, an
+// object of command names to resource limits, such as
+//
+//	{"java": {"Memory": "2Gi", "CPU": "2.0"}}
+//
+// where the quantities may be strings in the format of the corresponding
+// command line flags or numbers of bytes and milliCPUs.
+func LoadCommandDefaults(path string) (CommandDefaults, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var defaults CommandDefaults
+	if err := json.Unmarshal(b, &defaults); err != nil {
+		return nil, fmt.Errorf("could not parse command defaults %s: %w", path, err)
+	}
+	for command, r := range defaults {
+		if err := validateIOLimits(r.IO); err != nil {
+			return nil, fmt.Errorf("command defaults for %s: %w", command, err)
+		}
+		if r.CpuSetMems != "" {
+			if err := validateCPUList(r.CpuSetMems); err != nil {
+				return nil, fmt.Errorf("command defaults for %s: %w", command, err)
+			}
+		}
+	}
+	return defaults, nil
+}
+
+// apply sets each limit r does not set (is zero) to the default for the
+// command of spec, if it has one.
+func (d CommandDefaults) apply(r *ResourceLimits, spec JobSpec) {
+	def, ok := d[filepath.Base(spec.FirstCommand())]
+	if !ok {
+		return
+	}
+	if r.MaxProcesses == 0 {
+		r.MaxProcesses = def.MaxProcesses
+	}
+	if r.Memory == 0 {
+		r.Memory = def.Memory
+	}
+	if r.CPU == 0 {
+		r.CPU = def.CPU
+	}
+	if r.CpuBurst == 0 {
+		r.CpuBurst = def.CpuBurst
+	}
+	if len(r.IO) == 0 {
+		r.IO = def.IO
+	}
+	if r.CpuSetMems == "" {
+		r.CpuSetMems = def.CpuSetMems
+	}
+}
@@ -0,0 +1,90 @@
+package job
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCommandDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "defaults.json")
+	data := `{"java": {"Memory": "2Gi", "CPU": "1.5"}, "make": {"MaxProcesses": 64, "IO": ["8:0:1048576:0:0:0"]}}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	defaults, err := LoadCommandDefaults(path)
+	require.NoError(t, err)
+	expected := CommandDefaults{
+		"java": {Memory: 2 << 30, CPU: 1500},
+		"make": {MaxProcesses: 64, IO: []DiskIOLimits{{Major: 8, Minor: 0, ReadBPS: 1 << 20}}},
+	}
+	require.Equal(t, expected, defaults)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"java": {"Memory": "lots"}}`), 0o600))
+	_, err = LoadCommandDefaults(path)
+	require.ErrorIs(t, err, ErrInvalidQuantity)
+}
+
+func TestCommandDefaults(t *testing.T) {
+	max := ResourceLimits{MaxProcesses: 100, Memory: 4 << 30, CPU: 1000}
+	defaults := CommandDefaults{"java": {Memory: 2 << 30, MaxProcesses: 50}, "ffmpeg": {CPU: 500}}
+	tracker := newTestTracker("exit 0", WithMaxResources(max), WithCommandDefaults(defaults))
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	tests := map[string]struct {
+		spec     JobSpec
+		expected []CgroupWrite
+	}{
+		"command default": {
+			spec: JobSpec{Command: "/usr/bin/java", Args: []string{"-jar", "app.jar"}},
+			expected: []CgroupWrite{
+				{File: "pids.max", Value: "50"},
+				{File: "memory.max", Value: "2147483648"},
+				{File: "cpu.max", Value: "1000000 1000000"},
+			},
+		},
+		"job overrides command default": {
+			spec: JobSpec{Command: "java", Resources: ResourceLimits{Memory: 1 << 30}},
+			expected: []CgroupWrite{
+				{File: "pids.max", Value: "50"},
+				{File: "memory.max", Value: "1073741824"},
+				{File: "cpu.max", Value: "1000000 1000000"},
+			},
+		},
+		"sequence uses first command": {
+			spec: JobSpec{Commands: [][]string{{"/opt/jdk/bin/java", "-version"}, {"/bin/true"}}},
+			expected: []CgroupWrite{
+				{File: "pids.max", Value: "50"},
+				{File: "memory.max", Value: "2147483648"},
+				{File: "cpu.max", Value: "1000000 1000000"},
+			},
+		},
+		"cpu burst needing command default cpu": {
+			spec: JobSpec{Command: "ffmpeg", Resources: ResourceLimits{CpuBurst: 10000}},
+			expected: []CgroupWrite{
+				{File: "pids.max", Value: "100"},
+				{File: "memory.max", Value: "4294967296"},
+				{File: "cpu.max", Value: "500000 1000000"},
+				{File: "cpu.max.burst", Value: "10000"},
+			},
+		},
+		"other command gets global default": {
+			spec: JobSpec{Command: "/bin/ls"},
+			expected: []CgroupWrite{
+				{File: "pids.max", Value: "100"},
+				{File: "memory.max", Value: "4294967296"},
+				{File: "cpu.max", Value: "1000000 1000000"},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			writes, warnings, err := tracker.ExplainCgroup(userCtx, tc.spec)
+			require.NoError(t, err)
+			require.Empty(t, warnings)
+			require.Equal(t, tc.expected, writes)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CommandLimits are the most jobs that may run at once by the base name of
+// their command, such as "backup", across all users.
+type CommandLimits map[string]int
+
+// WithCommandLimits sets the most jobs that may run at once by the base
+// name of their command. Jobs of a command at its limit are not started,
+// even if their spec asks to be queued. The limits apply on top of that of
+// WithMaxRunningJobs.
+func WithCommandLimits(limits CommandLimits) TrackerOption {
+	return func(t *Tracker) {
+		t.commandLimits = limits
+	}
+}
+
+// LoadCommandLimits reads CommandLimits from the JSON file at path, an
+// object of command names to the most jobs of each running at once, such as
+//
+//	{"backup": 2}
+func LoadCommandLimits(path string) (CommandLimits, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var limits CommandLimits
+	if err := json.Unmarshal(b, &limits); err != nil {
+		return nil, fmt.Errorf("could not parse command limits %s: %w", path, err)
+	}
+	for command, n := range limits {
+		if n < 1 {
+			return nil, fmt.Errorf("command limit for %s: %d is less than 1", command, n)
+		}
+	}
+	return limits, nil
+}
+
+// checkCommandLimit returns an error wrapping ErrTooManyJobs if the command
+// of spec is at its limit. Queued jobs count as running as they will run
+// once there is room. It must be called with the tracker locked.
+func (t *Tracker) checkCommandLimit(spec JobSpec) error {
+	command := filepath.Base(spec.FirstCommand())
+	limit, ok := t.commandLimits[command]
+	if !ok {
+		return nil
+	}
+	n := 0
+	for _, j := range t.jobs {
+		j.mu.Lock()
+		state, first := j.Status.State, j.Spec.FirstCommand()
+		j.mu.Unlock()
+		if (state == JobStateRunning || state == JobStateQueued) && filepath.Base(first) == command {
+			n++
+		}
+	}
+	if n >= limit {
+		return fmt.Errorf("%w: %d %s jobs (%d)", ErrTooManyJobs, n, command, limit)
+	}
+	return nil
+}
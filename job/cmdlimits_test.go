@@ -0,0 +1,66 @@
+package job
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCommandLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"backup": 2, "make": 8}`), 0o600))
+
+	limits, err := LoadCommandLimits(path)
+	require.NoError(t, err)
+	require.Equal(t, CommandLimits{"backup": 2, "make": 8}, limits)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"backup": 0}`), 0o600))
+	_, err = LoadCommandLimits(path)
+	require.Error(t, err)
+}
+
+func TestCommandLimits(t *testing.T) {
+	tracker := newTestTracker("exec 2>&1; exec sleep 60",
+		WithCommandLimits(CommandLimits{"backup": 2}), WithMaxRunningJobs(3))
+	eveCtx := AddUserToContext(context.Background(), "eve")
+	bobCtx := AddUserToContext(context.Background(), "bob")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+	defer tracker.Shutdown(adminCtx) //nolint:errcheck
+
+	backup := JobSpec{Command: "/usr/local/bin/backup", Queue: true}
+	other := JobSpec{Command: "/bin/sh", Queue: true}
+
+	// The limit is across all users.
+	first, _, err := tracker.Start(eveCtx, backup)
+	require.NoError(t, err)
+	_, _, err = tracker.Start(eveCtx, other)
+	require.NoError(t, err)
+	_, _, err = tracker.Start(bobCtx, other)
+	require.NoError(t, err)
+
+	// A queued job counts towards the limit as it will run.
+	queued, warnings, err := tracker.Start(bobCtx, backup)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	require.Equal(t, JobState(JobStateQueued), jobState(t, tracker, bobCtx, queued))
+
+	// Jobs of the command past its limit are not started or queued.
+	_, _, err = tracker.Start(bobCtx, backup)
+	require.ErrorIs(t, err, ErrTooManyJobs)
+	require.ErrorContains(t, err, "2 backup jobs")
+
+	// Other commands are only held to the global limit.
+	_, _, err = tracker.Start(bobCtx, other)
+	require.NoError(t, err)
+
+	// Completing a job of the command makes room for another.
+	require.NoError(t, tracker.Stop(eveCtx, first, false /* cleanup */))
+	require.Eventually(t, func() bool {
+		_, _, err := tracker.Start(eveCtx, backup)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,34 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidCPUList = errors.New("invalid cpuset list")
+
+// validateCPUList checks that s is in the list format used by the cgroup
+// cpuset files: comma separated node (or CPU) numbers and ranges of
+// numbers, such as "0-1,3". A range must not be descending.
+func validateCPUList(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		lo, err := strconv.ParseUint(first, 10, 32)
+		if err != nil {
+			return fmt.Errorf("%w: %q: bad number %q", ErrInvalidCPUList, s, first)
+		}
+		if !isRange {
+			continue
+		}
+		hi, err := strconv.ParseUint(last, 10, 32)
+		if err != nil {
+			return fmt.Errorf("%w: %q: bad number %q", ErrInvalidCPUList, s, last)
+		}
+		if hi < lo {
+			return fmt.Errorf("%w: %q: descending range %s", ErrInvalidCPUList, s, part)
+		}
+	}
+	return nil
+}
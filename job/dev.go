@@ -0,0 +1,113 @@
+package job
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// devNode is a character device node created in a job's /dev.
+type devNode struct {
+	name  string
+	major uint32
+	minor uint32
+}
+
+// devNodes are the device nodes created in a job's /dev when the job spec
+// has MountDev set. These are the devices most programs expect to exist.
+var devNodes = []devNode{
+	{name: "null", major: 1, minor: 3},
+	{name: "zero", major: 1, minor: 5},
+	{name: "full", major: 1, minor: 7},
+	{name: "random", major: 1, minor: 8},
+	{name: "urandom", major: 1, minor: 9},
+	{name: "tty", major: 5, minor: 0},
+}
+
+// devLinks are the symlinks created in a job's /dev, mapping name to target.
+var devLinks = [][2]string{
+	{"fd", "/proc/self/fd"},
+	{"stdin", "/proc/self/fd/0"},
+	{"stdout", "/proc/self/fd/1"},
+	{"stderr", "/proc/self/fd/2"},
+}
+
+// mountDev mounts a tmpfs on /dev under root and populates it with
+// devNodes and devLinks. It must be called in the job's mount namespace.
+//
+// Device nodes are created unless bindNodes is set, in which case the
+// host's are bind mounted instead, as root in a user namespace cannot
+// create them. The host's /dev must then still be reachable, so it must be
+// called before changing root to root. Otherwise it is called after any
+// chroot, with an empty root, and after /proc is mounted, as the links
+// point into /proc.
+func mountDev(root string, bindNodes bool) error {
+	dir := filepath.Join("/", root, "dev")
+	var hostNodes []*os.File
+	if bindNodes {
+		// Hold the host's nodes open, as the tmpfs may hide them if
+		// root is "/". They are bind mounted through /proc/self/fd.
+		for _, d := range devNodes {
+			f, err := os.OpenFile(filepath.Join("/dev", d.name), unix.O_PATH, 0)
+			if err != nil {
+				return fmt.Errorf("could not open host device %s: %w", d.name, err)
+			}
+			defer f.Close()
+			hostNodes = append(hostNodes, f)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create %s: %w", dir, err)
+	}
+	flags := uintptr(syscall.MS_NOSUID | syscall.MS_NOEXEC)
+	if err := syscall.Mount("tmpfs", dir, "tmpfs", flags, "mode=0755"); err != nil {
+		return fmt.Errorf("could not mount %s: %w", dir, err)
+	}
+
+	for i, d := range devNodes {
+		path := filepath.Join(dir, d.name)
+		if bindNodes {
+			if err := bindDevNode(hostNodes[i], path); err != nil {
+				return err
+			}
+			continue
+		}
+		dev := int(unix.Mkdev(d.major, d.minor))
+		if err := syscall.Mknod(path, syscall.S_IFCHR|0666, dev); err != nil {
+			return fmt.Errorf("could not create %s: %w", path, err)
+		}
+		// mknod(2) mode is subject to the umask, so set it explicitly.
+		if err := os.Chmod(path, 0666); err != nil {
+			return fmt.Errorf("could not chmod %s: %w", path, err)
+		}
+	}
+
+	for _, l := range devLinks {
+		path := filepath.Join(dir, l[0])
+		if err := os.Symlink(l[1], path); err != nil {
+			return fmt.Errorf("could not create %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// bindDevNode bind mounts the open host device node f at path, creating an
+// empty file at path to mount it on.
+func bindDevNode(f *os.File, path string) error {
+	mp, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %w", path, err)
+	}
+	_ = mp.Close()
+	source := "/proc/self/fd/" + strconv.Itoa(int(f.Fd()))
+	if err := syscall.Mount(source, path, "", syscall.MS_BIND, ""); err != nil {
+		return fmt.Errorf("could not bind mount %s: %w", path, err)
+	}
+	return nil
+}
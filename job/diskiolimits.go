@@ -66,6 +66,39 @@ func (d *DiskIOLimits) UnmarshalText(b []byte) (err error) {
 	return err
 }
 
+var ErrInvalidIOLimits = errors.New("invalid io limits")
+
+// deviceKey identifies the device the limits are for. Once the device is
+// resolved it is identified by its major and minor number, so different
+// paths to the same device have the same key.
+func (d *DiskIOLimits) deviceKey() string {
+	if d.Major != 0 || d.Minor != 0 {
+		return fmt.Sprintf("%d:%d", d.Major, d.Minor)
+	}
+	return d.Device
+}
+
+// validateIOLimits checks that no device has more than one set of io
+// limits. Only the last limits written to the cgroup for a device take
+// effect, so duplicates would silently ignore the earlier limits.
+func validateIOLimits(limits []DiskIOLimits) error {
+	seen := map[string]bool{}
+	for _, d := range limits {
+		key := d.deviceKey()
+		if seen[key] {
+			return fmt.Errorf("%w: more than one limit for device %s", ErrInvalidIOLimits, key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// MarshalText marshals a DiskIOLimits in the format accepted by
+// UnmarshalText, with the device as its major and minor numbers.
+func (d DiskIOLimits) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
 func (d *DiskIOLimits) String() string {
 	return fmt.Sprintf("%d:%d:%d:%d:%d:%d", d.Major, d.Minor, d.ReadBPS, d.WriteBPS, d.ReadIOPS, d.WriteIOPS)
 }
@@ -0,0 +1,82 @@
+package job
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDraining is returned when starting a job on a tracker that is being
+// drained.
+var ErrDraining = errors.New("server is draining")
+
+// DrainStatus is the progress of draining a tracker.
+type DrainStatus struct {
+	// Draining is true once the tracker has been asked to drain.
+	Draining bool
+	// Running and Queued are the number of jobs still running and queued.
+	Running int
+	Queued  int
+}
+
+// Drained returns true once the tracker is draining and has no jobs left
+// to run, so it can be shut down without stopping any.
+func (s DrainStatus) Drained() bool {
+	return s.Draining && s.Running == 0 && s.Queued == 0
+}
+
+// Drain stops the tracker starting new jobs, which fail with ErrDraining,
+// while its running and queued jobs run to completion, as for taking the
+// server out of service for maintenance. It returns at once with the
+// progress of the drain, which can be polled with DrainStatus. Draining an
+// already draining tracker changes nothing. Only admins can drain the
+// tracker.
+func (t *Tracker) Drain(ctx context.Context) (DrainStatus, error) {
+	user, ok := GetUserFromContext(ctx)
+	if !ok || !t.isAdmin(ctx, user) {
+		return DrainStatus{}, ErrUnauthorized
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.isDraining() {
+		close(t.draining)
+	}
+	return t.drainStatus(), nil
+}
+
+// DrainStatus returns the progress of draining the tracker. Only admins can
+// get it.
+func (t *Tracker) DrainStatus(ctx context.Context) (DrainStatus, error) {
+	user, ok := GetUserFromContext(ctx)
+	if !ok || !t.isAdmin(ctx, user) {
+		return DrainStatus{}, ErrUnauthorized
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.drainStatus(), nil
+}
+
+// Draining returns a channel that is closed when the tracker starts
+// draining.
+func (t *Tracker) Draining() <-chan struct{} {
+	return t.draining
+}
+
+// isDraining returns true if the tracker has been asked to drain. It must be
+// called with the tracker locked.
+func (t *Tracker) isDraining() bool {
+	select {
+	case <-t.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// drainStatus returns the progress of draining the tracker. It must be
+// called with the tracker locked.
+func (t *Tracker) drainStatus() DrainStatus {
+	return DrainStatus{Draining: t.isDraining(), Running: t.running, Queued: len(t.queue)}
+}
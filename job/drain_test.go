@@ -0,0 +1,90 @@
+package job
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	// Each job runs until the file named by its argument exists, so the
+	// test can complete the jobs one at a time.
+	dir := t.TempDir()
+	argMaker := func(jd JobDescription) (string, []string) {
+		gate := filepath.Join(dir, jd.Spec.Args[0])
+		return "/bin/sh", []string{"sh", "-c", "exec 2>&1; while [ ! -e " + gate + " ]; do sleep 0.01; done"}
+	}
+	tracker := NewTracker(argMaker, []string{"admin"}, WithMaxRunningJobs(2))
+	tracker.noNamespaces = true
+	userCtx := AddUserToContext(context.Background(), "eve")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+	finish := func(name string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	var ids []string
+	for _, name := range []string{"a", "b", "c"} {
+		id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh", Args: []string{name}, Queue: true})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	st, err := tracker.DrainStatus(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, DrainStatus{Running: 2, Queued: 1}, st)
+	require.False(t, st.Drained())
+
+	_, err = tracker.Drain(userCtx)
+	require.ErrorIs(t, err, ErrUnauthorized)
+	_, err = tracker.DrainStatus(userCtx)
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	st, err = tracker.Drain(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, DrainStatus{Draining: true, Running: 2, Queued: 1}, st)
+	select {
+	case <-tracker.Draining():
+	default:
+		t.Fatal("draining channel not closed")
+	}
+
+	// New jobs are not started, but queued jobs still run.
+	_, _, err = tracker.Start(userCtx, JobSpec{Command: "/bin/sh", Args: []string{"d"}})
+	require.ErrorIs(t, err, ErrDraining)
+
+	drainStatus := func() DrainStatus {
+		st, err := tracker.DrainStatus(adminCtx)
+		require.NoError(t, err)
+		return st
+	}
+	finish("a")
+	require.Eventually(t, func() bool {
+		return drainStatus() == DrainStatus{Draining: true, Running: 2}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	finish("b")
+	require.Eventually(t, func() bool {
+		return drainStatus() == DrainStatus{Draining: true, Running: 1}
+	}, 5*time.Second, 10*time.Millisecond)
+	require.False(t, drainStatus().Drained())
+
+	// Draining again changes nothing.
+	st, err = tracker.Drain(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, DrainStatus{Draining: true, Running: 1}, st)
+
+	finish("c")
+	require.Eventually(t, func() bool { return drainStatus().Drained() }, 5*time.Second, 10*time.Millisecond)
+
+	// No job was stopped to drain the tracker.
+	for _, id := range ids {
+		jd, err := tracker.Get(userCtx, id)
+		require.NoError(t, err)
+		require.Equal(t, JobState(JobStateCompleted), jd.Status.State)
+		require.Zero(t, jd.Status.ExitCode)
+	}
+}
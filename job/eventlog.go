@@ -0,0 +1,136 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of lifecycle event of a job.
+type EventType string
+
+const (
+	// EventStart is a job's command starting to run.
+	EventStart EventType = "start"
+	// EventStop is a running job being asked to stop.
+	EventStop EventType = "stop"
+	// EventComplete is a job completing successfully.
+	EventComplete EventType = "complete"
+	// EventFail is a job completing unsuccessfully, including a queued
+	// job that never started.
+	EventFail EventType = "fail"
+	// EventChown is an admin changing the owner of a job.
+	EventChown EventType = "chown"
+)
+
+// Event is a lifecycle event of a job, as recorded in an EventLog.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    EventType `json:"type"`
+	JobID   string    `json:"job_id"`
+	Owner   string    `json:"owner"`
+	Command string    `json:"command"`
+	Pid     int       `json:"pid,omitempty"`
+	// Client is the address of the client that started the job, if known.
+	Client string `json:"client,omitempty"`
+	// ExitCode, Signal and ExitReason are only set for complete and fail
+	// events.
+	ExitCode   uint32 `json:"exit_code,omitempty"`
+	Signal     int    `json:"signal,omitempty"`
+	ExitReason string `json:"exit_reason,omitempty"`
+	// PreviousOwner and Admin are only set for chown events, with Owner
+	// the job's new owner.
+	PreviousOwner string `json:"previous_owner,omitempty"`
+	Admin         string `json:"admin,omitempty"`
+}
+
+// newEvent returns an event of the given type for the job described by jd.
+func newEvent(typ EventType, jd JobDescription) Event {
+	ev := Event{
+		Time:    time.Now(),
+		Type:    typ,
+		JobID:   jd.ID,
+		Owner:   jd.Status.Owner,
+		Command: jd.Spec.FirstCommand(),
+		Pid:     jd.Status.Pid,
+		Client:  jd.Status.Client,
+	}
+	if typ == EventComplete || typ == EventFail {
+		ev.ExitCode = jd.Status.ExitCode
+		ev.Signal = int(jd.Status.Signal)
+		ev.ExitReason = jd.Status.ExitReason()
+	}
+	return ev
+}
+
+// EventLog appends the lifecycle events of jobs to a file as JSON lines,
+// one event per line in the order they happened.
+//
+// The file is opened for each event rather than held open, so it can be
+// rotated by renaming it: the next event creates a new file at the path.
+// EventLog does no rotation itself.
+type EventLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewEventLog returns an EventLog appending to the file at path, creating
+// it if it does not exist.
+func NewEventLog(path string) (*EventLog, error) {
+	f, err := openEventLog(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open event log: %w", err)
+	}
+	_ = f.Close()
+	return &EventLog{path: path}, nil
+}
+
+func openEventLog(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+}
+
+// Record appends ev to the log. Recording to a nil EventLog does nothing.
+func (l *EventLog) Record(ev Event) error {
+	if l == nil {
+		return nil
+	}
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := openEventLog(l.path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// recordEvent records ev in the job's event log, if it has one. It writes
+// to the log's file, so it is not called with the job locked.
+func (j *Job) recordEvent(ev Event) {
+	if err := j.eventLog.Record(ev); err != nil {
+		// XXX Should log, but no logger yet
+		fmt.Fprintf(os.Stderr, "could not record %s event of job %s: %v\n", ev.Type, j.ID, err)
+	}
+}
+
+// recordCompletion records the completed job in its event log, as failed if
+// it did not complete successfully.
+func (j *Job) recordCompletion() {
+	jd := j.Description()
+	typ := EventComplete
+	if jd.Status.Failed() {
+		typ = EventFail
+	}
+	j.recordEvent(newEvent(typ, jd))
+}
@@ -0,0 +1,195 @@
+package job
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readEventLog returns the events in the event log file at path.
+func readEventLog(t *testing.T, path string) []Event {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ev Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+		events = append(events, ev)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestTrackerEventLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	eventLog, err := NewEventLog(path)
+	require.NoError(t, err)
+
+	// Run the script given as each job's argument.
+	argMaker := func(jd JobDescription) (string, []string) {
+		return "/bin/sh", []string{"sh", "-c", jd.Spec.Args[0]}
+	}
+	tracker := NewTracker(argMaker, nil, WithEventLog(eventLog))
+	tracker.noNamespaces = true
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	run := func(script string) string {
+		id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh", Args: []string{script}})
+		require.NoError(t, err)
+		return id
+	}
+	okID := run("exit 0")
+	_, err = tracker.Wait(userCtx, okID)
+	require.NoError(t, err)
+	failID := run("exit 3")
+	_, err = tracker.Wait(userCtx, failID)
+	require.NoError(t, err)
+	stopID := run("exec sleep 60 2>&1")
+	require.NoError(t, tracker.Stop(userCtx, stopID, false /* cleanup */))
+
+	events := readEventLog(t, path)
+	var got []string
+	for _, ev := range events {
+		got = append(got, string(ev.Type)+" "+ev.JobID)
+		require.Equal(t, "eve", ev.Owner)
+		require.Equal(t, "/bin/sh", ev.Command)
+		require.NotZero(t, ev.Pid)
+	}
+	expected := []string{
+		"start " + okID, "complete " + okID,
+		"start " + failID, "fail " + failID,
+		"start " + stopID, "stop " + stopID, "fail " + stopID,
+	}
+	require.Equal(t, expected, got)
+	for i := 1; i < len(events); i++ {
+		require.False(t, events[i].Time.Before(events[i-1].Time))
+	}
+
+	require.Equal(t, "exited with code 0", events[1].ExitReason)
+	require.Equal(t, uint32(3), events[3].ExitCode)
+	require.Equal(t, "exited with code 3", events[3].ExitReason)
+	require.Empty(t, events[5].ExitReason)
+	require.Equal(t, 9, events[6].Signal)
+	require.Equal(t, "terminated by signal 9 (killed)", events[6].ExitReason)
+
+	// A renamed log is not written to again, so it can be rotated.
+	require.NoError(t, os.Rename(path, path+".1"))
+	_, err = tracker.Wait(userCtx, run("exit 0"))
+	require.NoError(t, err)
+	require.Len(t, readEventLog(t, path+".1"), len(expected))
+	require.Len(t, readEventLog(t, path), 2)
+}
+
+func TestEventLogQueuedJobCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	eventLog, err := NewEventLog(path)
+	require.NoError(t, err)
+	tracker := newTestTracker("exec sleep 60 2>&1", WithEventLog(eventLog), WithMaxRunningJobs(1))
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	running, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tracker.Stop(userCtx, running, true /* cleanup */) })
+	queued, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh", Queue: true})
+	require.NoError(t, err)
+	require.NoError(t, tracker.Stop(userCtx, queued, false /* cleanup */))
+
+	// The queued job never started, so it only fails.
+	events := readEventLog(t, path)
+	require.Len(t, events, 2)
+	require.Equal(t, EventStart, events[0].Type)
+	require.Equal(t, running, events[0].JobID)
+	require.Equal(t, EventFail, events[1].Type)
+	require.Equal(t, queued, events[1].JobID)
+	require.Contains(t, events[1].ExitReason, ErrCancelled.Error())
+}
+
+func TestEventLogWrittenUnlocked(t *testing.T) {
+	// Writing to a FIFO blocks until it is read, standing in for a slow
+	// event log file.
+	path := filepath.Join(t.TempDir(), "events.fifo")
+	require.NoError(t, syscall.Mkfifo(path, 0o600))
+	j := NewJob("test-1", JobSpec{Command: "/bin/sh"}, shellArgMaker("exec 2>&1; exit 0"))
+	j.noNamespaces = true
+	j.eventLog = &EventLog{path: path}
+	started := make(chan error, 1)
+	go func() { started <- j.Start("eve") }()
+
+	// The job is not locked while its start is being recorded.
+	described := make(chan JobDescription)
+	go func() {
+		for {
+			if jd := j.Description(); jd.Status.Pid != 0 {
+				described <- jd
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	select {
+	case <-described:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job locked while recording its start")
+	}
+
+	var events []Event
+	for len(events) < 2 {
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var ev Event
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+			events = append(events, ev)
+		}
+		require.NoError(t, scanner.Err())
+		require.NoError(t, f.Close())
+	}
+	require.NoError(t, <-started)
+	<-j.reaped
+	t.Cleanup(j.Cleanup)
+
+	// The job's completion is recorded after its start, though it exited
+	// while the start was being recorded.
+	require.Len(t, events, 2)
+	require.Equal(t, EventStart, events[0].Type)
+	require.Equal(t, EventComplete, events[1].Type)
+}
+
+func TestEventLogChown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	eventLog, err := NewEventLog(path)
+	require.NoError(t, err)
+	tracker := newTestTracker("exec 2>&1; exit 0", WithEventLog(eventLog))
+	userCtx := AddUserToContext(context.Background(), "eve")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	_, err = tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+	_, err = tracker.Chown(adminCtx, id, "mallory")
+	require.NoError(t, err)
+
+	events := readEventLog(t, path)
+	require.Len(t, events, 3)
+	ev := events[2]
+	require.Equal(t, EventChown, ev.Type)
+	require.Equal(t, id, ev.JobID)
+	require.Equal(t, "mallory", ev.Owner)
+	require.Equal(t, "eve", ev.PreviousOwner)
+	require.Equal(t, "admin", ev.Admin)
+	// Only completion events carry the job's exit.
+	require.Empty(t, ev.ExitReason)
+}
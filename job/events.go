@@ -0,0 +1,111 @@
+package job
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// limitEventInterval is how often a running job's cgroup event counters
+// are checked for resource limits being hit.
+const limitEventInterval = 250 * time.Millisecond
+
+// limitEvent is a cgroup event counter that is reported in a job's logs
+// when it increments.
+type limitEvent struct {
+	file    string
+	key     string
+	message string
+	// limit names the limit that was hit, for the job's exit reason.
+	limit string
+}
+
+var limitEvents = []limitEvent{
+	{"memory.events", "max", "memory limit reached, process throttled", "memory limit"},
+	{"memory.events", "oom_kill", "memory limit exceeded, process killed by the OOM killer", "memory limit"},
+	{"pids.events", "max", "process limit reached, fork failed", "process limit"},
+}
+
+// watchLimits forwards logs from in to out, injecting a log marker into out
+// each time the job identified by id hits one of its resource limits. The
+// job's cgroup event counters are checked on each tick. Once in is closed,
+// the counters are checked a final time and out is closed. It returns the
+// names of the limits that were hit, in the order they were first hit.
+func watchLimits(id string, in <-chan Log, out chan<- Log, tick <-chan time.Time) []string {
+	counts := make(map[limitEvent]uint64)
+	var hit []string
+	check := func() {
+		events := map[string]map[string]uint64{}
+		for _, ev := range limitEvents {
+			if _, ok := events[ev.file]; !ok {
+				events[ev.file] = readEvents(id, ev.file)
+			}
+			n := events[ev.file][ev.key]
+			if n > counts[ev] {
+				line := "[jobber] " + ev.message + "\n"
+				out <- Log{Timestamp: time.Now(), Line: []byte(line)}
+				if !slices.Contains(hit, ev.limit) {
+					hit = append(hit, ev.limit)
+				}
+			}
+			counts[ev] = n
+		}
+	}
+
+	for {
+		select {
+		case l, ok := <-in:
+			if !ok {
+				check()
+				close(out)
+				return hit
+			}
+			out <- l
+		case <-tick:
+			check()
+		}
+	}
+}
+
+// readEvents reads a cgroup events file (e.g. memory.events) of the job
+// identified by id. It returns nil if the file cannot be read, which is the
+// case if the job's cgroup or the controller does not exist.
+func readEvents(id, file string) map[string]uint64 {
+	b, err := os.ReadFile(filepath.Join(cgroupDir(id), file))
+	if err != nil {
+		return nil
+	}
+	events := make(map[string]uint64)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		key, val, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseUint(val, 10, 64); err == nil {
+			events[key] = n
+		}
+	}
+	return events
+}
+
+// readPeakProcesses returns the most processes that the cgroup of the job
+// identified by id has had at once, from its pids.peak file. It returns
+// zero if the file cannot be read, as pids.peak is only on newer kernels.
+func readPeakProcesses(id string) uint32 {
+	b, err := os.ReadFile(filepath.Join(cgroupDir(id), "pids.peak"))
+	if err != nil {
+		return 0
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(n)
+}
@@ -0,0 +1,105 @@
+package job
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchLimits(t *testing.T) {
+	root := fakeCgroupRoot(t)
+	dir := filepath.Join(root, "job-1")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	writeEvents := func(file, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+	}
+	writeEvents("memory.events", "low 0\nhigh 0\nmax 0\noom 0\noom_kill 0\n")
+	writeEvents("pids.events", "max 0\n")
+
+	in, out := make(chan Log), make(chan Log)
+	tick := make(chan time.Time)
+	hitc := make(chan []string)
+	go func() { hitc <- watchLimits("job-1", in, out, tick) }()
+
+	in <- Log{Line: []byte("hello\n")}
+	require.Equal(t, "hello\n", string((<-out).Line))
+
+	// No markers while the counters do not change.
+	tick <- time.Now()
+	in <- Log{Line: []byte("world\n")}
+	require.Equal(t, "world\n", string((<-out).Line))
+
+	writeEvents("pids.events", "max 2\n")
+	tick <- time.Now()
+	require.Equal(t, "[jobber] process limit reached, fork failed\n", string((<-out).Line))
+
+	// Counters that increment after the last tick are reported when the
+	// job's output closes.
+	writeEvents("memory.events", "low 0\nhigh 0\nmax 1\noom 1\noom_kill 1\n")
+	close(in)
+	require.Equal(t, "[jobber] memory limit reached, process throttled\n", string((<-out).Line))
+	require.Equal(t, "[jobber] memory limit exceeded, process killed by the OOM killer\n", string((<-out).Line))
+	_, ok := <-out
+	require.False(t, ok)
+	require.Equal(t, []string{"process limit", "memory limit"}, <-hitc)
+}
+
+func TestProcessLimitExitReason(t *testing.T) {
+	root := fakeCgroupRoot(t)
+	dir := filepath.Join(root, "test-1")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	events := filepath.Join(dir, "pids.events")
+	require.NoError(t, os.WriteFile(events, []byte("max 0\n"), 0644))
+
+	// The job bumps its own pids.events max counter, as the kernel would
+	// when a fork fails, then fails.
+	j, output, err := runToCompletion(t, "exec 2>&1; echo 'max 3' > "+events+"; exit 1")
+	require.NoError(t, err)
+	require.Equal(t, "[jobber] process limit reached, fork failed\n", output)
+
+	jd := j.Description()
+	require.Equal(t, []string{"process limit"}, jd.Status.LimitsHit)
+	require.Equal(t, "exited with code 1 after hitting its process limit", jd.Status.ExitReason())
+}
+
+func TestPeakProcesses(t *testing.T) {
+	root := fakeCgroupRoot(t)
+	dir := filepath.Join(root, "test-1")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pids.peak"), []byte("7\n"), 0644))
+
+	j, _, err := runToCompletion(t, "exit 0")
+	require.NoError(t, err)
+	require.Equal(t, uint32(7), j.Description().Status.PeakProcesses)
+
+	// Kernels without pids.peak leave it unknown.
+	require.NoError(t, os.Remove(filepath.Join(dir, "pids.peak")))
+	j, _, err = runToCompletion(t, "exit 0")
+	require.NoError(t, err)
+	require.Equal(t, uint32(0), j.Description().Status.PeakProcesses)
+}
+
+func TestExitReasonWithLimits(t *testing.T) {
+	tests := map[string]struct {
+		status JobStatus
+		reason string
+	}{
+		"success after limit": {
+			status: JobStatus{LimitsHit: []string{"process limit"}},
+			reason: "exited with code 0",
+		},
+		"killed after limits": {
+			status: JobStatus{ExitCode: 137, Signal: 9, LimitsHit: []string{"memory limit", "process limit"}},
+			reason: "terminated by signal 9 (killed) after hitting its memory limit and process limit",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.reason, tc.status.ExitReason())
+		})
+	}
+}
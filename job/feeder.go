@@ -2,10 +2,12 @@ package job
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"reflect"
+	"sync"
 	"time"
 
 	"golang.org/x/exp/slices"
@@ -19,23 +21,154 @@ import (
 // long as there is an infeed. If the infeed is closed, all followers
 // become non-followers and will be closed when they reach the end of
 // the recorded logs.
+//
+// If the feeder has a maximum number of lines or bytes, the buffer is a
+// ring: once it exceeds either, the oldest logs are dropped. Outfeeds are
+// then fed from the earliest log still buffered, and the first log an
+// outfeed is sent after any it missed records how many were dropped.
+// The feeder may also be asked to drop its oldest logs by a budget shared
+// with the feeders of other jobs.
 type feeder struct {
 	control  chan outfeed
-	infeed   <-chan Log
-	outfeeds []*outfeed
-	cases    []reflect.SelectCase
-	buffer   []Log
+	snapshot chan chan FeederStats
+	pages    chan pageRequest
+	drain    chan chan struct{}
+	// trimSignal is sent on by the feeder's budget when it is to trim its
+	// logs.
+	trimSignal chan struct{}
+	infeed     <-chan Log
+	outfeeds   []*outfeed
+	cases      []reflect.SelectCase
+	buffer     []Log
+	// dropped is the number of logs dropped from the start of the buffer,
+	// so it is the index of the log at buffer[0]. bufferBytes is the size
+	// of the lines in the buffer.
+	dropped     int
+	bufferBytes int
+	// maxLines and maxBytes limit the logs kept in the buffer. Zero is no
+	// limit. The latest log is always kept, however large.
+	maxLines int
+	maxBytes int
+	// budget, if not nil, limits the logs kept by this and other feeders.
+	budget *logBudget
 	// outOffset is the number of select cases before the first
 	// outfeed in the cases slice.
 	outOffset    int
 	infeedClosed bool
+	// drainWaiters are closed once the feeder is drained: the infeed has
+	// closed and all outfeeds have been sent all the logs.
+	drainWaiters []chan struct{}
+	// spool, if not nil, is written each log as it is recorded, and is
+	// closed when the infeed closes.
+	spool *spoolWriter
+	// stopped is closed when the feeder goroutine returns, after which
+	// pages are empty.
+	stopped chan struct{}
 }
 
 type Log struct {
 	Timestamp time.Time
 	Line      []byte
+	// Index is the position of the log in the job's output, from zero. It
+	// is set by the feeder when the log is recorded.
+	Index int
+	// Stream is the output stream of the job the line was read from. Lines
+	// added by jobber itself, such as limit markers, are on stdout.
+	Stream Stream
+	// Partial is set when a line longer than the maximum line size was
+	// split, on each part of it but the last. The line continues in the
+	// next log of the same stream.
+	Partial bool `json:",omitempty"`
+	// Dropped is the number of logs before this one that were dropped
+	// from the job's log buffer before they could be read. It is only set
+	// on the first log read after the gap.
+	Dropped int `json:",omitempty"`
 }
 
+// DefaultMaxLineBytes is the size at which lines of a job's output are
+// split into multiple logs, unless set with WithMaxLineBytes.
+const DefaultMaxLineBytes = 512
+
+// Stream is one of a job's output streams.
+type Stream int
+
+const (
+	StreamStdout Stream = iota
+	StreamStderr
+)
+
+func (s Stream) String() string {
+	switch s {
+	case StreamStdout:
+		return "stdout"
+	case StreamStderr:
+		return "stderr"
+	}
+	return fmt.Sprintf("Stream(%d)", int(s))
+}
+
+// FeederStats is a snapshot of the state of a feeder, for debugging.
+type FeederStats struct {
+	// BufferLen is the number of logs in the buffer.
+	BufferLen int
+	// Dropped is the number of logs dropped from the buffer to keep it
+	// within its limits.
+	Dropped int
+	// InfeedClosed is true once the job's output has closed.
+	InfeedClosed bool
+	// Outfeeds are the currently attached outfeeds in the order they
+	// were attached.
+	Outfeeds []OutfeedStats
+}
+
+// OutfeedStats is a snapshot of the state of an outfeed attached to a
+// feeder.
+type OutfeedStats struct {
+	// Pos is the index of the next log to be sent.
+	Pos int
+	// Lag is the number of recorded logs not yet sent.
+	Lag int
+	// Follow is true if the outfeed is following the logs.
+	Follow bool
+	// Waiting is true if the outfeed has been sent all recorded logs and
+	// is waiting for more.
+	Waiting bool
+}
+
+// LogPage is a range of the logs recorded by a feeder.
+type LogPage struct {
+	Logs []Log
+	// Total is the number of logs recorded when the page was taken,
+	// including any dropped from the buffer. It grows while the job is
+	// running.
+	Total int
+	// End is the index after the last log the page covers, from which
+	// the next page starts. It is only set by Tracker.LogsPage, and is
+	// before the end asked for if the page was cut short.
+	End int
+}
+
+// pageRequest is a request to a feeder for the recorded logs with indexes
+// in [start, end), or if window is set, with timestamps in [from, to],
+// replied to on reply.
+type pageRequest struct {
+	start, end int
+	window     bool
+	from, to   time.Time
+	reply      chan LogPage
+}
+
+// Indexes of the fixed select cases in feeder.cases
+const (
+	controlCase = iota
+	infeedCase
+	snapshotCase
+	pageCase
+	drainCase
+	trimCase
+	doneCase
+)
+
 type outfeed struct {
 	ch     chan<- Log
 	done   <-chan struct{}
@@ -45,41 +178,115 @@ type outfeed struct {
 
 func newFeeder(infeed <-chan Log) *feeder {
 	control := make(chan outfeed)
+	snapshot := make(chan chan FeederStats)
+	pages := make(chan pageRequest)
+	drain := make(chan chan struct{})
+	trimSignal := make(chan struct{}, 1)
 	f := feeder{
-		infeed:  infeed,
-		control: control,
+		infeed:     infeed,
+		control:    control,
+		snapshot:   snapshot,
+		pages:      pages,
+		drain:      drain,
+		trimSignal: trimSignal,
+		stopped:    make(chan struct{}),
 		cases: []reflect.SelectCase{
-			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(control)},
-			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(infeed)},
+			controlCase:  {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(control)},
+			infeedCase:   {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(infeed)},
+			snapshotCase: {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(snapshot)},
+			pageCase:     {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(pages)},
+			drainCase:    {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(drain)},
+			trimCase:     {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(trimSignal)},
 		},
 	}
 	return &f
 }
 
-func (f *feeder) attachOutfeed(follow bool, done <-chan struct{}) <-chan Log {
+// attachOutfeed returns a channel fed the recorded logs from the log with
+// index start. It is closed at once if the feeder has stopped.
+func (f *feeder) attachOutfeed(follow bool, start int, done <-chan struct{}) <-chan Log {
 	ch := make(chan Log)
 	feed := outfeed{
 		ch:     ch,
 		done:   done,
+		pos:    start,
 		follow: follow,
 	}
-	f.control <- feed
+	select {
+	case f.control <- feed:
+	case <-f.stopped:
+		close(ch)
+	}
 	return ch
 }
 
+// stats returns a snapshot of the state of the feeder, or an empty
+// snapshot if the feeder has stopped. It is taken by the feeder goroutine
+// so it is consistent.
+func (f *feeder) stats() FeederStats {
+	ch := make(chan FeederStats)
+	select {
+	case f.snapshot <- ch:
+	case <-f.stopped:
+		return FeederStats{}
+	}
+	return <-ch
+}
+
+// page returns the recorded logs with indexes in [start, end) and the
+// number of logs recorded. If end is zero or past the recorded logs, the
+// page ends with the last recorded log. It is taken by the feeder
+// goroutine so it is consistent.
+func (f *feeder) page(start, end int) LogPage {
+	return f.request(pageRequest{start: start, end: end, reply: make(chan LogPage)})
+}
+
+// window returns the recorded logs timestamped in [from, to] and the
+// number of logs recorded. A zero from or to leaves that end of the window
+// open. It is taken by the feeder goroutine so it is consistent.
+func (f *feeder) window(from, to time.Time) LogPage {
+	return f.request(pageRequest{window: true, from: from, to: to, reply: make(chan LogPage)})
+}
+
+// request sends req to the feeder goroutine and returns its reply, or an
+// empty page if the feeder has stopped.
+func (f *feeder) request(req pageRequest) LogPage {
+	select {
+	case f.pages <- req:
+	case <-f.stopped:
+		return LogPage{}
+	}
+	return <-req.reply
+}
+
+// waitDrained waits until the infeed has closed and every attached outfeed
+// has been sent all the recorded logs and closed, or until ctx is done.
+func (f *feeder) waitDrained(ctx context.Context) {
+	ch := make(chan struct{})
+	select {
+	case f.drain <- ch:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-ch:
+	case <-ctx.Done():
+	}
+}
+
 // Start runs the loop of the feeder. It will run until the done channel is
 // closed, which happens when the job this feeder is attached to is cleaned
 // up. Until then, it is always possible to get a feed of the recorded logs,
 // even if the job has long since terminated.
 func (f *feeder) Start(done <-chan struct{}) {
-	doneCase := reflect.SelectCase{
+	f.cases = append(f.cases, reflect.SelectCase{
 		Dir:  reflect.SelectRecv,
 		Chan: reflect.ValueOf(done),
-	}
-	f.cases = append(f.cases, doneCase)
+	})
 	f.outOffset = len(f.cases) // offset of first outfeed in select cases slice
 
 	disabled := reflect.Value{}
+	f.updateBudget()
 
 	for {
 		i, rcv, ok := reflect.Select(f.cases)
@@ -87,28 +294,56 @@ func (f *feeder) Start(done <-chan struct{}) {
 		isOutfeedDone := i >= f.outOffset && (i-f.outOffset)%2 == 1
 		feedIdx := (i - f.outOffset) / 2
 		switch {
-		case i == 0 && ok: // control
+		case i == controlCase && ok:
 			outfeed := rcv.Interface().(outfeed)
 			f.addOutfeed(&outfeed)
-		case i == 1 && ok: // infeed
+		case i == infeedCase && ok:
 			l := rcv.Interface().(Log)
+			l.Index = f.total()
 			f.buffer = append(f.buffer, l)
+			f.bufferBytes += len(l.Line)
+			f.spoolLog(l)
+			f.evict()
+			f.updateBudget()
 			f.wakeSleepers()
-		case i == 1 && !ok: // infeed closed
+		case i == infeedCase && !ok: // infeed closed
 			f.infeedClosed = true
-			f.cases[1].Chan = disabled
+			f.cases[infeedCase].Chan = disabled
+			f.closeSpool()
 			f.removeSleepers()
-		case i == 2: // done
+			f.updateBudget()
+		case i == snapshotCase && ok:
+			ch := rcv.Interface().(chan FeederStats)
+			ch <- f.takeSnapshot()
+		case i == pageCase && ok:
+			req := rcv.Interface().(pageRequest)
+			if req.window {
+				req.reply <- f.takeWindow(req.from, req.to)
+			} else {
+				req.reply <- f.takePage(req.start, req.end)
+			}
+		case i == drainCase && ok:
+			ch := rcv.Interface().(chan struct{})
+			f.drainWaiters = append(f.drainWaiters, ch)
+		case i == trimCase:
+			f.budget.trim(f)
+		case i == doneCase:
 			for _, feed := range f.outfeeds {
 				close(feed.ch)
 			}
+			f.outfeeds = nil
+			f.notifyDrained()
+			if f.budget != nil {
+				f.budget.remove(f)
+			}
+			close(f.stopped)
 			return
 		case isOutfeed:
 			feed := f.outfeeds[feedIdx]
 			feed.pos++
-			if feed.pos < len(f.buffer) {
+			if feed.pos < f.total() {
 				// Set up the feed for its next line
-				f.cases[i].Send = reflect.ValueOf(f.buffer[feed.pos])
+				f.cases[i].Send = reflect.ValueOf(f.next(feed))
 			} else if feed.follow && !f.infeedClosed {
 				// Disable send channel until more logs come in
 				f.cases[i].Chan = disabled
@@ -120,13 +355,166 @@ func (f *feeder) Start(done <-chan struct{}) {
 		case isOutfeedDone:
 			f.removeOutfeed(feedIdx)
 		}
+		if f.infeedClosed && len(f.outfeeds) == 0 {
+			f.notifyDrained()
+		}
+	}
+}
+
+// total returns the number of logs recorded, including those dropped.
+func (f *feeder) total() int {
+	return f.dropped + len(f.buffer)
+}
+
+// evict drops the oldest logs from the buffer while it exceeds the
+// feeder's limits, always keeping the latest log.
+func (f *feeder) evict() {
+	n, bytes := 0, f.bufferBytes
+	for len(f.buffer)-n > 1 &&
+		((f.maxLines > 0 && len(f.buffer)-n > f.maxLines) || (f.maxBytes > 0 && bytes > f.maxBytes)) {
+		bytes -= len(f.buffer[n].Line)
+		n++
+	}
+	f.drop(n)
+}
+
+// trim drops the oldest logs from the buffer until the lines dropped are
+// at least size bytes, always keeping the latest log.
+func (f *feeder) trim(size int) {
+	n := 0
+	for len(f.buffer)-n > 1 && size > 0 {
+		size -= len(f.buffer[n].Line)
+		n++
+	}
+	f.drop(n)
+}
+
+// drop drops the oldest n logs from the buffer.
+func (f *feeder) drop(n int) {
+	if n == 0 {
+		return
+	}
+	// Clear the dropped logs so their lines can be collected. The backing
+	// array is replaced with one holding only the buffered logs when
+	// append next grows it.
+	for i := 0; i < n; i++ {
+		f.bufferBytes -= len(f.buffer[i].Line)
+		f.buffer[i] = Log{}
 	}
+	f.buffer = f.buffer[n:]
+	f.dropped += n
+}
+
+// updateBudget reports the size of the buffer to the feeder's budget, if it
+// has one.
+func (f *feeder) updateBudget() {
+	if f.budget != nil {
+		f.budget.update(f)
+	}
+}
+
+// budgetUsage returns the size of the lines in the buffer, how much of it
+// can be trimmed, the timestamp of the oldest log and whether the infeed
+// has closed, for the feeder's budget.
+func (f *feeder) budgetUsage() (bytes, trimmable int, oldest time.Time, ended bool) {
+	if len(f.buffer) == 0 {
+		return 0, 0, time.Time{}, f.infeedClosed
+	}
+	latest := len(f.buffer[len(f.buffer)-1].Line)
+	return f.bufferBytes, f.bufferBytes - latest, f.buffer[0].Timestamp, f.infeedClosed
+}
+
+// next returns the log to send to feed next. If logs at the feed's
+// position have been dropped, the feed is moved on to the earliest log in
+// the buffer, which is returned with the number the feed missed. The feed
+// must not be past the last log.
+func (f *feeder) next(feed *outfeed) Log {
+	var missed int
+	if feed.pos < f.dropped {
+		missed = f.dropped - feed.pos
+		feed.pos = f.dropped
+	}
+	l := f.buffer[feed.pos-f.dropped]
+	l.Dropped = missed
+	return l
+}
+
+// notifyDrained wakes anyone waiting for the feeder to drain.
+func (f *feeder) notifyDrained() {
+	for _, ch := range f.drainWaiters {
+		close(ch)
+	}
+	f.drainWaiters = nil
+}
+
+func (f *feeder) takeSnapshot() FeederStats {
+	disabled := reflect.Value{}
+	stats := FeederStats{BufferLen: len(f.buffer), Dropped: f.dropped, InfeedClosed: f.infeedClosed}
+	for i, feed := range f.outfeeds {
+		caseIdx := i*2 + f.outOffset
+		stats.Outfeeds = append(stats.Outfeeds, OutfeedStats{
+			Pos:     feed.pos,
+			Lag:     f.total() - feed.pos,
+			Follow:  feed.follow,
+			Waiting: f.cases[caseIdx].Chan == disabled,
+		})
+	}
+	return stats
+}
+
+func (f *feeder) takePage(start, end int) LogPage {
+	total := f.total()
+	if end <= 0 || end > total {
+		end = total
+	}
+	if start < 0 {
+		start = 0
+	}
+	var missed int
+	if start < f.dropped {
+		missed = f.dropped - start
+		start = f.dropped
+	}
+	if end < f.dropped {
+		end = f.dropped
+	}
+	if start > end {
+		start = end
+	}
+	// Copy the range, as the buffer may be reallocated as it grows.
+	page := LogPage{Logs: slices.Clone(f.buffer[start-f.dropped : end-f.dropped]), Total: total}
+	if len(page.Logs) > 0 {
+		page.Logs[0].Dropped = missed
+	}
+	return page
+}
+
+func (f *feeder) takeWindow(from, to time.Time) LogPage {
+	page := LogPage{Total: f.total()}
+	for _, l := range f.buffer {
+		if !from.IsZero() && l.Timestamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && l.Timestamp.After(to) {
+			continue
+		}
+		page.Logs = append(page.Logs, l)
+	}
+	return page
 }
 
 func (f *feeder) addOutfeed(feed *outfeed) {
+	// A negative start position counts back from the end of the logs
+	// recorded so far, so only the last -pos logs are sent.
+	if feed.pos < 0 {
+		feed.pos += f.total()
+		if feed.pos < 0 {
+			feed.pos = 0
+		}
+	}
 	// If feed start position is past the end of the buffer and it is not
 	// following, close the channel and return
-	if feed.pos >= len(f.buffer) && (!feed.follow || f.infeedClosed) {
+	if feed.pos >= f.total() && (!feed.follow || f.infeedClosed) {
 		close(feed.ch)
 		return
 	}
@@ -134,9 +522,9 @@ func (f *feeder) addOutfeed(feed *outfeed) {
 	f.outfeeds = append(f.outfeeds, feed)
 
 	c := reflect.SelectCase{Dir: reflect.SelectSend}
-	if feed.pos < len(f.buffer) {
+	if feed.pos < f.total() {
 		c.Chan = reflect.ValueOf(feed.ch)
-		c.Send = reflect.ValueOf(f.buffer[feed.pos])
+		c.Send = reflect.ValueOf(f.next(feed))
 	}
 	f.cases = append(f.cases, c)
 
@@ -151,9 +539,9 @@ func (f *feeder) wakeSleepers() {
 	disabled := reflect.Value{}
 	for i, feed := range f.outfeeds {
 		caseIdx := i*2 + f.outOffset
-		if f.cases[caseIdx].Chan == disabled && feed.pos < len(f.buffer) {
+		if f.cases[caseIdx].Chan == disabled && feed.pos < f.total() {
 			f.cases[caseIdx].Chan = reflect.ValueOf(feed.ch)
-			f.cases[caseIdx].Send = reflect.ValueOf(f.buffer[feed.pos])
+			f.cases[caseIdx].Send = reflect.ValueOf(f.next(feed))
 		}
 	}
 }
@@ -187,13 +575,40 @@ func (f *feeder) removeOutfeed(i int) {
 	f.cases = slices.Delete(f.cases, caseIdx, caseIdx+2)
 }
 
-func infeed(r io.Reader, out chan<- Log) {
-	// XXX Unfortunately this is unlikely to work to put a maximum size on
-	// the read. This just sets the minimum size of the buffer, but it could
-	// potentially grow. We will probably need to do our own chunking of
-	// the data read. Still to do.
-	const maxLineSize = 512
-	buf := bufio.NewReaderSize(r, maxLineSize)
+// infeedStreams reads the lines of a job's stdout and stderr streams and
+// sends them to out, each tagged with the stream it was read from, closing
+// out once both streams are done. Lines are split per stream, so a partial
+// line on one stream is never joined with a line of the other. The lines of
+// the two streams are interleaved in timestamp order. Lines longer than
+// maxLineBytes are split into parts of at most that size, all but the last
+// being partial. If maxLineBytes is not positive, DefaultMaxLineBytes is used.
+func infeedStreams(stdout, stderr io.Reader, maxLineBytes int, out chan<- Log) {
+	if maxLineBytes <= 0 {
+		maxLineBytes = DefaultMaxLineBytes
+	}
+	// sendMu is held while a line is timestamped and sent, so that lines
+	// are sent in timestamp order whichever stream they are from.
+	var sendMu sync.Mutex
+	done := make(chan struct{})
+	go func() {
+		infeed(stderr, StreamStderr, maxLineBytes, &sendMu, out)
+		close(done)
+	}()
+	infeed(stdout, StreamStdout, maxLineBytes, &sendMu, out)
+	<-done
+	close(out)
+}
+
+// infeed reads lines from r and sends them to out tagged with stream,
+// holding sendMu while timestamping and sending each. Lines are split into
+// parts of at most maxLineBytes, so no more than that is buffered however
+// long a line is.
+func infeed(r io.Reader, stream Stream, maxLineBytes int, sendMu *sync.Mutex, out chan<- Log) {
+	// The buffer never grows: ReadSlice returns ErrBufferFull with the
+	// buffer's contents when it fills before a newline. Its size may be
+	// above maxLineBytes, as bufio has a minimum, so lines are split
+	// again below.
+	buf := bufio.NewReaderSize(r, maxLineBytes)
 
 	// The infeed loop terminates when the Reader r returns an error or
 	// EOF. This occurs when the process attached to that reader exits
@@ -203,9 +618,21 @@ func infeed(r io.Reader, out chan<- Log) {
 	// running, and perhaps somehow re-attach to them later. This is
 	// way way way out of scope :)
 	for {
-		line, err := buf.ReadBytes('\n')
-		if len(line) > 0 {
-			out <- Log{Timestamp: time.Now(), Line: line}
+		line, err := buf.ReadSlice('\n')
+		for len(line) > 0 {
+			n := len(line)
+			if n > maxLineBytes {
+				n = maxLineBytes
+			}
+			// The slice is only valid until the next read, so copy it.
+			l := Log{Line: append([]byte(nil), line[:n]...), Stream: stream}
+			line = line[n:]
+			// A line that ends at EOF without a newline is complete.
+			l.Partial = len(line) > 0 || err == bufio.ErrBufferFull
+			sendMu.Lock()
+			l.Timestamp = time.Now()
+			out <- l
+			sendMu.Unlock()
 		}
 		if err != nil && err != bufio.ErrBufferFull && err != io.EOF {
 			// XXX Should log, but no logger yet
@@ -215,5 +642,26 @@ func infeed(r io.Reader, out chan<- Log) {
 			break
 		}
 	}
-	close(out)
+}
+
+// spoolLog writes l to the feeder's spool, if it has one. If it cannot be
+// written, the job's output is spooled no further, but it is still kept in
+// memory.
+func (f *feeder) spoolLog(l Log) {
+	if f.spool == nil {
+		return
+	}
+	if err := f.spool.write(l); err != nil {
+		// XXX Should log, but no logger yet
+		fmt.Fprintf(os.Stderr, "could not spool output, spooling stopped: %v\n", err)
+		f.closeSpool()
+	}
+}
+
+func (f *feeder) closeSpool() {
+	if f.spool == nil {
+		return
+	}
+	_ = f.spool.close()
+	f.spool = nil
 }
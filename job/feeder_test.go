@@ -0,0 +1,428 @@
+package job
+
+import (
+	"crypto/sha256"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeederStats(t *testing.T) {
+	in := make(chan Log)
+	done := make(chan struct{})
+	defer close(done)
+	f := newFeeder(in)
+	go f.Start(done)
+
+	require.Equal(t, FeederStats{}, f.stats())
+
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		in <- Log{Line: []byte(line)}
+	}
+
+	follower := f.attachOutfeed(true /* follow */, 0, nil)
+	reader := f.attachOutfeed(false /* follow */, 0, nil)
+	for i := 0; i < 3; i++ {
+		<-follower
+	}
+	<-reader
+
+	expected := FeederStats{
+		BufferLen: 3,
+		Outfeeds: []OutfeedStats{
+			{Pos: 3, Lag: 0, Follow: true, Waiting: true},
+			{Pos: 1, Lag: 2},
+		},
+	}
+	require.Equal(t, expected, f.stats())
+
+	// Closing the infeed closes the waiting follower, leaving just the
+	// reader that has not reached the end of the logs.
+	close(in)
+	_, ok := <-follower
+	require.False(t, ok)
+
+	expected = FeederStats{
+		BufferLen:    3,
+		InfeedClosed: true,
+		Outfeeds:     []OutfeedStats{{Pos: 1, Lag: 2}},
+	}
+	require.Equal(t, expected, f.stats())
+}
+
+func TestFeederStatsStopped(t *testing.T) {
+	in := make(chan Log)
+	done := make(chan struct{})
+	f := newFeeder(in)
+	go f.Start(done)
+	close(done)
+	<-f.stopped
+
+	stats := make(chan FeederStats)
+	go func() { stats <- f.stats() }()
+	select {
+	case s := <-stats:
+		require.Equal(t, FeederStats{}, s)
+	case <-time.After(time.Second):
+		t.Fatal("stats of a stopped feeder did not return")
+	}
+}
+
+func TestFeederStartPosition(t *testing.T) {
+	in := make(chan Log)
+	done := make(chan struct{})
+	defer close(done)
+	f := newFeeder(in)
+	go f.Start(done)
+
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		in <- Log{Line: []byte(line)}
+	}
+	close(in)
+
+	var got []string
+	for l := range f.attachOutfeed(false /* follow */, 1, nil) {
+		got = append(got, string(l.Line))
+	}
+	require.Equal(t, []string{"two\n", "three\n"}, got)
+
+	// Starting past the end of the logs gets nothing.
+	_, ok := <-f.attachOutfeed(true /* follow */, 5, nil)
+	require.False(t, ok)
+}
+
+func TestFeederTail(t *testing.T) {
+	in := make(chan Log)
+	done := make(chan struct{})
+	defer close(done)
+	f := newFeeder(in)
+	go f.Start(done)
+
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		in <- Log{Line: []byte(line)}
+	}
+
+	// A tail longer than the logs gets them all.
+	var got []string
+	for l := range f.attachOutfeed(false /* follow */, -5, nil) {
+		got = append(got, string(l.Line))
+	}
+	require.Equal(t, []string{"one\n", "two\n", "three\n"}, got)
+
+	// Following a tail gets the tail of the logs so far, then new logs.
+	ch := f.attachOutfeed(true /* follow */, -2, nil)
+	require.Equal(t, "two\n", string((<-ch).Line))
+	require.Equal(t, "three\n", string((<-ch).Line))
+	in <- Log{Line: []byte("four\n")}
+	require.Equal(t, "four\n", string((<-ch).Line))
+	close(in)
+	_, ok := <-ch
+	require.False(t, ok)
+}
+
+func TestFeederPage(t *testing.T) {
+	in := make(chan Log)
+	done := make(chan struct{})
+	defer close(done)
+	f := newFeeder(in)
+	go f.Start(done)
+
+	lines := func(page LogPage) []string {
+		got := []string{}
+		for _, l := range page.Logs {
+			got = append(got, string(l.Line))
+		}
+		return got
+	}
+
+	require.Equal(t, 0, f.page(0, 0).Total)
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		in <- Log{Line: []byte(line)}
+	}
+
+	tests := map[string]struct {
+		start, end int
+		expected   []string
+	}{
+		"all":            {start: 0, end: 0, expected: []string{"one\n", "two\n", "three\n"}},
+		"range":          {start: 1, end: 2, expected: []string{"two\n"}},
+		"to end":         {start: 1, end: 0, expected: []string{"two\n", "three\n"}},
+		"end past total": {start: 2, end: 10, expected: []string{"three\n"}},
+		"start past end": {start: 5, end: 10, expected: []string{}},
+		"empty range":    {start: 2, end: 2, expected: []string{}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			page := f.page(tc.start, tc.end)
+			require.Equal(t, tc.expected, lines(page))
+			require.Equal(t, 3, page.Total)
+		})
+	}
+
+	// The total grows as more logs are recorded.
+	in <- Log{Line: []byte("four\n")}
+	page := f.page(3, 0)
+	require.Equal(t, []string{"four\n"}, lines(page))
+	require.Equal(t, 4, page.Total)
+}
+
+func TestFeederWindow(t *testing.T) {
+	in := make(chan Log)
+	done := make(chan struct{})
+	defer close(done)
+	f := newFeeder(in)
+	go f.Start(done)
+
+	base := time.Date(2022, 5, 27, 12, 0, 0, 0, time.UTC)
+	at := func(sec int) time.Time { return base.Add(time.Duration(sec) * time.Second) }
+	for i, line := range []string{"one\n", "two\n", "three\n"} {
+		in <- Log{Timestamp: at(i * 10), Line: []byte(line)}
+	}
+
+	tests := map[string]struct {
+		from, to time.Time
+		expected []string
+	}{
+		"unbounded":    {expected: []string{"one\n", "two\n", "three\n"}},
+		"inclusive":    {from: at(0), to: at(10), expected: []string{"one\n", "two\n"}},
+		"within":       {from: at(5), to: at(15), expected: []string{"two\n"}},
+		"open from":    {to: at(5), expected: []string{"one\n"}},
+		"open to":      {from: at(15), expected: []string{"three\n"}},
+		"between logs": {from: at(11), to: at(19), expected: []string{}},
+		"before logs":  {from: at(-60), to: at(-1), expected: []string{}},
+		"after logs":   {from: at(21), to: at(60), expected: []string{}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			page := f.window(tc.from, tc.to)
+			got := []string{}
+			for _, l := range page.Logs {
+				got = append(got, string(l.Line))
+			}
+			require.Equal(t, tc.expected, got)
+			require.Equal(t, 3, page.Total)
+		})
+	}
+
+	// Logs in a window keep their index.
+	page := f.window(at(20), time.Time{})
+	require.Equal(t, 2, page.Logs[0].Index)
+}
+
+func TestFeederIndex(t *testing.T) {
+	in := make(chan Log)
+	done := make(chan struct{})
+	defer close(done)
+	f := newFeeder(in)
+	go f.Start(done)
+
+	const numLines = 100
+	follower := f.attachOutfeed(true /* follow */, 0, nil)
+	go func() {
+		for i := 0; i < numLines; i++ {
+			// Any index set by the sender is replaced.
+			in <- Log{Line: []byte("line\n"), Index: -1}
+		}
+		close(in)
+	}()
+
+	// Followers see every line numbered in order without gaps.
+	next := 0
+	for l := range follower {
+		require.Equal(t, next, l.Index)
+		next++
+	}
+	require.Equal(t, numLines, next)
+
+	// Lines read from a later start or in a page keep their index.
+	next = 42
+	for l := range f.attachOutfeed(false /* follow */, 42, nil) {
+		require.Equal(t, next, l.Index)
+		next++
+	}
+	require.Equal(t, numLines, next)
+	page := f.page(10, 13)
+	require.Equal(t, []int{10, 11, 12}, []int{page.Logs[0].Index, page.Logs[1].Index, page.Logs[2].Index})
+}
+
+func TestInfeedStreams(t *testing.T) {
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+	out := make(chan Log)
+	go infeedStreams(stdoutR, stderrR, 0, out)
+
+	// A partial line on one stream is not ended by a line on the other.
+	write := func(w io.Writer, s string) {
+		_, err := w.Write([]byte(s))
+		require.NoError(t, err)
+	}
+	write(stdoutW, "one ")
+	write(stderrW, "oops\n")
+	require.Equal(t, Log{Line: []byte("oops\n"), Stream: StreamStderr}, withoutTimestamp(<-out))
+	write(stdoutW, "two\n")
+	require.Equal(t, Log{Line: []byte("one two\n"), Stream: StreamStdout}, withoutTimestamp(<-out))
+
+	// out is closed once both streams are done.
+	require.NoError(t, stdoutW.Close())
+	write(stderrW, "last")
+	require.NoError(t, stderrW.Close())
+	l := <-out
+	require.Equal(t, Log{Line: []byte("last"), Stream: StreamStderr}, withoutTimestamp(l))
+	_, ok := <-out
+	require.False(t, ok)
+}
+
+func TestFeederRingBuffer(t *testing.T) {
+	in := make(chan Log)
+	done := make(chan struct{})
+	defer close(done)
+	f := newFeeder(in)
+	f.maxLines = 3
+	go f.Start(done)
+
+	// A follower that falls behind is moved on past the dropped logs.
+	follower := f.attachOutfeed(true /* follow */, 0, nil)
+	in <- Log{Line: []byte("0\n")}
+	require.Equal(t, Log{Line: []byte("0\n"), Index: 0}, <-follower)
+	for _, line := range []string{"1\n", "2\n", "3\n", "4\n", "5\n"} {
+		in <- Log{Line: []byte(line)}
+	}
+	// Log 1 was due to be sent before it was dropped.
+	require.Equal(t, 1, (<-follower).Index)
+	require.Equal(t, Log{Line: []byte("3\n"), Index: 3, Dropped: 1}, <-follower)
+	require.Equal(t, Log{Line: []byte("4\n"), Index: 4}, <-follower)
+	require.Equal(t, Log{Line: []byte("5\n"), Index: 5}, <-follower)
+
+	stats := f.stats()
+	require.Equal(t, 3, stats.BufferLen)
+	require.Equal(t, 3, stats.Dropped)
+
+	// A new reader starts at the earliest log kept, told how many it
+	// missed.
+	var got []Log
+	for l := range f.attachOutfeed(false /* follow */, 0, nil) {
+		got = append(got, l)
+	}
+	expected := []Log{
+		{Line: []byte("3\n"), Index: 3, Dropped: 3},
+		{Line: []byte("4\n"), Index: 4},
+		{Line: []byte("5\n"), Index: 5},
+	}
+	require.Equal(t, expected, got)
+	got = nil
+	for l := range f.attachOutfeed(false /* follow */, 4, nil) {
+		got = append(got, l)
+	}
+	require.Equal(t, expected[1:], got)
+
+	page := f.page(1, 0)
+	require.Equal(t, 6, page.Total)
+	require.Equal(t, expected[0].Line, page.Logs[0].Line)
+	require.Equal(t, 2, page.Logs[0].Dropped)
+	require.Len(t, page.Logs, 3)
+	page = f.page(0, 2)
+	require.Empty(t, page.Logs)
+	require.Equal(t, 6, page.Total)
+}
+
+func TestFeederRingBufferBytes(t *testing.T) {
+	in := make(chan Log)
+	done := make(chan struct{})
+	defer close(done)
+	f := newFeeder(in)
+	f.maxBytes = 10
+	go f.Start(done)
+
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		in <- Log{Line: []byte(line)}
+	}
+	// "two\n" and "three\n" are 10 bytes between them.
+	page := f.page(0, 0)
+	require.Len(t, page.Logs, 2)
+	require.Equal(t, "two\n", string(page.Logs[0].Line))
+	require.Equal(t, 1, page.Logs[0].Dropped)
+
+	// The latest log is kept even if it exceeds the limit alone.
+	in <- Log{Line: []byte("a very long line\n")}
+	page = f.page(0, 0)
+	require.Len(t, page.Logs, 1)
+	require.Equal(t, 3, page.Logs[0].Dropped)
+	require.Equal(t, 4, page.Total)
+}
+
+// repeatReader reads pattern repeated endlessly.
+type repeatReader struct {
+	pattern []byte
+	off     int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.pattern[r.off]
+		r.off = (r.off + 1) % len(r.pattern)
+	}
+	return len(p), nil
+}
+
+func TestInfeedLongLine(t *testing.T) {
+	const size = 10 << 20
+	const maxLineBytes = 1000
+	blob := io.LimitReader(&repeatReader{pattern: []byte("0123456789abcdef")}, size)
+	want := sha256.New()
+	r := io.TeeReader(blob, want)
+	out := make(chan Log)
+	go infeedStreams(r, strings.NewReader("short\n"), maxLineBytes, out)
+
+	// The line is split into parts no larger than the maximum, so no more
+	// than that is read at once, and the parts make up the whole line.
+	got := sha256.New()
+	var n, parts int
+	var last Log
+	for l := range out {
+		if l.Stream == StreamStderr {
+			require.Equal(t, Log{Line: []byte("short\n"), Stream: StreamStderr}, withoutTimestamp(l))
+			continue
+		}
+		require.LessOrEqual(t, len(l.Line), maxLineBytes)
+		if parts > 0 {
+			require.True(t, last.Partial, "part %d", parts)
+		}
+		_, _ = got.Write(l.Line)
+		n += len(l.Line)
+		parts++
+		last = l
+	}
+	require.Equal(t, size, n)
+	require.Equal(t, (size+maxLineBytes-1)/maxLineBytes, parts)
+	// The line ended at EOF, so its last part is complete.
+	require.False(t, last.Partial)
+	require.Equal(t, want.Sum(nil), got.Sum(nil))
+}
+
+func TestInfeedSplitLines(t *testing.T) {
+	// The maximum is below bufio's minimum buffer size.
+	out := make(chan Log)
+	go infeedStreams(strings.NewReader("abcdefghij\nabcd\nabcdefgh"), strings.NewReader(""), 4, out)
+	var got []Log
+	for l := range out {
+		got = append(got, withoutTimestamp(l))
+	}
+	expected := []Log{
+		{Line: []byte("abcd"), Partial: true},
+		{Line: []byte("efgh"), Partial: true},
+		{Line: []byte("ij\n")},
+		{Line: []byte("abcd"), Partial: true},
+		{Line: []byte("\n")},
+		{Line: []byte("abcd"), Partial: true},
+		{Line: []byte("efgh")},
+	}
+	require.Equal(t, expected, got)
+}
+
+func withoutTimestamp(l Log) Log {
+	l.Timestamp = time.Time{}
+	return l
+}
@@ -0,0 +1,137 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// ResourceSample is the resource usage of a job at a point in time, read
+// from its cgroup.
+type ResourceSample struct {
+	Time time.Time
+	// Memory is the memory the job was using, in bytes.
+	Memory uint64
+	// CPUTime is the CPU time the job had used so far.
+	CPUTime time.Duration
+	// Processes is the number of processes the job had.
+	Processes uint64
+}
+
+// ResourceHistory is the resource usage of a job sampled over time, oldest
+// first. Dropped is the number of earlier samples dropped to keep within
+// the tracker's maximum.
+type ResourceHistory struct {
+	Samples []ResourceSample
+	Dropped int
+}
+
+// ErrNotSampled is returned for the resource history of a job that was not
+// started with SampleResources set, so has none.
+var ErrNotSampled = errors.New("job does not sample its resource usage")
+
+// DefaultSampleInterval and DefaultMaxSamples are how often the resource
+// usage of jobs that sample it is read, and how many samples are kept for
+// each, unless set with WithResourceSampling. Together they keep an hour.
+const (
+	DefaultSampleInterval = 10 * time.Second
+	DefaultMaxSamples     = 360
+)
+
+// WithResourceSampling sets how often the resource usage of jobs with
+// SampleResources in their spec is read, and the most samples kept for
+// each job, beyond which the oldest are dropped. Zero values leave the
+// defaults.
+func WithResourceSampling(interval time.Duration, maxSamples int) TrackerOption {
+	return func(t *Tracker) {
+		if interval > 0 {
+			t.sampleInterval = interval
+		}
+		if maxSamples > 0 {
+			t.maxSamples = maxSamples
+		}
+	}
+}
+
+// ResourceHistory returns the resource usage of the job identified by id
+// sampled over its run, if its spec samples it.
+func (t *Tracker) ResourceHistory(ctx context.Context, id string) (ResourceHistory, error) {
+	user, ok := GetUserFromContext(ctx)
+	if !ok {
+		return ResourceHistory{}, ErrUnauthorized
+	}
+
+	t.refreshReplica()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	j, err := t.lookup(user, id)
+	if err != nil {
+		return ResourceHistory{}, err
+	}
+	jd := j.summary()
+	if !t.canView(ctx, user, jd.Status.Owner) {
+		return ResourceHistory{}, ErrUnauthorized
+	}
+	if !jd.Spec.SampleResources {
+		return ResourceHistory{}, fmt.Errorf("%s: %w", id, ErrNotSampled)
+	}
+	return j.ResourceHistory(), nil
+}
+
+// ResourceHistory returns the resource usage samples of the job.
+func (j *Job) ResourceHistory() ResourceHistory {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	samples := make([]ResourceSample, len(j.samples))
+	copy(samples, j.samples)
+	return ResourceHistory{Samples: samples, Dropped: j.samplesDropped}
+}
+
+// recordSample adds s to the job's resource usage samples, dropping the
+// oldest if there are more than the job's maximum.
+func (j *Job) recordSample(s ResourceSample) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.samples = append(j.samples, s)
+	max := j.maxSamples
+	if max <= 0 {
+		max = DefaultMaxSamples
+	}
+	if n := len(j.samples) - max; n > 0 {
+		j.samples = j.samples[n:]
+		j.samplesDropped += n
+	}
+}
+
+// sampleResources records a sample of the resource usage of the job
+// identified by id with record on each tick, timestamped with the tick's
+// time, until stop is closed.
+func sampleResources(id string, tick <-chan time.Time, stop <-chan struct{}, record func(ResourceSample)) {
+	for {
+		select {
+		case now := <-tick:
+			record(readResourceSample(id, now))
+		case <-stop:
+			return
+		}
+	}
+}
+
+// readResourceSample reads a sample of the resource usage of the job
+// identified by id from its cgroup at time now. Usage that cannot be read
+// is zero.
+func readResourceSample(id string, now time.Time) ResourceSample {
+	dir := cgroupDir(id)
+	s := ResourceSample{
+		Time:      now,
+		Memory:    readCgroupUint(filepath.Join(dir, "memory.current")),
+		Processes: readCgroupUint(filepath.Join(dir, "pids.current")),
+	}
+	if usec, ok := readEvents(id, "cpu.stat")["usage_usec"]; ok {
+		s.CPUTime = time.Duration(usec) * time.Microsecond
+	}
+	return s
+}
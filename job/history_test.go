@@ -0,0 +1,104 @@
+package job
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleResources(t *testing.T) {
+	root := fakeCgroupRoot(t)
+	dir := filepath.Join(root, "test-1")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	writeFile := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	tick, stop, done := make(chan time.Time), make(chan struct{}), make(chan struct{})
+	recorded := make(chan ResourceSample)
+	go func() {
+		sampleResources("test-1", tick, stop, func(s ResourceSample) { recorded <- s })
+		close(done)
+	}()
+	var samples []ResourceSample
+	sample := func(now time.Time) {
+		t.Helper()
+		tick <- now
+		samples = append(samples, <-recorded)
+	}
+
+	start := time.Date(2022, 5, 27, 12, 24, 4, 0, time.UTC)
+	usage := []struct {
+		memory, cpuUsec, procs string
+	}{
+		{"4096", "1500", "1"},
+		{"8192", "4000", "3"},
+		{"6144", "9000", "2"},
+	}
+	var want []ResourceSample
+	for i, u := range usage {
+		writeFile("memory.current", u.memory+"\n")
+		writeFile("cpu.stat", "usage_usec "+u.cpuUsec+"\nuser_usec 0\n")
+		writeFile("pids.current", u.procs+"\n")
+		now := start.Add(time.Duration(i) * 10 * time.Second)
+		sample(now)
+		want = append(want, readResourceSample("test-1", now))
+	}
+	// A cgroup file that cannot be read is zero.
+	require.NoError(t, os.Remove(filepath.Join(dir, "pids.current")))
+	sample(start.Add(30 * time.Second))
+	close(stop)
+	<-done
+
+	want = append(want, ResourceSample{Time: start.Add(30 * time.Second), Memory: 6144, CPUTime: 9 * time.Millisecond})
+	require.Equal(t, want, samples)
+	require.Equal(t, ResourceSample{Time: start, Memory: 4096, CPUTime: 1500 * time.Microsecond, Processes: 1}, samples[0])
+}
+
+func TestRecordSampleDropsOldest(t *testing.T) {
+	j := NewJob("test-1", JobSpec{Command: "/bin/sh"}, nil)
+	j.maxSamples = 2
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		j.recordSample(ResourceSample{Time: start.Add(time.Duration(i) * time.Second), Processes: uint64(i)})
+	}
+	history := j.ResourceHistory()
+	require.Equal(t, 3, history.Dropped)
+	require.Len(t, history.Samples, 2)
+	require.Equal(t, uint64(3), history.Samples[0].Processes)
+	require.Equal(t, uint64(4), history.Samples[1].Processes)
+}
+
+func TestTrackerResourceHistory(t *testing.T) {
+	fakeCgroupRoot(t)
+	tracker := newTestTracker("exec sleep 60 2>&1", WithResourceSampling(time.Millisecond, 10))
+	userCtx := AddUserToContext(context.Background(), "eve")
+	otherCtx := AddUserToContext(context.Background(), "mallory")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sleep", SampleResources: true})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tracker.Stop(userCtx, id, false /* cleanup */) })
+
+	// Samples are taken every millisecond and capped at 10.
+	require.Eventually(t, func() bool {
+		history, err := tracker.ResourceHistory(userCtx, id)
+		return err == nil && len(history.Samples) == 10 && history.Dropped > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = tracker.ResourceHistory(otherCtx, id)
+	require.ErrorIs(t, err, ErrUnauthorized)
+	_, err = tracker.ResourceHistory(adminCtx, id)
+	require.NoError(t, err)
+
+	unsampled, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sleep"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tracker.Stop(userCtx, unsampled, false /* cleanup */) })
+	_, err = tracker.ResourceHistory(userCtx, unsampled)
+	require.ErrorIs(t, err, ErrNotSampled)
+}
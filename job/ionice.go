@@ -0,0 +1,129 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// IOClass is an IO scheduling class as used by ioprio_set(2). The values
+// match the kernel's IOPRIO_CLASS_* constants.
+type IOClass int
+
+const (
+	IOClassNone IOClass = iota
+	IOClassRealtime
+	IOClassBestEffort
+	IOClassIdle
+)
+
+// MaxIOPriority is the highest (lowest priority) level within the realtime
+// and best-effort IO scheduling classes.
+const MaxIOPriority = 7
+
+var ioClassNames = map[IOClass]string{
+	IOClassNone:       "none",
+	IOClassRealtime:   "realtime",
+	IOClassBestEffort: "best-effort",
+	IOClassIdle:       "idle",
+}
+
+var ErrInvalidIONice = errors.New("invalid ionice")
+
+func (c IOClass) String() string {
+	if name, ok := ioClassNames[c]; ok {
+		return name
+	}
+	return "IOClass(" + strconv.Itoa(int(c)) + ")"
+}
+
+// IONice is the IO scheduling class and priority level within that class
+// for a job. The zero value leaves the job's IO scheduling unchanged.
+type IONice struct {
+	Class    IOClass
+	Priority int
+}
+
+// UnmarshalText unmarshals a string ([]byte) into an IONice. It is used by
+// kong to unmarshal the command line argument into a structured value.
+//
+// The format of the input string is a class name (none, realtime,
+// best-effort or idle) optionally followed by a colon and a priority level
+// from 0 (highest) to 7 (lowest). The idle class does not take a priority.
+func (n *IONice) UnmarshalText(b []byte) error {
+	name, level, hasLevel := strings.Cut(string(b), ":")
+
+	found := false
+	for class, className := range ioClassNames {
+		if name == className {
+			n.Class, found = class, true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: unknown class %q", ErrInvalidIONice, name)
+	}
+
+	n.Priority = 0
+	if hasLevel {
+		p, err := strconv.Atoi(level)
+		if err != nil {
+			return fmt.Errorf("%w: could not parse priority %s: %v", ErrInvalidIONice, level, err)
+		}
+		n.Priority = p
+	}
+	return n.Validate()
+}
+
+// MarshalText marshals an IONice in the format accepted by UnmarshalText.
+func (n IONice) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+func (n IONice) String() string {
+	if n.Class == IOClassRealtime || n.Class == IOClassBestEffort {
+		return fmt.Sprintf("%s:%d", n.Class, n.Priority)
+	}
+	return n.Class.String()
+}
+
+// Validate checks that the class is known and the priority is valid for
+// the class.
+func (n IONice) Validate() error {
+	switch n.Class {
+	case IOClassRealtime, IOClassBestEffort:
+		if n.Priority < 0 || n.Priority > MaxIOPriority {
+			return fmt.Errorf("%w: priority %d not in range 0 to %d", ErrInvalidIONice, n.Priority, MaxIOPriority)
+		}
+	case IOClassNone, IOClassIdle:
+		if n.Priority != 0 {
+			return fmt.Errorf("%w: class %s does not take a priority", ErrInvalidIONice, n.Class)
+		}
+	default:
+		return fmt.Errorf("%w: unknown class %d", ErrInvalidIONice, n.Class)
+	}
+	return nil
+}
+
+// ioprio returns the value to pass to ioprio_set(2) for the class and
+// priority.
+func (n IONice) ioprio() uintptr {
+	const ioprioClassShift = 13
+	return uintptr(n.Class)<<ioprioClassShift | uintptr(n.Priority)
+}
+
+// set sets the IO scheduling class and priority of the calling thread, as
+// IOPRIO_WHO_PROCESS applies to a single thread on Linux. The caller must be
+// locked to its OS thread for it to apply to a command it then execs.
+func (n IONice) set() error {
+	const ioprioWhoProcess = 1
+	_, _, errno := syscall.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, 0 /* self */, n.ioprio())
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
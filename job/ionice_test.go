@@ -0,0 +1,53 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIONiceUnmarshalText(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected IONice
+		err      bool
+	}{
+		"none":                  {input: "none", expected: IONice{Class: IOClassNone}},
+		"idle":                  {input: "idle", expected: IONice{Class: IOClassIdle}},
+		"best-effort":           {input: "best-effort", expected: IONice{Class: IOClassBestEffort}},
+		"best-effort priority":  {input: "best-effort:7", expected: IONice{Class: IOClassBestEffort, Priority: 7}},
+		"realtime priority":     {input: "realtime:2", expected: IONice{Class: IOClassRealtime, Priority: 2}},
+		"unknown class":         {input: "urgent", err: true},
+		"priority out of range": {input: "realtime:8", err: true},
+		"negative priority":     {input: "best-effort:-1", err: true},
+		"bad priority":          {input: "best-effort:high", err: true},
+		"idle with priority":    {input: "idle:3", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var n IONice
+			err := n.UnmarshalText([]byte(tc.input))
+			if tc.err {
+				require.ErrorIs(t, err, ErrInvalidIONice)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, n)
+		})
+	}
+}
+
+func TestIONiceString(t *testing.T) {
+	require.Equal(t, "idle", IONice{Class: IOClassIdle}.String())
+	require.Equal(t, "best-effort:4", IONice{Class: IOClassBestEffort, Priority: 4}.String())
+	require.Equal(t, "realtime:0", IONice{Class: IOClassRealtime}.String())
+}
+
+func TestIONiceIOPrio(t *testing.T) {
+	// IOPRIO_PRIO_VALUE(class, data) is (class << 13) | data
+	require.Equal(t, uintptr(0), IONice{}.ioprio())
+	require.Equal(t, uintptr(1<<13|3), IONice{Class: IOClassRealtime, Priority: 3}.ioprio())
+	require.Equal(t, uintptr(2<<13|7), IONice{Class: IOClassBestEffort, Priority: 7}.ioprio())
+	require.Equal(t, uintptr(3<<13), IONice{Class: IOClassIdle}.ioprio())
+}
@@ -5,17 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
+	"unicode"
 )
 
 const JobberCG = "/sys/fs/cgroup/jobber"
 
+// cgroupRoot is the directory of the jobber cgroup that job cgroups are
+// created under. It is only changed by tests to write to a fake cgroup fs.
+var cgroupRoot = JobberCG
+
 type ArgMaker func(JobDescription) (string, []string)
 
 type Job struct {
@@ -25,30 +34,281 @@ type Job struct {
 
 	argMaker ArgMaker
 
+	// mu guards Status, cmd, logFeeder and the channels below. A tracker
+	// may hold its own lock while taking mu, so mu is never held while
+	// taking the tracker's lock, nor while waiting for the job's process.
 	mu  sync.Mutex
 	cmd *exec.Cmd
 
 	logFeeder *feeder
 
+	// stopGracePeriod is how long Stop waits after sending SIGTERM for
+	// the job to exit before killing it. If zero, it is killed at once.
+	stopGracePeriod time.Duration
+
+	// noNamespaces runs the job's command without creating new namespaces.
+	// It is only for testing job handling without root privileges.
+	noNamespaces bool
+
+	// spool, if not nil, keeps the job's output on disk as well as in
+	// memory.
+	spool *Spool
+
+	// store, if not nil, keeps the job's description and logs so they
+	// survive a restart of the server. persistMu serialises saving them.
+	store     Store
+	persistMu sync.Mutex
+
+	// eventLog, if not nil, records the job's lifecycle events.
+	eventLog *EventLog
+	// pushgateway, if not nil, is pushed the job's final metrics when it
+	// completes.
+	pushgateway *Pushgateway
+
+	// maxLineBytes is the size at which lines of the job's output are
+	// split. If not positive, DefaultMaxLineBytes is used.
+	maxLineBytes int
+
+	// maxLogLines and maxLogBytes limit the logs kept in memory for the
+	// job, beyond which its oldest logs are dropped. Zero is no limit.
+	maxLogLines int
+	maxLogBytes int
+	// logBudget, if not nil, limits the logs kept in memory for the job
+	// and others.
+	logBudget *logBudget
+
+	// userNSIDs are the host IDs the job's IDs are mapped to if its spec
+	// isolates users. If empty, DefaultIDMapping is used.
+	userNSIDs IDMapping
+
+	// usage is the job's resource usage when last read from its cgroup.
+	usage ResourceUsage
+
+	// samples are the job's resource usage read every sampleInterval if
+	// its spec samples it, up to maxSamples, beyond which the oldest are
+	// dropped and counted in samplesDropped. If not positive,
+	// DefaultSampleInterval and DefaultMaxSamples are used.
+	samples        []ResourceSample
+	samplesDropped int
+	sampleInterval time.Duration
+	maxSamples     int
+
+	// stopping is set once the job is being stopped, and timedOut if that
+	// is because it ran past its spec's timeout.
+	stopping bool
+	timedOut bool
+
 	reaped chan struct{}
 	done   chan struct{}
+
+	// admitted is closed when a queued job leaves the queue, whether it
+	// started or not. It is nil for a job that was never queued.
+	admitted chan struct{}
 }
 
 type JobSpec struct {
-	Command string   `arg:"" help:"Command for jobber server to run"`
+	Command string   `arg:"" optional:"" help:"Command for jobber server to run"`
 	Args    []string `arg:"" optional:"" help:"Arguments to command"`
 
+	// Commands is a sequence of command lines run one after the other
+	// instead of Command and Args, stopping at the first that fails. It
+	// cannot be given as command line flags.
+	Commands [][]string `kong:"-"`
+
 	Root           string `help:"run in isolated root directory"`
 	IsolateNetwork bool   `help:"run in isolated network namespace"`
+	IsolateUsers   bool   `help:"run in isolated user namespace, as root in the job but an unprivileged user on the host"`
+	MountDev       bool   `help:"mount a minimal /dev (null, zero, full, random, urandom, tty) in the job"`
+	Nice           int    `help:"scheduling niceness of the job (-20 to 19)"`
+	IONice         IONice `name:"ionice" help:"IO scheduling class and priority (realtime[:0-7], best-effort[:0-7], idle)"`
+	Umask          Umask  `help:"file mode creation mask of the job in octal, such as 022 (default: the server's)"`
+
+	Workdir string      `help:"working directory of the job (default /)"`
+	Mounts  []BindMount `name:"mount" help:"bind mount a server path into the job (source:target[:ro])"`
+
+	// RunAsUser and RunAsGroup are the user and group IDs the job's
+	// command runs as, once the job is set up as root. Zero for both
+	// leaves the command running as root. A user must be given a group,
+	// so the command does not keep the root group.
+	RunAsUser  uint32 `name:"user" help:"user ID to run the job's command as (default: root)"`
+	RunAsGroup uint32 `name:"group" help:"group ID to run the job's command as, required with --user (default: root)"`
+
+	TraceSyscalls bool `help:"log failing syscalls of the job to its output (for debugging)"`
+
+	// Name is the ID to give the job instead of one generated from its
+	// command. It becomes the job's cgroup directory and hostname, so is
+	// limited to maxNameLen lowercase letters, digits and hyphens. It is
+	// handled by the tracker and is not passed on to ExecPart2.
+	Name string `help:"ID to give the job instead of a generated one ([a-z0-9-], unique on the server)"`
+
+	// StopOnClientDisconnect stops the job if the client that ran it stops
+	// following its logs before it completes. Other clients following its
+	// logs do not stop it. It is handled by the tracker and is not passed
+	// on to ExecPart2.
+	StopOnClientDisconnect bool `help:"stop the job if this client disconnects from its logs before it completes"`
+
+	// SampleResources records the job's resource usage at the tracker's
+	// sample interval for its resource history. It is handled by Start
+	// and is not passed on to ExecPart2.
+	SampleResources bool `help:"record the job's memory, CPU time and processes over its run, shown with stats-history"`
+
+	// MaxLogLinesPerSec limits the rate of lines kept from the job's
+	// output. It is handled by Start and is not passed on to ExecPart2.
+	MaxLogLinesPerSec uint32 `help:"maximum lines per second kept from the job's output; the rest are dropped (0 for the server default)"`
+
+	// Queue queues the job if the tracker is running its maximum number
+	// of jobs, rather than failing to start it. It is handled by the
+	// tracker and is not passed on to ExecPart2.
+	Queue bool `help:"if the server is running its maximum number of jobs, queue the job to run when others complete rather than failing"`
+
+	// Timeout is the longest the job may run before it is stopped. It is
+	// a whole number of seconds, and zero is no limit. It is handled by
+	// Start and is not passed on to ExecPart2.
+	Timeout time.Duration `help:"stop the job if it runs longer than this, in whole seconds such as 30s (0 for no limit)"`
+
+	// SetupTimeout is the longest ExecPart2 may take to set up the job
+	// before running its command, after which it fails to start. Zero is
+	// no limit. It is set by the tracker rather than the client, and
+	// cannot be given as a command line flag.
+	SetupTimeout time.Duration `kong:"-"`
 
 	Resources ResourceLimits `embed:""`
 }
 
+// Validate checks that the job spec can be run, returning an error
+// describing the first problem found.
+func (s JobSpec) Validate() error {
+	if s.Command == "" && len(s.Commands) == 0 {
+		return ErrNoCommand
+	}
+	if s.Command != "" && len(s.Commands) > 0 {
+		return fmt.Errorf("%w: both a command and a command sequence given", ErrInvalidCommands)
+	}
+	for i, argv := range s.Commands {
+		if len(argv) == 0 || argv[0] == "" {
+			return fmt.Errorf("%w: command %d is empty", ErrInvalidCommands, i+1)
+		}
+	}
+	if s.Name != "" {
+		if err := checkName(s.Name); err != nil {
+			return err
+		}
+	}
+	if s.TraceSyscalls && len(s.Commands) > 0 {
+		return fmt.Errorf("%w: syscall tracing is not supported for a command sequence", ErrInvalidCommands)
+	}
+	if s.Nice < MinNice || s.Nice > MaxNice {
+		return fmt.Errorf("%w: %d not in range %d to %d", ErrInvalidNice, s.Nice, MinNice, MaxNice)
+	}
+	if err := s.IONice.Validate(); err != nil {
+		return err
+	}
+	if err := s.Umask.Validate(); err != nil {
+		return err
+	}
+	if s.IsolateUsers {
+		// Raising priority needs privileges on the host, which root in
+		// a user namespace does not have.
+		if s.Nice < 0 {
+			return fmt.Errorf("%w: %d needs privileges a job isolating users does not have", ErrInvalidNice, s.Nice)
+		}
+		if s.IONice.Class == IOClassRealtime {
+			return fmt.Errorf("%w: class %s needs privileges a job isolating users does not have", ErrInvalidIONice, s.IONice.Class)
+		}
+	}
+	if s.Timeout < 0 || s.Timeout%time.Second != 0 {
+		return fmt.Errorf("%w: %v is not a whole number of seconds", ErrInvalidTimeout, s.Timeout)
+	}
+	if s.SetupTimeout < 0 {
+		return fmt.Errorf("%w: negative setup timeout %v", ErrInvalidTimeout, s.SetupTimeout)
+	}
+	if s.RunAsUser != 0 && s.RunAsGroup == 0 {
+		return fmt.Errorf("%w: user %d given without a group", ErrInvalidRunAs, s.RunAsUser)
+	}
+	if s.Workdir != "" && !filepath.IsAbs(s.Workdir) {
+		return fmt.Errorf("%w: %q", ErrInvalidWorkdir, s.Workdir)
+	}
+	for _, m := range s.Mounts {
+		if err := m.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateIOLimits(s.Resources.IO); err != nil {
+		return err
+	}
+	if s.Resources.CpuSetMems != "" {
+		if err := validateCPUList(s.Resources.CpuSetMems); err != nil {
+			return err
+		}
+	}
+	if burst := s.Resources.CpuBurst; burst > 0 {
+		if s.Resources.CPU == 0 {
+			return fmt.Errorf("%w: a cpu limit is required", ErrInvalidCPUBurst)
+		}
+		if quota := s.Resources.CPU.quota(); uint64(burst) > quota {
+			return fmt.Errorf("%w: %dus exceeds the cpu quota of %dus", ErrInvalidCPUBurst, burst, quota)
+		}
+	}
+	return nil
+}
+
+// NumArgs returns the number of arguments to the job's command, or the
+// total over the commands of its command sequence.
+func (s JobSpec) NumArgs() int {
+	n := len(s.Args)
+	for _, argv := range s.Commands {
+		if len(argv) > 0 {
+			n += len(argv) - 1
+		}
+	}
+	return n
+}
+
+// maxNameLen is the longest name a job may be given, which is the longest
+// hostname label.
+const maxNameLen = 63
+
+// checkName returns an error wrapping ErrInvalidName saying why name
+// cannot be used as a job ID, and so as a cgroup directory name and
+// hostname, or nil if it can.
+func checkName(name string) error {
+	if len(name) > maxNameLen {
+		return fmt.Errorf("%w: %q is %d characters, longer than the maximum of %d", ErrInvalidName, name, len(name), maxNameLen)
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-':
+			// Allowed.
+		case c == '/' || c == '\\':
+			return fmt.Errorf("%w: %q contains a path separator", ErrInvalidName, name)
+		case unicode.IsControl(c):
+			return fmt.Errorf("%w: %q contains a control character", ErrInvalidName, name)
+		default:
+			return fmt.Errorf("%w: %q contains %q; only lowercase letters, digits and hyphens are allowed", ErrInvalidName, name, c)
+		}
+	}
+	if name[0] == '-' || name[len(name)-1] == '-' {
+		return fmt.Errorf("%w: %q starts or ends with a hyphen", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// FirstCommand returns the command the job runs, or the first command of
+// its command sequence.
+func (s JobSpec) FirstCommand() string {
+	if len(s.Commands) > 0 {
+		return s.Commands[0][0]
+	}
+	return s.Command
+}
+
 type ResourceLimits struct {
 	MaxProcesses uint32         `help:"maximum number of processes"`
-	Memory       uint64         `help:"maximum memory (bytes)"`
-	CPU          uint32         `help:"maximum CPU (milliCPU)"`
+	Memory       ByteSize       `help:"maximum memory in bytes, optionally with a unit (e.g. 512Mi, 2G)"`
+	CPU          MilliCPU       `help:"maximum CPU in milliCPUs (e.g. 500 or 500m), or in CPUs with a decimal point (e.g. 1.5)"`
+	CpuBurst     uint32         `name:"cpu-burst" help:"CPU time in microseconds per second the job may burst above its CPU limit (requires --cpu)"`
 	IO           []DiskIOLimits `name:"io" help:"disk io limits (dev:rbps:wbps:riops:wiops)"`
+	CpuSetMems   string         `name:"cpuset-mems" help:"NUMA memory nodes the job may use (e.g. 0-1,3)"`
 }
 
 type JobState int
@@ -57,96 +317,344 @@ const (
 	JobStatePreStart = iota
 	JobStateRunning
 	JobStateCompleted
+	// JobStateQueued is a job waiting for the tracker to start it.
+	JobStateQueued
+	// JobStateCancelled is a job that was cancelled while queued, so never
+	// ran.
+	JobStateCancelled
 )
 
 type JobStatus struct {
+	// StartTime is when the job started, or for a queued job, when it was
+	// queued.
 	StartTime time.Time
-	Owner     string
-	State     JobState
-	ExitCode  uint32
-	ExitError error
+	// CompletionTime is when the job completed, or zero if it has not.
+	CompletionTime time.Time
+	Owner          string
+	Pid            int
+	State          JobState
+	ExitCode       uint32
+	// Signal is the signal that terminated the job, or zero if it exited
+	// normally.
+	Signal syscall.Signal
+	// ExitError is why the job did not exit successfully, if it did not.
+	// It is not marshalled to JSON, as an error cannot be unmarshalled.
+	ExitError error `json:"-"`
+	// LimitsHit names the resource limits the job hit while running, such
+	// as "process limit", in the order they were first hit.
+	LimitsHit []string
+	// PeakProcesses is the most processes the job had at once, recorded
+	// when it completes. It is zero if it could not be read, such as on
+	// kernels without pids.peak.
+	PeakProcesses uint32
+	// Client is the network address of the client that started the job,
+	// such as "192.0.2.1:51234", if known.
+	Client string
+}
+
+// Ended returns whether the job will not run any more: it completed, or was
+// cancelled while queued.
+func (s JobStatus) Ended() bool {
+	return s.State == JobStateCompleted || s.State == JobStateCancelled
+}
+
+// Failed returns whether the job completed unsuccessfully: it exited with a
+// non-zero exit code, was terminated by a signal, timed out, never started
+// or was lost when the server restarted.
+func (s JobStatus) Failed() bool {
+	return s.Ended() && (s.ExitCode != 0 || s.Signal != 0 || s.TimedOut() || errors.Is(s.ExitError, ErrServerRestarted))
+}
+
+// TimedOut returns whether the job was stopped for running past its spec's
+// timeout.
+func (s JobStatus) TimedOut() bool {
+	return errors.Is(s.ExitError, ErrTimedOut)
+}
+
+// ExitReason returns a description of how a completed job terminated. If
+// the job failed after hitting any resource limits, they are included as a
+// likely cause.
+func (s JobStatus) ExitReason() string {
+	if errors.Is(s.ExitError, ErrServerRestarted) {
+		return s.ExitError.Error()
+	}
+	if s.Pid == 0 && s.ExitError != nil {
+		return "not started: " + s.ExitError.Error()
+	}
+	var reason string
+	if s.Signal != 0 {
+		reason = fmt.Sprintf("terminated by signal %d (%s)", s.Signal, s.Signal)
+	} else {
+		reason = fmt.Sprintf("exited with code %d", s.ExitCode)
+	}
+	if s.ExitCode != 0 && len(s.LimitsHit) > 0 {
+		reason += " after hitting its " + strings.Join(s.LimitsHit, " and ")
+	}
+	if s.TimedOut() {
+		reason = "timed out: " + reason
+	}
+	return reason
 }
 
 type JobDescription struct {
 	ID     string
 	Spec   JobSpec
 	Status JobStatus
+	// Usage is the resources the job is using, or used once completed.
+	Usage ResourceUsage
 }
 
+// MinNice and MaxNice are the range of valid niceness values for a job.
+const (
+	MinNice = -20
+	MaxNice = 19
+)
+
 var (
-	ErrAlreadyStarted = errors.New("job already started")
+	ErrAlreadyStarted  = errors.New("job already started")
+	ErrInvalidNice     = errors.New("invalid nice value")
+	ErrInvalidWorkdir  = errors.New("working directory is not an absolute path")
+	ErrInvalidCommands = errors.New("invalid command sequence")
+	ErrInvalidCPUBurst = errors.New("invalid cpu burst")
+	ErrInvalidTimeout  = errors.New("invalid timeout")
+	ErrInvalidRunAs    = errors.New("invalid user or group to run as")
+	ErrTooManyArgs     = errors.New("too many arguments")
+	ErrInvalidName     = errors.New("invalid job name")
+	ErrJobExists       = errors.New("job already exists")
+	ErrTimedOut        = errors.New("timed out")
+	ErrSetupTimedOut   = errors.New("job setup timed out")
+	ErrSetupRead       = errors.New("could not read the result of starting the job")
 )
 
 func NewJob(id string, spec JobSpec, argMaker ArgMaker) *Job {
 	return &Job{ID: id, Spec: spec, argMaker: argMaker}
 }
 
+// setOwner sets the owner of the job, returning the previous owner.
+func (j *Job) setOwner(owner string) string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	prev := j.Status.Owner
+	j.Status.Owner = owner
+	return prev
+}
+
 // Start runs the job.
 func (j *Job) Start(owner string) error {
+	// Closed once the start is recorded, which must precede the job's
+	// completion even if it completes as soon as it is unlocked.
+	started := make(chan struct{})
+	defer close(started)
+
 	j.mu.Lock()
-	defer j.mu.Unlock()
+	ev, err := j.start(owner, started)
+	j.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	j.recordEvent(ev)
+	return nil
+}
 
-	if j.Status.State != JobStatePreStart {
-		return fmt.Errorf("%s: %w", j.ID, ErrAlreadyStarted)
+// start runs the job, returning the event of it starting to be recorded
+// once the job is unlocked. The job's completion is not recorded until
+// started is closed. It must be called with the job locked.
+func (j *Job) start(owner string, started <-chan struct{}) (Event, error) {
+	if j.Status.State != JobStatePreStart && j.Status.State != JobStateQueued {
+		return Event{}, fmt.Errorf("%s: %w", j.ID, ErrAlreadyStarted)
 	}
 
 	j.Status.State = JobStateRunning
 	j.Status.StartTime = time.Now()
 	j.Status.Owner = owner
 
-	output, err := j.ExecPart1()
+	stdout, stderr, err := j.ExecPart1()
 	if err != nil {
 		// j.Status.State = JobStateCompleted
-		return err
+		return Event{}, err
 	}
+	j.Status.Pid = j.cmd.Process.Pid
+	ev := newEvent(EventStart, JobDescription{ID: j.ID, Spec: j.Spec, Status: j.Status})
 
 	// At this point, the job's command has successfully started, so we
 	// will not return an error. A feeder will be attached to the job's
-	// output stream and left to run until EOF/error, at which point it
+	// output streams and left to run until EOF/error, at which point it
 	// will Wait on the process to collect its exit code.
-	j.done = make(chan struct{})
-	j.reaped = make(chan struct{})
+	// A queued job already has these channels for those waiting on it.
+	if j.reaped == nil {
+		j.done = make(chan struct{})
+		j.reaped = make(chan struct{})
+	}
+	infeedLines := make(chan Log)
 	logchan := make(chan Log)
+	var lines <-chan Log = infeedLines
+	if rate := j.Spec.MaxLogLinesPerSec; rate > 0 {
+		limited := make(chan Log)
+		rateTicker := time.NewTicker(logRateInterval)
+		go func() {
+			limitLogRate(infeedLines, limited, rate, rateTicker.C)
+			rateTicker.Stop()
+		}()
+		lines = limited
+	}
+	ticker := time.NewTicker(limitEventInterval)
+	watched := make(chan struct{})
+	var limitsHit []string
 	go func() {
-		infeed(output, logchan)
+		limitsHit = watchLimits(j.ID, lines, logchan, ticker.C)
+		ticker.Stop()
+		close(watched)
+	}()
+	stopSampling, sampled := make(chan struct{}), make(chan struct{})
+	if j.Spec.SampleResources {
+		interval := j.sampleInterval
+		if interval <= 0 {
+			interval = DefaultSampleInterval
+		}
+		sampleTicker := time.NewTicker(interval)
+		go func() {
+			sampleResources(j.ID, sampleTicker.C, stopSampling, j.recordSample)
+			sampleTicker.Stop()
+			close(sampled)
+		}()
+	} else {
+		close(sampled)
+	}
+	go func() {
+		infeedStreams(stdout, stderr, j.maxLineBytes, infeedLines)
+		_ = stderr.Close()
 
 		j.mu.Lock()
 		cmd := j.cmd
 		j.mu.Unlock()
 
 		err := cmd.Wait()
+		// The limit watcher and sampler read the job's cgroup until
+		// they are done, so wait for them before removing the cgroup.
+		<-watched
+		close(stopSampling)
+		<-sampled
 
 		j.mu.Lock()
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			// XXX ExitCode() can return -1 if exited via a signal, which
-			// is strange as it is meant to be 128+signum. Just mask it
-			// to 255 for now and figure it out later.
-			j.Status.ExitCode = uint32(exitErr.ExitCode()) & 0xFF
+			j.Status.ExitCode = exitCode(exitErr)
+			if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+				j.Status.Signal = ws.Signal()
+			}
 		}
 		j.Status.ExitError = err
+		if j.timedOut {
+			// A job may exit successfully when stopped for timing out.
+			j.Status.ExitError = fmt.Errorf("%w after %v", ErrTimedOut, j.Spec.Timeout)
+			if err != nil {
+				j.Status.ExitError = fmt.Errorf("%w: %v", j.Status.ExitError, err)
+			}
+		}
+		j.Status.LimitsHit = limitsHit
+		j.Status.PeakProcesses = readPeakProcesses(j.ID)
 		j.Status.State = JobStateCompleted
-		close(j.reaped)
+		j.Status.CompletionTime = time.Now()
+		// Keep the last of the job's usage, as its cgroup is removed.
+		j.usage = readResourceUsage(j.ID)
+		j.usage.Memory = 0
 		j.cleanupCgroup()
 		j.mu.Unlock()
+		// Store the completed job before anyone waiting for it to be
+		// reaped can clean it up.
+		j.persist()
+		<-started
+		j.recordCompletion()
+		j.pushMetrics()
+		close(j.reaped)
 	}()
+	if j.Spec.Timeout > 0 {
+		go j.enforceTimeout(j.reaped)
+	}
 	j.logFeeder = newFeeder(logchan)
+	j.logFeeder.maxLines, j.logFeeder.maxBytes = j.maxLogLines, j.maxLogBytes
+	j.logFeeder.budget = j.logBudget
+	if j.spool != nil {
+		w, err := j.spool.create(j.ID)
+		if err != nil {
+			// XXX Should log, but no logger yet
+			fmt.Fprintf(os.Stderr, "could not spool output of job %s: %v\n", j.ID, err)
+		} else {
+			j.logFeeder.spool = w
+		}
+	}
 	go j.logFeeder.Start(j.done)
-	return nil
+	if j.admitted != nil {
+		close(j.admitted)
+	}
+	return ev, nil
 }
 
-// Stop terminates the job (with extreme prejudice - SIGKILL).
+// Stop terminates the job. If the job has a stop grace period, it is sent
+// SIGTERM and given that long to exit before it is killed with SIGKILL,
+// otherwise it is killed at once. It waits until the job has been reaped,
+// unless the context is cancelled.
 func (j *Job) Stop(ctx context.Context) {
 	j.mu.Lock()
+	var ev *Event
+	if !j.stopping && j.Status.State == JobStateRunning {
+		e := newEvent(EventStop, JobDescription{ID: j.ID, Spec: j.Spec, Status: j.Status})
+		ev = &e
+	}
+	j.stopping = true
+	j.mu.Unlock()
+	if ev != nil {
+		j.recordEvent(*ev)
+	}
+	j.stop(ctx)
+}
+
+// enforceTimeout stops the job if it is still running once its spec's
+// timeout has passed since it started, unless it is already being stopped.
+// It returns without stopping the job once reaped is closed, so it never
+// stops a job that has completed.
+func (j *Job) enforceTimeout(reaped <-chan struct{}) {
+	timer := time.NewTimer(j.Spec.Timeout)
+	defer timer.Stop()
+	select {
+	case <-reaped:
+		return
+	case <-timer.C:
+	}
 
-	// XXX No SIGTERM, No grace period
-	_ = j.cmd.Process.Kill() // SIGKILL
+	j.mu.Lock()
+	if j.stopping || j.Status.State != JobStateRunning {
+		j.mu.Unlock()
+		return
+	}
+	j.stopping, j.timedOut = true, true
+	j.mu.Unlock()
+	j.stop(context.Background())
+}
 
-	reaped := j.reaped
+// stop signals the job's process to exit and waits for it to be reaped. See
+// Stop.
+func (j *Job) stop(ctx context.Context) {
+	j.mu.Lock()
+	process, reaped, grace := j.cmd.Process, j.reaped, j.stopGracePeriod
 	// We need to release the job lock while we wait for it to be
 	// reaped, as the reaper needs the lock to update the job's
 	// status and exit code.
 	j.mu.Unlock()
 
+	if grace > 0 {
+		_ = process.Signal(syscall.SIGTERM)
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-reaped:
+			return
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+	_ = process.Kill() // SIGKILL
+
 	// Wait for the job to be reaped or for the context to be cancelled.
 	select {
 	case <-reaped:
@@ -155,20 +663,211 @@ func (j *Job) Stop(ctx context.Context) {
 }
 
 func (j *Job) Description() JobDescription {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return JobDescription{ID: j.ID, Spec: j.Spec, Status: j.Status, Usage: j.resourceUsage()}
+}
+
+// summary returns a description of the job without its resource usage,
+// which is read from its cgroup, for callers that need only its spec or
+// status, such as its owner or state.
+func (j *Job) summary() JobDescription {
 	j.mu.Lock()
 	defer j.mu.Unlock()
 	return JobDescription{ID: j.ID, Spec: j.Spec, Status: j.Status}
 }
 
-func (j *Job) AttachOutfeed(follow bool, done <-chan struct{}) <-chan Log {
+// Wait waits for the job to complete and be reaped, or for the context to
+// be cancelled. It returns a description of the job at that point.
+func (j *Job) Wait(ctx context.Context) JobDescription {
+	j.mu.Lock()
+	reaped := j.reaped
+	j.mu.Unlock()
+
+	select {
+	case <-reaped:
+	case <-ctx.Done():
+	}
+	return j.Description()
+}
+
+// AttachOutfeed returns a channel that streams the job's logs, starting
+// from the line with index start. A negative start streams only the last
+// -start lines recorded so far, then any later lines if following. Logs
+// dropped from the job's log buffer are read from its spool, if it has one.
+//
+// If the job is queued, the logs are streamed once it starts when follow is
+// set. A job that never started has no logs.
+func (j *Job) AttachOutfeed(follow bool, start int, done <-chan struct{}) <-chan Log {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.Status.State == JobStateQueued && follow {
+		return j.attachWhenAdmitted(start, done)
+	}
+	if j.logFeeder == nil {
+		ch := make(chan Log)
+		close(ch)
+		return ch
+	}
+	if j.spool != nil {
+		stats := j.logFeeder.stats()
+		if start < 0 {
+			start += stats.Dropped + stats.BufferLen
+			if start < 0 {
+				start = 0
+			}
+		}
+		if start < stats.Dropped {
+			return j.attachSpooled(j.logFeeder, follow, start, done)
+		}
+	}
+	return j.logFeeder.attachOutfeed(follow, start, done)
+}
+
+// attachSpooled returns a channel that streams the job's logs from the line
+// with index start, which has been dropped from the job's log buffer: the
+// logs in its spool are sent first, then the logs after them from feeder.
+// The first log sent records any logs missing from the spool as dropped.
+func (j *Job) attachSpooled(feeder *feeder, follow bool, start int, done <-chan struct{}) <-chan Log {
+	out := make(chan Log)
+	go func() {
+		defer close(out)
+		// Logs that are no longer spooled either are counted as
+		// dropped by the feeder.
+		spooled, _ := j.SpooledLogs(start)
+		next := start
+		for i, l := range spooled {
+			if i == 0 {
+				l.Dropped = l.Index - start
+			}
+			select {
+			case out <- l:
+			case <-done:
+				return
+			}
+			next = l.Index + 1
+		}
+		for l := range feeder.attachOutfeed(follow, next, done) {
+			select {
+			case out <- l:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// LogsPage returns the job's logs with indexes in [start, end) and the
+// number of logs recorded so far. If end is zero or past the recorded logs,
+// the page ends with the last recorded log. Logs dropped from the job's log
+// buffer are read from its spool, if it has one. A job that never started
+// has no logs.
+func (j *Job) LogsPage(start, end int) LogPage {
+	j.mu.Lock()
+	feeder := j.logFeeder
+	j.mu.Unlock()
+	if feeder == nil {
+		return LogPage{}
+	}
+	page := feeder.page(start, end)
+	if j.spool == nil {
+		return page
+	}
+
+	if start < 0 {
+		start = 0
+	}
+	if end <= 0 || end > page.Total {
+		end = page.Total
+	}
+	// The page's logs start at the first log still buffered, or there
+	// are none if all of [start, end) was dropped.
+	first := end
+	if len(page.Logs) > 0 {
+		first = page.Logs[0].Index
+	}
+	if start >= first {
+		return page
+	}
+	spooled, err := j.SpooledLogs(start)
+	if err != nil {
+		return page
+	}
+	var logs []Log
+	for _, l := range spooled {
+		if l.Index >= first {
+			break
+		}
+		logs = append(logs, l)
+	}
+	if len(logs) == 0 {
+		return page
+	}
+	// Logs no longer spooled either are still counted as dropped.
+	logs[0].Dropped = logs[0].Index - start
+	if len(page.Logs) > 0 {
+		page.Logs[0].Dropped = first - (logs[len(logs)-1].Index + 1)
+	}
+	page.Logs = append(logs, page.Logs...)
+	return page
+}
+
+// LogsWindow returns the job's logs timestamped in [from, to] and the
+// number of logs recorded so far. A zero from or to leaves that end of the
+// window open. A job that never started has no logs.
+func (j *Job) LogsWindow(from, to time.Time) LogPage {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.logFeeder == nil {
+		return LogPage{}
+	}
+	return j.logFeeder.window(from, to)
+}
+
+// FeederStats returns a snapshot of the state of the job's log feeder.
+func (j *Job) FeederStats() FeederStats {
 	j.mu.Lock()
 	defer j.mu.Unlock()
-	return j.logFeeder.attachOutfeed(follow, done)
+	if j.logFeeder == nil {
+		return FeederStats{}
+	}
+	return j.logFeeder.stats()
 }
 
+// drainTimeout is the longest Cleanup waits for the log feeder to send the
+// last of a job's logs to the clients reading them.
+const drainTimeout = 5 * time.Second
+
+// Cleanup releases the job's resources, closing the log streams of any
+// clients still reading them. So that those clients see the last logs of a
+// job that has just been stopped, it first waits a short time for the log
+// feeder to send them all the logs.
 func (j *Job) Cleanup() {
-	// lock not needed
-	close(j.done)
+	// Don't hold the lock while waiting for the feeder to drain.
+	j.mu.Lock()
+	feeder, done := j.logFeeder, j.done
+	j.mu.Unlock()
+
+	if feeder != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer cancel()
+		feeder.waitDrained(ctx)
+	}
+	if j.spool != nil {
+		_ = j.spool.remove(j.ID)
+	}
+	close(done)
+}
+
+// SpooledLogs returns the job's output kept in its spool from the log with
+// index start, decrypted if it is encrypted. It returns an error if the
+// job's output is not spooled.
+func (j *Job) SpooledLogs(start int) ([]Log, error) {
+	if j.spool == nil {
+		return nil, ErrNotSpooled
+	}
+	return j.spool.read(j.ID, start)
 }
 
 // ExecPart1 starts the execution of a job's command, ensuring it runs in new
@@ -180,10 +879,11 @@ func (j *Job) Cleanup() {
 // define how to propagate Job parameters into a Job for ExecPart2 in a child
 // process.
 //
-// If successful, it returns an io.ReadCloser that can be read for the command's
-// combined stdout/stderr stream. Once that has closed, Job.cmd.Wait() should be
-// called on the job to capture the exit code of the process and reap it.
-func (j *Job) ExecPart1() (io.ReadCloser, error) {
+// If successful, it returns io.ReadClosers that can be read for the command's
+// stdout and stderr streams. Once both have been read to EOF, Job.cmd.Wait()
+// should be called on the job to capture the exit code of the process and reap
+// it. The stderr stream is not closed by Wait, so must be closed by the caller.
+func (j *Job) ExecPart1() (stdout, stderr io.ReadCloser, err error) {
 	cmd := &exec.Cmd{
 		Stdin: nil, // /dev/null
 		SysProcAttr: &syscall.SysProcAttr{
@@ -192,43 +892,157 @@ func (j *Job) ExecPart1() (io.ReadCloser, error) {
 		},
 	}
 
-	stdout, err := cmd.StdoutPipe()
+	stdout, err = cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	errPipe, err := cmd.StderrPipe()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	stderr, err := cmd.StderrPipe()
+	// The job's stderr is passed as an extra file, as the stderr pipe is
+	// used for errors starting the command. See ExecPart2.
+	stderrR, stderrW, err := os.Pipe()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	cmd.ExtraFiles = []*os.File{stderrW}
 
 	if j.Spec.IsolateNetwork {
 		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWNET
 	}
+	isolateUsers := j.Spec.IsolateUsers && !j.noNamespaces
+	var syncR, syncW *os.File
+	if isolateUsers {
+		j.isolateUsers(cmd.SysProcAttr)
+		if syncR, syncW, err = os.Pipe(); err != nil {
+			_ = stderrR.Close()
+			_ = stderrW.Close()
+			return nil, nil, err
+		}
+		cmd.ExtraFiles = append(cmd.ExtraFiles, syncR)
+	}
+	if j.noNamespaces {
+		cmd.SysProcAttr = nil
+	}
 
 	jd := JobDescription{ID: j.ID, Spec: j.Spec, Status: j.Status}
 	cmd.Path, cmd.Args = j.argMaker(jd)
-	if err := cmd.Start(); err != nil {
-		return nil, err
+	err = cmd.Start()
+	// Only the child writes to the job's stderr.
+	_ = stderrW.Close()
+	if syncR != nil {
+		_ = syncR.Close()
+	}
+	if err != nil {
+		_ = stderrR.Close()
+		if syncW != nil {
+			_ = syncW.Close()
+		}
+		return nil, nil, err
+	}
+
+	if isolateUsers {
+		// The job waits in ExecPart2 for us to put it in its cgroup, as
+		// it cannot from its user namespace. See waitForCgroup.
+		err := setupCgroupFor(j.ID, cmd.Process.Pid, j.Spec.Resources)
+		_ = syncW.Close()
+		if err != nil {
+			j.abortExec(cmd)
+			_ = stderrR.Close()
+			return nil, nil, err
+		}
 	}
 
 	// Read from the stderr pipe. If we get io.EOF without reading anything
 	// it means the command has successfully been executed. Otherwise something
 	// failed and the command was not executed at all. The reason/error is
 	// written to the stderr pipe.
-	errmsg, err := io.ReadAll(stderr)
+	//
+	// A command that execs successfully and exits immediately is still a
+	// successful start: stderr is closed on exec (or on exit), so we get
+	// io.EOF with no message regardless of how quickly the command exits.
+	// Its output remains buffered in the stdout pipe and its exit status
+	// is collected by Wait once that has been read.
+	errmsg, err := readSetupResult(errPipe)
 	if err != nil {
-		// could not read stderr. oh o
-		// XXX what does this mean and how do we need to handle it.
-		j.cleanupCgroup()
-		return nil, err
+		// The pipe could not be read to EOF, so whether the command was
+		// executed is unknown. It is killed rather than left running
+		// untracked, with the part of any message read kept in the error.
+		j.abortExec(cmd)
+		_ = stderrR.Close()
+		if len(errmsg) > 0 {
+			err = fmt.Errorf("%w after reading %q", err, errmsg)
+		}
+		return nil, nil, err
 	}
 	if len(errmsg) > 0 {
-		j.cleanupCgroup()
-		return nil, errors.New(string(errmsg))
+		j.abortExec(cmd)
+		_ = stderrR.Close()
+		return nil, nil, errors.New(string(errmsg))
 	}
 
 	j.cmd = cmd
-	return stdout, nil
+	return stdout, stderrR, nil
+}
+
+// setupReadAttempts is the number of times in a row reading the result of
+// starting a job's command is tried when it fails with a transient error,
+// and setupReadBackoff is the delay before each retry.
+const (
+	setupReadAttempts = 4
+	setupReadBackoff  = time.Millisecond
+)
+
+// readSetupResult reads the pipe that the child writes an error message to
+// if it cannot execute the job's command, until EOF, returning the message.
+// The message is empty if the command was executed. Reads that fail with a
+// transient error (EINTR or EAGAIN) are retried, as reading stops short of
+// EOF would lose the rest of the message or mistake a command that failed
+// to start for one that started. Reads of an *os.File already retry those
+// internally, so this guards against other readers and kernels that behave
+// otherwise. Any other error, or a transient one that persists, is returned
+// wrapping ErrSetupRead along with the part of the message read.
+func readSetupResult(r io.Reader) ([]byte, error) {
+	var msg []byte
+	buf := make([]byte, 512)
+	failures := 0
+	for {
+		n, err := r.Read(buf)
+		msg = append(msg, buf[:n]...)
+		if n > 0 {
+			failures = 0
+		}
+		switch {
+		case err == nil:
+		case errors.Is(err, io.EOF):
+			return msg, nil
+		case isRetryable(err) && failures < setupReadAttempts-1:
+			failures++
+			time.Sleep(setupReadBackoff)
+		default:
+			return msg, fmt.Errorf("%w: %w", ErrSetupRead, err)
+		}
+	}
+}
+
+// abortExec cleans up after a command that failed to start. The child
+// process has exited or is about to, so it is killed to be sure and then
+// reaped so it does not linger as a zombie.
+func (j *Job) abortExec(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	j.cleanupCgroup()
+}
+
+// exitCode returns the exit code of an exited process. If the process was
+// terminated by a signal, the exit code is 128 plus the signal number as a
+// shell would report it.
+func exitCode(exitErr *exec.ExitError) uint32 {
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + uint32(ws.Signal())
+	}
+	return uint32(exitErr.ExitCode()) & 0xFF
 }
 
 func (j *Job) cleanupCgroup() {
@@ -238,31 +1052,61 @@ func (j *Job) cleanupCgroup() {
 	// XXX See how to do this automatically with CLONE_NEWCGROUP/CLONE_INTO_CGROUP
 	// XXX Handle error somehow, which may not be an error if the child
 	// never got to creating the cgroup.
-	_ = syscall.Rmdir(filepath.Join(JobberCG, j.ID))
+	_ = syscall.Rmdir(cgroupDir(j.ID))
 }
 
+// setupTimeoutExitCode is the exit code of ExecPart2 when the job's setup
+// times out.
+const setupTimeoutExitCode = 1
+
+// startSetupWatchdog guards the setup of a job in ExecPart2, which would
+// otherwise hang the start of the job if a step such as a cgroup write or a
+// mount hangs, with ExecPart1 waiting for its error output. If the returned
+// stop function is not called within timeout, the watchdog writes an error
+// to errFile and calls exit. A zero timeout starts no watchdog.
+func startSetupWatchdog(timeout time.Duration, errFile io.Writer, exit func(code int)) (stop func()) {
+	if timeout <= 0 {
+		return func() {}
+	}
+	timer := time.AfterFunc(timeout, func() {
+		fmt.Fprintf(errFile, "%v after %v", ErrSetupTimedOut, timeout)
+		exit(setupTimeoutExitCode)
+	})
+	return func() { timer.Stop() }
+}
+
+// jobStderrFd is the file descriptor ExecPart1 passes the job's stderr to
+// ExecPart2 on, the first after the standard streams.
+const jobStderrFd = 3
+
 // ExecPart2 runs the job in a cgroup configured from the job's parameters
 // and configures the namespaces it is already in. It is expected that the
 // process is already running in "empty" namespaces based on the job's
 // configuration.
 //
 // It is expected that the standard io streams are set up as follows:
-// * stdin: /dev/null
-// * stdout: where the process's stdout and stderr are sent
-// * stderr: where error messages due to the inability to run the program
-//   are sent - e.g. errors setting up the cgroup, being unable to exec
-//   the program (not found), etc.
+//   - stdin: /dev/null
+//   - stdout: where the process's stdout is sent
+//   - stderr: where error messages due to the inability to run the program
+//     are sent - e.g. errors setting up the cgroup, being unable to exec
+//     the program (not found), etc.
+//   - fd 3: where the process's stderr is sent
 //
 // When the command is executed, it will have the stderr stream it received
-// closed and will instead have the stdout stream on stderr too.
+// closed and will instead have fd 3 as its stderr, with fd 3 itself closed.
 //
 // It does not return an error, instead writing errors to stderr to be
 // captured by the parent process in ExecPart1().
 func (j *Job) ExecPart2() {
+	// The nice value and IO priority set for the job only apply to the
+	// calling thread, so the goroutine must stay on the thread that execs
+	// the command.
+	// It is never unlocked, as the process is replaced by the command.
+	runtime.LockOSThread()
+
 	// We want to duplicate stderr to a new file descriptor so we can set
-	// up the command to capture its stdout/stderr to the same stream.
-	// The new file descriptor should be set up FD_CLOEXEC to close it when
-	// the command is executed.
+	// up the command's stderr from fd 3. The new file descriptor should be
+	// set up FD_CLOEXEC to close it when the command is executed.
 	errfd, err := syscall.Dup(int(os.Stderr.Fd()))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "could not dup stderr: %v", err)
@@ -273,59 +1117,73 @@ func (j *Job) ExecPart2() {
 	// does not return error
 	syscall.CloseOnExec(errfd)
 
-	if err := syscall.Dup2(syscall.Stdout, syscall.Stderr); err != nil {
-		fmt.Fprintf(errFile, "could not dup stdout: %v", err)
+	if err := syscall.Dup2(jobStderrFd, syscall.Stderr); err != nil {
+		fmt.Fprintf(errFile, "could not dup job stderr: %v", err)
 		return
 	}
+	_ = syscall.Close(jobStderrFd)
 
-	if err := j.execPart2(); err != nil {
+	if err := j.execPart2(errFile); err != nil {
 		fmt.Fprint(errFile, err)
 	}
 }
 
 // execPart2 sets up the job's cgroup and namespaces and execs its command.
-func (j *Job) execPart2() error {
-	if err := newCgroup(j.ID); err != nil {
-		return err
-	}
+// errFile is only used when the command is not exec'ed directly (tracing or
+// a command sequence), to signal that the command has started.
+func (j *Job) execPart2(errFile *os.File) error {
+	stopWatchdog := startSetupWatchdog(j.Spec.SetupTimeout, errFile, os.Exit)
+	defer stopWatchdog()
 
 	spec := j.Spec
-	r := spec.Resources
 
-	if r.MaxProcesses > 0 {
-		err := cgWrite(j.ID, "pids.max", strconv.FormatUint(uint64(r.MaxProcesses), 10))
-		if err != nil {
-			return fmt.Errorf("could not set pids.max: %w", err)
+	if spec.IsolateUsers {
+		if err := waitForCgroup(); err != nil {
+			return err
 		}
+	} else if err := setupCgroupFor(j.ID, os.Getpid(), spec.Resources); err != nil {
+		return err
 	}
 
-	if r.Memory > 0 {
-		err := cgWrite(j.ID, "memory.max", strconv.FormatUint(r.Memory, 10))
-		if err != nil {
-			return fmt.Errorf("could not set memory.max: %w", err)
+	if spec.Nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0 /* self */, spec.Nice); err != nil {
+			return fmt.Errorf("could not set nice value %d: %w", spec.Nice, err)
 		}
 	}
 
-	if r.CPU > 0 {
-		// Units are in microseconds, so scale our milliCPUs to microCPUs
-		// XXX Not sure this is right. Seems very bursty in practice.
-		err := cgWrite(j.ID, "cpu.max", fmt.Sprintf("%d 1000000", r.CPU*1000))
-		if err != nil {
-			return fmt.Errorf("could not set cpu.max: %w", err)
+	if spec.IONice.Class != IOClassNone {
+		if err := spec.IONice.set(); err != nil {
+			return fmt.Errorf("could not set ionice %s: %w", spec.IONice, err)
 		}
 	}
 
-	for _, iolim := range r.IO {
-		err := cgWrite(j.ID, "io.max", iolim.cgval())
-		if err != nil {
-			return fmt.Errorf("could not set io.max: %s: %w", iolim.cgval(), err)
-		}
+	if spec.Umask.Set {
+		syscall.Umask(int(spec.Umask.Mask))
 	}
 
-	if err := syscall.Sethostname([]byte(j.ID)); err != nil {
+	// The hostname is the job ID without any owner namespace.
+	if err := syscall.Sethostname([]byte(path.Base(j.ID))); err != nil {
 		return fmt.Errorf("could not set container hostname: %w", err)
 	}
 
+	if spec.IsolateNetwork {
+		if err := upLoopback(); err != nil {
+			return err
+		}
+	}
+
+	if err := makeMountsPrivate(); err != nil {
+		return err
+	}
+	if err := bindMounts(spec.Root, spec.Mounts); err != nil {
+		return err
+	}
+	if spec.MountDev && spec.IsolateUsers {
+		if err := mountDev(spec.Root, true /* bindNodes */); err != nil {
+			return err
+		}
+	}
+
 	if spec.Root != "" {
 		if err := syscall.Chroot(spec.Root); err != nil {
 			return fmt.Errorf("could not set root directory to %s: %w", spec.Root, err)
@@ -338,8 +1196,35 @@ func (j *Job) execPart2() error {
 	if err := syscall.Mount("proc", "/proc", "proc", 0 /* flags */, "" /* data */); err != nil {
 		return fmt.Errorf("could not mount /proc: %w", err)
 	}
+	if spec.MountDev && !spec.IsolateUsers {
+		if err := mountDev("", false /* bindNodes */); err != nil {
+			return err
+		}
+	}
+	if spec.Workdir != "" {
+		if err := syscall.Chdir(spec.Workdir); err != nil {
+			return fmt.Errorf("could not change to working directory %s: %w", spec.Workdir, err)
+		}
+	}
+
+	if spec.RunAsUser != 0 || spec.RunAsGroup != 0 {
+		if err := dropPrivileges(spec.RunAsUser, spec.RunAsGroup); err != nil {
+			return err
+		}
+	}
+
+	// Setup is done. The watchdog must not fire while a sequence or a
+	// traced command runs, nor if the exec below fails.
+	stopWatchdog()
+
+	if len(spec.Commands) > 0 {
+		return runSequence(spec.Commands, errFile)
+	}
 
 	argv := append([]string{filepath.Base(spec.Command)}, spec.Args...)
+	if spec.TraceSyscalls {
+		return traceExec(spec.Command, argv, errFile)
+	}
 	err := syscall.Exec(spec.Command, argv, nil /* environ */)
 	if err != nil {
 		return fmt.Errorf("could not exec %s: %w", spec.Command, err)
@@ -349,9 +1234,111 @@ func (j *Job) execPart2() error {
 	return nil
 }
 
+// dropPrivileges switches the process to run as the user uid and group
+// gid, with no supplementary groups. The groups are changed first, as
+// changing them needs the privileges the process gives up with its user.
+func dropPrivileges(uid, gid uint32) error {
+	if err := syscall.Setgroups([]int{}); err != nil {
+		return fmt.Errorf("could not clear supplementary groups: %w", err)
+	}
+	if err := syscall.Setgid(int(gid)); err != nil {
+		return fmt.Errorf("could not set group to %d: %w", gid, err)
+	}
+	if err := syscall.Setuid(int(uid)); err != nil {
+		return fmt.Errorf("could not set user to %d: %w", uid, err)
+	}
+	return nil
+}
+
+// setCgroupLimits writes the resource limits r to the cgroup of the job
+// identified by id. Zero values are not written, leaving the cgroup default.
+func setCgroupLimits(id string, r ResourceLimits) error {
+	return writeCgroupLimits(r, func(setting, value string) error {
+		return cgWrite(id, setting, value)
+	})
+}
+
+// CgroupWrite is a value written to a cgroup setting file.
+type CgroupWrite struct {
+	File  string
+	Value string
+}
+
+// CgroupWrites returns the cgroup writes that would be made to set the
+// resource limits r, in the order they would be made, without writing
+// anything.
+func CgroupWrites(r ResourceLimits) []CgroupWrite {
+	var writes []CgroupWrite
+	_ = writeCgroupLimits(r, func(setting, value string) error {
+		writes = append(writes, CgroupWrite{File: setting, Value: value})
+		return nil
+	})
+	return writes
+}
+
+// cpuPeriod is the period in microseconds of a job's cpu.max quota.
+const cpuPeriod = 1000000
+
+// quota returns the CPU time in microseconds per cpuPeriod of c, as
+// written to cpu.max.
+func (c MilliCPU) quota() uint64 {
+	// Units are in microseconds, so scale our milliCPUs to microCPUs
+	return uint64(c) * 1000
+}
+
+// writeCgroupLimits calls write with each cgroup setting file and value
+// needed to set the resource limits r.
+func writeCgroupLimits(r ResourceLimits, write func(setting, value string) error) error {
+	if r.MaxProcesses > 0 {
+		err := write("pids.max", strconv.FormatUint(uint64(r.MaxProcesses), 10))
+		if err != nil {
+			return fmt.Errorf("could not set pids.max: %w", err)
+		}
+	}
+
+	if r.Memory > 0 {
+		err := write("memory.max", strconv.FormatUint(uint64(r.Memory), 10))
+		if err != nil {
+			return fmt.Errorf("could not set memory.max: %w", err)
+		}
+	}
+
+	if r.CPU > 0 {
+		// XXX Not sure this is right. Seems very bursty in practice.
+		err := write("cpu.max", fmt.Sprintf("%d %d", r.CPU.quota(), cpuPeriod))
+		if err != nil {
+			return fmt.Errorf("could not set cpu.max: %w", err)
+		}
+	}
+
+	if r.CpuBurst > 0 {
+		err := write("cpu.max.burst", strconv.FormatUint(uint64(r.CpuBurst), 10))
+		if err != nil {
+			return fmt.Errorf("could not set cpu.max.burst: %w", err)
+		}
+	}
+
+	if r.CpuSetMems != "" {
+		if err := write("cpuset.mems", r.CpuSetMems); err != nil {
+			return fmt.Errorf("could not set cpuset.mems: %w", err)
+		}
+	}
+
+	for _, iolim := range r.IO {
+		err := write("io.max", iolim.cgval())
+		if err != nil {
+			return fmt.Errorf("could not set io.max: %s: %w", iolim.cgval(), err)
+		}
+	}
+	return nil
+}
+
+// cgroupControllers are the cgroup controllers enabled for job cgroups.
+// XXX Not sure if cpuset is required.
+const cgroupControllers = "+cpu +cpuset +io +memory +pids"
+
 func InitCgroups() error {
-	// XXX Not sure if cpuset is required.
-	const controllers = "+cpu +cpuset +io +memory +pids"
+	const controllers = cgroupControllers
 	if err := os.WriteFile("/sys/fs/cgroup/cgroup.subtree_control", []byte(controllers), 0700); err != nil {
 		return fmt.Errorf("could not configure root cgroup controllers: %w", err)
 	}
@@ -367,20 +1354,59 @@ func InitCgroups() error {
 	return nil
 }
 
-func newCgroup(id string) error {
-	jobCG := filepath.Join(JobberCG, id)
+// cgroupDir returns the directory of the cgroup of the job identified by id.
+// Job IDs namespaced by owner contain a slash, so the ID is escaped to keep
+// each job's cgroup directly under the cgroup root.
+func cgroupDir(id string) string {
+	return filepath.Join(cgroupRoot, url.PathEscape(id))
+}
+
+func newCgroup(id string, pid int) error {
+	jobCG := cgroupDir(id)
 	err := os.Mkdir(jobCG, 0755)
 	if err != nil && !os.IsExist(err) {
 		return fmt.Errorf("could not create job (%s) cgroup: %w", id, err)
 	}
 
-	if err := cgWrite(id, "cgroup.procs", strconv.Itoa(os.Getpid())); err != nil {
-		return fmt.Errorf("could not put outselves into cgroup: %w", err)
+	if err := cgWrite(id, "cgroup.procs", strconv.Itoa(pid)); err != nil {
+		return fmt.Errorf("could not put process %d into cgroup: %w", pid, err)
 	}
 
 	return nil
 }
 
+// cgWriteAttempts is the number of times a cgroup write is tried when it
+// fails with a retryable error, and cgWriteBackoff is the delay before the
+// first retry. The delay doubles on each subsequent retry.
+const (
+	cgWriteAttempts = 4
+	cgWriteBackoff  = time.Millisecond
+)
+
+// writeFile writes cgroup files. It is only changed by tests to simulate
+// cgroup write failures.
+var writeFile = os.WriteFile
+
+// cgWrite writes value to the setting file of the cgroup of the job
+// identified by id. Writes that fail transiently, as can happen when a
+// controller is still initializing just after the cgroup is created, are
+// retried a few times with a short backoff.
 func cgWrite(id, setting, value string) error {
-	return os.WriteFile(filepath.Join(JobberCG, id, setting), []byte(value), 0700)
+	filename := filepath.Join(cgroupDir(id), setting)
+	backoff := cgWriteBackoff
+	for attempt := 1; ; attempt++ {
+		err := writeFile(filename, []byte(value), 0700)
+		if err == nil || attempt == cgWriteAttempts || !isRetryable(err) {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// isRetryable returns true if err is a transient error for which a cgroup
+// write, or a read of the result of starting a job, may succeed if tried
+// again.
+func isRetryable(err error) bool {
+	return errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR)
 }
@@ -0,0 +1,509 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// shellArgMaker returns an ArgMaker that runs script with /bin/sh instead
+// of running part 2 of the container setup. This lets us exercise
+// ExecPart1 and the job lifecycle without needing root for cgroups and
+// namespaces. The script must close (or redirect) stderr to signal a
+// successful start, as part 2 does when it execs the job's command.
+func shellArgMaker(script string) ArgMaker {
+	return func(JobDescription) (string, []string) {
+		return "/bin/sh", []string{"sh", "-c", script}
+	}
+}
+
+// runToCompletion starts a job that runs script without any namespaces,
+// waits for it to be reaped and returns its output.
+func runToCompletion(t *testing.T, script string) (*Job, string, error) {
+	t.Helper()
+	j := NewJob("test-1", JobSpec{Command: "/bin/sh"}, shellArgMaker(script))
+	j.noNamespaces = true
+	if err := j.Start("owner"); err != nil {
+		return j, "", err
+	}
+	t.Cleanup(j.Cleanup)
+
+	var output string
+	for l := range j.AttachOutfeed(true /* follow */, 0, nil) {
+		output += string(l.Line)
+	}
+	<-j.reaped
+	return j, output, nil
+}
+
+func TestFastExitingJob(t *testing.T) {
+	tests := map[string]struct {
+		script   string
+		output   string
+		exitCode uint32
+		reason   string
+	}{
+		"exit zero": {
+			script: "exec 2>&1; exit 0",
+			reason: "exited with code 0",
+		},
+		"exit non-zero": {
+			script:   "exec 2>&1; exit 3",
+			exitCode: 3,
+			reason:   "exited with code 3",
+		},
+		"output then exit": {
+			script:   "exec 2>&1; echo hello; exit 4",
+			output:   "hello\n",
+			exitCode: 4,
+			reason:   "exited with code 4",
+		},
+		"crash": {
+			script:   "exec 2>&1; kill -SEGV $$",
+			exitCode: 128 + 11,
+			reason:   "terminated by signal 11 (segmentation fault)",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			j, output, err := runToCompletion(t, tc.script)
+			require.NoError(t, err)
+			require.Equal(t, tc.output, output)
+
+			jd := j.Description()
+			require.Equal(t, JobState(JobStateCompleted), jd.Status.State)
+			require.Equal(t, tc.exitCode, jd.Status.ExitCode)
+			require.Equal(t, tc.reason, jd.Status.ExitReason())
+			require.Equal(t, tc.exitCode != 0, jd.Status.Failed())
+			require.False(t, jd.Status.CompletionTime.Before(jd.Status.StartTime))
+		})
+	}
+}
+
+func TestJobStreams(t *testing.T) {
+	// The job's stderr is on fd 3, as ExecPart2 would set it up.
+	script := "exec 2>&3 3>&-; echo out; echo err >&2; printf 'partial'; echo line2 >&2; echo ' done'"
+	j, _, err := runToCompletion(t, script)
+	require.NoError(t, err)
+
+	var stdout, stderr string
+	var prev time.Time
+	for i, l := range j.LogsPage(0, 10).Logs {
+		require.Equal(t, i, l.Index)
+		require.False(t, l.Timestamp.Before(prev), "lines out of timestamp order")
+		prev = l.Timestamp
+		switch l.Stream {
+		case StreamStdout:
+			stdout += string(l.Line)
+		case StreamStderr:
+			stderr += string(l.Line)
+		}
+	}
+	require.Equal(t, "out\npartial done\n", stdout)
+	require.Equal(t, "err\nline2\n", stderr)
+}
+
+func TestStartFailure(t *testing.T) {
+	_, _, err := runToCompletion(t, "echo could not exec >&2; exit 0")
+	require.EqualError(t, err, "could not exec\n")
+}
+
+func TestStartFailurePartialWrites(t *testing.T) {
+	// The child writes its error in parts before closing the pipe, which
+	// must all be read rather than only the first.
+	_, _, err := runToCompletion(t, "printf 'could not ' >&2; sleep 0.05; printf 'exec: ' >&2; sleep 0.05; echo 'no such file' >&2; exit 1")
+	require.EqualError(t, err, "could not exec: no such file\n")
+}
+
+// flakyReader is an io.Reader that returns the results of its reads in turn,
+// then io.EOF.
+type flakyReader []struct {
+	data string
+	err  error
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if len(*r) == 0 {
+		return 0, io.EOF
+	}
+	res := (*r)[0]
+	*r = (*r)[1:]
+	return copy(p, res.data), res.err
+}
+
+func TestReadSetupResult(t *testing.T) {
+	eintr := &os.PathError{Op: "read", Path: "|0", Err: syscall.EINTR}
+	eagain := &os.PathError{Op: "read", Path: "|0", Err: syscall.EAGAIN}
+	tests := map[string]struct {
+		reads   flakyReader
+		msg     string
+		wantErr error
+	}{
+		"executed": {},
+		"message": {
+			reads: flakyReader{{data: "could not exec"}},
+			msg:   "could not exec",
+		},
+		"message in parts": {
+			reads: flakyReader{{data: "could "}, {data: "not "}, {data: "exec"}},
+			msg:   "could not exec",
+		},
+		"interrupted": {
+			reads: flakyReader{{data: "could not ", err: eintr}, {err: eagain}, {data: "exec", err: eintr}},
+			msg:   "could not exec",
+		},
+		"interrupted until progress": {
+			reads: flakyReader{{err: eintr}, {err: eintr}, {err: eintr}, {data: "could "}, {err: eintr}, {err: eintr}, {err: eintr}, {data: "not exec"}},
+			msg:   "could not exec",
+		},
+		"interrupted persistently": {
+			reads:   flakyReader{{data: "could "}, {err: eintr}, {err: eintr}, {err: eintr}, {err: eintr}, {data: "not exec"}},
+			msg:     "could ",
+			wantErr: syscall.EINTR,
+		},
+		"read failure": {
+			reads:   flakyReader{{data: "could ", err: syscall.EIO}, {data: "not exec"}},
+			msg:     "could ",
+			wantErr: syscall.EIO,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg, err := readSetupResult(&tc.reads)
+			require.Equal(t, tc.msg, string(msg))
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrSetupRead)
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestJobTimeout(t *testing.T) {
+	start := func(t *testing.T, script string, grace time.Duration) *Job {
+		t.Helper()
+		// Validate only allows whole seconds, but a job does not check.
+		spec := JobSpec{Command: "/bin/sh", Timeout: 100 * time.Millisecond}
+		j := NewJob("test-1", spec, shellArgMaker(script))
+		j.noNamespaces = true
+		j.stopGracePeriod = grace
+		require.NoError(t, j.Start("owner"))
+		t.Cleanup(j.Cleanup)
+		return j
+	}
+
+	t.Run("timed out", func(t *testing.T) {
+		j := start(t, "exec 2>&1; exec sleep 10", 0)
+		status := j.Wait(context.Background()).Status
+		require.True(t, status.TimedOut())
+		require.True(t, status.Failed())
+		require.ErrorIs(t, status.ExitError, ErrTimedOut)
+		require.Equal(t, "timed out: terminated by signal 9 (killed)", status.ExitReason())
+	})
+
+	t.Run("timed out and exited successfully", func(t *testing.T) {
+		j := start(t, "exec 2>&1; trap 'exit 0' TERM; while :; do sleep 0.01; done", time.Second)
+		status := j.Wait(context.Background()).Status
+		require.True(t, status.TimedOut())
+		require.ErrorIs(t, status.ExitError, ErrTimedOut)
+		require.EqualError(t, status.ExitError, "timed out after 100ms")
+	})
+
+	t.Run("completed in time", func(t *testing.T) {
+		j := start(t, "exec 2>&1; exit 0", 0)
+		status := j.Wait(context.Background()).Status
+		require.False(t, status.TimedOut())
+		require.Equal(t, "exited with code 0", status.ExitReason())
+
+		// The timer does not fire once the job has been reaped.
+		time.Sleep(200 * time.Millisecond)
+		require.Equal(t, status, j.Description().Status)
+	})
+
+	t.Run("stopped before timeout", func(t *testing.T) {
+		j := start(t, "exec 2>&1; trap '' TERM; echo ready; exec sleep 10", time.Second)
+		<-j.AttachOutfeed(true /* follow */, 0, nil)
+		// The timeout passes while Stop waits for the job to exit, but
+		// the job was stopped, not timed out.
+		j.Stop(context.Background())
+		status := j.Description().Status
+		require.False(t, status.TimedOut())
+		require.Equal(t, "terminated by signal 9 (killed)", status.ExitReason())
+	})
+}
+
+func TestJobSpecValidate(t *testing.T) {
+	tests := map[string]struct {
+		spec JobSpec
+		err  error
+	}{
+		"valid":            {spec: JobSpec{Command: "/bin/true"}},
+		"no command":       {spec: JobSpec{}, err: ErrNoCommand},
+		"min nice":         {spec: JobSpec{Command: "/bin/true", Nice: MinNice}},
+		"max nice":         {spec: JobSpec{Command: "/bin/true", Nice: MaxNice}},
+		"nice too low":     {spec: JobSpec{Command: "/bin/true", Nice: MinNice - 1}, err: ErrInvalidNice},
+		"nice too high":    {spec: JobSpec{Command: "/bin/true", Nice: MaxNice + 1}, err: ErrInvalidNice},
+		"command sequence": {spec: JobSpec{Commands: [][]string{{"/bin/true"}, {"/bin/echo", "hi"}}}},
+		"name": {
+			spec: JobSpec{Command: "/bin/true", Name: "nightly-build-2"},
+		},
+		"name with uppercase": {
+			spec: JobSpec{Command: "/bin/true", Name: "Nightly"},
+			err:  ErrInvalidName,
+		},
+		"name with slash": {
+			spec: JobSpec{Command: "/bin/true", Name: "../nightly"},
+			err:  ErrInvalidName,
+		},
+		"name starting with hyphen": {
+			spec: JobSpec{Command: "/bin/true", Name: "-nightly"},
+			err:  ErrInvalidName,
+		},
+		"name too long": {
+			spec: JobSpec{Command: "/bin/true", Name: strings.Repeat("a", maxNameLen+1)},
+			err:  ErrInvalidName,
+		},
+		"run as user and group": {
+			spec: JobSpec{Command: "/bin/true", RunAsUser: 1000, RunAsGroup: 1000},
+		},
+		"run as group": {
+			spec: JobSpec{Command: "/bin/true", RunAsGroup: 1000},
+		},
+		"run as user without group": {
+			spec: JobSpec{Command: "/bin/true", RunAsUser: 1000},
+			err:  ErrInvalidRunAs,
+		},
+		"isolate users nice": {
+			spec: JobSpec{Command: "/bin/true", IsolateUsers: true, Nice: MaxNice},
+		},
+		"isolate users negative nice": {
+			spec: JobSpec{Command: "/bin/true", IsolateUsers: true, Nice: -1},
+			err:  ErrInvalidNice,
+		},
+		"isolate users realtime io": {
+			spec: JobSpec{Command: "/bin/true", IsolateUsers: true, IONice: IONice{Class: IOClassRealtime}},
+			err:  ErrInvalidIONice,
+		},
+		"command and sequence": {
+			spec: JobSpec{Command: "/bin/true", Commands: [][]string{{"/bin/true"}}},
+			err:  ErrInvalidCommands,
+		},
+		"empty sequence command": {
+			spec: JobSpec{Commands: [][]string{{"/bin/true"}, {}}},
+			err:  ErrInvalidCommands,
+		},
+		"cpuset mems":        {spec: JobSpec{Command: "/bin/true", Resources: ResourceLimits{CpuSetMems: "0-1,3"}}},
+		"cpuset mems single": {spec: JobSpec{Command: "/bin/true", Resources: ResourceLimits{CpuSetMems: "2"}}},
+		"cpuset mems descending": {
+			spec: JobSpec{Command: "/bin/true", Resources: ResourceLimits{CpuSetMems: "3-1"}},
+			err:  ErrInvalidCPUList,
+		},
+		"cpuset mems garbage": {
+			spec: JobSpec{Command: "/bin/true", Resources: ResourceLimits{CpuSetMems: "0,,1"}},
+			err:  ErrInvalidCPUList,
+		},
+		"timeout":                {spec: JobSpec{Command: "/bin/true", Timeout: 30 * time.Second}},
+		"negative timeout":       {spec: JobSpec{Command: "/bin/true", Timeout: -time.Second}, err: ErrInvalidTimeout},
+		"fractional timeout":     {spec: JobSpec{Command: "/bin/true", Timeout: 1500 * time.Millisecond}, err: ErrInvalidTimeout},
+		"setup timeout":          {spec: JobSpec{Command: "/bin/true", SetupTimeout: 1500 * time.Millisecond}},
+		"negative setup timeout": {spec: JobSpec{Command: "/bin/true", SetupTimeout: -time.Second}, err: ErrInvalidTimeout},
+		"cpu burst":              {spec: JobSpec{Command: "/bin/true", Resources: ResourceLimits{CPU: 500, CpuBurst: 500000}}},
+		"cpu burst without cpu": {
+			spec: JobSpec{Command: "/bin/true", Resources: ResourceLimits{CpuBurst: 1000}},
+			err:  ErrInvalidCPUBurst,
+		},
+		"cpu burst above quota": {
+			spec: JobSpec{Command: "/bin/true", Resources: ResourceLimits{CPU: 500, CpuBurst: 500001}},
+			err:  ErrInvalidCPUBurst,
+		},
+		"io limits different devices": {
+			spec: JobSpec{Command: "/bin/true", Resources: ResourceLimits{IO: []DiskIOLimits{
+				{Major: 8, Minor: 0, ReadBPS: 100},
+				{Major: 8, Minor: 16, ReadBPS: 200},
+			}}},
+		},
+		"io limits duplicate device": {
+			spec: JobSpec{Command: "/bin/true", Resources: ResourceLimits{IO: []DiskIOLimits{
+				{Major: 8, Minor: 0, ReadBPS: 100},
+				{Major: 8, Minor: 0, WriteBPS: 200},
+			}}},
+			err: ErrInvalidIOLimits,
+		},
+		"io limits duplicate device path": {
+			spec: JobSpec{Command: "/bin/true", Resources: ResourceLimits{IO: []DiskIOLimits{
+				{Device: "/dev/sda", ReadBPS: 100},
+				{Device: "/dev/sda", ReadBPS: 200},
+			}}},
+			err: ErrInvalidIOLimits,
+		},
+		"io limits same device different paths": {
+			spec: JobSpec{Command: "/bin/true", Resources: ResourceLimits{IO: []DiskIOLimits{
+				{Device: "/dev/sda", Major: 8, Minor: 0, ReadBPS: 100},
+				{Device: "/dev/disk/by-id/ata-disk", Major: 8, Minor: 0, ReadBPS: 200},
+			}}},
+			err: ErrInvalidIOLimits,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.spec.Validate()
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestSetupWatchdog(t *testing.T) {
+	exited := make(chan int, 1)
+	exit := func(code int) { exited <- code }
+
+	// A slow setup step is aborted with an error once the timeout passes.
+	var errOut bytes.Buffer
+	stop := startSetupWatchdog(10*time.Millisecond, &errOut, exit)
+	slowStep := time.After(time.Second)
+	select {
+	case code := <-exited:
+		require.Equal(t, setupTimeoutExitCode, code)
+	case <-slowStep:
+		t.Fatal("watchdog did not abort slow setup")
+	}
+	stop()
+	require.Equal(t, "job setup timed out after 10ms", errOut.String())
+
+	// A setup that completes in time is left alone.
+	errOut.Reset()
+	stop = startSetupWatchdog(20*time.Millisecond, &errOut, exit)
+	stop()
+	time.Sleep(40 * time.Millisecond)
+	require.Empty(t, exited)
+	require.Empty(t, errOut.String())
+
+	// There is no watchdog without a timeout.
+	startSetupWatchdog(0, &errOut, func(int) { t.Error("watchdog without timeout fired") })
+}
+
+// fakeCgroupRoot points the job cgroups at a temporary directory for the
+// duration of the test, so cgroup writes can be checked without root.
+func fakeCgroupRoot(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	orig := cgroupRoot
+	cgroupRoot = dir
+	t.Cleanup(func() { cgroupRoot = orig })
+	return dir
+}
+
+func TestSetCgroupLimits(t *testing.T) {
+	root := fakeCgroupRoot(t)
+	require.NoError(t, os.Mkdir(filepath.Join(root, "job-1"), 0755))
+
+	r := ResourceLimits{MaxProcesses: 10, CpuSetMems: "0-1,3"}
+	require.NoError(t, setCgroupLimits("job-1", r))
+
+	expected := map[string]string{
+		"pids.max":    "10",
+		"cpuset.mems": "0-1,3",
+	}
+	entries, err := os.ReadDir(filepath.Join(root, "job-1"))
+	require.NoError(t, err)
+	got := map[string]string{}
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(root, "job-1", e.Name()))
+		require.NoError(t, err)
+		got[e.Name()] = string(b)
+	}
+	require.Equal(t, expected, got)
+}
+
+func TestCgroupWrites(t *testing.T) {
+	r := ResourceLimits{
+		MaxProcesses: 100,
+		Memory:       1 << 30,
+		CPU:          1500,
+		CpuBurst:     250000,
+		CpuSetMems:   "0",
+		IO: []DiskIOLimits{
+			{Major: 8, Minor: 0, ReadBPS: 1 << 20, WriteIOPS: 100},
+			{Major: 8, Minor: 16, WriteBPS: 4096},
+		},
+	}
+	expected := []CgroupWrite{
+		{File: "pids.max", Value: "100"},
+		{File: "memory.max", Value: "1073741824"},
+		{File: "cpu.max", Value: "1500000 1000000"},
+		{File: "cpu.max.burst", Value: "250000"},
+		{File: "cpuset.mems", Value: "0"},
+		{File: "io.max", Value: "8:0 rbps=1048576 wiops=100"},
+		{File: "io.max", Value: "8:16 wbps=4096"},
+	}
+	require.Equal(t, expected, CgroupWrites(r))
+	require.Empty(t, CgroupWrites(ResourceLimits{}))
+}
+
+// failingWriteFile makes the first `fails` writes to cgroup files fail with
+// err before writing normally. It returns a pointer to the number of writes
+// attempted.
+func failingWriteFile(t *testing.T, fails int, err error) *int {
+	t.Helper()
+	attempts := 0
+	orig := writeFile
+	writeFile = func(name string, data []byte, perm fs.FileMode) error {
+		attempts++
+		if attempts <= fails {
+			return &fs.PathError{Op: "write", Path: name, Err: err}
+		}
+		return orig(name, data, perm)
+	}
+	t.Cleanup(func() { writeFile = orig })
+	return &attempts
+}
+
+func TestCgWriteRetry(t *testing.T) {
+	tests := map[string]struct {
+		fails    int
+		err      error
+		attempts int
+		wantErr  bool
+	}{
+		"no failure":        {fails: 0, err: syscall.EAGAIN, attempts: 1},
+		"EAGAIN once":       {fails: 1, err: syscall.EAGAIN, attempts: 2},
+		"EINTR twice":       {fails: 2, err: syscall.EINTR, attempts: 3},
+		"EAGAIN persistent": {fails: cgWriteAttempts, err: syscall.EAGAIN, attempts: cgWriteAttempts, wantErr: true},
+		"not retryable":     {fails: 1, err: syscall.EINVAL, attempts: 1, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := fakeCgroupRoot(t)
+			require.NoError(t, os.Mkdir(filepath.Join(root, "job-1"), 0755))
+			attempts := failingWriteFile(t, tc.fails, tc.err)
+
+			err := cgWrite("job-1", "pids.max", "10")
+			require.Equal(t, tc.attempts, *attempts)
+			if tc.wantErr {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			b, err := os.ReadFile(filepath.Join(root, "job-1", "pids.max"))
+			require.NoError(t, err)
+			require.Equal(t, "10", string(b))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package job
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// IDFormat is the scheme for generating the suffix of job IDs, which are
+// of the form command-suffix.
+type IDFormat string
+
+const (
+	// IDFormatHex is a random 32 bit number in hex, such as 1f3a09c2. It
+	// is short, but likely to collide once there are tens of thousands
+	// of jobs with the same command.
+	IDFormatHex IDFormat = "hex"
+	// IDFormatULID is a lowercase ULID, such as
+	// 01g3zkp1v8m5x9qk2b7n4c6d8e: a millisecond timestamp then 80 random
+	// bits, so IDs sort in the order they were allocated.
+	IDFormatULID IDFormat = "ulid"
+	// IDFormatUUID is a random (version 4) UUID, such as
+	// 9b2e4f1c-3a5d-4e6f-8a7b-0c1d2e3f4a5b.
+	IDFormatUUID IDFormat = "uuid"
+)
+
+// WithIDFormat sets the scheme for generating the suffix of job IDs. The
+// default is IDFormatHex.
+func WithIDFormat(f IDFormat) TrackerOption {
+	return func(t *Tracker) {
+		t.idFormat = f
+	}
+}
+
+// suffix returns a new job ID suffix in format f, using now as the time
+// for formats that include one. An unknown format is IDFormatHex.
+func (f IDFormat) suffix(now time.Time) string {
+	switch f {
+	case IDFormatULID:
+		return newULID(now)
+	case IDFormatUUID:
+		return newUUID()
+	}
+	// pseudo-randomness is good enough for this.
+	return strconv.FormatUint(uint64(rand.Uint32()), 16)
+}
+
+// crockford is the Crockford base32 alphabet ULIDs are encoded in,
+// lowercased to match the rest of job IDs.
+const crockford = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// newULID returns a ULID for now: 48 bits of Unix milliseconds followed by
+// 80 random bits, encoded as 26 base32 digits.
+func newULID(now time.Time) string {
+	var b [16]byte
+	ms := uint64(now.UnixMilli())
+	binary.BigEndian.PutUint16(b[0:], uint16(ms>>32))
+	binary.BigEndian.PutUint32(b[2:], uint32(ms))
+	randomBytes(b[6:])
+
+	// The 128 bits are encoded 5 at a time from the most significant,
+	// after 2 bits of padding so they fill the 26 digits.
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var s [26]byte
+	for i := len(s) - 1; i >= 0; i-- {
+		s[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(s[:])
+}
+
+// newUUID returns a random (version 4, variant 1) UUID.
+func newUUID() string {
+	var b [16]byte
+	randomBytes(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// randomBytes fills b with cryptographically random bytes, or if they are
+// unavailable, pseudo-random ones, which are still good enough for IDs.
+func randomBytes(b []byte) {
+	if _, err := crand.Read(b); err != nil {
+		_, _ = rand.Read(b)
+	}
+}
@@ -0,0 +1,148 @@
+package job
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDFormat(t *testing.T) {
+	tests := map[IDFormat]*regexp.Regexp{
+		"":           regexp.MustCompile(`^sleep-[0-9a-f]{1,8}$`),
+		IDFormatHex:  regexp.MustCompile(`^sleep-[0-9a-f]{1,8}$`),
+		IDFormatULID: regexp.MustCompile(`^sleep-[0-7][0-9a-hjkmnp-tv-z]{25}$`),
+		IDFormatUUID: regexp.MustCompile(`^sleep-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+	}
+	for format, re := range tests {
+		t.Run(string(format), func(t *testing.T) {
+			tracker := NewTracker(nil, nil, WithIDFormat(format))
+			seen := map[string]bool{}
+			for i := 0; i < 1000; i++ {
+				id := tracker.allocateID(JobSpec{Command: "/bin/sleep"}, "eve")
+				require.Regexp(t, re, id)
+				require.False(t, seen[id], "duplicate id %s", id)
+				seen[id] = true
+			}
+		})
+	}
+}
+
+func TestULIDSortable(t *testing.T) {
+	start := time.Date(2022, 5, 27, 12, 24, 4, 0, time.UTC)
+	prev := newULID(start)
+	// The timestamp is the first 10 digits.
+	require.Equal(t, "01g42qjjn0", prev[:10])
+	for i := 1; i < 100; i++ {
+		id := newULID(start.Add(time.Duration(i) * time.Millisecond))
+		require.Less(t, prev, id)
+		prev = id
+	}
+}
+
+func TestIDFormatWithUserIDPrefix(t *testing.T) {
+	tracker := newTestTracker("exit 0", WithIDFormat(IDFormatUUID), WithUserIDPrefix(true))
+	userCtx := AddUserToContext(context.Background(), "eve")
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	require.Regexp(t, `^eve/sh-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+	_, err = tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+}
+
+func TestJobName(t *testing.T) {
+	tracker := newTestTracker("exit 0")
+	userCtx := AddUserToContext(context.Background(), "eve")
+	otherCtx := AddUserToContext(context.Background(), "mallory")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh", Name: "nightly"})
+	require.NoError(t, err)
+	require.Equal(t, "nightly", id)
+	_, err = tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+
+	// The name is taken even once the job has completed, and by another
+	// user's job.
+	_, _, err = tracker.Start(otherCtx, JobSpec{Command: "/bin/sh", Name: "nightly"})
+	require.ErrorIs(t, err, ErrJobExists)
+
+	id, _, err = tracker.Start(otherCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	require.Regexp(t, `^sh-[0-9a-f]{1,8}$`, id)
+	_, err = tracker.Wait(otherCtx, id)
+	require.NoError(t, err)
+}
+
+func TestJobNameWithUserIDPrefix(t *testing.T) {
+	tracker := newTestTracker("exit 0", WithUserIDPrefix(true))
+	userCtx := AddUserToContext(context.Background(), "eve")
+	otherCtx := AddUserToContext(context.Background(), "mallory")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh", Name: "nightly"})
+	require.NoError(t, err)
+	require.Equal(t, "eve/nightly", id)
+	_, err = tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+
+	_, _, err = tracker.Start(userCtx, JobSpec{Command: "/bin/sh", Name: "nightly"})
+	require.ErrorIs(t, err, ErrJobExists)
+
+	// Names are only unique for each user.
+	id, _, err = tracker.Start(otherCtx, JobSpec{Command: "/bin/sh", Name: "nightly"})
+	require.NoError(t, err)
+	require.Equal(t, "mallory/nightly", id)
+	_, err = tracker.Wait(otherCtx, id)
+	require.NoError(t, err)
+}
+
+func TestCheckName(t *testing.T) {
+	tests := map[string]struct {
+		name string
+		err  string
+	}{
+		"valid":             {name: "nightly-build-2"},
+		"longest":           {name: strings.Repeat("a", maxNameLen)},
+		"too long":          {name: strings.Repeat("a", maxNameLen+1), err: "is 64 characters, longer than the maximum of 63"},
+		"slash":             {name: "nightly/..", err: `"nightly/.." contains a path separator`},
+		"backslash":         {name: `a\b`, err: "contains a path separator"},
+		"control character": {name: "night\nly", err: `"night\nly" contains a control character`},
+		"uppercase":         {name: "Nightly", err: `"Nightly" contains 'N'; only lowercase letters, digits and hyphens are allowed`},
+		"leading hyphen":    {name: "-nightly", err: `"-nightly" starts or ends with a hyphen`},
+		"trailing hyphen":   {name: "nightly-", err: `"nightly-" starts or ends with a hyphen`},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkName(tc.name)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrInvalidName)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestReservedNamePrefixes(t *testing.T) {
+	tracker := newTestTracker("exit 0", WithReservedNamePrefixes([]string{"jobber-", "sys-"}))
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	_, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh", Name: "sys-backup"})
+	require.ErrorIs(t, err, ErrInvalidName)
+	require.EqualError(t, err, `invalid job name: "sys-backup" starts with the reserved prefix "sys-"`)
+	_, _, err = tracker.ExplainCgroup(userCtx, JobSpec{Command: "/bin/sh", Name: "jobber-gc"})
+	require.ErrorIs(t, err, ErrInvalidName)
+
+	// Jobs without a name, and names only containing a prefix, are fine.
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	_, err = tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+	id, _, err = tracker.Start(userCtx, JobSpec{Command: "/bin/sh", Name: "my-sys-backup"})
+	require.NoError(t, err)
+	_, err = tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+}
@@ -0,0 +1,154 @@
+package job
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// logBudget limits the memory used by the log buffers of all jobs, on top
+// of any limit on each job's buffer. Feeders report the size of their
+// buffer to the budget as it changes. When their total exceeds the budget,
+// the budget asks feeders to trim their oldest logs until it no longer
+// does: first those of jobs whose output has ended, then those of running
+// jobs, taking from the feeder whose oldest log is oldest first. As the
+// latest log of each feeder is always kept, the budget can be exceeded by
+// those alone.
+//
+// Trimming is done by each feeder's own goroutine when it is next woken by
+// its trim channel, so the total may exceed the budget briefly.
+type logBudget struct {
+	max int
+
+	mu sync.Mutex
+	// used is the size of the lines buffered by all feeders, and trimming
+	// the part of it feeders have been asked to trim and not yet trimmed.
+	used     int
+	trimming int
+	feeders  map[*feeder]*logUsage
+}
+
+// logUsage is the usage of the log budget by a feeder.
+type logUsage struct {
+	// bytes is the size of the lines in the feeder's buffer, and trimmable
+	// the part of it that can be trimmed: all but the latest log.
+	bytes     int
+	trimmable int
+	// oldest is the timestamp of the oldest log in the buffer.
+	oldest time.Time
+	// ended is true once the feeder's infeed has closed.
+	ended bool
+	// trim is the number of bytes the feeder has been asked to trim.
+	trim int
+}
+
+func newLogBudget(maxBytes int) *logBudget {
+	return &logBudget{max: maxBytes, feeders: map[*feeder]*logUsage{}}
+}
+
+// update records the current usage of f, asking feeders to trim their logs
+// if the budget is exceeded.
+func (b *logBudget) update(f *feeder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.updateLocked(f)
+	b.plan()
+}
+
+// trim trims the logs of f by as much as it has been asked to. It must be
+// called from the feeder's goroutine.
+func (b *logBudget) trim(f *feeder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	u, ok := b.feeders[f]
+	if !ok || u.trim == 0 {
+		return
+	}
+	f.trim(u.trim)
+	b.trimming -= u.trim
+	u.trim = 0
+	b.updateLocked(f)
+	b.plan()
+}
+
+// remove stops accounting for f, as its logs have been released.
+func (b *logBudget) remove(f *feeder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if u, ok := b.feeders[f]; ok {
+		b.used -= u.bytes
+		b.trimming -= u.trim
+		delete(b.feeders, f)
+	}
+}
+
+// updateLocked records the current usage of f. b.mu must be held.
+func (b *logBudget) updateLocked(f *feeder) {
+	u, ok := b.feeders[f]
+	if !ok {
+		u = &logUsage{}
+		b.feeders[f] = u
+	}
+	b.used -= u.bytes
+	u.bytes, u.trimmable, u.oldest, u.ended = f.budgetUsage()
+	b.used += u.bytes
+	// The feeder may have dropped logs it was asked to trim itself.
+	if u.trim > u.trimmable {
+		b.trimming -= u.trim - u.trimmable
+		u.trim = u.trimmable
+	}
+}
+
+// plan asks feeders to trim their logs until what remains is within the
+// budget. b.mu must be held.
+func (b *logBudget) plan() {
+	excess := b.used - b.trimming - b.max
+	if excess <= 0 {
+		return
+	}
+	feeders := make([]*feeder, 0, len(b.feeders))
+	for f := range b.feeders {
+		feeders = append(feeders, f)
+	}
+	sort.Slice(feeders, func(i, j int) bool {
+		ui, uj := b.feeders[feeders[i]], b.feeders[feeders[j]]
+		if ui.ended != uj.ended {
+			return ui.ended
+		}
+		return ui.oldest.Before(uj.oldest)
+	})
+	for _, f := range feeders {
+		u := b.feeders[f]
+		n := u.trimmable - u.trim
+		if n <= 0 {
+			continue
+		}
+		if n > excess {
+			n = excess
+		}
+		u.trim += n
+		b.trimming += n
+		excess -= n
+		// A trim already signalled covers this one too.
+		select {
+		case f.trimSignal <- struct{}{}:
+		default:
+		}
+		if excess <= 0 {
+			return
+		}
+	}
+}
+
+// WithLogMemoryBudget limits the memory used by the logs kept for all jobs
+// to the given number of bytes of output. Once exceeded, the oldest logs are
+// dropped, first from jobs whose output has ended, as if by the limit on
+// each job's logs set with WithMaxLogBuffer. Zero is no limit.
+func WithLogMemoryBudget(bytes int) TrackerOption {
+	return func(t *Tracker) {
+		t.logBudget = nil
+		if bytes > 0 {
+			t.logBudget = newLogBudget(bytes)
+		}
+	}
+}
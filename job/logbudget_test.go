@@ -0,0 +1,135 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// startBudgetFeeder starts a feeder with budget, recording lines timestamped
+// from start a second apart. The feeder is stopped when the test ends.
+func startBudgetFeeder(t *testing.T, budget *logBudget, start time.Time, lines ...string) (*feeder, chan<- Log) {
+	t.Helper()
+	in := make(chan Log)
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	f := newFeeder(in)
+	f.budget = budget
+	go f.Start(done)
+	sendLogs(f, in, start, lines...)
+	return f, in
+}
+
+// sendLogs sends lines to f on in, timestamped from start a second apart,
+// and waits for f to record them.
+func sendLogs(f *feeder, in chan<- Log, start time.Time, lines ...string) {
+	for i, line := range lines {
+		in <- Log{Timestamp: start.Add(time.Duration(i) * time.Second), Line: []byte(line)}
+	}
+	f.stats()
+}
+
+// bufferedLines returns the lines buffered by f.
+func bufferedLines(f *feeder) []string {
+	var lines []string
+	for _, l := range f.page(0, 0).Logs {
+		lines = append(lines, string(l.Line))
+	}
+	return lines
+}
+
+func budgetUsed(b *logBudget) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+func TestLogBudgetTrimsEndedJobsFirst(t *testing.T) {
+	budget := newLogBudget(15)
+	start := time.Date(2022, 5, 27, 12, 0, 0, 0, time.UTC)
+
+	// The running job's logs are older, but those of the ended job are
+	// trimmed first.
+	running, in := startBudgetFeeder(t, budget, start, "r0\n", "r1\n")
+	ended, endedIn := startBudgetFeeder(t, budget, start.Add(time.Hour), "e0\n", "e1\n", "e2\n")
+	close(endedIn)
+	require.Eventually(t, func() bool { return ended.stats().InfeedClosed }, time.Second, time.Millisecond)
+	require.Equal(t, 15, budgetUsed(budget))
+
+	// Going over budget by 9 bytes trims the ended job's logs, but its
+	// latest is kept, so the running job's oldest is trimmed too.
+	sendLogs(running, in, start.Add(time.Minute), "r2\n", "r3\n", "r4\n")
+	require.Eventually(t, func() bool { return budgetUsed(budget) <= 15 }, time.Second, time.Millisecond)
+	require.Equal(t, []string{"e2\n"}, bufferedLines(ended))
+	require.Equal(t, []string{"r1\n", "r2\n", "r3\n", "r4\n"}, bufferedLines(running))
+
+	// Readers are told of the trimmed logs as of those dropped by a
+	// job's own limits.
+	l := <-ended.attachOutfeed(false /* follow */, 0, nil)
+	require.Equal(t, "e2\n", string(l.Line))
+	require.Equal(t, 2, l.Dropped)
+}
+
+func TestLogBudgetTrimsOldestLogs(t *testing.T) {
+	budget := newLogBudget(30)
+	start := time.Date(2022, 5, 27, 12, 0, 0, 0, time.UTC)
+
+	newer, newerIn := startBudgetFeeder(t, budget, start.Add(time.Hour), "n0\n", "n1\n", "n2\n", "n3\n")
+	older, olderIn := startBudgetFeeder(t, budget, start, "o0\n", "o1\n", "o2\n", "o3\n")
+	require.Equal(t, 24, budgetUsed(budget))
+
+	sendLogs(older, olderIn, start.Add(time.Minute), "o4\n", "o5\n", "o6\n")
+	require.Eventually(t, func() bool { return budgetUsed(budget) <= 30 }, time.Second, time.Millisecond)
+	require.Equal(t, []string{"o1\n", "o2\n", "o3\n", "o4\n", "o5\n", "o6\n"}, bufferedLines(older))
+	require.Equal(t, []string{"n0\n", "n1\n", "n2\n", "n3\n"}, bufferedLines(newer))
+
+	// The older job's logs are trimmed whichever job goes over budget.
+	sendLogs(newer, newerIn, start.Add(2*time.Hour), "n4\n")
+	require.Eventually(t, func() bool { return budgetUsed(budget) <= 30 }, time.Second, time.Millisecond)
+	require.Equal(t, []string{"o2\n", "o3\n", "o4\n", "o5\n", "o6\n"}, bufferedLines(older))
+	require.Equal(t, []string{"n0\n", "n1\n", "n2\n", "n3\n", "n4\n"}, bufferedLines(newer))
+}
+
+func TestLogBudgetReleasedWhenFeederStops(t *testing.T) {
+	budget := newLogBudget(100)
+	in := make(chan Log)
+	done := make(chan struct{})
+	f := newFeeder(in)
+	f.budget = budget
+	go f.Start(done)
+	sendLogs(f, in, time.Now(), "one\n", "two\n")
+	require.Equal(t, 8, budgetUsed(budget))
+
+	close(done)
+	<-f.stopped
+	require.Zero(t, budgetUsed(budget))
+	require.Empty(t, budget.feeders)
+}
+
+func TestTrackerLogMemoryBudget(t *testing.T) {
+	tracker := newTestTracker("for i in 1 2 3 4 5 6 7 8 9 10; do echo line $i; done", WithLogMemoryBudget(50))
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+		require.NoError(t, err)
+		_, err = tracker.Wait(userCtx, id)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	// Each job wrote 71 bytes, so all but about 50 bytes of the latest
+	// logs are trimmed, but the last line of each job is kept.
+	require.Eventually(t, func() bool { return budgetUsed(tracker.logBudget) <= 50 }, 5*time.Second, time.Millisecond)
+	for _, id := range ids {
+		logs := tracker.jobs[id].logFeeder.page(0, 0).Logs
+		require.NotEmpty(t, logs)
+		require.Equal(t, "line 10\n", string(logs[len(logs)-1].Line))
+	}
+	// The earlier jobs had ended, so were trimmed first.
+	require.Len(t, tracker.jobs[ids[0]].logFeeder.page(0, 0).Logs, 1)
+	require.Len(t, tracker.jobs[ids[1]].logFeeder.page(0, 0).Logs, 1)
+}
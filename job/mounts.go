@@ -0,0 +1,152 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// BindMount is a directory or file on the host that is bind mounted into a
+// job's filesystem namespace.
+type BindMount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+var ErrInvalidMount = errors.New("invalid bind mount")
+
+// UnmarshalText unmarshals a string ([]byte) into a BindMount. It is used
+// by kong to unmarshal the command line argument into a structured value.
+//
+// The format of the input string is "source:target" or "source:target:ro"
+// where source is a path on the host and target is a path in the job's
+// filesystem namespace.
+func (m *BindMount) UnmarshalText(b []byte) error {
+	parts := strings.Split(string(b), ":")
+	switch {
+	case len(parts) == 2:
+		m.ReadOnly = false
+	case len(parts) == 3 && parts[2] == "ro":
+		m.ReadOnly = true
+	default:
+		return fmt.Errorf("%w: %s: expected source:target[:ro]", ErrInvalidMount, string(b))
+	}
+	m.Source, m.Target = parts[0], parts[1]
+	return m.Validate()
+}
+
+// MarshalText marshals a BindMount in the format accepted by UnmarshalText.
+func (m BindMount) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m BindMount) String() string {
+	if m.ReadOnly {
+		return m.Source + ":" + m.Target + ":ro"
+	}
+	return m.Source + ":" + m.Target
+}
+
+// Validate checks that the source and target of the mount are absolute
+// paths.
+func (m BindMount) Validate() error {
+	if !filepath.IsAbs(m.Source) {
+		return fmt.Errorf("%w: source %q is not an absolute path", ErrInvalidMount, m.Source)
+	}
+	if !filepath.IsAbs(m.Target) {
+		return fmt.Errorf("%w: target %q is not an absolute path", ErrInvalidMount, m.Target)
+	}
+	return nil
+}
+
+// makeMountsPrivate makes every mount in the job's mount namespace private.
+// A new mount namespace starts as a copy of the host's, and mounts that are
+// shared on the host (the default under systemd) stay in the same peer
+// group, so without this the job's mounts would propagate back to the host.
+// It must be called in the job's mount namespace before any other mounts.
+func makeMountsPrivate() error {
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("could not make mounts private: %w", err)
+	}
+	return nil
+}
+
+// bindMounts bind mounts each of mounts at its target under root. It must
+// be called in the job's mount namespace before changing root to root.
+// Missing target directories are created, but only under a root other than
+// "/" so as not to modify the host's filesystem. Without one, a missing
+// target is an error naming it, which reaches the client on the job's
+// stderr.
+func bindMounts(root string, mounts []BindMount) error {
+	if root == "" {
+		root = "/"
+	}
+	for _, m := range mounts {
+		target := filepath.Join(root, m.Target)
+		if root != "/" {
+			if err := mountPoint(m.Source, target); err != nil {
+				return fmt.Errorf("could not create mount point %s: %w", target, err)
+			}
+		} else if _, err := os.Stat(target); errors.Is(err, os.ErrNotExist) {
+			// Without a root, the target would be created on the host.
+			return fmt.Errorf("%w: target %s does not exist and is only created under a job root", ErrInvalidMount, m.Target)
+		}
+		if err := syscall.Mount(m.Source, target, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+			return fmt.Errorf("could not bind mount %s: %w", m, err)
+		}
+		if m.ReadOnly {
+			// A bind mount cannot be made read-only when it is created;
+			// it needs to be remounted.
+			flags := uintptr(syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_RDONLY)
+			locked, err := lockedMountFlags(target)
+			if err != nil {
+				return fmt.Errorf("could not make %s read-only: %w", target, err)
+			}
+			if err := syscall.Mount("", target, "", flags|locked, ""); err != nil {
+				return fmt.Errorf("could not make %s read-only: %w", target, err)
+			}
+		}
+	}
+	return nil
+}
+
+// lockedMountFlagMask are the mount flags that, in a user namespace, are
+// locked on mounts copied from the host, so cannot be cleared by a
+// remount. The statfs(2) flags of a mount have the same values.
+const lockedMountFlagMask = syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC |
+	syscall.MS_NOATIME | syscall.MS_NODIRATIME | syscall.MS_RELATIME
+
+// lockedMountFlags returns the flags of the mount at path that a remount
+// of it must keep to succeed in a user namespace. Keeping them elsewhere
+// changes nothing.
+func lockedMountFlags(path string) (uintptr, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uintptr(st.Flags) & lockedMountFlagMask, nil
+}
+
+// mountPoint creates target as an empty directory or file to match the
+// type of source, if target does not already exist.
+func mountPoint(source, target string) error {
+	fi, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		return os.MkdirAll(target, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_RDONLY, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
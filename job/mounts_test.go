@@ -0,0 +1,104 @@
+package job
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// mountedAt returns whether there is a mount at dir in the mount namespace
+// whose mountinfo file is given.
+func mountedAt(mountinfo, dir string) (bool, error) {
+	b, err := os.ReadFile(mountinfo)
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		// The mount point is the fifth field.
+		if fields := strings.Fields(line); len(fields) > 4 && fields[4] == dir {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestMakeMountsPrivate(t *testing.T) {
+	// Make a shared mount on the host, so that mounts under it in a new
+	// mount namespace would propagate back to the host unless private.
+	shared := t.TempDir()
+	err := unix.Mount(shared, shared, "", unix.MS_BIND, "")
+	if errors.Is(err, unix.EPERM) {
+		t.Skip("mounting requires CAP_SYS_ADMIN")
+	}
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = unix.Unmount(shared, unix.MNT_DETACH) })
+	require.NoError(t, unix.Mount("", shared, "", unix.MS_SHARED, ""))
+	target := filepath.Join(shared, "tmp")
+	require.NoError(t, os.Mkdir(target, 0o755))
+
+	errc := make(chan error)
+	go func() {
+		// The new mount namespace is only for this thread. It is not
+		// unlocked so the thread exits with the goroutine rather than
+		// being reused in the namespace.
+		runtime.LockOSThread()
+		if err := unix.Unshare(unix.CLONE_NEWNS); err != nil {
+			errc <- err
+			return
+		}
+		if err := makeMountsPrivate(); err != nil {
+			errc <- err
+			return
+		}
+		if err := unix.Mount("tmpfs", target, "tmpfs", 0, ""); err != nil {
+			errc <- err
+			return
+		}
+		mounted, err := mountedAt("/proc/thread-self/mountinfo", target)
+		if err == nil && !mounted {
+			err = errors.New("tmpfs not mounted in the job's mount namespace")
+		}
+		errc <- err
+	}()
+	require.NoError(t, <-errc)
+
+	// Any thread but the one that unshared is in the host mount namespace.
+	mounted, err := mountedAt("/proc/thread-self/mountinfo", target)
+	require.NoError(t, err)
+	require.False(t, mounted, "job mount propagated to the host")
+}
+
+func TestLockedMountFlags(t *testing.T) {
+	dir := t.TempDir()
+	flags := uintptr(unix.MS_NOSUID | unix.MS_NODEV | unix.MS_NOEXEC | unix.MS_RDONLY)
+	err := unix.Mount("tmpfs", dir, "tmpfs", flags, "")
+	if errors.Is(err, unix.EPERM) {
+		t.Skip("mounting requires CAP_SYS_ADMIN")
+	}
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = unix.Unmount(dir, unix.MNT_DETACH) })
+
+	locked, err := lockedMountFlags(dir)
+	require.NoError(t, err)
+	// Read-only is not locked, but is what a remount is changing.
+	require.Equal(t, uintptr(unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC), locked&^unix.MS_RELATIME)
+}
+
+func TestBindMountsMissingTarget(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "missing")
+	mounts := []BindMount{{Source: t.TempDir(), Target: target}}
+
+	// The target is checked before anything is mounted, so this does not
+	// need privileges.
+	err := bindMounts("", mounts)
+	require.ErrorIs(t, err, ErrInvalidMount)
+	require.Contains(t, err.Error(), target)
+	_, err = os.Stat(target)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,33 @@
+package job
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// upLoopback brings up the loopback interface of the network namespace the
+// calling thread is in. A new network namespace has only a loopback
+// interface and it starts down, so a job with an isolated network cannot
+// even use localhost until it is brought up. No other interfaces are
+// configured, so the job has no connectivity outside its namespace.
+func upLoopback() error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("could not bring up loopback interface: %w", err)
+	}
+	defer unix.Close(fd)
+
+	ifr, err := unix.NewIfreq("lo")
+	if err != nil {
+		return fmt.Errorf("could not bring up loopback interface: %w", err)
+	}
+	if err := unix.IoctlIfreq(fd, unix.SIOCGIFFLAGS, ifr); err != nil {
+		return fmt.Errorf("could not get loopback interface flags: %w", err)
+	}
+	ifr.SetUint16(ifr.Uint16() | unix.IFF_UP)
+	if err := unix.IoctlIfreq(fd, unix.SIOCSIFFLAGS, ifr); err != nil {
+		return fmt.Errorf("could not bring up loopback interface: %w", err)
+	}
+	return nil
+}
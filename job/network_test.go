@@ -0,0 +1,58 @@
+package job
+
+import (
+	"errors"
+	"net"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestUpLoopback(t *testing.T) {
+	errc := make(chan error)
+	go func() {
+		// The new network namespace is only for this thread. It is not
+		// unlocked so the thread exits with the goroutine rather than
+		// being reused in the namespace.
+		runtime.LockOSThread()
+		if err := unix.Unshare(unix.CLONE_NEWNET); err != nil {
+			errc <- err
+			return
+		}
+		if err := upLoopback(); err != nil {
+			errc <- err
+			return
+		}
+
+		ifaces, err := net.Interfaces()
+		if err != nil {
+			errc <- err
+			return
+		}
+		if len(ifaces) != 1 || ifaces[0].Name != "lo" || ifaces[0].Flags&net.FlagUp == 0 {
+			errc <- errors.New("expected only an up loopback interface")
+			return
+		}
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer l.Close()
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			errc <- err
+			return
+		}
+		errc <- conn.Close()
+	}()
+
+	err := <-errc
+	if errors.Is(err, unix.EPERM) {
+		t.Skip("creating a network namespace requires CAP_SYS_ADMIN")
+	}
+	require.NoError(t, err)
+}
@@ -0,0 +1,75 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+var ErrInsufficientPrivileges = errors.New("insufficient privileges to run jobs")
+
+// capability is a Linux capability needed to run jobs, with what it is
+// needed for.
+type capability struct {
+	bit  int
+	name string
+	use  string
+}
+
+// requiredCapabilities are the capabilities the server needs to run jobs.
+// Running as root normally grants them all.
+var requiredCapabilities = []capability{
+	{unix.CAP_SYS_ADMIN, "CAP_SYS_ADMIN", "create namespaces and cgroups, mount filesystems and set hostnames"},
+	{unix.CAP_SYS_CHROOT, "CAP_SYS_CHROOT", "run jobs under a filesystem root"},
+	{unix.CAP_NET_ADMIN, "CAP_NET_ADMIN", "bring up the loopback interface of isolated networks"},
+}
+
+// CheckPrivileges checks that the process has the capabilities needed to
+// run jobs. It returns an error wrapping ErrInsufficientPrivileges that
+// names each missing capability and what it is needed for, so the server
+// can fail at startup rather than on each job.
+func CheckPrivileges() error {
+	b, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return fmt.Errorf("could not read capabilities: %w", err)
+	}
+	eff, err := effectiveCapabilities(string(b))
+	if err != nil {
+		return err
+	}
+	return checkCapabilities(eff)
+}
+
+// effectiveCapabilities returns the effective capability set from the
+// contents of a /proc/<pid>/status file.
+func effectiveCapabilities(status string) (uint64, error) {
+	for _, line := range strings.Split(status, "\n") {
+		if key, v, ok := strings.Cut(line, ":"); ok && key == "CapEff" {
+			eff, err := strconv.ParseUint(strings.TrimSpace(v), 16, 64)
+			if err != nil {
+				return 0, fmt.Errorf("could not parse capabilities %q: %w", v, err)
+			}
+			return eff, nil
+		}
+	}
+	return 0, errors.New("could not find effective capabilities")
+}
+
+// checkCapabilities returns an error wrapping ErrInsufficientPrivileges if
+// any required capabilities are missing from the capability set eff.
+func checkCapabilities(eff uint64) error {
+	var missing []string
+	for _, c := range requiredCapabilities {
+		if eff&(1<<c.bit) == 0 {
+			missing = append(missing, fmt.Sprintf("%s (to %s)", c.name, c.use))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: missing %s; run as root or grant the capabilities with setcap", ErrInsufficientPrivileges, strings.Join(missing, ", "))
+}
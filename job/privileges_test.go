@@ -0,0 +1,41 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveCapabilities(t *testing.T) {
+	status := "Name:\tjobber\nCapInh:\t0000000000000000\nCapPrm:\t000001ffffffffff\nCapEff:\t000001ffffffffff\n"
+	eff, err := effectiveCapabilities(status)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0x000001ffffffffff), eff)
+
+	_, err = effectiveCapabilities("Name:\tjobber\n")
+	require.Error(t, err)
+	_, err = effectiveCapabilities("CapEff:\tnothex\n")
+	require.Error(t, err)
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	require.NoError(t, checkCapabilities(0x000001ffffffffff))
+
+	err := checkCapabilities(0)
+	require.ErrorIs(t, err, ErrInsufficientPrivileges)
+	for _, c := range requiredCapabilities {
+		require.Contains(t, err.Error(), c.name)
+	}
+
+	// All but CAP_SYS_CHROOT.
+	var eff uint64
+	for _, c := range requiredCapabilities {
+		if c.name != "CAP_SYS_CHROOT" {
+			eff |= 1 << c.bit
+		}
+	}
+	err = checkCapabilities(eff)
+	require.ErrorIs(t, err, ErrInsufficientPrivileges)
+	require.Contains(t, err.Error(), "CAP_SYS_CHROOT (to run jobs under a filesystem root)")
+	require.NotContains(t, err.Error(), "CAP_SYS_ADMIN")
+}
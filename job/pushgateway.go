@@ -0,0 +1,156 @@
+package job
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pushTimeout is how long pushing the metrics of a job may take.
+const pushTimeout = 10 * time.Second
+
+// Pushgateway pushes the final metrics of completed jobs to a Prometheus
+// Pushgateway, so that jobs that complete between scrapes are not missed.
+// The metrics of each job are pushed to their own group, keyed by the job
+// ID under the Prometheus job "jobber", replacing any pushed before. As the
+// Pushgateway keeps groups until they are deleted, each group is deleted
+// once it has been kept for the retention period.
+type Pushgateway struct {
+	url       string
+	retention time.Duration
+	client    *http.Client
+}
+
+// NewPushgateway returns a Pushgateway pushing to the Pushgateway at the
+// http or https URL rawURL, such as http://pushgateway:9091, and deleting
+// the metrics of each job retention after they are pushed. A zero retention
+// keeps them until they are deleted by other means.
+func NewPushgateway(rawURL string, retention time.Duration) (*Pushgateway, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pushgateway URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid pushgateway URL %q: must be an http or https URL with a host", rawURL)
+	}
+	return &Pushgateway{
+		url:       strings.TrimSuffix(u.String(), "/"),
+		retention: retention,
+		client:    &http.Client{Timeout: pushTimeout},
+	}, nil
+}
+
+// groupURL returns the URL of the group of the metrics of the job
+// identified by id. The ID is encoded in base64 as the Pushgateway allows
+// for label values, as a namespaced ID contains a slash.
+func (p *Pushgateway) groupURL(id string) string {
+	return p.url + "/metrics/job/jobber/job_id@base64/" + base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// Push pushes the metrics of the completed job described by jd: how long
+// it ran, its exit code, and the peak memory and CPU time it used. Once
+// they have been pushed, they are deleted after the retention period in
+// the background, unless the server stops first.
+// Pushing to a nil Pushgateway does nothing.
+func (p *Pushgateway) Push(jd JobDescription) error {
+	if p == nil {
+		return nil
+	}
+	if err := p.do(http.MethodPut, jd.ID, jobMetrics(jd)); err != nil {
+		return err
+	}
+	if p.retention > 0 {
+		time.AfterFunc(p.retention, func() {
+			if err := p.do(http.MethodDelete, jd.ID, nil); err != nil {
+				// XXX Should log, but no logger yet
+				fmt.Fprintf(os.Stderr, "could not delete metrics of job %s: %v\n", jd.ID, err)
+			}
+		})
+	}
+	return nil
+}
+
+// do makes a request with the given method and body for the group of the
+// job identified by id.
+func (p *Pushgateway) do(method, id string, body []byte) error {
+	req, err := http.NewRequest(method, p.groupURL(id), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pushgateway returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// jobMetrics returns the metrics of the completed job described by jd in
+// the Prometheus text format, labelled with the job's owner and the base
+// name of its command.
+func jobMetrics(jd JobDescription) []byte {
+	labels := fmt.Sprintf(`{owner="%s",command="%s"}`,
+		escapeLabelValue(jd.Status.Owner), escapeLabelValue(filepath.Base(jd.Spec.FirstCommand())))
+	metrics := []struct {
+		name, help string
+		value      float64
+	}{
+		{"jobber_job_duration_seconds", "How long the job ran for.", jd.Status.CompletionTime.Sub(jd.Status.StartTime).Seconds()},
+		{"jobber_job_exit_code", "Exit code of the job.", float64(jd.Status.ExitCode)},
+		{"jobber_job_peak_memory_bytes", "Most memory the job used at once.", float64(jd.Usage.PeakMemory)},
+		{"jobber_job_cpu_seconds", "CPU time the job used in user and system mode.", jd.Usage.CPUTime.Seconds()},
+	}
+	var b bytes.Buffer
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s%s %s\n",
+			m.name, m.help, m.name, m.name, labels, strconv.FormatFloat(m.value, 'g', -1, 64))
+	}
+	return b.Bytes()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes s for use as a label value in the Prometheus
+// text format.
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+// WithPushgateway pushes the final metrics of each job run by the tracker
+// to p when it completes.
+func WithPushgateway(p *Pushgateway) TrackerOption {
+	return func(t *Tracker) {
+		t.pushgateway = p
+	}
+}
+
+// pushMetrics pushes the final metrics of the completed job to its
+// Pushgateway, if it has one. It pushes in the background so that a slow
+// Pushgateway does not hold up the job being reaped.
+func (j *Job) pushMetrics() {
+	if j.pushgateway == nil {
+		return
+	}
+	jd := j.Description()
+	go func() {
+		if err := j.pushgateway.Push(jd); err != nil {
+			// XXX Should log, but no logger yet
+			fmt.Fprintf(os.Stderr, "could not push metrics of job %s: %v\n", j.ID, err)
+		}
+	}()
+}
@@ -0,0 +1,122 @@
+package job
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type pushRequest struct {
+	method, path, contentType, body string
+}
+
+// newTestPushgateway returns a Pushgateway pushing to a test server that
+// sends each request it receives on the returned channel and responds with
+// code. The metrics pushed are deleted after retention.
+func newTestPushgateway(t *testing.T, code int, retention time.Duration) (*Pushgateway, <-chan pushRequest) {
+	t.Helper()
+	requests := make(chan pushRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- pushRequest{r.Method, r.URL.EscapedPath(), r.Header.Get("Content-Type"), string(body)}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(srv.Close)
+	p, err := NewPushgateway(srv.URL+"/", retention)
+	require.NoError(t, err)
+	return p, requests
+}
+
+func TestPushgatewayPush(t *testing.T) {
+	p, requests := newTestPushgateway(t, http.StatusOK, 0)
+	start := time.Date(2022, 5, 27, 12, 24, 4, 0, time.UTC)
+	jd := JobDescription{
+		ID:   "eve/backup-01234567",
+		Spec: JobSpec{Command: "/usr/bin/backup", Args: []string{"--full"}},
+		Status: JobStatus{
+			State:          JobStateCompleted,
+			Owner:          `e"ve`,
+			StartTime:      start,
+			CompletionTime: start.Add(90 * time.Second),
+			ExitCode:       3,
+		},
+		Usage: ResourceUsage{PeakMemory: 64 << 20, CPUTime: 1500 * time.Millisecond},
+	}
+	require.NoError(t, p.Push(jd))
+
+	req := <-requests
+	require.Equal(t, http.MethodPut, req.method)
+	require.Equal(t, "/metrics/job/jobber/job_id@base64/ZXZlL2JhY2t1cC0wMTIzNDU2Nw", req.path)
+	require.Equal(t, "text/plain; version=0.0.4", req.contentType)
+	expected := `# HELP jobber_job_duration_seconds How long the job ran for.
+# TYPE jobber_job_duration_seconds gauge
+jobber_job_duration_seconds{owner="e\"ve",command="backup"} 90
+# HELP jobber_job_exit_code Exit code of the job.
+# TYPE jobber_job_exit_code gauge
+jobber_job_exit_code{owner="e\"ve",command="backup"} 3
+# HELP jobber_job_peak_memory_bytes Most memory the job used at once.
+# TYPE jobber_job_peak_memory_bytes gauge
+jobber_job_peak_memory_bytes{owner="e\"ve",command="backup"} 6.7108864e+07
+# HELP jobber_job_cpu_seconds CPU time the job used in user and system mode.
+# TYPE jobber_job_cpu_seconds gauge
+jobber_job_cpu_seconds{owner="e\"ve",command="backup"} 1.5
+`
+	require.Equal(t, expected, req.body)
+}
+
+func TestPushgatewayRetention(t *testing.T) {
+	p, requests := newTestPushgateway(t, http.StatusOK, 10*time.Millisecond)
+	require.NoError(t, p.Push(JobDescription{ID: "test-1", Spec: JobSpec{Command: "/bin/sh"}}))
+	require.Equal(t, http.MethodPut, (<-requests).method)
+
+	// The group is deleted once its retention has passed.
+	select {
+	case req := <-requests:
+		require.Equal(t, http.MethodDelete, req.method)
+		require.Equal(t, "/metrics/job/jobber/job_id@base64/dGVzdC0x", req.path)
+		require.Empty(t, req.body)
+	case <-time.After(5 * time.Second):
+		t.Fatal("metrics of job not deleted")
+	}
+}
+
+func TestPushgatewayErrors(t *testing.T) {
+	for _, u := range []string{"pushgateway:9091", "ftp://pushgateway", "http://", "http://[::1"} {
+		_, err := NewPushgateway(u, 0)
+		require.Error(t, err, u)
+	}
+
+	p, requests := newTestPushgateway(t, http.StatusBadRequest, 0)
+	err := p.Push(JobDescription{ID: "test-1", Spec: JobSpec{Command: "/bin/sh"}})
+	require.ErrorContains(t, err, "400 Bad Request")
+	<-requests
+
+	var nilPushgateway *Pushgateway
+	require.NoError(t, nilPushgateway.Push(JobDescription{}))
+}
+
+func TestTrackerPushesCompletedJobMetrics(t *testing.T) {
+	p, requests := newTestPushgateway(t, http.StatusOK, 0)
+	tracker := newTestTracker("exit 3", WithPushgateway(p))
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	_, err = tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+
+	select {
+	case req := <-requests:
+		require.Equal(t, http.MethodPut, req.method)
+		require.Equal(t, "/metrics/job/jobber/job_id@base64/"+base64.RawURLEncoding.EncodeToString([]byte(id)), req.path)
+		require.Contains(t, req.body, "\njobber_job_exit_code{owner=\"eve\",command=\"sh\"} 3\n")
+	case <-time.After(5 * time.Second):
+		t.Fatal("metrics of completed job not pushed")
+	}
+}
@@ -0,0 +1,108 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrTooManyJobs = errors.New("server is running its maximum number of jobs")
+	ErrCancelled   = errors.New("cancelled while queued")
+	ErrNotQueued   = errors.New("job is not queued")
+)
+
+// notStartedExitCode is the exit code of a queued job that never started,
+// as a shell uses for a command it could not run.
+const notStartedExitCode = 127
+
+// Queue marks the job as queued for owner, to be started later with Start
+// or abandoned with abandon.
+func (j *Job) Queue(owner string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.Status.State = JobStateQueued
+	j.Status.StartTime = time.Now()
+	j.Status.Owner = owner
+	j.admitted = make(chan struct{})
+	j.done = make(chan struct{})
+	j.reaped = make(chan struct{})
+}
+
+// abandon ends a queued job without it running, leaving it in state and
+// recording err as the reason. Anyone waiting for the job to start or
+// complete is released.
+func (j *Job) abandon(state JobState, err error) {
+	j.mu.Lock()
+	j.Status.State = state
+	j.Status.CompletionTime = time.Now()
+	j.Status.ExitCode = notStartedExitCode
+	j.Status.ExitError = err
+	close(j.admitted)
+	close(j.reaped)
+	j.mu.Unlock()
+
+	j.persist()
+	j.recordCompletion()
+}
+
+// Cancel removes the queued job identified by id from the queue without it
+// ever running, leaving it cancelled. Unlike Stop, a job that is not queued
+// is left alone and ErrNotQueued returned, so that cancelling a job that
+// has just started does not kill it.
+func (t *Tracker) Cancel(ctx context.Context, id string) error {
+	user, ok := GetUserFromContext(ctx)
+	if !ok {
+		return ErrUnauthorized
+	}
+	if t.replicaStore != nil {
+		return ErrReadReplica
+	}
+
+	t.mu.Lock()
+	j, err := t.lookup(user, id)
+	if err != nil {
+		t.mu.Unlock()
+		return err
+	}
+	owner := j.summary().Status.Owner
+	if !t.canManage(ctx, user, owner) {
+		t.mu.Unlock()
+		return ErrUnauthorized
+	}
+	if !t.dequeue(j) {
+		t.mu.Unlock()
+		return fmt.Errorf("%s: %w", id, ErrNotQueued)
+	}
+	j.abandon(JobStateCancelled, ErrCancelled)
+	evicted := t.evictCompleted(owner)
+	t.mu.Unlock()
+	cleanupJobs(evicted)
+	return nil
+}
+
+// attachWhenAdmitted returns a channel that streams the job's logs from
+// the line with index start once the queued job leaves the queue, or is
+// closed when done is closed. It must be called with the job locked.
+func (j *Job) attachWhenAdmitted(start int, done <-chan struct{}) <-chan Log {
+	out := make(chan Log)
+	admitted := j.admitted
+	go func() {
+		defer close(out)
+		select {
+		case <-admitted:
+		case <-done:
+			return
+		}
+		for l := range j.AttachOutfeed(true /* follow */, start, done) {
+			select {
+			case out <- l:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return out
+}
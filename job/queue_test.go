@@ -0,0 +1,180 @@
+package job
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func jobState(t *testing.T, tracker *Tracker, ctx context.Context, id string) JobState {
+	t.Helper()
+	jd, err := tracker.Get(ctx, id)
+	require.NoError(t, err)
+	return JobState(jd.Status.State)
+}
+
+func TestQueuedJobs(t *testing.T) {
+	tracker := newTestTracker("exec 2>&1; echo started; exec sleep 60", WithMaxRunningJobs(2))
+	userCtx := AddUserToContext(context.Background(), "eve")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+	defer tracker.Shutdown(adminCtx) //nolint:errcheck
+
+	spec := JobSpec{Command: "/bin/sh"}
+	queueSpec := JobSpec{Command: "/bin/sh", Queue: true}
+
+	// Fill the slots.
+	var running []string
+	for i := 0; i < 2; i++ {
+		id, warnings, err := tracker.Start(userCtx, queueSpec)
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+		running = append(running, id)
+	}
+
+	_, _, err := tracker.Start(userCtx, spec)
+	require.ErrorIs(t, err, ErrTooManyJobs)
+
+	var queued []string
+	for i := 0; i < 2; i++ {
+		id, warnings, err := tracker.Start(userCtx, queueSpec)
+		require.NoError(t, err)
+		require.Len(t, warnings, 1)
+		require.Contains(t, warnings[0], "job queued behind")
+		require.Equal(t, JobState(JobStateQueued), jobState(t, tracker, userCtx, id))
+		queued = append(queued, id)
+	}
+	require.Len(t, tracker.List(userCtx, false /* completed */, false /* all */), 4)
+
+	// Following the logs of a queued job waits for it to start.
+	logs, err := tracker.GetLogChannel(queued[0], true /* follow */, 0, userCtx)
+	require.NoError(t, err)
+
+	// Completing a running job admits the first queued job, but not the
+	// second.
+	require.NoError(t, tracker.Stop(userCtx, running[0], false /* cleanup */))
+	require.Equal(t, "started\n", string((<-logs).Line))
+	require.Equal(t, JobState(JobStateRunning), jobState(t, tracker, userCtx, queued[0]))
+	require.Equal(t, JobState(JobStateQueued), jobState(t, tracker, userCtx, queued[1]))
+
+	// Stopping a queued job cancels it.
+	require.NoError(t, tracker.Stop(userCtx, queued[1], false /* cleanup */))
+	jd, err := tracker.Wait(userCtx, queued[1])
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateCancelled), jd.Status.State)
+	require.Equal(t, uint32(127), jd.Status.ExitCode)
+	require.Equal(t, "not started: cancelled while queued", jd.Status.ExitReason())
+
+	// A cancelled job has no logs.
+	logs, err = tracker.GetLogChannel(queued[1], true /* follow */, 0, userCtx)
+	require.NoError(t, err)
+	_, ok := <-logs
+	require.False(t, ok)
+
+	// With the queue empty and a free slot, jobs run at once.
+	require.NoError(t, tracker.Stop(userCtx, running[1], false /* cleanup */))
+	require.Eventually(t, func() bool {
+		_, _, err := tracker.Start(userCtx, spec)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCancelQueuedJobs(t *testing.T) {
+	startedFile := filepath.Join(t.TempDir(), "started")
+	tracker := newTestTracker("echo >> "+startedFile+"; exec 2>&1; echo started; exec sleep 60", WithMaxRunningJobs(1))
+	userCtx := AddUserToContext(context.Background(), "eve")
+	otherCtx := AddUserToContext(context.Background(), "mallory")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+	defer tracker.Shutdown(adminCtx) //nolint:errcheck
+
+	spec := JobSpec{Command: "/bin/sh", Queue: true}
+	running, _, err := tracker.Start(userCtx, spec)
+	require.NoError(t, err)
+	var queued []string
+	for i := 0; i < 3; i++ {
+		id, _, err := tracker.Start(userCtx, spec)
+		require.NoError(t, err)
+		queued = append(queued, id)
+	}
+
+	// Only queued jobs can be cancelled, and only by their owner or an
+	// admin.
+	require.ErrorIs(t, tracker.Cancel(userCtx, running), ErrNotQueued)
+	require.Equal(t, JobState(JobStateRunning), jobState(t, tracker, userCtx, running))
+	require.ErrorIs(t, tracker.Cancel(otherCtx, queued[0]), ErrUnauthorized)
+	require.ErrorIs(t, tracker.Cancel(userCtx, "unknown-job"), ErrUnknown)
+
+	require.NoError(t, tracker.Cancel(userCtx, queued[0]))
+	require.NoError(t, tracker.Cancel(adminCtx, queued[1]))
+	require.ErrorIs(t, tracker.Cancel(userCtx, queued[0]), ErrNotQueued)
+	for _, id := range queued[:2] {
+		jd, err := tracker.Wait(userCtx, id)
+		require.NoError(t, err)
+		require.Equal(t, JobState(JobStateCancelled), jd.Status.State)
+		require.Zero(t, jd.Status.Pid)
+	}
+	// Cancelled jobs are listed only with completed jobs.
+	require.Len(t, tracker.List(userCtx, false /* completed */, false /* all */), 2)
+	require.Len(t, tracker.List(userCtx, true /* completed */, false /* all */), 4)
+
+	// Completing the running job skips the cancelled jobs, admitting the
+	// last one.
+	logs, err := tracker.GetLogChannel(queued[2], true /* follow */, 0, userCtx)
+	require.NoError(t, err)
+	require.NoError(t, tracker.Stop(userCtx, running, false /* cleanup */))
+	require.Equal(t, "started\n", string((<-logs).Line))
+	require.NoError(t, tracker.Stop(userCtx, queued[2], false /* cleanup */))
+
+	// The cancelled jobs never started.
+	started, err := os.ReadFile(startedFile)
+	require.NoError(t, err)
+	require.Equal(t, "\n\n", string(started))
+}
+
+func TestQueuedJobFailsToStart(t *testing.T) {
+	failFile := filepath.Join(t.TempDir(), "fail")
+	script := "[ -e " + failFile + " ] && { echo could not exec >&2; exit 0; }; exec 2>&1; exec sleep 60"
+	tracker := newTestTracker(script, WithMaxRunningJobs(1))
+	userCtx := AddUserToContext(context.Background(), "eve")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+	defer tracker.Shutdown(adminCtx) //nolint:errcheck
+
+	spec := JobSpec{Command: "/bin/sh", Queue: true}
+	first, _, err := tracker.Start(userCtx, spec)
+	require.NoError(t, err)
+	second, _, err := tracker.Start(userCtx, spec)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(failFile, nil, 0644))
+	require.NoError(t, tracker.Stop(userCtx, first, true /* cleanup */))
+
+	jd, err := tracker.Wait(userCtx, second)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateCompleted), jd.Status.State)
+	require.Equal(t, "not started: could not start job: could not exec\n", jd.Status.ExitReason())
+}
+
+func TestShutdownAbandonsQueuedJobs(t *testing.T) {
+	tracker := newTestTracker("exec 2>&1; exec sleep 60", WithMaxRunningJobs(1))
+	userCtx := AddUserToContext(context.Background(), "eve")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+
+	spec := JobSpec{Command: "/bin/sh", Queue: true}
+	_, _, err := tracker.Start(userCtx, spec)
+	require.NoError(t, err)
+	queued, _, err := tracker.Start(userCtx, spec)
+	require.NoError(t, err)
+
+	logs, err := tracker.GetLogChannel(queued, true /* follow */, 0, userCtx)
+	require.NoError(t, err)
+
+	n, err := tracker.Shutdown(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	_, ok := <-logs
+	require.False(t, ok)
+	require.Empty(t, tracker.List(adminCtx, true /* completed */, true /* all */))
+}
@@ -0,0 +1,44 @@
+package job
+
+import (
+	"fmt"
+	"time"
+)
+
+// logRateInterval is the period over which a job's log line rate limit
+// applies.
+const logRateInterval = time.Second
+
+// limitLogRate forwards logs from in to out, passing at most perTick lines
+// between ticks and dropping the rest. On each tick, if any lines were
+// dropped since the last tick, a log marker saying how many is sent to out.
+// Once in is closed, any final marker is sent and out is closed.
+func limitLogRate(in <-chan Log, out chan<- Log, perTick uint32, tick <-chan time.Time) {
+	var sent, suppressed uint32
+	flush := func() {
+		if suppressed > 0 {
+			line := fmt.Sprintf("[jobber] %d lines suppressed\n", suppressed)
+			out <- Log{Timestamp: time.Now(), Line: []byte(line)}
+		}
+		sent, suppressed = 0, 0
+	}
+
+	for {
+		select {
+		case l, ok := <-in:
+			if !ok {
+				flush()
+				close(out)
+				return
+			}
+			if sent < perTick {
+				out <- l
+				sent++
+			} else {
+				suppressed++
+			}
+		case <-tick:
+			flush()
+		}
+	}
+}
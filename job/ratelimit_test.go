@@ -0,0 +1,66 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitLogRate(t *testing.T) {
+	in, out := make(chan Log), make(chan Log, 100)
+	tick := make(chan time.Time)
+	go limitLogRate(in, out, 3, tick)
+
+	for i := 0; i < 10; i++ {
+		in <- Log{Line: []byte(fmt.Sprintf("line %d\n", i))}
+	}
+	tick <- time.Now()
+	// Lines are counted afresh after each tick.
+	in <- Log{Line: []byte("line 10\n")}
+	tick <- time.Now()
+	close(in)
+
+	var got []string
+	for l := range out {
+		got = append(got, string(l.Line))
+	}
+	expected := []string{
+		"line 0\n", "line 1\n", "line 2\n",
+		"[jobber] 7 lines suppressed\n",
+		"line 10\n",
+	}
+	require.Equal(t, expected, got)
+}
+
+func TestLogRateLimitedJob(t *testing.T) {
+	const total, rate = 100000, 100
+	tracker := newTestTracker(fmt.Sprintf("exec 2>&1; seq 1 %d", total))
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	start := time.Now()
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh", MaxLogLinesPerSec: rate})
+	require.NoError(t, err)
+	ch, err := tracker.GetLogChannel(id, true /* follow */, 0, userCtx)
+	require.NoError(t, err)
+
+	kept, suppressed, markers := 0, 0, 0
+	for l := range ch {
+		var n int
+		if _, err := fmt.Sscanf(string(l.Line), "[jobber] %d lines suppressed\n", &n); err == nil {
+			suppressed += n
+			markers++
+			continue
+		}
+		require.False(t, strings.HasPrefix(string(l.Line), "[jobber]"), string(l.Line))
+		kept++
+	}
+
+	require.Equal(t, total, kept+suppressed)
+	require.Greater(t, markers, 0)
+	seconds := int(time.Since(start)/logRateInterval) + 1
+	require.LessOrEqual(t, kept, rate*seconds)
+}
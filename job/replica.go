@@ -0,0 +1,81 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+)
+
+// ErrReadReplica is returned for requests to start or change jobs made of a
+// tracker that is a read-only replica.
+var ErrReadReplica = errors.New("server is a read-only replica")
+
+// WithReadReplica makes the tracker a read-only replica of the tracker that
+// keeps its jobs in store, such as another server sharing its state
+// directory. The replica serves the jobs in store as they were last stored,
+// reloading them when read at most every refresh, and runs no jobs of its
+// own: it refuses to start, stop or change jobs with ErrReadReplica. As the
+// logs of a job are stored when it completes, the logs of a running job are
+// not available from a replica, and logs cannot be followed.
+func WithReadReplica(store Store, refresh time.Duration) TrackerOption {
+	return func(t *Tracker) {
+		t.replicaStore = store
+		t.replicaRefresh = refresh
+	}
+}
+
+// refreshReplica reloads the jobs of a read-only replica from its store, if
+// it has not done so within its refresh interval. Jobs whose stored
+// description has not changed are kept, so readers of their logs are not
+// cut off. It must be called without the tracker locked, as reading the
+// store is slow: the tracker is only locked to swap in the reloaded jobs.
+func (t *Tracker) refreshReplica() {
+	if t.replicaStore == nil {
+		return
+	}
+	t.replicaMu.Lock()
+	defer t.replicaMu.Unlock()
+	if !t.replicaRefreshed.IsZero() && time.Since(t.replicaRefreshed) < t.replicaRefresh {
+		return
+	}
+	t.replicaRefreshed = time.Now()
+
+	stored, err := t.replicaStore.Load()
+	if err != nil {
+		// XXX Should log, but no logger yet
+		fmt.Fprintf(os.Stderr, "could not load all stored jobs: %v\n", err)
+	}
+	// Only reloads replace the jobs of a replica, so those loaded last
+	// time are still the tracker's jobs.
+	t.mu.Lock()
+	current := make(map[string]*Job, len(t.jobs))
+	for id, j := range t.jobs {
+		current[id] = j
+	}
+	t.mu.Unlock()
+
+	jobs := make(map[string]*Job, len(stored))
+	loaded := make(map[string]JobDescription, len(stored))
+	for _, sj := range stored {
+		id := sj.Description.ID
+		if j, ok := current[id]; ok && reflect.DeepEqual(t.replicaLoaded[id], sj.Description) {
+			jobs[id] = j
+		} else {
+			jobs[id] = loadJob(sj, t.argMaker, t.logBudget)
+		}
+		loaded[id] = sj.Description
+	}
+	t.mu.Lock()
+	t.jobs = jobs
+	t.mu.Unlock()
+	t.replicaLoaded = loaded
+
+	for id, j := range current {
+		if jobs[id] != j {
+			// Stop the job's feeder once any readers are done.
+			go j.Cleanup()
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package job
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadReplica(t *testing.T) {
+	dir := t.TempDir()
+	primaryStore, err := NewFileStore(dir)
+	require.NoError(t, err)
+	replicaStore, err := NewFileStore(dir)
+	require.NoError(t, err)
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	primary := newTestTracker("exec 2>&1; echo hello; echo world", WithStore(primaryStore))
+	done, _, err := primary.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	_, err = primary.Wait(userCtx, done)
+	require.NoError(t, err)
+
+	// The replica has no live jobs, only those the primary stored.
+	replica := NewTracker(nil, nil, WithReadReplica(replicaStore, 0))
+	jd, err := replica.Get(userCtx, done)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateCompleted), jd.Status.State)
+	require.NotErrorIs(t, jd.Status.ExitError, ErrServerRestarted)
+	require.Len(t, replica.List(userCtx, true /* completed */, false /* all */), 1)
+
+	page, err := replica.LogsPage(userCtx, done, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	// Following the logs of a replica's job returns what is stored.
+	ch, err := replica.GetLogChannel(done, true /* follow */, 0, userCtx)
+	require.NoError(t, err)
+	var lines []string
+	for l := range ch {
+		lines = append(lines, string(l.Line))
+	}
+	require.Equal(t, []string{"hello\n", "world\n"}, lines)
+
+	// A job running on the primary is running on the replica, not lost.
+	running := JobDescription{
+		ID:     "sleep-01234567",
+		Spec:   JobSpec{Command: "/bin/sleep"},
+		Status: JobStatus{State: JobStateRunning, Owner: "eve", Pid: 4242},
+	}
+	require.NoError(t, primaryStore.Save(running, nil))
+	jd, err = replica.Get(userCtx, running.ID)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateRunning), jd.Status.State)
+	require.Len(t, replica.List(userCtx, false /* completed */, false /* all */), 1)
+
+	// Jobs removed by the primary are removed from the replica.
+	require.NoError(t, primary.Stop(userCtx, done, true /* cleanup */))
+	_, err = replica.Get(userCtx, done)
+	require.ErrorIs(t, err, ErrUnknown)
+
+	// The replica does not run or change jobs.
+	_, _, err = replica.Start(userCtx, JobSpec{Command: "/bin/true"})
+	require.ErrorIs(t, err, ErrReadReplica)
+	require.ErrorIs(t, replica.Stop(userCtx, running.ID, false /* cleanup */), ErrReadReplica)
+	_, err = replica.Chown(AddAdminToContext(userCtx), running.ID, "mallory")
+	require.ErrorIs(t, err, ErrReadReplica)
+	n, err := replica.Shutdown(AddAdminToContext(userCtx))
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
@@ -0,0 +1,126 @@
+package job
+
+import (
+	"context"
+	"fmt"
+)
+
+// Role is what a user may do with jobs.
+type Role int
+
+const (
+	// RoleOperator can run jobs and view and manage their own jobs. It is
+	// the role of users not given another.
+	RoleOperator Role = iota
+	// RoleViewer can view the status and logs of any job, but cannot run,
+	// stop or otherwise change jobs.
+	RoleViewer
+	// RoleAdmin can do anything with any job.
+	RoleAdmin
+)
+
+var roleNames = map[Role]string{
+	RoleOperator: "operator",
+	RoleViewer:   "viewer",
+	RoleAdmin:    "admin",
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("Role(%d)", int(r))
+}
+
+// ParseRole returns the role named s: viewer, operator or admin.
+func ParseRole(s string) (Role, error) {
+	for r, name := range roleNames {
+		if s == name {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown role %q: must be viewer, operator or admin", s)
+}
+
+// WithRoles sets the roles of users. Users without a role are operators,
+// unless they are admins. Making a user an admin is the same as listing
+// them as an admin of the tracker.
+func WithRoles(roles map[string]Role) TrackerOption {
+	return func(t *Tracker) {
+		t.roles = roles
+	}
+}
+
+// WithGroupRoles sets the roles of the users in groups, such as the
+// organizational units of their client certificates, added to the context
+// of their requests with AddGroupsToContext. A user's own role set with
+// WithRoles takes precedence over the roles of their groups, unless one of
+// them is admin.
+func WithGroupRoles(roles map[string]Role) TrackerOption {
+	return func(t *Tracker) {
+		t.groupRoles = roles
+	}
+}
+
+// role returns the role of user, whose credentials are in ctx: admin if
+// they are an admin by any means, otherwise their own role if they have
+// one, otherwise the role of their groups.
+func (t *Tracker) role(ctx context.Context, user string) Role {
+	if t.isAdmin(ctx, user) {
+		return RoleAdmin
+	}
+	if r, ok := t.roles[user]; ok {
+		return r
+	}
+	return t.groupRole(ctx)
+}
+
+// groupRole returns the most privileged role of the groups of the user in
+// ctx, admin being the most privileged and viewer the least, or operator if
+// none of their groups has a role.
+func (t *Tracker) groupRole(ctx context.Context) Role {
+	groups, _ := GetGroupsFromContext(ctx)
+	role, found := RoleOperator, false
+	for _, g := range groups {
+		r, ok := t.groupRoles[g]
+		if !ok {
+			continue
+		}
+		if r == RoleAdmin {
+			return RoleAdmin
+		}
+		if !found || r == RoleOperator {
+			role = r
+		}
+		found = true
+	}
+	return role
+}
+
+// canRun returns true if user may run jobs.
+func (t *Tracker) canRun(ctx context.Context, user string) bool {
+	return t.role(ctx, user) != RoleViewer
+}
+
+// canViewAll returns true if user may view the jobs of all users.
+func (t *Tracker) canViewAll(ctx context.Context, user string) bool {
+	return t.role(ctx, user) != RoleOperator
+}
+
+// canView returns true if user may view the status and logs of a job owned
+// by owner.
+func (t *Tracker) canView(ctx context.Context, user, owner string) bool {
+	return owner == user || t.canViewAll(ctx, user)
+}
+
+// canManage returns true if user may stop or otherwise change a job owned
+// by owner.
+func (t *Tracker) canManage(ctx context.Context, user, owner string) bool {
+	switch t.role(ctx, user) {
+	case RoleAdmin:
+		return true
+	case RoleOperator:
+		return owner == user
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package job
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoles(t *testing.T) {
+	roles := map[string]Role{"vic": RoleViewer, "olive": RoleOperator, "ada": RoleAdmin}
+	tracker := newTestTracker("exec sleep 60 2>&1", WithRoles(roles))
+	eveCtx := AddUserToContext(context.Background(), "eve")
+	vicCtx := AddUserToContext(context.Background(), "vic")
+	oliveCtx := AddUserToContext(context.Background(), "olive")
+	adaCtx := AddUserToContext(context.Background(), "ada")
+
+	id, _, err := tracker.Start(eveCtx, JobSpec{Command: "/bin/sleep"})
+	require.NoError(t, err)
+
+	// A viewer can view any job, but not stop it or run jobs.
+	jd, err := tracker.Get(vicCtx, id)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateRunning), jd.Status.State)
+	require.Len(t, tracker.List(vicCtx, false /* completed */, true /* all */), 1)
+	exists, err := tracker.Exists(vicCtx, id)
+	require.NoError(t, err)
+	require.True(t, exists)
+	_, err = tracker.LogsPage(vicCtx, id, 0, 0)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, tracker.Stop(vicCtx, id, false /* cleanup */), ErrUnauthorized)
+	require.ErrorIs(t, tracker.Cancel(vicCtx, id), ErrUnauthorized)
+	_, _, err = tracker.Start(vicCtx, JobSpec{Command: "/bin/sleep"})
+	require.ErrorIs(t, err, ErrUnauthorized)
+	jd, err = tracker.Get(eveCtx, id)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateRunning), jd.Status.State)
+
+	// An operator can neither view nor stop the jobs of others.
+	_, err = tracker.Get(oliveCtx, id)
+	require.ErrorIs(t, err, ErrUnauthorized)
+	require.Empty(t, tracker.List(oliveCtx, false /* completed */, true /* all */))
+	require.ErrorIs(t, tracker.Stop(oliveCtx, id, false /* cleanup */), ErrUnauthorized)
+
+	// An admin can stop any job.
+	require.NoError(t, tracker.Stop(adaCtx, id, false /* cleanup */))
+	jd, err = tracker.Get(eveCtx, id)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateCompleted), jd.Status.State)
+}
+
+func TestGroupRoles(t *testing.T) {
+	groupRoles := map[string]Role{"oncall": RoleViewer, "dev": RoleOperator, "ops": RoleAdmin}
+	tracker := newTestTracker("exec sleep 60 2>&1", WithGroupRoles(groupRoles), WithRoles(map[string]Role{"olive": RoleOperator}))
+	eveCtx := AddUserToContext(context.Background(), "eve")
+	ctx := func(user string, groups ...string) context.Context {
+		return AddGroupsToContext(AddUserToContext(context.Background(), user), groups)
+	}
+
+	id, _, err := tracker.Start(eveCtx, JobSpec{Command: "/bin/sleep"})
+	require.NoError(t, err)
+
+	// Users in a viewer group can view any job, but not run jobs.
+	_, err = tracker.Get(ctx("vic", "oncall"), id)
+	require.NoError(t, err)
+	_, _, err = tracker.Start(ctx("vic", "oncall"), JobSpec{Command: "/bin/sleep"})
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	// The most privileged group wins, and groups without a role are
+	// ignored.
+	_, err = tracker.Get(ctx("dan", "oncall", "dev"), id)
+	require.ErrorIs(t, err, ErrUnauthorized)
+	_, err = tracker.Get(ctx("dan", "other", "oncall"), id)
+	require.NoError(t, err)
+
+	// A user's own role takes precedence over that of their groups.
+	_, err = tracker.Get(ctx("olive", "oncall"), id)
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	// Users in an admin group can stop any job.
+	require.ErrorIs(t, tracker.Stop(ctx("dan", "dev"), id, false /* cleanup */), ErrUnauthorized)
+	require.NoError(t, tracker.Stop(ctx("ada", "dev", "ops"), id, false /* cleanup */))
+	jd, err := tracker.Get(eveCtx, id)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateCompleted), jd.Status.State)
+}
+
+func TestParseRole(t *testing.T) {
+	for _, r := range []Role{RoleViewer, RoleOperator, RoleAdmin} {
+		parsed, err := ParseRole(r.String())
+		require.NoError(t, err)
+		require.Equal(t, r, parsed)
+	}
+	_, err := ParseRole("root")
+	require.EqualError(t, err, `unknown role "root": must be viewer, operator or admin`)
+}
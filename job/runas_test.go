@@ -0,0 +1,45 @@
+package job
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDropPrivilegesHelper is not a real test. It is run as a subprocess by
+// TestDropPrivileges as dropping privileges cannot be undone.
+func TestDropPrivilegesHelper(t *testing.T) {
+	if os.Getenv("JOBBER_RUNAS_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+	require.NoError(t, dropPrivileges(65534, 65533))
+	status, err := os.ReadFile("/proc/self/status")
+	require.NoError(t, err)
+	os.Stdout.Write(status) //nolint:errcheck
+}
+
+func TestDropPrivileges(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing user requires root")
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDropPrivilegesHelper$")
+	cmd.Env = append(os.Environ(), "JOBBER_RUNAS_HELPER=1")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	// The real, effective, saved and filesystem IDs are all changed, and
+	// root's supplementary groups and capabilities are gone.
+	status := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			status[k] = strings.TrimSpace(v)
+		}
+	}
+	require.Equal(t, "65534\t65534\t65534\t65534", status["Uid"])
+	require.Equal(t, "65533\t65533\t65533\t65533", status["Gid"])
+	require.Empty(t, status["Groups"])
+	require.Equal(t, "0000000000000000", status["CapEff"])
+}
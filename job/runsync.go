@@ -0,0 +1,90 @@
+package job
+
+import (
+	"context"
+	"time"
+)
+
+// SyncResult is the result of running a job to completion with RunSync.
+type SyncResult struct {
+	JobDescription
+
+	// Output is the job's output, up to the tracker's maximum sync output.
+	Output []byte
+	// OutputTruncated is set if the job output more than was returned.
+	OutputTruncated bool
+	// TimedOut is set if the job was stopped for running past its timeout.
+	TimedOut bool
+	// DeadlineExceeded is set if the job was stopped because it would
+	// otherwise have run past the deadline of the caller's context.
+	DeadlineExceeded bool
+}
+
+// syncDeadlineMargin is how long before the deadline of the caller's
+// context a job run with RunSync is stopped, leaving time for the result to
+// reach the caller before the deadline passes.
+var syncDeadlineMargin = 100 * time.Millisecond
+
+// RunSync runs the given job to completion and returns its result, which
+// includes its output. The job is stopped if it is still running after
+// timeout, or the tracker's maximum sync timeout if that is shorter or
+// timeout is not positive. If ctx has a deadline sooner than that, the job
+// is instead stopped shortly before the deadline so the result can still be
+// returned, and the result says which of the two stopped it. The job is
+// removed from the tracker once it completes, so it is only ever seen
+// through the result. Warnings are as returned by Start.
+func (t *Tracker) RunSync(ctx context.Context, spec JobSpec, timeout time.Duration) (SyncResult, []string, error) {
+	user, ok := GetUserFromContext(ctx)
+	if !ok {
+		return SyncResult{}, nil, ErrUnauthorized
+	}
+	if timeout <= 0 || timeout > t.maxSyncTimeout {
+		timeout = t.maxSyncTimeout
+	}
+
+	id, warnings, err := t.Start(ctx, spec)
+	if err != nil {
+		return SyncResult{}, nil, err
+	}
+	// Stop and clean up the job without ctx, as it may be cancelled.
+	ownerCtx := AddUserToContext(context.Background(), user)
+	defer func() { _ = t.Stop(ownerCtx, id, true /* cleanup */) }()
+
+	deadline, byCaller := time.Now().Add(timeout), false
+	if d, ok := ctx.Deadline(); ok && d.Add(-syncDeadlineMargin).Before(deadline) {
+		deadline, byCaller = d.Add(-syncDeadlineMargin), true
+	}
+	runCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	logs, err := t.GetLogChannel(id, true /* follow */, 0, runCtx)
+	if err != nil {
+		return SyncResult{}, nil, err
+	}
+
+	var result SyncResult
+	for l := range logs {
+		room := t.maxSyncOutput - len(result.Output)
+		if len(l.Line) > room {
+			result.Output = append(result.Output, l.Line[:room]...)
+			result.OutputTruncated = true
+			continue
+		}
+		result.Output = append(result.Output, l.Line...)
+	}
+
+	if runCtx.Err() != nil {
+		if ctx.Err() != nil {
+			return SyncResult{}, nil, ctx.Err()
+		}
+		result.TimedOut, result.DeadlineExceeded = !byCaller, byCaller
+		if err := t.Stop(ownerCtx, id, false /* cleanup */); err != nil {
+			return SyncResult{}, nil, err
+		}
+	}
+
+	result.JobDescription, err = t.Wait(ownerCtx, id)
+	if err != nil {
+		return SyncResult{}, nil, err
+	}
+	return result, warnings, nil
+}
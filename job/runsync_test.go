@@ -0,0 +1,87 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSync(t *testing.T) {
+	tests := map[string]struct {
+		script    string
+		timeout   time.Duration
+		output    string
+		exitCode  uint32
+		truncated bool
+		timedOut  bool
+		deadline  time.Duration
+		exceeded  bool
+	}{
+		"success": {
+			script: "exec 2>&1; echo hello; echo world",
+			output: "hello\nworld\n",
+		},
+		"failure": {
+			script:   "exec 2>&1; echo oops; exit 3",
+			output:   "oops\n",
+			exitCode: 3,
+		},
+		"output cap exceeded": {
+			script:    "exec 2>&1; echo 0123456789; echo abcdefghij; echo more",
+			output:    "0123456789\nabcd",
+			truncated: true,
+		},
+		"timed out": {
+			script:   "exec 2>&1; echo started; exec sleep 60",
+			timeout:  100 * time.Millisecond,
+			output:   "started\n",
+			exitCode: 128 + 9, // SIGKILL
+			timedOut: true,
+		},
+		"caller deadline sooner than timeout": {
+			script:   "exec 2>&1; echo started; exec sleep 60",
+			timeout:  time.Minute,
+			deadline: 300 * time.Millisecond,
+			output:   "started\n",
+			exitCode: 128 + 9, // SIGKILL
+			exceeded: true,
+		},
+		"timeout sooner than caller deadline": {
+			script:   "exec 2>&1; echo started; exec sleep 60",
+			timeout:  100 * time.Millisecond,
+			deadline: time.Minute,
+			output:   "started\n",
+			exitCode: 128 + 9, // SIGKILL
+			timedOut: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tracker := newTestTracker(tc.script, WithSyncLimits(time.Minute, 15))
+			userCtx := AddUserToContext(context.Background(), "eve")
+			ctx := userCtx
+			if tc.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(userCtx, tc.deadline)
+				defer cancel()
+			}
+
+			result, _, err := tracker.RunSync(ctx, JobSpec{Command: "/bin/sh"}, tc.timeout)
+			require.NoError(t, err)
+			require.NoError(t, ctx.Err(), "result returned after the deadline")
+			require.Equal(t, tc.output, string(result.Output))
+			require.Equal(t, tc.truncated, result.OutputTruncated)
+			require.Equal(t, tc.timedOut, result.TimedOut)
+			require.Equal(t, tc.exceeded, result.DeadlineExceeded)
+			require.Equal(t, JobState(JobStateCompleted), result.Status.State)
+			require.Equal(t, tc.exitCode, result.Status.ExitCode)
+
+			// The job is not kept once it has completed.
+			_, err = tracker.Get(userCtx, result.ID)
+			require.ErrorIs(t, err, ErrUnknown)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package job
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// runSequence runs each of commands in turn, stopping at the first that
+// does not exit successfully. The output of the commands goes to the job's
+// output.
+//
+// errFile is closed once the first command has started, signalling a
+// successful start to ExecPart1. runSequence only returns if the first
+// command could not be started. Otherwise it exits the process with the
+// exit status of the last command run. A failure is noted in the job's
+// output, as is a later command that could not be started.
+func runSequence(commands [][]string, errFile io.Closer) error {
+	for i, argv := range commands {
+		cmd := &exec.Cmd{
+			Path:   argv[0],
+			Args:   append([]string{filepath.Base(argv[0])}, argv[1:]...),
+			Env:    []string{},
+			Stdout: os.Stdout,
+			Stderr: os.Stderr, // the job's stderr
+		}
+		if err := cmd.Start(); err != nil {
+			if i == 0 {
+				return fmt.Errorf("could not exec %s: %w", argv[0], err)
+			}
+			fmt.Fprintf(os.Stdout, "[jobber] command %d (%s) could not be run: %v\n", i+1, argv[0], err)
+			os.Exit(127)
+		}
+		if i == 0 {
+			errFile.Close()
+		}
+
+		err := cmd.Wait()
+		exitErr, ok := err.(*exec.ExitError)
+		if err != nil && !ok {
+			fmt.Fprintf(os.Stdout, "[jobber] command %d (%s) failed: %v\n", i+1, argv[0], err)
+			os.Exit(127)
+		}
+		if err == nil {
+			continue
+		}
+
+		status := JobStatus{ExitCode: exitCode(exitErr)}
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			status.Signal = ws.Signal()
+		}
+		fmt.Fprintf(os.Stdout, "[jobber] command %d (%s) %s\n", i+1, argv[0], status.ExitReason())
+		os.Exit(int(status.ExitCode))
+	}
+	os.Exit(0)
+	return nil // NOTREACHED
+}
@@ -0,0 +1,81 @@
+package job
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunSequenceHelper is not a real test. It is run as a subprocess by
+// TestRunSequence as runSequence exits the process.
+func TestRunSequenceHelper(t *testing.T) {
+	commands := os.Getenv("JOBBER_SEQUENCE_HELPER")
+	if commands == "" {
+		t.Skip("helper process")
+	}
+	var seq [][]string
+	require.NoError(t, json.Unmarshal([]byte(commands), &seq))
+	errFile, err := os.Open(os.DevNull)
+	require.NoError(t, err)
+	err = runSequence(seq, errFile)
+	require.NoError(t, err)
+}
+
+func TestRunSequence(t *testing.T) {
+	tests := map[string]struct {
+		commands [][]string
+		output   string
+		exitCode int
+	}{
+		"all succeed": {
+			commands: [][]string{
+				{"/bin/sh", "-c", "echo one"},
+				{"/bin/sh", "-c", "echo two"},
+			},
+			output: "one\ntwo\n",
+		},
+		"stop on failure": {
+			commands: [][]string{
+				{"/bin/sh", "-c", "echo one"},
+				{"/bin/sh", "-c", "echo two; exit 3"},
+				{"/bin/sh", "-c", "echo three"},
+			},
+			output:   "one\ntwo\n[jobber] command 2 (/bin/sh) exited with code 3\n",
+			exitCode: 3,
+		},
+		"later command not found": {
+			commands: [][]string{
+				{"/bin/sh", "-c", "echo one"},
+				{"/nonexistent"},
+			},
+			output:   "one\n[jobber] command 2 (/nonexistent) could not be run: fork/exec /nonexistent: no such file or directory\n",
+			exitCode: 127,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			commands, err := json.Marshal(tc.commands)
+			require.NoError(t, err)
+			cmd := exec.Command(os.Args[0], "-test.run=^TestRunSequenceHelper$")
+			cmd.Env = append(os.Environ(), "JOBBER_SEQUENCE_HELPER="+string(commands))
+			out, err := cmd.Output()
+			require.Equal(t, tc.output, string(out))
+			if tc.exitCode == 0 {
+				require.NoError(t, err)
+				return
+			}
+			var exitErr *exec.ExitError
+			require.ErrorAs(t, err, &exitErr)
+			require.Equal(t, tc.exitCode, exitErr.ExitCode())
+		})
+	}
+}
+
+func TestRunSequenceStartFailure(t *testing.T) {
+	err := runSequence([][]string{{"/nonexistent"}}, nil)
+	require.ErrorContains(t, err, "could not exec /nonexistent")
+}
@@ -0,0 +1,357 @@
+package job
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNotSpooled is returned when reading the spooled output of a job whose
+// output is not spooled.
+var ErrNotSpooled = errors.New("job output is not spooled")
+
+// Spool keeps the output of jobs on disk as well as in memory. Each job's
+// output is written to a series of segment files in a directory of its own
+// under the spool directory. Once the segment being written reaches the
+// rotate size, it is closed and compressed with gzip and a new segment is
+// started. If the spool grows past its maximum size, the oldest compressed
+// segments of all jobs are removed until it fits. The segment being written
+// by a job is never removed. The segments may be encrypted, with keys set
+// with Encrypt.
+type Spool struct {
+	dir        string
+	rotateSize int64
+	maxSize    int64
+	// keys, if not nil, encrypt the segments of the spool.
+	keys *SpoolKeys
+
+	// mu serialises removing segments to keep the spool under maxSize.
+	mu sync.Mutex
+	// compressing tracks segments being compressed, so tests can wait
+	// for them.
+	compressing sync.WaitGroup
+}
+
+// Segment file names are the segment's sequence number with these
+// suffixes. A segment is written with segmentSuffix and renamed with
+// compressedSuffix once compressed.
+const (
+	segmentSuffix    = ".log"
+	compressedSuffix = ".log.gz"
+)
+
+// NewSpool returns a spool that writes job output under dir, creating dir
+// if it does not exist. Segments are rotated once they reach rotateSize
+// bytes, and the oldest segments are removed while the spool is larger
+// than maxSize bytes. A zero maxSize is no maximum.
+func NewSpool(dir string, rotateSize, maxSize int64) (*Spool, error) {
+	if rotateSize <= 0 {
+		return nil, errors.New("spool rotate size must be positive")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("could not create spool directory: %w", err)
+	}
+	return &Spool{dir: dir, rotateSize: rotateSize, maxSize: maxSize}, nil
+}
+
+// jobDir returns the directory of the segments of the job identified by id.
+// The ID is escaped as it is for the job's cgroup.
+func (s *Spool) jobDir(id string) string {
+	return filepath.Join(s.dir, filepath.Base(cgroupDir(id)))
+}
+
+// spoolWriter writes the output of one job to its segments in a spool.
+type spoolWriter struct {
+	spool *Spool
+	id    string
+	dir   string
+	seq   int
+	f     *os.File
+	size  int64
+	// aead, if not nil, encrypts the segments with the key keyID.
+	aead  cipher.AEAD
+	keyID string
+}
+
+// create starts spooling the output of the job identified by id, removing
+// any output spooled for a previous job with the same ID.
+func (s *Spool) create(id string) (*spoolWriter, error) {
+	dir := s.jobDir(id)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.Mkdir(dir, 0700); err != nil {
+		return nil, err
+	}
+	w := &spoolWriter{spool: s, id: id, dir: dir}
+	if s.keys != nil {
+		w.keyID = s.keys.current
+		w.aead = s.keys.aeads[w.keyID]
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *spoolWriter) segmentName(seq int) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%08d%s", seq, segmentSuffix))
+}
+
+// open starts the next segment. An encrypted segment starts with the ID of
+// the key it is encrypted with.
+func (w *spoolWriter) open() error {
+	w.seq++
+	f, err := os.OpenFile(w.segmentName(w.seq), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	w.f, w.size = f, 0
+	if w.aead != nil {
+		n, err := io.WriteString(f, encryptedSegmentPrefix+w.keyID+"\n")
+		w.size += int64(n)
+		return err
+	}
+	return nil
+}
+
+// write appends l to the job's current segment, rotating it first if it has
+// reached the spool's rotate size. Each log is written with a single write
+// so it can be read back as soon as write returns.
+func (w *spoolWriter) write(l Log) error {
+	if w.size >= w.spool.rotateSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if w.aead != nil {
+		if b, err = encryptLog(w.aead, w.keyID, w.id, l.Index, b); err != nil {
+			return err
+		}
+	}
+	n, err := w.f.Write(append(b, '\n'))
+	w.size += int64(n)
+	return err
+}
+
+// rotate closes the current segment, compresses it in the background and
+// starts a new segment.
+func (w *spoolWriter) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	name := w.f.Name()
+	w.spool.compressing.Add(1)
+	go func() {
+		defer w.spool.compressing.Done()
+		if err := compressSegment(name); err != nil {
+			// XXX Should log, but no logger yet
+			fmt.Fprintf(os.Stderr, "could not compress spool segment %s: %v\n", name, err)
+			return
+		}
+		w.spool.enforceMaxSize()
+	}()
+	return w.open()
+}
+
+// close closes the job's current segment. It is left uncompressed.
+func (w *spoolWriter) close() error {
+	return w.f.Close()
+}
+
+// compressSegment compresses the segment file name, replacing it with a
+// compressed segment. Readers see either the segment or the compressed
+// segment in full, as the compressed segment is written under a temporary
+// name and renamed before the segment is removed.
+func compressSegment(name string) error {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	gzName := strings.TrimSuffix(name, segmentSuffix) + compressedSuffix
+	tmpName := gzName + ".tmp"
+	out, err := os.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, gzName); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+// enforceMaxSize removes the oldest compressed segments in the spool while
+// it is larger than its maximum size.
+func (s *Spool) enforceMaxSize() {
+	if s.maxSize == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type segment struct {
+		path string
+		info fs.FileInfo
+	}
+	var total int64
+	var compressed []segment
+	_ = filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		total += info.Size()
+		if strings.HasSuffix(path, compressedSuffix) {
+			compressed = append(compressed, segment{path, info})
+		}
+		return nil
+	})
+
+	sort.Slice(compressed, func(i, j int) bool {
+		return compressed[i].info.ModTime().Before(compressed[j].info.ModTime())
+	})
+	for _, seg := range compressed {
+		if total <= s.maxSize {
+			break
+		}
+		if err := os.Remove(seg.path); err == nil {
+			total -= seg.info.Size()
+		}
+	}
+}
+
+// read returns the spooled output of the job identified by id from the log
+// with index start, reading across the job's segments whether compressed
+// or not. If earlier segments have been removed to keep the spool under its
+// maximum size, the output returned starts with the oldest log remaining.
+func (s *Spool) read(id string, start int) ([]Log, error) {
+	dir := s.jobDir(id)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// A segment being compressed exists both compressed and not, so
+	// collect the segments by sequence number.
+	segments := map[int]string{}
+	for _, e := range entries {
+		name := e.Name()
+		var seqStr string
+		switch {
+		case strings.HasSuffix(name, compressedSuffix):
+			seqStr = strings.TrimSuffix(name, compressedSuffix)
+		case strings.HasSuffix(name, segmentSuffix):
+			seqStr = strings.TrimSuffix(name, segmentSuffix)
+		default:
+			continue
+		}
+		seq, err := strconv.Atoi(seqStr)
+		if err != nil {
+			continue
+		}
+		if _, ok := segments[seq]; !ok || strings.HasSuffix(name, compressedSuffix) {
+			segments[seq] = filepath.Join(dir, name)
+		}
+	}
+	seqs := make([]int, 0, len(segments))
+	for seq := range segments {
+		seqs = append(seqs, seq)
+	}
+	sort.Ints(seqs)
+
+	var logs []Log
+	for _, seq := range seqs {
+		segLogs, err := readSegment(segments[seq], id, s.keys)
+		if errors.Is(err, fs.ErrNotExist) {
+			// Removed or compressed since the directory was read.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range segLogs {
+			if l.Index >= start {
+				logs = append(logs, l)
+			}
+		}
+	}
+	return logs, nil
+}
+
+// readSegment reads the logs in a segment file of the job identified by id,
+// which is decompressed if it is a compressed segment and decrypted with
+// keys if it is encrypted.
+func readSegment(name, id string, keys *SpoolKeys) ([]Log, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(name, compressedSuffix) {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	br := bufio.NewReader(r)
+	if prefix, err := br.Peek(len(encryptedSegmentPrefix)); err == nil && string(prefix) == encryptedSegmentPrefix {
+		_, _ = br.Discard(len(prefix))
+		return readEncryptedSegment(name, id, br, keys)
+	}
+
+	var logs []Log
+	dec := json.NewDecoder(br)
+	for {
+		var l Log
+		err := dec.Decode(&l)
+		if err == io.EOF {
+			return logs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		logs = append(logs, l)
+	}
+}
+
+// remove removes the spooled output of the job identified by id.
+func (s *Spool) remove(id string) error {
+	return os.RemoveAll(s.jobDir(id))
+}
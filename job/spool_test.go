@@ -0,0 +1,352 @@
+package job
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeSpool(t *testing.T, s *Spool, id string, n int) *spoolWriter {
+	t.Helper()
+	w, err := s.create(id)
+	require.NoError(t, err)
+	for i := 0; i < n; i++ {
+		l := Log{Timestamp: time.Unix(int64(i), 0).UTC(), Line: []byte(fmt.Sprintf("line %d\n", i)), Index: i}
+		require.NoError(t, w.write(l))
+	}
+	return w
+}
+
+func segmentNames(t *testing.T, s *Spool, id string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(s.jobDir(id))
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestSpoolRotation(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 200, 0)
+	require.NoError(t, err)
+	w := writeSpool(t, s, "test-1", 20)
+	s.compressing.Wait()
+
+	// All but the segment being written are compressed.
+	names := segmentNames(t, s, "test-1")
+	require.Greater(t, len(names), 2)
+	for _, name := range names[:len(names)-1] {
+		require.True(t, strings.HasSuffix(name, compressedSuffix), name)
+	}
+	require.True(t, strings.HasSuffix(names[len(names)-1], segmentSuffix))
+
+	// Logs are read back in order across the segments, including from
+	// the segment still being written.
+	logs, err := s.read("test-1", 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 20)
+	for i, l := range logs {
+		require.Equal(t, i, l.Index)
+		require.Equal(t, fmt.Sprintf("line %d\n", i), string(l.Line))
+		require.Equal(t, time.Unix(int64(i), 0).UTC(), l.Timestamp)
+	}
+
+	logs, err = s.read("test-1", 15)
+	require.NoError(t, err)
+	require.Len(t, logs, 5)
+	require.Equal(t, 15, logs[0].Index)
+
+	require.NoError(t, w.close())
+	require.NoError(t, s.remove("test-1"))
+	_, err = s.read("test-1", 0)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestSpoolMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewSpool(dir, 200, 1000)
+	require.NoError(t, err)
+	w1 := writeSpool(t, s, "test-1", 100)
+	w2 := writeSpool(t, s, "test-2", 100)
+	s.compressing.Wait()
+	defer w1.close()
+	defer w2.close()
+
+	var total int64
+	err = filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return err
+	})
+	require.NoError(t, err)
+	require.LessOrEqual(t, total, int64(1000))
+
+	// The oldest output is gone, but the latest is still there.
+	for _, id := range []string{"test-1", "test-2"} {
+		logs, err := s.read(id, 0)
+		require.NoError(t, err)
+		require.NotEmpty(t, logs)
+		require.Greater(t, logs[0].Index, 0)
+		require.Equal(t, 99, logs[len(logs)-1].Index)
+	}
+}
+
+func TestJobSpool(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 100, 0)
+	require.NoError(t, err)
+	j := NewJob("test-1", JobSpec{Command: "/bin/sh"}, shellArgMaker("exec 2>&1; for i in $(seq 50); do echo line $i; done"))
+	j.noNamespaces = true
+	j.spool = s
+	require.NoError(t, j.Start("owner"))
+
+	var output string
+	for l := range j.AttachOutfeed(true /* follow */, 0, nil) {
+		output += string(l.Line)
+	}
+	<-j.reaped
+	s.compressing.Wait()
+
+	logs, err := j.SpooledLogs(0)
+	require.NoError(t, err)
+	var spooled string
+	for _, l := range logs {
+		spooled += string(l.Line)
+	}
+	require.Equal(t, output, spooled)
+	require.Contains(t, spooled, "line 50\n")
+
+	// Cleaning up the job removes its spooled output.
+	j.Cleanup()
+	_, err = j.SpooledLogs(0)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = NewJob("test-2", JobSpec{}, nil).SpooledLogs(0)
+	require.ErrorIs(t, err, ErrNotSpooled)
+}
+
+func TestJobSpoolServesDroppedLogs(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 100, 0)
+	require.NoError(t, err)
+	j := NewJob("test-1", JobSpec{Command: "/bin/sh"}, shellArgMaker("for i in $(seq 0 49); do echo line $i; done"))
+	j.noNamespaces = true
+	j.spool = s
+	j.maxLogLines = 10
+	require.NoError(t, j.Start("owner"))
+	t.Cleanup(j.Cleanup)
+	<-j.reaped
+	require.Eventually(t, func() bool { return j.FeederStats().InfeedClosed }, time.Second, 10*time.Millisecond)
+	require.Equal(t, 40, j.FeederStats().Dropped)
+
+	requireLines := func(t *testing.T, logs []Log, from, to int) {
+		t.Helper()
+		require.Len(t, logs, to-from)
+		for i, l := range logs {
+			require.Equal(t, from+i, l.Index)
+			require.Equal(t, fmt.Sprintf("line %d\n", from+i), string(l.Line))
+			require.Zero(t, l.Dropped)
+		}
+	}
+
+	// A page starting before the first log still buffered is read from
+	// the spool, whether or not it reaches the buffer.
+	page := j.LogsPage(5, 45)
+	require.Equal(t, 50, page.Total)
+	requireLines(t, page.Logs, 5, 45)
+	requireLines(t, j.LogsPage(0, 20).Logs, 0, 20)
+	requireLines(t, j.LogsPage(42, 0).Logs, 42, 50)
+
+	var logs []Log
+	for l := range j.AttachOutfeed(false /* follow */, 0, nil) {
+		logs = append(logs, l)
+	}
+	requireLines(t, logs, 0, 50)
+	logs = nil
+	for l := range j.AttachOutfeed(false /* follow */, -15, nil) {
+		logs = append(logs, l)
+	}
+	requireLines(t, logs, 35, 50)
+
+	// Encrypted spooled logs are decrypted when served.
+	s.Encrypt(writeSpoolKeys(t, "k1"))
+	j2 := NewJob("test-2", JobSpec{Command: "/bin/sh"}, shellArgMaker("for i in $(seq 0 49); do echo line $i; done"))
+	j2.noNamespaces = true
+	j2.spool = s
+	j2.maxLogLines = 10
+	require.NoError(t, j2.Start("owner"))
+	t.Cleanup(j2.Cleanup)
+	<-j2.reaped
+	require.Eventually(t, func() bool { return j2.FeederStats().InfeedClosed }, time.Second, 10*time.Millisecond)
+	requireLines(t, j2.LogsPage(0, 0).Logs, 0, 50)
+
+	// Without a spool, the dropped logs are counted but not served.
+	j.spool = nil
+	page = j.LogsPage(5, 45)
+	require.Len(t, page.Logs, 5)
+	require.Equal(t, 40, page.Logs[0].Index)
+	require.Equal(t, 35, page.Logs[0].Dropped)
+}
+
+// writeSpoolKeys writes a spool key file with a random key for each of ids
+// and loads it.
+func writeSpoolKeys(t *testing.T, ids ...string) *SpoolKeys {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("# spool keys, current first\n\n")
+	for _, id := range ids {
+		key := make([]byte, 32)
+		_, err := rand.Read(key)
+		require.NoError(t, err)
+		fmt.Fprintf(&b, "%s %s\n", id, base64.StdEncoding.EncodeToString(key))
+	}
+	path := filepath.Join(t.TempDir(), "keys")
+	require.NoError(t, os.WriteFile(path, []byte(b.String()), 0600))
+	keys, err := LoadSpoolKeys(path)
+	require.NoError(t, err)
+	return keys
+}
+
+func TestSpoolEncryption(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 200, 0)
+	require.NoError(t, err)
+	keys := writeSpoolKeys(t, "k1")
+	s.Encrypt(keys)
+	w := writeSpool(t, s, "test-1", 20)
+	defer w.close()
+	s.compressing.Wait()
+
+	// Both compressed and uncompressed segments are encrypted, and are
+	// tagged with the key they are encrypted with.
+	names := segmentNames(t, s, "test-1")
+	require.Greater(t, len(names), 2)
+	for _, name := range names {
+		segment, err := readSegment(filepath.Join(s.jobDir("test-1"), name), "test-1", nil)
+		require.ErrorContains(t, err, "encrypted with key k1, which is not loaded")
+		require.Nil(t, segment)
+	}
+	b, err := os.ReadFile(filepath.Join(s.jobDir("test-1"), names[len(names)-1]))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(b), encryptedSegmentPrefix+"k1\n"))
+	require.NotContains(t, string(b), "line")
+
+	logs, err := s.read("test-1", 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 20)
+	for i, l := range logs {
+		require.Equal(t, i, l.Index)
+		require.Equal(t, fmt.Sprintf("line %d\n", i), string(l.Line))
+		require.Equal(t, time.Unix(int64(i), 0).UTC(), l.Timestamp)
+	}
+
+	// Segments encrypted with a key that is not loaded, or not encrypted
+	// with the key they claim, cannot be read.
+	s.Encrypt(writeSpoolKeys(t, "k2"))
+	_, err = s.read("test-1", 0)
+	require.ErrorContains(t, err, "encrypted with key k1, which is not loaded")
+	s.Encrypt(writeSpoolKeys(t, "k1"))
+	_, err = s.read("test-1", 0)
+	require.ErrorContains(t, err, "could not decrypt log")
+}
+
+func TestSpoolEncryptionBindsPosition(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 1<<20, 0)
+	require.NoError(t, err)
+	s.Encrypt(writeSpoolKeys(t, "k1"))
+	for _, id := range []string{"test-1", "test-2"} {
+		require.NoError(t, writeSpool(t, s, id, 3).close())
+	}
+	segment := func(id string) string {
+		return filepath.Join(s.jobDir(id), segmentNames(t, s, id)[0])
+	}
+	readLines := func(id string) []string {
+		b, err := os.ReadFile(segment(id))
+		require.NoError(t, err)
+		return strings.SplitAfter(string(b), "\n")
+	}
+	lines1, lines2 := readLines("test-1"), readLines("test-2")
+	tamper := func(lines ...string) error {
+		require.NoError(t, os.WriteFile(segment("test-1"), []byte(strings.Join(lines, "")), 0600))
+		_, err := s.read("test-1", 0)
+		return err
+	}
+
+	// Lines are the header then "index sealed-log".
+	require.NoError(t, tamper(lines1...))
+	require.ErrorContains(t, tamper(lines1[0], lines2[1], lines1[2], lines1[3]), "could not decrypt log 0")
+	require.ErrorContains(t, tamper(lines1[0], "1"+strings.TrimPrefix(lines1[1], "0"), lines1[3]), "could not decrypt log 1")
+	require.ErrorContains(t, tamper(lines1[0], lines1[2], lines1[1], lines1[3]), "log 0 is out of order after log 1")
+}
+
+func TestSpoolKeyRotation(t *testing.T) {
+	s, err := NewSpool(t.TempDir(), 200, 0)
+	require.NoError(t, err)
+	w := writeSpool(t, s, "plain-1", 5)
+	require.NoError(t, w.close())
+
+	k1 := writeSpoolKeys(t, "k1")
+	s.Encrypt(k1)
+	w = writeSpool(t, s, "test-1", 20)
+	require.NoError(t, w.close())
+
+	// Rotating the keys encrypts new output with the new key, while output
+	// encrypted with the old key and output not encrypted can still be
+	// read.
+	k2 := writeSpoolKeys(t, "k2")
+	k2.aeads["k1"] = k1.aeads["k1"]
+	s.Encrypt(k2)
+	w = writeSpool(t, s, "test-2", 20)
+	require.NoError(t, w.close())
+	s.compressing.Wait()
+
+	names := segmentNames(t, s, "test-2")
+	b, err := os.ReadFile(filepath.Join(s.jobDir("test-2"), names[len(names)-1]))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(b), encryptedSegmentPrefix+"k2\n"))
+
+	for id, n := range map[string]int{"plain-1": 5, "test-1": 20, "test-2": 20} {
+		logs, err := s.read(id, 0)
+		require.NoError(t, err, id)
+		require.Len(t, logs, n, id)
+		require.Equal(t, fmt.Sprintf("line %d\n", n-1), string(logs[n-1].Line))
+	}
+}
+
+func TestLoadSpoolKeys(t *testing.T) {
+	key16 := base64.StdEncoding.EncodeToString(make([]byte, 16))
+	key32 := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	tests := map[string]struct {
+		keys    string
+		current string
+		err     string
+	}{
+		"one key":          {keys: "k1 " + key32 + "\n", current: "k1"},
+		"first is current": {keys: "# keys\n\nk2 " + key16 + "\n  k1\t" + key32, current: "k2"},
+		"no keys":          {keys: "# no keys\n", err: "no keys"},
+		"missing key":      {keys: "k1\n", err: ":1: expected a key ID and key"},
+		"duplicate":        {keys: "k1 " + key32 + "\nk1 " + key16 + "\n", err: ":2: duplicate key ID k1"},
+		"not base64":       {keys: "k1 not-base64!\n", err: ":1: key k1 is not base64"},
+		"wrong size":       {keys: "k1 " + base64.StdEncoding.EncodeToString(make([]byte, 20)) + "\n", err: "invalid key size 20"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys")
+			require.NoError(t, os.WriteFile(path, []byte(tc.keys), 0600))
+			keys, err := LoadSpoolKeys(path)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.current, keys.current)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package job
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SpoolKeys are the AES keys the output of jobs is encrypted with in a
+// spool, by key ID. Segments are encrypted with the current key when they
+// are started. The other keys are kept to read segments started before
+// the keys were rotated.
+type SpoolKeys struct {
+	current string
+	aeads   map[string]cipher.AEAD
+}
+
+// encryptedSegmentPrefix starts the first line of an encrypted segment,
+// followed by the ID of the key it is encrypted with. Each following line
+// is a log encrypted with AES-GCM: the log's index, a space, and the base64
+// encoding of a random nonce followed by the sealed JSON of the log. The
+// key ID, job ID and index are the additional data, so a sealed log cannot
+// be moved to another position, segment or job unnoticed. The lines of a
+// segment that is not encrypted are the JSON of its logs, so never start
+// with the prefix.
+const encryptedSegmentPrefix = "#jobber-encrypted "
+
+// LoadSpoolKeys reads SpoolKeys from the file at path. Each line is a key
+// ID and the base64 encoded key, of 16, 24 or 32 bytes for AES-128, AES-192
+// or AES-256, separated by white space, such as
+//
+//	2022-06 kUT3Qx3zG3zdbTb1TqYUYqC6qk9R3Ak4bC3T8Y3XbVQ=
+//
+// The first key is the current key. Blank lines and lines starting with #
+// are ignored.
+func LoadSpoolKeys(path string) (*SpoolKeys, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys := &SpoolKeys{aeads: map[string]cipher.AEAD{}}
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a key ID and key", path, i+1)
+		}
+		id := fields[0]
+		if _, ok := keys.aeads[id]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate key ID %s", path, i+1, id)
+		}
+		key, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: key %s is not base64: %w", path, i+1, id, err)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: key %s: %w", path, i+1, id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		keys.aeads[id] = aead
+		if keys.current == "" {
+			keys.current = id
+		}
+	}
+	if keys.current == "" {
+		return nil, fmt.Errorf("%s: no keys", path)
+	}
+	return keys, nil
+}
+
+// Encrypt makes the spool encrypt the segments it starts from now on with
+// the current key of keys, and read segments encrypted with any of them.
+// Segments that are not encrypted can still be read.
+func (s *Spool) Encrypt(keys *SpoolKeys) {
+	s.keys = keys
+}
+
+// logAAD returns the additional data a log is sealed with: the ID of the
+// key it is encrypted with, the ID of the job it is from and its index.
+func logAAD(keyID, jobID string, index int) []byte {
+	return []byte(keyID + "\x00" + jobID + "\x00" + strconv.Itoa(index))
+}
+
+// encryptLog returns the line of an encrypted segment holding the JSON b of
+// the log with index index from the job jobID, encrypted with aead for the
+// key keyID.
+func encryptLog(aead cipher.AEAD, keyID, jobID string, index int, b []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, b, logAAD(keyID, jobID, index))
+	line := []byte(strconv.Itoa(index) + " ")
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(sealed)))
+	base64.StdEncoding.Encode(encoded, sealed)
+	return append(line, encoded...), nil
+}
+
+// readEncryptedSegment reads the logs of the encrypted segment name of the
+// job jobID from r, positioned after the prefix of its first line.
+func readEncryptedSegment(name, jobID string, r *bufio.Reader, keys *SpoolKeys) ([]Log, error) {
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	keyID := strings.TrimSuffix(header, "\n")
+	var aead cipher.AEAD
+	if keys != nil {
+		aead = keys.aeads[keyID]
+	}
+	if aead == nil {
+		return nil, fmt.Errorf("%s: encrypted with key %s, which is not loaded", name, keyID)
+	}
+
+	var logs []Log
+	prev := -1
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			return logs, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		indexStr, encoded, ok := strings.Cut(string(bytes.TrimSuffix(line, []byte("\n"))), " ")
+		if !ok {
+			return nil, fmt.Errorf("%s: log without an index", name)
+		}
+		index, err := strconv.Atoi(indexStr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if index <= prev {
+			return nil, fmt.Errorf("%s: log %d is out of order after log %d", name, index, prev)
+		}
+		prev = index
+		sealed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if len(sealed) < aead.NonceSize() {
+			return nil, fmt.Errorf("%s: truncated log", name)
+		}
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		b, err := aead.Open(nil, nonce, ciphertext, logAAD(keyID, jobID, index))
+		if err != nil {
+			return nil, fmt.Errorf("%s: could not decrypt log %d: %w", name, index, err)
+		}
+		var l Log
+		if err := json.Unmarshal(b, &l); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if l.Index != index {
+			return nil, fmt.Errorf("%s: log %d is sealed as log %d", name, l.Index, index)
+		}
+		logs = append(logs, l)
+	}
+}
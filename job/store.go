@@ -0,0 +1,267 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrServerRestarted is the exit error of a restored job that had not
+// completed when it was stored, as it was lost when the server stopped.
+var ErrServerRestarted = errors.New("server restarted while job was running")
+
+// Store keeps the descriptions and logs of jobs so that a tracker can
+// restore them when the server restarts.
+type Store interface {
+	// Save stores the description and logs of a job, replacing any
+	// stored for the job before.
+	Save(jd JobDescription, logs []Log) error
+	// Load returns the stored jobs. If some could not be loaded, it
+	// returns the rest along with an error.
+	Load() ([]StoredJob, error)
+	// Remove removes a stored job. Removing a job that is not stored is
+	// not an error.
+	Remove(id string) error
+}
+
+// StoredJob is the description and logs of a job kept in a Store.
+type StoredJob struct {
+	Description JobDescription
+	Logs        []Log
+}
+
+// FileStore is a Store that keeps each job in a JSON file in a directory.
+type FileStore struct {
+	dir string
+	// groupReadable makes the job files readable by their group.
+	groupReadable bool
+}
+
+// NewFileStore returns a FileStore keeping jobs in dir, creating it if it
+// does not exist. The directory and job files are private to the user the
+// server runs as, unless shared with ShareWithGroup.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("could not create state directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// ShareWithGroup makes the store's directory and the job files in it,
+// including those saved from now on, readable by their group, so a
+// read-only replica can share them without running as the same user.
+func (s *FileStore) ShareWithGroup() error {
+	if err := os.Chmod(s.dir, 0o750); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), storedJobSuffix) {
+			continue
+		}
+		if err := os.Chmod(filepath.Join(s.dir, entry.Name()), 0o640); err != nil {
+			return err
+		}
+	}
+	s.groupReadable = true
+	return nil
+}
+
+// storedJobFile is the contents of a job's file in a FileStore. As errors
+// cannot be unmarshalled, the job's exit error is kept as its message and
+// the sentinel error it wraps, if any.
+type storedJobFile struct {
+	Description JobDescription
+	ExitError   string `json:",omitempty"`
+	ExitErrorIs string `json:",omitempty"`
+	Logs        []Log
+}
+
+// storedSentinels are the errors a job's exit error may wrap that are
+// still matched by errors.Is once it is restored from a FileStore.
+var storedSentinels = []error{ErrTimedOut, ErrServerRestarted, ErrNotStarted, ErrCancelled, ErrShutdown}
+
+// storedError is a job's exit error restored from a FileStore.
+type storedError struct {
+	msg string
+	is  error
+}
+
+func (e storedError) Error() string { return e.msg }
+
+func (e storedError) Is(target error) bool { return e.is != nil && target == e.is }
+
+const storedJobSuffix = ".json"
+
+// filename returns the name of the file that the job identified by id is
+// kept in. The ID is escaped, as a namespaced ID contains a slash.
+func (s *FileStore) filename(id string) string {
+	return filepath.Join(s.dir, url.PathEscape(id)+storedJobSuffix)
+}
+
+// Save writes the job's file, replacing it atomically so a job is never
+// left half written.
+func (s *FileStore) Save(jd JobDescription, logs []Log) error {
+	sf := storedJobFile{Description: jd, Logs: logs}
+	if err := jd.Status.ExitError; err != nil {
+		sf.ExitError = err.Error()
+		for _, sentinel := range storedSentinels {
+			if errors.Is(err, sentinel) {
+				sf.ExitErrorIs = sentinel.Error()
+				break
+			}
+		}
+	}
+	b, err := json.Marshal(sf)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if s.groupReadable {
+		if err := f.Chmod(0o640); err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+			return err
+		}
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.filename(jd.ID))
+}
+
+// Load reads all the job files in the store's directory.
+func (s *FileStore) Load() ([]StoredJob, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []StoredJob
+	var errs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, storedJobSuffix) {
+			continue
+		}
+		sj, err := s.load(filepath.Join(s.dir, name))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		jobs = append(jobs, sj)
+	}
+	if len(errs) > 0 {
+		return jobs, fmt.Errorf("could not load jobs: %s", strings.Join(errs, "; "))
+	}
+	return jobs, nil
+}
+
+func (s *FileStore) load(filename string) (StoredJob, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return StoredJob{}, err
+	}
+	var sf storedJobFile
+	if err := json.Unmarshal(b, &sf); err != nil {
+		return StoredJob{}, err
+	}
+	if sf.ExitError != "" {
+		serr := storedError{msg: sf.ExitError}
+		for _, sentinel := range storedSentinels {
+			if sf.ExitErrorIs == sentinel.Error() {
+				serr.is = sentinel
+				break
+			}
+		}
+		sf.Description.Status.ExitError = serr
+	}
+	return StoredJob{Description: sf.Description, Logs: sf.Logs}, nil
+}
+
+// Remove removes the job's file.
+func (s *FileStore) Remove(id string) error {
+	err := os.Remove(s.filename(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// restoreJob returns a completed job with the description and logs of sj,
+// whose logs can be read as those of a job that ran. A job that had not
+// completed when it was stored is completed now with ErrServerRestarted as
+// its exit error.
+func restoreJob(sj StoredJob, argMaker ArgMaker, budget *logBudget) *Job {
+	j := loadJob(sj, argMaker, budget)
+	if !j.Status.Ended() {
+		j.Status.State = JobStateCompleted
+		j.Status.CompletionTime = time.Now()
+		j.Status.ExitError = ErrServerRestarted
+		j.usage.Memory = 0
+	}
+	return j
+}
+
+// loadJob returns a job with the description and logs of sj as stored,
+// whose logs can be read as those of a job that ran. It cannot be started
+// or stopped, and waiting for it returns at once. Its logs count towards
+// budget, if not nil.
+func loadJob(sj StoredJob, argMaker ArgMaker, budget *logBudget) *Job {
+	jd := sj.Description
+	j := NewJob(jd.ID, jd.Spec, argMaker)
+	j.Status = jd.Status
+	j.usage = jd.Usage
+
+	j.done = make(chan struct{})
+	j.reaped = make(chan struct{})
+	close(j.reaped)
+	infeed := make(chan Log)
+	close(infeed)
+	j.logFeeder = newFeeder(infeed)
+	j.logFeeder.buffer = sj.Logs
+	for _, l := range sj.Logs {
+		j.logFeeder.bufferBytes += len(l.Line)
+	}
+	j.logFeeder.budget = budget
+	// The logs may start after some that were dropped before the job was
+	// stored. Those dropped are counted again when the logs are read.
+	if len(sj.Logs) > 0 {
+		j.logFeeder.dropped = sj.Logs[0].Index
+		j.logFeeder.buffer[0].Dropped = 0
+	}
+	go j.logFeeder.Start(j.done)
+	return j
+}
+
+// persist saves the job's description and logs to its store, if it has
+// one. Saves are serialised so that an earlier description of the job never
+// replaces a later one.
+func (j *Job) persist() {
+	if j.store == nil {
+		return
+	}
+	j.persistMu.Lock()
+	defer j.persistMu.Unlock()
+	if err := j.store.Save(j.Description(), j.LogsPage(0, 0).Logs); err != nil {
+		// XXX Should log, but no logger yet
+		fmt.Fprintf(os.Stderr, "could not store job %s: %v\n", j.ID, err)
+	}
+}
@@ -0,0 +1,172 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "state"))
+	require.NoError(t, err)
+
+	start := time.Date(2022, 5, 27, 12, 0, 0, 0, time.UTC)
+	jd := JobDescription{
+		ID: "eve/sleep-01234567",
+		Spec: JobSpec{
+			Command: "/bin/sleep",
+			Args:    []string{"60"},
+			IONice:  IONice{Class: IOClassBestEffort, Priority: 4},
+			Umask:   Umask{Mask: 0o22, Set: true},
+			Mounts:  []BindMount{{Source: "/srv", Target: "/data", ReadOnly: true}},
+			Timeout: 30 * time.Second,
+			Resources: ResourceLimits{
+				Memory: 1 << 20,
+				CPU:    500,
+				IO:     []DiskIOLimits{{Major: 8, Minor: 0, ReadBPS: 100}},
+			},
+		},
+		Status: JobStatus{
+			StartTime:      start,
+			CompletionTime: start.Add(30 * time.Second),
+			Owner:          "eve",
+			Pid:            4242,
+			State:          JobStateCompleted,
+			Signal:         9,
+			ExitError:      fmt.Errorf("%w after 30s: signal: killed", ErrTimedOut),
+		},
+		Usage: ResourceUsage{PeakMemory: 4096, CPUTime: time.Second},
+	}
+	logs := []Log{
+		{Timestamp: start.Add(time.Second), Line: []byte("hello\n"), Index: 0},
+		{Timestamp: start.Add(2 * time.Second), Line: []byte("oops\n"), Index: 1, Stream: StreamStderr},
+	}
+	require.NoError(t, store.Save(jd, logs))
+
+	stored, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	got := stored[0]
+
+	// The exit error is restored with its message, still matching the
+	// sentinel it wrapped.
+	exitErr := got.Description.Status.ExitError
+	require.EqualError(t, exitErr, "timed out after 30s: signal: killed")
+	require.ErrorIs(t, exitErr, ErrTimedOut)
+	require.True(t, got.Description.Status.TimedOut())
+	got.Description.Status.ExitError = nil
+	jd.Status.ExitError = nil
+	require.Equal(t, jd, got.Description)
+	require.Equal(t, logs, got.Logs)
+
+	require.NoError(t, store.Remove(jd.ID))
+	stored, err = store.Load()
+	require.NoError(t, err)
+	require.Empty(t, stored)
+	require.NoError(t, store.Remove(jd.ID))
+}
+
+func TestFileStoreShareWithGroup(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(JobDescription{ID: "old-1"}, nil))
+	mode := func(name string) os.FileMode {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return fi.Mode().Perm()
+	}
+	// Unless shared, the store is private to its user.
+	require.Equal(t, os.FileMode(0o700), mode(""))
+	require.Equal(t, os.FileMode(0o600), mode("old-1.json"))
+
+	// Sharing it shares the jobs already saved and those saved later.
+	require.NoError(t, store.ShareWithGroup())
+	require.NoError(t, store.Save(JobDescription{ID: "new-1"}, nil))
+	require.Equal(t, os.FileMode(0o750), mode(""))
+	require.Equal(t, os.FileMode(0o640), mode("old-1.json"))
+	require.Equal(t, os.FileMode(0o640), mode("new-1.json"))
+}
+
+func TestFileStoreLoadError(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(JobDescription{ID: "good-1"}, nil))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad-1.json"), []byte("{"), 0o600))
+
+	// The jobs that can be loaded are, along with an error for the rest.
+	stored, err := store.Load()
+	require.ErrorContains(t, err, "bad-1.json")
+	require.Len(t, stored, 1)
+	require.Equal(t, "good-1", stored[0].Description.ID)
+}
+
+func TestTrackerRestore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	tracker := newTestTracker("exec 2>&1; echo hello; echo world", WithStore(store))
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	jd, err := tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+
+	// A job running when the server stopped is lost.
+	lost := JobDescription{
+		ID:     "sleep-01234567",
+		Spec:   JobSpec{Command: "/bin/sleep"},
+		Status: JobStatus{State: JobStateRunning, Owner: "eve", Pid: 4242, StartTime: jd.Status.StartTime},
+	}
+	require.NoError(t, store.Save(lost, []Log{{Line: []byte("lost\n")}}))
+
+	restarted := newTestTracker("exit 0", WithStore(store))
+	got, err := restarted.Get(userCtx, id)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateCompleted), got.Status.State)
+	require.Equal(t, jd.Status.ExitCode, got.Status.ExitCode)
+	require.True(t, jd.Status.CompletionTime.Equal(got.Status.CompletionTime))
+
+	page, err := restarted.LogsPage(userCtx, id, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	require.Equal(t, "world\n", string(page.Logs[1].Line))
+	var lines []string
+	for l := range restarted.jobs[id].AttachOutfeed(true /* follow */, 0, nil) {
+		lines = append(lines, string(l.Line))
+	}
+	require.Equal(t, []string{"hello\n", "world\n"}, lines)
+
+	got, err = restarted.Get(userCtx, lost.ID)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateCompleted), got.Status.State)
+	require.ErrorIs(t, got.Status.ExitError, ErrServerRestarted)
+	require.True(t, got.Status.Failed())
+	require.Equal(t, "server restarted while job was running", got.Status.ExitReason())
+	require.Len(t, restarted.List(userCtx, true /* completed */, false /* all */), 2)
+
+	// A job stored after dropping logs from its buffer still has them
+	// counted.
+	trimmed := JobDescription{ID: "trimmed-01234567", Spec: JobSpec{Command: "/bin/sh"}, Status: JobStatus{State: JobStateCompleted, Owner: "eve"}}
+	require.NoError(t, store.Save(trimmed, []Log{{Line: []byte("six\n"), Index: 5, Dropped: 5}, {Line: []byte("seven\n"), Index: 6}}))
+	page, err = newTestTracker("exit 0", WithStore(store)).LogsPage(userCtx, trimmed.ID, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 7, page.Total)
+	require.Equal(t, []Log{{Line: []byte("six\n"), Index: 5, Dropped: 5}, {Line: []byte("seven\n"), Index: 6}}, page.Logs)
+	require.NoError(t, store.Remove(trimmed.ID))
+
+	// Removing a job removes it from the store too.
+	require.NoError(t, restarted.Stop(userCtx, id, true /* cleanup */))
+	again := newTestTracker("exit 0", WithStore(store))
+	_, err = again.Get(userCtx, id)
+	require.ErrorIs(t, err, ErrUnknown)
+	got, err = again.Get(userCtx, lost.ID)
+	require.NoError(t, err)
+	require.ErrorIs(t, got.Status.ExitError, ErrServerRestarted)
+}
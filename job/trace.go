@@ -0,0 +1,207 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// traceExec runs command under ptrace, writing a line to the job's output
+// for each syscall made by the command (or its descendants) that fails.
+// This is for diagnosing jobs that fail in a container but not on the host.
+//
+// errFile is closed once the command has started, signalling a successful
+// start to ExecPart1. traceExec only returns if the command could not be
+// started. Otherwise it exits the process with the exit status of the
+// command once it and all its descendants have exited.
+func traceExec(command string, argv []string, errFile io.Closer) error {
+	if !traceSupported {
+		return fmt.Errorf("syscall tracing is not supported on %s", runtime.GOARCH)
+	}
+
+	// ptrace requests must all come from the thread that started the
+	// traced process.
+	runtime.LockOSThread()
+
+	cmd := &exec.Cmd{
+		Path:        command,
+		Args:        argv,
+		Env:         []string{},
+		Stdout:      os.Stdout,
+		Stderr:      os.Stderr, // the job's stderr
+		SysProcAttr: &syscall.SysProcAttr{Ptrace: true},
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("could not exec %s: %w", command, err)
+	}
+	errFile.Close()
+
+	t := tracer{out: os.Stdout, inSyscall: make(map[int]bool)}
+	ws, err := t.run(cmd.Process.Pid)
+	if err != nil {
+		fmt.Fprintf(os.Stdout, "[jobber trace] tracing failed: %v\n", err)
+		_ = cmd.Process.Kill()
+		os.Exit(1)
+	}
+	if ws.Signaled() {
+		// Terminate ourselves with the same signal so the job's exit
+		// status reflects that of the command.
+		signal := ws.Signal()
+		_ = syscall.Kill(os.Getpid(), signal)
+		os.Exit(128 + int(signal))
+	}
+	os.Exit(ws.ExitStatus())
+	return nil // NOTREACHED
+}
+
+// tracer follows a traced process and its descendants, reporting failing
+// syscalls.
+type tracer struct {
+	out io.Writer
+	// inSyscall tracks whether each traced task is between the entry
+	// and exit stops of a syscall.
+	inSyscall map[int]bool
+}
+
+const traceOptions = syscall.PTRACE_O_TRACESYSGOOD | syscall.PTRACE_O_TRACECLONE |
+	syscall.PTRACE_O_TRACEFORK | syscall.PTRACE_O_TRACEVFORK | unix.PTRACE_O_EXITKILL
+
+// run traces pid, which must be stopped at its initial exec, until it and
+// all its traced descendants have exited. It returns the wait status of
+// pid.
+func (t *tracer) run(pid int) (syscall.WaitStatus, error) {
+	var ws syscall.WaitStatus
+	if _, err := syscall.Wait4(pid, &ws, 0, nil); err != nil {
+		return ws, err
+	}
+	if err := syscall.PtraceSetOptions(pid, traceOptions); err != nil {
+		return ws, fmt.Errorf("could not set ptrace options: %w", err)
+	}
+	if err := syscall.PtraceSyscall(pid, 0); err != nil {
+		return ws, err
+	}
+
+	var status syscall.WaitStatus
+	for {
+		wpid, err := syscall.Wait4(-1, &ws, syscall.WALL, nil)
+		if errors.Is(err, syscall.ECHILD) {
+			return status, nil // no more traced tasks
+		}
+		if err != nil {
+			return status, err
+		}
+
+		switch {
+		case ws.Exited() || ws.Signaled():
+			delete(t.inSyscall, wpid)
+			if wpid == pid {
+				status = ws
+			}
+			continue
+		case !ws.Stopped():
+			continue
+		}
+
+		signal := 0
+		switch stopSig := ws.StopSignal(); {
+		case stopSig == syscall.SIGTRAP|0x80: // syscall stop
+			t.syscallStop(wpid)
+		case stopSig == syscall.SIGTRAP:
+			// ptrace event stop (clone, fork, exec). New tasks are
+			// traced automatically.
+		case stopSig == syscall.SIGSTOP && !t.traced(wpid):
+			// Initial stop of a new task.
+			t.inSyscall[wpid] = false
+		default:
+			// Deliver the signal to the task.
+			signal = int(stopSig)
+		}
+		// The task may have been killed while stopped, so an error
+		// restarting it is not fatal.
+		_ = syscall.PtraceSyscall(wpid, signal)
+	}
+}
+
+func (t *tracer) traced(pid int) bool {
+	_, ok := t.inSyscall[pid]
+	return ok
+}
+
+// syscallStop handles a syscall entry or exit stop of pid, reporting the
+// syscall if it is an exit from a failed syscall.
+func (t *tracer) syscallStop(pid int) {
+	entering := !t.inSyscall[pid]
+	t.inSyscall[pid] = entering
+	if entering {
+		return
+	}
+
+	var regs syscall.PtraceRegs
+	if err := syscall.PtraceGetRegs(pid, &regs); err != nil {
+		return
+	}
+	nr, ret := syscallResult(&regs)
+	if ret >= 0 || ret < -4095 {
+		return // not an error return
+	}
+	errno := syscall.Errno(-ret)
+	fmt.Fprintf(t.out, "[jobber trace] %d %s() = -1 %s (%v)\n", pid, syscallName(nr), errnoName(errno), errno)
+}
+
+// errnoName returns the symbolic name of errno, such as ENOENT.
+func errnoName(errno syscall.Errno) string {
+	if name, ok := errnoNames[errno]; ok {
+		return name
+	}
+	return fmt.Sprintf("errno %d", int(errno))
+}
+
+var errnoNames = map[syscall.Errno]string{
+	syscall.EPERM:        "EPERM",
+	syscall.ENOENT:       "ENOENT",
+	syscall.ESRCH:        "ESRCH",
+	syscall.EINTR:        "EINTR",
+	syscall.EIO:          "EIO",
+	syscall.ENXIO:        "ENXIO",
+	syscall.E2BIG:        "E2BIG",
+	syscall.ENOEXEC:      "ENOEXEC",
+	syscall.EBADF:        "EBADF",
+	syscall.ECHILD:       "ECHILD",
+	syscall.EAGAIN:       "EAGAIN",
+	syscall.ENOMEM:       "ENOMEM",
+	syscall.EACCES:       "EACCES",
+	syscall.EFAULT:       "EFAULT",
+	syscall.EBUSY:        "EBUSY",
+	syscall.EEXIST:       "EEXIST",
+	syscall.EXDEV:        "EXDEV",
+	syscall.ENODEV:       "ENODEV",
+	syscall.ENOTDIR:      "ENOTDIR",
+	syscall.EISDIR:       "EISDIR",
+	syscall.EINVAL:       "EINVAL",
+	syscall.EMFILE:       "EMFILE",
+	syscall.ENOTTY:       "ENOTTY",
+	syscall.ENOSPC:       "ENOSPC",
+	syscall.ESPIPE:       "ESPIPE",
+	syscall.EROFS:        "EROFS",
+	syscall.EPIPE:        "EPIPE",
+	syscall.ERANGE:       "ERANGE",
+	syscall.ENAMETOOLONG: "ENAMETOOLONG",
+	syscall.ENOSYS:       "ENOSYS",
+	syscall.ENOTEMPTY:    "ENOTEMPTY",
+	syscall.ELOOP:        "ELOOP",
+	syscall.ENODATA:      "ENODATA",
+	syscall.ENOTSOCK:     "ENOTSOCK",
+	syscall.EAFNOSUPPORT: "EAFNOSUPPORT",
+	syscall.EADDRINUSE:   "EADDRINUSE",
+	syscall.ENETUNREACH:  "ENETUNREACH",
+	syscall.ECONNREFUSED: "ECONNREFUSED",
+	syscall.EHOSTUNREACH: "EHOSTUNREACH",
+	syscall.EINPROGRESS:  "EINPROGRESS",
+	syscall.ETIMEDOUT:    "ETIMEDOUT",
+}
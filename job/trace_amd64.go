@@ -0,0 +1,88 @@
+package job
+
+import (
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const traceSupported = true
+
+// syscallResult returns the syscall number and return value from the
+// registers of a task at a syscall exit stop.
+func syscallResult(regs *syscall.PtraceRegs) (nr uint64, ret int64) {
+	return regs.Orig_rax, int64(regs.Rax)
+}
+
+// syscallName returns the name of the syscall numbered nr, or its number
+// for syscalls not commonly relevant to why a job fails.
+func syscallName(nr uint64) string {
+	if name, ok := syscallNames[nr]; ok {
+		return name
+	}
+	return "syscall_" + strconv.FormatUint(nr, 10)
+}
+
+var syscallNames = map[uint64]string{
+	unix.SYS_READ:        "read",
+	unix.SYS_WRITE:       "write",
+	unix.SYS_OPEN:        "open",
+	unix.SYS_CLOSE:       "close",
+	unix.SYS_STAT:        "stat",
+	unix.SYS_FSTAT:       "fstat",
+	unix.SYS_LSTAT:       "lstat",
+	unix.SYS_MMAP:        "mmap",
+	unix.SYS_MPROTECT:    "mprotect",
+	unix.SYS_BRK:         "brk",
+	unix.SYS_IOCTL:       "ioctl",
+	unix.SYS_ACCESS:      "access",
+	unix.SYS_PIPE:        "pipe",
+	unix.SYS_DUP2:        "dup2",
+	unix.SYS_SOCKET:      "socket",
+	unix.SYS_CONNECT:     "connect",
+	unix.SYS_ACCEPT:      "accept",
+	unix.SYS_BIND:        "bind",
+	unix.SYS_LISTEN:      "listen",
+	unix.SYS_CLONE:       "clone",
+	unix.SYS_FORK:        "fork",
+	unix.SYS_VFORK:       "vfork",
+	unix.SYS_EXECVE:      "execve",
+	unix.SYS_WAIT4:       "wait4",
+	unix.SYS_KILL:        "kill",
+	unix.SYS_FCNTL:       "fcntl",
+	unix.SYS_GETCWD:      "getcwd",
+	unix.SYS_CHDIR:       "chdir",
+	unix.SYS_RENAME:      "rename",
+	unix.SYS_MKDIR:       "mkdir",
+	unix.SYS_RMDIR:       "rmdir",
+	unix.SYS_LINK:        "link",
+	unix.SYS_UNLINK:      "unlink",
+	unix.SYS_SYMLINK:     "symlink",
+	unix.SYS_READLINK:    "readlink",
+	unix.SYS_CHMOD:       "chmod",
+	unix.SYS_CHOWN:       "chown",
+	unix.SYS_SETUID:      "setuid",
+	unix.SYS_SETGID:      "setgid",
+	unix.SYS_STATFS:      "statfs",
+	unix.SYS_PRCTL:       "prctl",
+	unix.SYS_CHROOT:      "chroot",
+	unix.SYS_MOUNT:       "mount",
+	unix.SYS_UMOUNT2:     "umount2",
+	unix.SYS_SETHOSTNAME: "sethostname",
+	unix.SYS_GETDENTS64:  "getdents64",
+	unix.SYS_OPENAT:      "openat",
+	unix.SYS_MKDIRAT:     "mkdirat",
+	unix.SYS_NEWFSTATAT:  "newfstatat",
+	unix.SYS_UNLINKAT:    "unlinkat",
+	unix.SYS_RENAMEAT:    "renameat",
+	unix.SYS_READLINKAT:  "readlinkat",
+	unix.SYS_FACCESSAT:   "faccessat",
+	unix.SYS_UNSHARE:     "unshare",
+	unix.SYS_PRLIMIT64:   "prlimit64",
+	unix.SYS_EXECVEAT:    "execveat",
+	unix.SYS_STATX:       "statx",
+	unix.SYS_FACCESSAT2:  "faccessat2",
+	unix.SYS_CLONE3:      "clone3",
+	unix.SYS_OPENAT2:     "openat2",
+}
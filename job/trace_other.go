@@ -0,0 +1,18 @@
+//go:build !amd64
+
+package job
+
+import (
+	"strconv"
+	"syscall"
+)
+
+const traceSupported = false
+
+func syscallResult(regs *syscall.PtraceRegs) (nr uint64, ret int64) {
+	return 0, 0
+}
+
+func syscallName(nr uint64) string {
+	return "syscall_" + strconv.FormatUint(nr, 10)
+}
@@ -0,0 +1,39 @@
+package job
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTraceExecHelper is not a real test. It is run as a subprocess by
+// TestTraceExec as traceExec exits the process.
+func TestTraceExecHelper(t *testing.T) {
+	if os.Getenv("JOBBER_TRACE_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+	errFile, err := os.Open(os.DevNull)
+	require.NoError(t, err)
+	err = traceExec("/bin/sh", []string{"sh", "-c", "cat /nonexistent; exit 3"}, errFile)
+	require.NoError(t, err)
+}
+
+func TestTraceExec(t *testing.T) {
+	if !traceSupported {
+		t.Skip("syscall tracing not supported")
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestTraceExecHelper$")
+	cmd.Env = append(os.Environ(), "JOBBER_TRACE_HELPER=1")
+	out, err := cmd.CombinedOutput()
+	if strings.Contains(string(out), "operation not permitted") {
+		t.Skip("ptrace not permitted")
+	}
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 3, exitErr.ExitCode())
+	require.Contains(t, string(out), "cat: /nonexistent: No such file or directory\n")
+	require.Contains(t, string(out), "() = -1 ENOENT (no such file or directory)\n")
+}
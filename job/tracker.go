@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
+	"os"
 	"path/filepath"
-	"strconv"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 var (
@@ -22,30 +25,381 @@ var (
 // Tracker maintains a set of Jobs that are either running or have completed.
 // Jobs can be added (started), stopped (including removed via cleanup if
 // desired), listed and attached to for log output.
+//
+// The tracker's mu guards its jobs and other mutable fields. It may be held
+// while taking the lock of a job (e.g. to get its description), but never
+// the other way around, so the lock order is always the tracker then the
+// job. A job's reaper takes only the job's lock to record its exit, so jobs
+// complete whether or not the tracker is locked. As a result, each job's
+// description is a consistent snapshot of that job, but a list of jobs is
+// not an atomic snapshot of them all: a job listed as running may have
+// completed by the time the list is returned.
 type Tracker struct {
 	jobs   map[string]*Job
 	mu     sync.Mutex
 	admins map[string]bool
+	// roles are the roles of users, and groupRoles the roles of the users
+	// in groups. Users without either are operators.
+	roles      map[string]Role
+	groupRoles map[string]Role
 
 	argMaker ArgMaker
 
+	// maxResources are ceilings on the resource limits of jobs. Zero
+	// values are not ceilings.
+	maxResources ResourceLimits
+
+	// defaultMaxLogLinesPerSec is the log line rate limit of jobs that do
+	// not set one. Zero is no limit.
+	defaultMaxLogLinesPerSec uint32
+
+	// maxIOLimits is the maximum number of io limits a job may have.
+	maxIOLimits int
+
+	// maxArgs is the maximum number of arguments a job may have.
+	maxArgs int
+
+	// reservedNamePrefixes are the prefixes the names given to jobs may
+	// not start with.
+	reservedNamePrefixes []string
+
+	// commandDefaults are the default resource limits of jobs by their
+	// command.
+	commandDefaults CommandDefaults
+
+	// commandLimits are the most jobs running at once by their command.
+	commandLimits CommandLimits
+
+	// sampleInterval and maxSamples are how often the resource usage of
+	// jobs that sample it is read and how many samples are kept.
+	sampleInterval time.Duration
+	maxSamples     int
+
+	// maxSyncTimeout and maxSyncOutput limit how long a job run with
+	// RunSync may run and how much of its output is returned.
+	maxSyncTimeout time.Duration
+	maxSyncOutput  int
+
+	// shutdownConcurrency is the maximum number of jobs stopped at once
+	// when shutting down.
+	shutdownConcurrency int
+
+	// setupTimeout is set in the spec of each job started. It is the
+	// longest the job's setup may take before it fails to start.
+	setupTimeout time.Duration
+
+	// stopGracePeriod is passed on to each job started. It is how long a
+	// job is given to exit after SIGTERM when stopped.
+	stopGracePeriod time.Duration
+
+	// noNamespaces is passed on to each job started. It is only for
+	// testing without root privileges.
+	noNamespaces bool
+
+	// spool is passed on to each job started. If not nil, it keeps the
+	// output of jobs on disk.
+	spool *Spool
+
+	// store, if not nil, keeps jobs so they are restored when the tracker
+	// is next created with it.
+	store Store
+
+	// maxLineBytes is passed on to each job started. It is the size at
+	// which lines of the job's output are split.
+	maxLineBytes int
+
+	// maxLogLines and maxLogBytes are passed on to each job started. They
+	// limit the logs kept in memory for the job. Zero is no limit.
+	maxLogLines int
+	maxLogBytes int
+	// logBudget, if not nil, is passed on to each job started and
+	// restored. It limits the logs kept in memory for all jobs.
+	logBudget *logBudget
+
+	// userNSIDs is passed on to each job started. It is the host IDs the
+	// IDs of jobs isolating users are mapped to.
+	userNSIDs IDMapping
+
+	// eventLog, if not nil, is passed on to each job started to record
+	// its lifecycle events.
+	eventLog *EventLog
+	// pushgateway, if not nil, is passed on to each job started to push
+	// its final metrics to.
+	pushgateway *Pushgateway
+
+	// replicaStore, if not nil, makes the tracker a read-only replica
+	// serving the jobs in it. They are reloaded when read at most every
+	// replicaRefresh. replicaLoaded is the stored description of each job
+	// as last loaded, at replicaRefreshed. replicaMu serialises reloads,
+	// which are done without the tracker locked, and guards replicaLoaded
+	// and replicaRefreshed.
+	replicaStore     Store
+	replicaRefresh   time.Duration
+	replicaMu        sync.Mutex
+	replicaLoaded    map[string]JobDescription
+	replicaRefreshed time.Time
+
+	// cgroupErr is the result of the last cgroup health check. Jobs are
+	// not started while it is set.
+	cgroupErr error
+
+	// maxRunningJobs is the maximum number of jobs running at once. Zero
+	// is no limit. running is the number of jobs currently running, and
+	// queue holds the jobs waiting to run in the order they are started.
+	maxRunningJobs int
+	running        int
+	queue          []*Job
+
+	// maxCompletedPerUser is the most completed jobs kept for each owner.
+	// Zero is no limit.
+	maxCompletedPerUser int
+
+	// userIDPrefix namespaces the IDs of new jobs by their owner.
+	userIDPrefix bool
+
+	// idFormat is the scheme for the suffix of the IDs of new jobs.
+	idFormat IDFormat
+
 	shutdown bool
+	// draining is closed once the tracker starts draining, after which it
+	// starts no new jobs.
+	draining chan struct{}
+}
+
+// TrackerOption is an option for configuring a Tracker.
+type TrackerOption func(*Tracker)
+
+// WithMaxResources sets ceilings on the resource limits of jobs started by
+// the tracker. Jobs requesting more than a ceiling, or no limit at all, have
+// that limit reduced to the ceiling. Zero values in max are not ceilings.
+// Only the MaxProcesses, Memory and CPU limits are used.
+func WithMaxResources(max ResourceLimits) TrackerOption {
+	return func(t *Tracker) {
+		t.maxResources = max
+	}
+}
+
+// WithDefaultMaxLogLinesPerSec sets the log line rate limit of jobs started
+// without one. Zero is no limit.
+func WithDefaultMaxLogLinesPerSec(n uint32) TrackerOption {
+	return func(t *Tracker) {
+		t.defaultMaxLogLinesPerSec = n
+	}
+}
+
+// WithMaxIOLimits sets the maximum number of io limits a job may have. Jobs
+// with more are not started. It must be at least 1.
+func WithMaxIOLimits(n int) TrackerOption {
+	return func(t *Tracker) {
+		if n > 0 {
+			t.maxIOLimits = n
+		}
+	}
+}
+
+// WithMaxArgs sets the maximum number of arguments a job may have, over
+// all its commands if it has a command sequence. Jobs with more are not
+// started. It must be at least 1.
+func WithMaxArgs(n int) TrackerOption {
+	return func(t *Tracker) {
+		if n > 0 {
+			t.maxArgs = n
+		}
+	}
 }
 
-func NewTracker(argMaker ArgMaker, admins []string) *Tracker {
+// WithReservedNamePrefixes stops jobs being given names starting with any
+// of prefixes, such as "jobber-", keeping them for the server's own use.
+func WithReservedNamePrefixes(prefixes []string) TrackerOption {
+	return func(t *Tracker) {
+		t.reservedNamePrefixes = prefixes
+	}
+}
+
+// WithSyncLimits sets the longest a job run with RunSync may run and the
+// most output returned for it. Zero values leave the defaults.
+func WithSyncLimits(maxTimeout time.Duration, maxOutput int) TrackerOption {
+	return func(t *Tracker) {
+		if maxTimeout > 0 {
+			t.maxSyncTimeout = maxTimeout
+		}
+		if maxOutput > 0 {
+			t.maxSyncOutput = maxOutput
+		}
+	}
+}
+
+// WithShutdownConcurrency sets the maximum number of jobs stopped at once
+// when the tracker is shut down. It must be at least 1.
+func WithShutdownConcurrency(n int) TrackerOption {
+	return func(t *Tracker) {
+		if n > 0 {
+			t.shutdownConcurrency = n
+		}
+	}
+}
+
+// WithStopGracePeriod sets how long jobs are given to exit after being sent
+// SIGTERM when they are stopped, before being killed with SIGKILL. Jobs are
+// killed with SIGKILL at once if d is zero.
+func WithStopGracePeriod(d time.Duration) TrackerOption {
+	return func(t *Tracker) {
+		t.stopGracePeriod = d
+	}
+}
+
+// WithSetupTimeout sets the longest a job may take to set up its cgroup and
+// namespaces before running its command. A job taking longer fails to
+// start with ErrSetupTimedOut. Zero is no limit.
+func WithSetupTimeout(d time.Duration) TrackerOption {
+	return func(t *Tracker) {
+		t.setupTimeout = d
+	}
+}
+
+// WithSpool keeps the output of jobs in spool on disk as well as in memory.
+func WithSpool(spool *Spool) TrackerOption {
+	return func(t *Tracker) {
+		t.spool = spool
+	}
+}
+
+// WithStore keeps the description and logs of jobs in store, restoring the
+// jobs kept in it when the tracker is created. Jobs that were running or
+// queued when stored are restored as completed with ErrServerRestarted.
+func WithStore(store Store) TrackerOption {
+	return func(t *Tracker) {
+		t.store = store
+	}
+}
+
+// WithMaxLineBytes sets the size at which lines of the output of jobs are
+// split into multiple logs, bounding the memory used to read a line. It
+// must be at least 1.
+func WithMaxLineBytes(n int) TrackerOption {
+	return func(t *Tracker) {
+		if n > 0 {
+			t.maxLineBytes = n
+		}
+	}
+}
+
+// WithMaxLogBuffer limits the logs kept in memory for each job to the given
+// number of lines and bytes of output. Once a job's logs exceed either, its
+// oldest logs are dropped; readers are sent the logs that remain along with
+// how many were dropped. Zero is no limit. Spooled output is not limited.
+func WithMaxLogBuffer(lines, bytes int) TrackerOption {
+	return func(t *Tracker) {
+		t.maxLogLines, t.maxLogBytes = lines, bytes
+	}
+}
+
+// WithEventLog records the lifecycle events of the tracker's jobs in
+// eventLog: when each starts, is stopped, and completes or fails, and when
+// an admin changes its owner.
+func WithEventLog(eventLog *EventLog) TrackerOption {
+	return func(t *Tracker) {
+		t.eventLog = eventLog
+	}
+}
+
+// WithMaxRunningJobs sets the maximum number of jobs running at once. Once
+// it is reached, jobs are queued until a running job completes if their
+// spec asks for it, otherwise they are not started. Zero is no limit.
+func WithMaxRunningJobs(n int) TrackerOption {
+	return func(t *Tracker) {
+		t.maxRunningJobs = n
+	}
+}
+
+// WithMaxCompletedPerUser sets the most completed jobs kept for each owner.
+// When a job completes and its owner has more, their jobs that completed
+// first are cleaned up. Zero is no limit.
+func WithMaxCompletedPerUser(n int) TrackerOption {
+	return func(t *Tracker) {
+		t.maxCompletedPerUser = n
+	}
+}
+
+// WithUserIDPrefix namespaces the IDs of jobs by their owner when on, so a
+// job started by alice has an ID such as alice/greeting-01234567. Owners can
+// refer to their jobs with or without the namespace.
+func WithUserIDPrefix(on bool) TrackerOption {
+	return func(t *Tracker) {
+		t.userIDPrefix = on
+	}
+}
+
+// DefaultMaxIOLimits is the maximum number of io limits a job may have
+// unless set with WithMaxIOLimits.
+const DefaultMaxIOLimits = 16
+
+// DefaultMaxArgs is the maximum number of arguments a job may have unless
+// set with WithMaxArgs.
+const DefaultMaxArgs = 4096
+
+// DefaultMaxSyncTimeout and DefaultMaxSyncOutput are the limits on jobs
+// run with RunSync unless set with WithSyncLimits.
+const (
+	DefaultMaxSyncTimeout = time.Minute
+	DefaultMaxSyncOutput  = 1 << 20
+)
+
+// DefaultShutdownConcurrency is the maximum number of jobs stopped at once
+// when shutting down a tracker unless set with WithShutdownConcurrency.
+const DefaultShutdownConcurrency = 16
+
+func NewTracker(argMaker ArgMaker, admins []string, opts ...TrackerOption) *Tracker {
 	t := &Tracker{
-		jobs:     make(map[string]*Job),
-		admins:   make(map[string]bool),
-		argMaker: argMaker,
+		jobs:                make(map[string]*Job),
+		admins:              make(map[string]bool),
+		argMaker:            argMaker,
+		maxIOLimits:         DefaultMaxIOLimits,
+		maxArgs:             DefaultMaxArgs,
+		maxSyncTimeout:      DefaultMaxSyncTimeout,
+		maxSyncOutput:       DefaultMaxSyncOutput,
+		shutdownConcurrency: DefaultShutdownConcurrency,
+		maxLineBytes:        DefaultMaxLineBytes,
+		draining:            make(chan struct{}),
 	}
 	for _, admin := range admins {
 		t.admins[admin] = true
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.store != nil {
+		t.restore()
+	}
+	t.refreshReplica()
 	return t
 }
 
+// restore adds the jobs kept in the tracker's store. A job that had not
+// completed is stored again once restored, so it is not restored as lost
+// with a new completion time after another restart.
+func (t *Tracker) restore() {
+	stored, err := t.store.Load()
+	if err != nil {
+		// XXX Should log, but no logger yet
+		fmt.Fprintf(os.Stderr, "could not restore all stored jobs: %v\n", err)
+	}
+	for _, sj := range stored {
+		j := restoreJob(sj, t.argMaker, t.logBudget)
+		j.store = t.store
+		if !sj.Description.Status.Ended() {
+			j.persist()
+		}
+		t.jobs[j.ID] = j
+	}
+}
+
 type userContextKey struct{}
 
+type clientContextKey struct{}
+
+type groupsContextKey struct{}
+
 func AddUserToContext(ctx context.Context, user string) context.Context {
 	return context.WithValue(ctx, userContextKey{}, user)
 }
@@ -55,36 +409,323 @@ func GetUserFromContext(ctx context.Context) (string, bool) {
 	return u, ok
 }
 
+// AddGroupsToContext adds the groups the user of ctx belongs to, such as
+// the organizational units of their client certificate, to ctx.
+func AddGroupsToContext(ctx context.Context, groups []string) context.Context {
+	return context.WithValue(ctx, groupsContextKey{}, groups)
+}
+
+// GetGroupsFromContext returns the groups added to ctx with
+// AddGroupsToContext.
+func GetGroupsFromContext(ctx context.Context) ([]string, bool) {
+	groups, ok := ctx.Value(groupsContextKey{}).([]string)
+	return groups, ok
+}
+
+// AddClientToContext adds the network address of the client making a
+// request to ctx, which is recorded on the jobs it starts.
+func AddClientToContext(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, addr)
+}
+
+// GetClientFromContext returns the network address of the client added to
+// ctx with AddClientToContext.
+func GetClientFromContext(ctx context.Context) (string, bool) {
+	addr, ok := ctx.Value(clientContextKey{}).(string)
+	return addr, ok
+}
+
+type adminContextKey struct{}
+
+// AddAdminToContext marks the user in ctx as an admin, in addition to the
+// admins the tracker was created with. It is for users authenticated with
+// credentials that grant admin privileges.
+func AddAdminToContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminContextKey{}, true)
+}
+
+// isAdmin returns true if user is one of the tracker's admins, has the admin
+// role themselves or through one of their groups in ctx, or ctx marks the
+// user as an admin.
+func (t *Tracker) isAdmin(ctx context.Context, user string) bool {
+	admin, _ := ctx.Value(adminContextKey{}).(bool)
+	return admin || t.admins[user] || t.roles[user] == RoleAdmin || t.groupRole(ctx) == RoleAdmin
+}
+
 // Start runs the given job. If it starts, the job will be tracked and can be
 // operated upon. If it does not start, an error is returned and the job is
-// not tracked.
-func (t *Tracker) Start(ctx context.Context, spec JobSpec) (string, error) {
+// not tracked. Any adjustments made to the job spec that did not prevent the
+// job from starting are returned as warnings.
+func (t *Tracker) Start(ctx context.Context, spec JobSpec) (string, []string, error) {
 	user, ok := GetUserFromContext(ctx)
-	if !ok {
-		return "", ErrUnauthorized
+	if !ok || !t.canRun(ctx, user) {
+		return "", nil, ErrUnauthorized
+	}
+	if t.replicaStore != nil {
+		return "", nil, ErrReadReplica
 	}
 
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	if t.shutdown {
-		return "", ErrShutdown
+		return "", nil, ErrShutdown
+	}
+	if t.isDraining() {
+		return "", nil, ErrDraining
+	}
+
+	if t.cgroupErr != nil {
+		// Fail fast rather than when setting up the job's cgroup.
+		return "", nil, t.cgroupErr
+	}
+
+	// Validate the limits the job will have, which may be valid only with
+	// its command's defaults, such as a cpu burst without a cpu limit.
+	t.commandDefaults.apply(&spec.Resources, spec)
+	if err := t.validate(spec); err != nil {
+		return "", nil, err
 	}
 
-	if spec.Command == "" {
-		return "", ErrNoCommand
+	warnings := clampResources(&spec.Resources, t.maxResources, runtime.NumCPU())
+	if spec.MaxLogLinesPerSec == 0 {
+		spec.MaxLogLinesPerSec = t.defaultMaxLogLinesPerSec
 	}
+	spec.SetupTimeout = t.setupTimeout
 
-	id := t.allocateID(spec)
+	// Jobs already queued are ahead of this one, even if there is room
+	// for it to run.
+	full := t.maxRunningJobs > 0 && (t.running >= t.maxRunningJobs || len(t.queue) > 0)
+	if full && !spec.Queue {
+		return "", nil, fmt.Errorf("%w (%d)", ErrTooManyJobs, t.maxRunningJobs)
+	}
+	if err := t.checkCommandLimit(spec); err != nil {
+		return "", nil, err
+	}
+
+	id := t.allocateID(spec, user)
+	if spec.Name != "" {
+		id = spec.Name
+		if t.userIDPrefix {
+			id = user + "/" + id
+		}
+		if _, ok := t.jobs[id]; ok {
+			return "", nil, fmt.Errorf("%s: %w", id, ErrJobExists)
+		}
+	}
 	j := NewJob(id, spec, t.argMaker)
+	j.Status.Client, _ = GetClientFromContext(ctx)
+	j.stopGracePeriod = t.stopGracePeriod
+	j.noNamespaces = t.noNamespaces
+	j.spool = t.spool
+	j.store = t.store
+	j.eventLog = t.eventLog
+	j.pushgateway = t.pushgateway
+	j.maxLineBytes = t.maxLineBytes
+	j.maxLogLines, j.maxLogBytes = t.maxLogLines, t.maxLogBytes
+	j.logBudget = t.logBudget
+	j.userNSIDs = t.userNSIDs
+	j.sampleInterval, j.maxSamples = t.sampleInterval, t.maxSamples
+
+	if full {
+		j.Queue(user)
+		j.persist()
+		t.jobs[id] = j
+		t.queue = append(t.queue, j)
+		w := fmt.Sprintf("server is running its maximum of %d jobs; job queued behind %d others", t.maxRunningJobs, len(t.queue)-1)
+		return id, append(warnings, w), nil
+	}
 
 	if err := j.Start(user); err != nil {
 		// don't track a job we can't start
-		return "", fmt.Errorf("%w: %v", ErrNotStarted, err) // would be nice to wrap both
+		return "", nil, fmt.Errorf("%w: %v", ErrNotStarted, err) // would be nice to wrap both
 	}
 	t.jobs[id] = j
+	t.started(j)
+	// Store the running job so it is known to have been lost if the
+	// server stops without it completing.
+	j.persist()
+
+	return id, warnings, nil
+}
+
+// started counts j as running until it completes, at which point the next
+// queued jobs are admitted. It must be called with the tracker locked.
+func (t *Tracker) started(j *Job) {
+	t.running++
+	go func() {
+		jd := j.Wait(context.Background())
+		t.mu.Lock()
+		t.running--
+		evicted := t.admitQueued()
+		evicted = append(evicted, t.evictCompleted(jd.Status.Owner)...)
+		t.mu.Unlock()
+		cleanupJobs(evicted)
+	}()
+}
+
+// evictCompleted stops tracking the jobs of owner that completed first
+// while owner has more completed jobs than the tracker keeps for each
+// owner, returning them. They must be cleaned up with cleanupJobs once the
+// tracker is unlocked, as cleaning up a job can wait for its logs to
+// drain. It must be called with the tracker locked.
+func (t *Tracker) evictCompleted(owner string) []*Job {
+	if t.maxCompletedPerUser == 0 {
+		return nil
+	}
+	var completed []*Job
+	completionTimes := make(map[*Job]time.Time)
+	for _, j := range t.jobs {
+		jd := j.summary()
+		if jd.Status.Owner == owner && jd.Status.Ended() {
+			completed = append(completed, j)
+			completionTimes[j] = jd.Status.CompletionTime
+		}
+	}
+	if len(completed) <= t.maxCompletedPerUser {
+		return nil
+	}
+	sort.Slice(completed, func(a, b int) bool {
+		return completionTimes[completed[a]].Before(completionTimes[completed[b]])
+	})
+	evicted := completed[:len(completed)-t.maxCompletedPerUser]
+	for _, j := range evicted {
+		t.untrack(j)
+	}
+	return evicted
+}
+
+// cleanupJobs cleans up jobs that are no longer tracked. It must be called
+// with the tracker unlocked.
+func cleanupJobs(jobs []*Job) {
+	for _, j := range jobs {
+		j.Cleanup()
+	}
+}
+
+// admitQueued starts queued jobs, in the order they were queued, while
+// there is room for them to run. A queued job that fails to start is
+// completed with the error as its exit reason. It returns the jobs evicted
+// as a result, to be cleaned up with cleanupJobs. It must be called with
+// the tracker locked.
+func (t *Tracker) admitQueued() []*Job {
+	var evicted []*Job
+	for len(t.queue) > 0 && (t.maxRunningJobs == 0 || t.running < t.maxRunningJobs) {
+		j := t.queue[0]
+		t.queue = t.queue[1:]
+		owner := j.summary().Status.Owner
+		if err := j.Start(owner); err != nil {
+			j.abandon(JobStateCompleted, fmt.Errorf("%w: %v", ErrNotStarted, err))
+			evicted = append(evicted, t.evictCompleted(owner)...)
+			continue
+		}
+		t.started(j)
+	}
+	return evicted
+}
+
+// dequeue removes j from the queue, returning false if it was not queued.
+// It must be called with the tracker locked.
+func (t *Tracker) dequeue(j *Job) bool {
+	for i, qj := range t.queue {
+		if qj == j {
+			t.queue = append(t.queue[:i], t.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ExplainCgroup returns the cgroup writes that Start would make to set the
+// resource limits of a job with the given spec, without running it. The
+// limits are adjusted as Start would and the adjustments are returned as
+// warnings.
+func (t *Tracker) ExplainCgroup(ctx context.Context, spec JobSpec) ([]CgroupWrite, []string, error) {
+	if _, ok := GetUserFromContext(ctx); !ok {
+		return nil, nil, ErrUnauthorized
+	}
+	// As for Start, defaults are applied before the spec is validated.
+	t.commandDefaults.apply(&spec.Resources, spec)
+	if err := t.validate(spec); err != nil {
+		return nil, nil, err
+	}
+
+	t.mu.Lock()
+	max := t.maxResources
+	t.mu.Unlock()
+
+	warnings := clampResources(&spec.Resources, max, runtime.NumCPU())
+	return CgroupWrites(spec.Resources), warnings, nil
+}
+
+// validate validates spec and checks it is within the tracker's limits.
+func (t *Tracker) validate(spec JobSpec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	if n := len(spec.Resources.IO); n > t.maxIOLimits {
+		return fmt.Errorf("%w: %d given, the maximum is %d", ErrInvalidIOLimits, n, t.maxIOLimits)
+	}
+	if n := spec.NumArgs(); n > t.maxArgs {
+		return fmt.Errorf("%w: %d given, the maximum is %d", ErrTooManyArgs, n, t.maxArgs)
+	}
+	for _, prefix := range t.reservedNamePrefixes {
+		if spec.Name != "" && prefix != "" && strings.HasPrefix(spec.Name, prefix) {
+			return fmt.Errorf("%w: %q starts with the reserved prefix %q", ErrInvalidName, spec.Name, prefix)
+		}
+	}
+	return nil
+}
+
+// clampResources reduces any resource limits that exceed the ceilings in
+// max, or cannot be satisfied by the host, returning a warning for each
+// requested limit that was reduced. A limit that was not requested (zero)
+// is set to its ceiling without a warning. Zero values in max are not
+// ceilings.
+func clampResources(r *ResourceLimits, max ResourceLimits, numCPU int) []string {
+	var warnings []string
+	clamp := func(name string, req *uint64, ceiling uint64, unit string) {
+		if ceiling == 0 || (*req != 0 && *req <= ceiling) {
+			return
+		}
+		if *req != 0 {
+			w := fmt.Sprintf("%s limit of %d%s exceeds the server maximum; clamped to %d%s", name, *req, unit, ceiling, unit)
+			warnings = append(warnings, w)
+		}
+		*req = ceiling
+	}
+
+	procs, mem, cpu := uint64(r.MaxProcesses), uint64(r.Memory), uint64(r.CPU)
+	clamp("process", &procs, uint64(max.MaxProcesses), "")
+	clamp("memory", &mem, uint64(max.Memory), " bytes")
+	clamp("cpu", &cpu, uint64(max.CPU), " mCPU")
+	r.MaxProcesses, r.Memory, r.CPU = uint32(procs), ByteSize(mem), MilliCPU(cpu)
+
+	if maxCPU := MilliCPU(numCPU) * 1000; r.CPU > maxCPU {
+		w := fmt.Sprintf("cpu limit of %d mCPU exceeds the %d CPUs available; clamped to %d mCPU", r.CPU, numCPU, maxCPU)
+		warnings = append(warnings, w)
+		r.CPU = maxCPU
+	}
+
+	// A burst larger than a clamped quota would be rejected by the kernel.
+	if r.CpuBurst > 0 && uint64(r.CpuBurst) > r.CPU.quota() {
+		w := fmt.Sprintf("cpu burst of %dus exceeds the clamped cpu quota; clamped to %dus", r.CpuBurst, r.CPU.quota())
+		warnings = append(warnings, w)
+		r.CpuBurst = uint32(r.CPU.quota())
+	}
+	return warnings
+}
+
+// CheckCgroups checks the health of the cgroups that jobs are run in,
+// returning the result. While the last check failed, Start fails with the
+// error from the check.
+func (t *Tracker) CheckCgroups() error {
+	err := CheckCgroups()
 
-	return id, nil
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cgroupErr = err
+	return err
 }
 
 // Stop kills the job identified by id. It waits until the job exits before
@@ -94,34 +735,106 @@ func (t *Tracker) Stop(ctx context.Context, id string, cleanup bool) error {
 	if !ok {
 		return ErrUnauthorized
 	}
+	if t.replicaStore != nil {
+		return ErrReadReplica
+	}
 
+	// The tracker is only locked to look up the job and dequeue it, not
+	// while a running job is stopped, which can take as long as its stop
+	// grace period and being reaped.
 	t.mu.Lock()
-	defer t.mu.Unlock()
-
-	j, ok := t.jobs[id]
-	if !ok {
-		return fmt.Errorf("%s: %w", id, ErrUnknown)
+	j, err := t.lookup(user, id)
+	if err != nil {
+		t.mu.Unlock()
+		return err
 	}
 
-	jd := j.Description()
+	jd := j.summary()
 
-	if jd.Status.Owner != user && !t.admins[user] {
+	if !t.canManage(ctx, user, jd.Status.Owner) {
+		t.mu.Unlock()
 		// XXX should probably be ErrUnknown to avoid enumeration attacks
 		return ErrUnauthorized
 	}
 
+	var evicted []*Job
+	if jd.Status.State == JobStateQueued && t.dequeue(j) {
+		j.abandon(JobStateCancelled, ErrCancelled)
+		evicted = t.evictCompleted(jd.Status.Owner)
+	}
+	t.mu.Unlock()
+	cleanupJobs(evicted)
+
 	if jd.Status.State == JobStateRunning {
 		j.Stop(ctx)
 	}
 
 	if cleanup {
-		j.Cleanup()
-		delete(t.jobs, id)
+		// The job may have been removed while it was stopped, by another
+		// stop or by evicting completed jobs, in which case it has been
+		// cleaned up already.
+		t.mu.Lock()
+		tracked := t.untrack(j)
+		t.mu.Unlock()
+		if tracked {
+			j.Cleanup()
+		}
 	}
 
 	return nil
 }
 
+// untrack stops tracking j and removes it from the tracker's store,
+// returning false if it was not tracked. It must be called with the tracker
+// locked.
+func (t *Tracker) untrack(j *Job) bool {
+	if t.jobs[j.ID] != j {
+		return false
+	}
+	delete(t.jobs, j.ID)
+	if t.store != nil {
+		if err := t.store.Remove(j.ID); err != nil {
+			// XXX Should log, but no logger yet
+			fmt.Fprintf(os.Stderr, "could not remove stored job %s: %v\n", j.ID, err)
+		}
+	}
+	return true
+}
+
+// Chown makes owner the owner of the job identified by id, returning the
+// previous owner. An empty owner clears the owner, leaving the job to be
+// managed only by admins. Only admins can change a job's owner. The job's
+// ID is not changed, even if it is namespaced by its previous owner.
+func (t *Tracker) Chown(ctx context.Context, id, owner string) (string, error) {
+	user, ok := GetUserFromContext(ctx)
+	if !ok || !t.isAdmin(ctx, user) {
+		return "", ErrUnauthorized
+	}
+	if t.replicaStore != nil {
+		return "", ErrReadReplica
+	}
+
+	t.mu.Lock()
+	j, err := t.lookup(user, id)
+	if err != nil {
+		t.mu.Unlock()
+		return "", err
+	}
+	prev := j.setOwner(owner)
+	j.persist()
+	ev := newEvent(EventChown, j.summary())
+	t.mu.Unlock()
+
+	// Recorded by the tracker, as jobs restored from its store have no
+	// event log of their own.
+	ev.PreviousOwner, ev.Admin = prev, user
+	if err := t.eventLog.Record(ev); err != nil {
+		// XXX Should log, but no logger yet
+		fmt.Fprintf(os.Stderr, "could not record %s event of job %s: %v\n", ev.Type, id, err)
+	}
+	return prev, nil
+}
+
 // Get returns a copy of the job identified by id if it exists in the tracker,
 // otherwise an error. The copy returned is not an active job that can be
 // manipulated - it is just for the data.
@@ -131,112 +844,375 @@ func (t *Tracker) Get(ctx context.Context, id string) (JobDescription, error) {
 		return JobDescription{}, ErrUnauthorized
 	}
 
+	t.refreshReplica()
 	t.mu.Lock()
-	defer t.mu.Unlock()
-
-	j, ok := t.jobs[id]
-	if !ok {
-		return JobDescription{}, fmt.Errorf("%s: %w", id, ErrUnknown)
+	j, err := t.lookup(user, id)
+	if err != nil {
+		t.mu.Unlock()
+		return JobDescription{}, err
 	}
 
-	jd := j.Description()
-
-	if jd.Status.Owner != user && !t.admins[user] {
+	if !t.canView(ctx, user, j.summary().Status.Owner) {
+		t.mu.Unlock()
 		// XXX should probably be ErrUnknown to avoid enumeration attacks
 		return JobDescription{}, ErrUnauthorized
 	}
+	t.mu.Unlock()
 
-	return jd, nil
+	// Don't hold the tracker lock while reading the job's cgroup.
+	return j.Description(), nil
 
 }
 
+// Exists returns whether the job identified by id exists and may be seen
+// by the user in ctx. A job of another user does not exist unless the user
+// may view all jobs, as admins and viewers may, so that users cannot find
+// out which IDs are in use.
+func (t *Tracker) Exists(ctx context.Context, id string) (bool, error) {
+	user, ok := GetUserFromContext(ctx)
+	if !ok {
+		return false, ErrUnauthorized
+	}
+
+	t.refreshReplica()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	j, err := t.lookup(user, id)
+	if errors.Is(err, ErrUnknown) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return t.canView(ctx, user, j.summary().Status.Owner), nil
+}
+
 // List returns a copy of all the jobs for a owner, or all jobs if the given
-// owner is empty. Only running jobs are returned, unless completed is true.
+// owner is empty. Only running and queued jobs are returned, unless
+// completed is true.
 func (t *Tracker) List(ctx context.Context, completed, all bool) []JobDescription {
 	user, ok := GetUserFromContext(ctx)
 	if !ok {
 		return nil
 	}
 
+	t.refreshReplica()
 	t.mu.Lock()
-	defer t.mu.Unlock()
-
-	var jobs []JobDescription
+	var listed []*Job
 	for _, j := range t.jobs {
-		// XXX maybe clean up locking by using a function in the loop body
-		jd := j.Description()
-		if user != jd.Status.Owner && !(all && t.admins[user]) {
+		status := j.summary().Status
+		if user != status.Owner && !(all && t.canViewAll(ctx, user)) {
 			continue
 		}
-		if !completed && jd.Status.State == JobStateCompleted {
+		if !completed && status.Ended() {
 			continue
 		}
-		jobs = append(jobs, jd)
+		listed = append(listed, j)
 	}
+	t.mu.Unlock()
 
+	// Don't hold the tracker lock while reading the jobs' cgroups.
+	var jobs []JobDescription
+	for _, j := range listed {
+		jobs = append(jobs, j.Description())
+	}
 	return jobs
 }
 
 // GetLogChannel returns a channel that streams the logs of the job identified
-// by id. If follow is set, the stream will continue until the job terminates.
+// by id, starting from the line with index start, or with only the last
+// -start lines recorded so far if start is negative. If follow is set, the
+// stream will continue until the job terminates.
 // Regardless of the follow flag, if the context is closed, then the
 // returned log channel is detached from the log feeder and is closed.
-func (t *Tracker) GetLogChannel(id string, follow bool, ctx context.Context) (<-chan Log, error) {
+func (t *Tracker) GetLogChannel(id string, follow bool, start int, ctx context.Context) (<-chan Log, error) {
+	return t.getLogChannel(id, follow, false /* stopJob */, start, ctx)
+}
+
+// FollowRunLogs returns a channel that follows the logs of the job
+// identified by id as GetLogChannel does, for the client that ran the job.
+// If the job was started with StopOnClientDisconnect and the user may
+// manage it, the job is stopped if the context is closed while the job is
+// still running. Streams from GetLogChannel never stop a job.
+func (t *Tracker) FollowRunLogs(id string, start int, ctx context.Context) (<-chan Log, error) {
+	return t.getLogChannel(id, true /* follow */, true /* stopJob */, start, ctx)
+}
+
+// getLogChannel returns a channel streaming the logs of the job identified
+// by id, as GetLogChannel does, also stopping the job as FollowRunLogs does
+// if stopJob is set.
+func (t *Tracker) getLogChannel(id string, follow, stopJob bool, start int, ctx context.Context) (<-chan Log, error) {
 	user, ok := GetUserFromContext(ctx)
 	if !ok {
 		return nil, ErrUnauthorized
 	}
 
+	t.refreshReplica()
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	j, ok := t.jobs[id]
-	if !ok {
-		return nil, fmt.Errorf("%s: %w", id, ErrUnknown)
+	j, err := t.lookup(user, id)
+	if err != nil {
+		return nil, err
 	}
 
-	jd := j.Description()
+	jd := j.summary()
 
-	if jd.Status.Owner != user && !t.admins[user] {
+	if !t.canView(ctx, user, jd.Status.Owner) {
 		// XXX should probably be ErrUnknown to avoid enumeration attacks
 		return nil, ErrUnauthorized
 	}
 
-	return j.AttachOutfeed(follow, ctx.Done()), nil
+	if t.replicaStore != nil {
+		// The stored logs of a job are all a replica has.
+		follow = false
+	}
+
+	if follow && stopJob && jd.Spec.StopOnClientDisconnect && t.canManage(ctx, user, jd.Status.Owner) {
+		go stopOnDisconnect(ctx, j)
+	}
+
+	return j.AttachOutfeed(follow, start, ctx.Done()), nil
 }
 
-func (t *Tracker) Shutdown(ctx context.Context) (int, error) {
+// maxPageLines and maxPageBytes limit the logs returned by LogsPage, so
+// that a page fits in a gRPC message, which is limited to 4MiB by default.
+const (
+	maxPageLines = 10000
+	maxPageBytes = 2 << 20
+)
+
+// LogsPage returns the logs of the job identified by id with indexes in
+// [start, end), and the number of logs the job has output so far. If end is
+// zero or past the job's logs, the page ends with the last log. The page is
+// cut short after maxPageLines logs or maxPageBytes of them, but always
+// has at least one log if there is any; its End is the index to ask for
+// the next page from.
+func (t *Tracker) LogsPage(ctx context.Context, id string, start, end int) (LogPage, error) {
 	user, ok := GetUserFromContext(ctx)
-	if !ok || !t.admins[user] {
-		return 0, ErrUnauthorized
+	if !ok {
+		return LogPage{}, ErrUnauthorized
+	}
+
+	t.refreshReplica()
+	t.mu.Lock()
+	j, err := t.lookup(user, id)
+	if err != nil {
+		t.mu.Unlock()
+		return LogPage{}, err
+	}
+
+	if jd := j.summary(); !t.canView(ctx, user, jd.Status.Owner) {
+		t.mu.Unlock()
+		// XXX should probably be ErrUnknown to avoid enumeration attacks
+		return LogPage{}, ErrUnauthorized
+	}
+	t.mu.Unlock()
+
+	if start < 0 {
+		start = 0
+	}
+	if end <= 0 || end-start > maxPageLines {
+		end = start + maxPageLines
+	}
+	// Don't hold the tracker lock while logs are read from the spool.
+	page := j.LogsPage(start, end)
+	page.End = end
+	if page.End > page.Total {
+		page.End = page.Total
+	}
+	if page.End < start {
+		page.End = start
+	}
+	size := 0
+	for i, l := range page.Logs {
+		size += len(l.Line)
+		if size > maxPageBytes && i > 0 {
+			page.Logs, page.End = page.Logs[:i], l.Index
+			break
+		}
+	}
+	return page, nil
+}
+
+// LogsWindow returns the logs of the job identified by id timestamped in
+// [from, to], and the number of logs the job has output so far. A zero from
+// or to leaves that end of the window open.
+func (t *Tracker) LogsWindow(ctx context.Context, id string, from, to time.Time) (LogPage, error) {
+	user, ok := GetUserFromContext(ctx)
+	if !ok {
+		return LogPage{}, ErrUnauthorized
+	}
+
+	t.refreshReplica()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	j, err := t.lookup(user, id)
+	if err != nil {
+		return LogPage{}, err
+	}
+
+	if jd := j.summary(); !t.canView(ctx, user, jd.Status.Owner) {
+		// XXX should probably be ErrUnknown to avoid enumeration attacks
+		return LogPage{}, ErrUnauthorized
+	}
+
+	return j.LogsWindow(from, to), nil
+}
+
+// stopOnDisconnect stops j if ctx is closed before j completes.
+func stopOnDisconnect(ctx context.Context, j *Job) {
+	select {
+	case <-ctx.Done():
+	case <-j.reaped:
+		return
+	}
+	if j.summary().Status.State == JobStateRunning {
+		j.Stop(context.Background())
+	}
+}
+
+// Wait waits for the job identified by id to complete, or for the context
+// to be cancelled, and returns a copy of the job at that point.
+func (t *Tracker) Wait(ctx context.Context, id string) (JobDescription, error) {
+	user, ok := GetUserFromContext(ctx)
+	if !ok {
+		return JobDescription{}, ErrUnauthorized
 	}
 
+	t.refreshReplica()
+	t.mu.Lock()
+	j, err := t.lookup(user, id)
+	t.mu.Unlock()
+	if err != nil {
+		return JobDescription{}, err
+	}
+
+	if jd := j.summary(); !t.canView(ctx, user, jd.Status.Owner) {
+		// XXX should probably be ErrUnknown to avoid enumeration attacks
+		return JobDescription{}, ErrUnauthorized
+	}
+
+	// Don't hold the tracker lock while waiting.
+	return j.Wait(ctx), nil
+}
+
+// FeederStats returns a snapshot of the state of the log feeder of the job
+// identified by id, for debugging. Only admins can get feeder stats.
+func (t *Tracker) FeederStats(ctx context.Context, id string) (FeederStats, error) {
+	user, ok := GetUserFromContext(ctx)
+	if !ok || !t.isAdmin(ctx, user) {
+		return FeederStats{}, ErrUnauthorized
+	}
+
+	t.refreshReplica()
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	j, err := t.lookup(user, id)
+	if err != nil {
+		return FeederStats{}, err
+	}
+
+	return j.FeederStats(), nil
+}
+
+// Shutdown stops all running jobs, abandons queued jobs with ErrShutdown,
+// and stops tracking them all, returning the number of running jobs
+// stopped. Each job is cleaned up, including its log feeder and spooled
+// output, once it is reaped. Jobs are given their stop grace period to exit
+// unless ctx is done first, in which case they are killed at once and
+// cleaned up without waiting for them to be reaped. The tracker is not
+// locked while jobs are stopped and cleaned up.
+// Once shut down, the tracker starts no more jobs, failing with
+// ErrShutdown. Only admins can shut down the tracker.
+func (t *Tracker) Shutdown(ctx context.Context) (int, error) {
+	user, ok := GetUserFromContext(ctx)
+	if !ok || !t.isAdmin(ctx, user) {
+		return 0, ErrUnauthorized
+	}
+
+	// The tracker is only locked to collect and untrack the jobs, not
+	// while they are stopped and cleaned up, which can take as long as
+	// their stop grace period and draining their logs.
+	t.mu.Lock()
 	t.shutdown = true
+	if t.replicaStore != nil {
+		// A replica's jobs are run by another server.
+		t.mu.Unlock()
+		return 0, nil
+	}
 
-	count := 0
+	var running []*Job
 	for _, j := range t.jobs {
-		if j.Status.State != JobStateRunning {
-			continue
+		if j.summary().Status.State == JobStateRunning {
+			running = append(running, j)
 		}
-		count++
-		j.Stop(context.Background()) // don't let a canceled client context stop us
-		j.Cleanup()
+	}
+	for _, j := range running {
+		delete(t.jobs, j.ID)
+	}
+	queued := t.queue
+	t.queue = nil
+	for _, j := range queued {
+		j.abandon(JobStateCompleted, ErrShutdown)
 		delete(t.jobs, j.ID)
 	}
+	t.mu.Unlock()
+
+	// Stop the jobs concurrently so that shutting down takes about as long
+	// as the slowest job takes to stop, rather than the sum of them all.
+	sem := make(chan struct{}, t.shutdownConcurrency)
+	var wg sync.WaitGroup
+	for _, j := range running {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(j *Job) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			// A done ctx cuts short waiting for the job, but it is
+			// still killed.
+			j.Stop(ctx)
+			j.Cleanup()
+		}(j)
+	}
+	wg.Wait()
 
-	return count, nil
+	for _, j := range queued {
+		j.Cleanup()
+	}
+
+	return len(running), nil
 }
 
-func (t *Tracker) allocateID(spec JobSpec) string {
+// lookup returns the job identified by id. When job IDs are namespaced by
+// owner, an id without a namespace is looked up in user's namespace. It
+// must be called with the tracker locked.
+func (t *Tracker) lookup(user, id string) (*Job, error) {
+	if j, ok := t.jobs[id]; ok {
+		return j, nil
+	}
+	if t.userIDPrefix && !strings.Contains(id, "/") {
+		if j, ok := t.jobs[user+"/"+id]; ok {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", id, ErrUnknown)
+}
+
+func (t *Tracker) allocateID(spec JobSpec, owner string) string {
 	// XXX If we have 4 billion jobs with the same command, this could loop
 	// infinitely. A good program would check that :(
+	command := spec.FirstCommand()
 	for {
-		// pseudo-randomness is good enough for this.
-		base := filepath.Base(spec.Command) + "-"
-		id := base + strconv.FormatUint(uint64(rand.Uint32()), 16)
+		base := filepath.Base(command) + "-"
+		if t.userIDPrefix {
+			base = owner + "/" + base
+		}
+		id := base + t.idFormat.suffix(time.Now())
 		if _, ok := t.jobs[id]; !ok {
 			return id
 		}
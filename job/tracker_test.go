@@ -0,0 +1,749 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClampResources(t *testing.T) {
+	max := ResourceLimits{MaxProcesses: 10, Memory: 1 << 20, CPU: 2000}
+	tests := map[string]struct {
+		max      ResourceLimits
+		req      ResourceLimits
+		expected ResourceLimits
+		warnings []string
+	}{
+		"no maximums": {
+			req:      ResourceLimits{MaxProcesses: 100, Memory: 1 << 30, CPU: 4000},
+			expected: ResourceLimits{MaxProcesses: 100, Memory: 1 << 30, CPU: 4000},
+		},
+		"within maximums": {
+			max:      max,
+			req:      ResourceLimits{MaxProcesses: 10, Memory: 1 << 10, CPU: 1000},
+			expected: ResourceLimits{MaxProcesses: 10, Memory: 1 << 10, CPU: 1000},
+		},
+		"unlimited set to maximums": {
+			max:      max,
+			expected: max,
+		},
+		"above maximums": {
+			max:      max,
+			req:      ResourceLimits{MaxProcesses: 11, Memory: 1 << 30, CPU: 4000},
+			expected: max,
+			warnings: []string{
+				"process limit of 11 exceeds the server maximum; clamped to 10",
+				"memory limit of 1073741824 bytes exceeds the server maximum; clamped to 1048576 bytes",
+				"cpu limit of 4000 mCPU exceeds the server maximum; clamped to 2000 mCPU",
+			},
+		},
+		"above available CPUs": {
+			req:      ResourceLimits{CPU: 64000},
+			expected: ResourceLimits{CPU: 8000},
+			warnings: []string{"cpu limit of 64000 mCPU exceeds the 8 CPUs available; clamped to 8000 mCPU"},
+		},
+		"burst within clamped quota": {
+			max:      max,
+			req:      ResourceLimits{CPU: 4000, CpuBurst: 500000},
+			expected: ResourceLimits{MaxProcesses: 10, Memory: 1 << 20, CPU: 2000, CpuBurst: 500000},
+			warnings: []string{"cpu limit of 4000 mCPU exceeds the server maximum; clamped to 2000 mCPU"},
+		},
+		"burst above clamped quota": {
+			max:      max,
+			req:      ResourceLimits{CPU: 4000, CpuBurst: 3000000},
+			expected: ResourceLimits{MaxProcesses: 10, Memory: 1 << 20, CPU: 2000, CpuBurst: 2000000},
+			warnings: []string{
+				"cpu limit of 4000 mCPU exceeds the server maximum; clamped to 2000 mCPU",
+				"cpu burst of 3000000us exceeds the clamped cpu quota; clamped to 2000000us",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := tc.req
+			warnings := clampResources(&r, tc.max, 8)
+			require.Equal(t, tc.expected, r)
+			require.Equal(t, tc.warnings, warnings)
+		})
+	}
+}
+
+// newTestTracker returns a tracker that runs jobs with script through
+// /bin/sh without any namespaces, with "admin" as an admin user.
+func newTestTracker(script string, opts ...TrackerOption) *Tracker {
+	t := NewTracker(shellArgMaker(script), []string{"admin"}, opts...)
+	t.noNamespaces = true
+	return t
+}
+
+func TestMaxIOLimits(t *testing.T) {
+	tracker := newTestTracker("exit 0", WithMaxIOLimits(2))
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	spec := JobSpec{Command: "/bin/sh"}
+	for i := uint32(0); i < 3; i++ {
+		spec.Resources.IO = append(spec.Resources.IO, DiskIOLimits{Major: 8, Minor: i * 16, ReadBPS: 100})
+	}
+	_, _, err := tracker.Start(userCtx, spec)
+	require.ErrorIs(t, err, ErrInvalidIOLimits)
+	_, _, err = tracker.ExplainCgroup(userCtx, spec)
+	require.ErrorIs(t, err, ErrInvalidIOLimits)
+
+	spec.Resources.IO = spec.Resources.IO[:2]
+	writes, _, err := tracker.ExplainCgroup(userCtx, spec)
+	require.NoError(t, err)
+	require.Len(t, writes, 2)
+}
+
+func TestStartRecordsClient(t *testing.T) {
+	tracker := newTestTracker("exit 0")
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	id, _, err := tracker.Start(AddClientToContext(userCtx, "192.0.2.1:51234"), JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	jd, err := tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+	require.Equal(t, "192.0.2.1:51234", jd.Status.Client)
+
+	id, _, err = tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	jd, err = tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+	require.Empty(t, jd.Status.Client)
+}
+
+func TestMaxArgs(t *testing.T) {
+	tracker := newTestTracker("exit 0", WithMaxArgs(3))
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	tests := map[string]struct {
+		spec JobSpec
+		err  string
+	}{
+		"under":         {spec: JobSpec{Command: "/bin/ls", Args: []string{"a", "b"}}},
+		"at":            {spec: JobSpec{Command: "/bin/ls", Args: []string{"a", "b", "c"}}},
+		"over":          {spec: JobSpec{Command: "/bin/ls", Args: []string{"a", "b", "c", "d"}}, err: "4 given, the maximum is 3"},
+		"sequence at":   {spec: JobSpec{Commands: [][]string{{"/bin/ls", "a"}, {"/bin/ls", "b", "c"}}}},
+		"sequence over": {spec: JobSpec{Commands: [][]string{{"/bin/ls", "a", "b"}, {"/bin/ls", "c", "d"}}}, err: "4 given, the maximum is 3"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := tracker.ExplainCgroup(userCtx, tc.spec)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrTooManyArgs)
+			require.ErrorContains(t, err, tc.err)
+			_, _, err = tracker.Start(userCtx, tc.spec)
+			require.ErrorIs(t, err, ErrTooManyArgs)
+		})
+	}
+}
+
+func TestShutdownConcurrency(t *testing.T) {
+	tracker := newTestTracker("exec sleep 60 2>&1", WithShutdownConcurrency(3))
+	userCtx := AddUserToContext(context.Background(), "eve")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+
+	const numJobs = 8
+	for i := 0; i < numJobs; i++ {
+		_, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sleep"})
+		require.NoError(t, err)
+	}
+	require.Len(t, tracker.List(userCtx, false /* completed */, false /* all */), numJobs)
+
+	_, err := tracker.Shutdown(userCtx)
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	count, err := tracker.Shutdown(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, numJobs, count)
+	require.Empty(t, tracker.List(adminCtx, true /* completed */, true /* all */))
+
+	_, _, err = tracker.Start(userCtx, JobSpec{Command: "/bin/sleep"})
+	require.ErrorIs(t, err, ErrShutdown)
+}
+
+func TestStopOnClientDisconnect(t *testing.T) {
+	tracker := newTestTracker("exec sleep 60 2>&1")
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	start := func(stopOnDisconnect bool) string {
+		spec := JobSpec{Command: "/bin/sleep", StopOnClientDisconnect: stopOnDisconnect}
+		id, _, err := tracker.Start(userCtx, spec)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = tracker.Stop(userCtx, id, true /* cleanup */) })
+		return id
+	}
+	followAndDisconnect := func(id string, run bool) {
+		ctx, cancel := context.WithCancel(userCtx)
+		follow := tracker.FollowRunLogs
+		if !run {
+			follow = func(id string, start int, ctx context.Context) (<-chan Log, error) {
+				return tracker.GetLogChannel(id, true /* follow */, start, ctx)
+			}
+		}
+		ch, err := follow(id, 0, ctx)
+		require.NoError(t, err)
+		cancel()
+		for range ch {
+		}
+	}
+
+	// Other streams following the job's logs, even the owner's, do not
+	// stop it.
+	id := start(true)
+	followAndDisconnect(id, false)
+	ctx, cancel := context.WithTimeout(userCtx, 100*time.Millisecond)
+	defer cancel()
+	jd, err := tracker.Wait(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateRunning), jd.Status.State)
+
+	// The stream of the client that ran the job stops it.
+	followAndDisconnect(id, true)
+	ctx, cancel = context.WithTimeout(userCtx, 5*time.Second)
+	defer cancel()
+	jd, err = tracker.Wait(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateCompleted), jd.Status.State)
+	require.Equal(t, syscall.SIGKILL, jd.Status.Signal)
+
+	// Without the option, the job keeps running.
+	id = start(false)
+	followAndDisconnect(id, true)
+	ctx, cancel = context.WithTimeout(userCtx, 100*time.Millisecond)
+	defer cancel()
+	jd, err = tracker.Wait(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, JobState(JobStateRunning), jd.Status.State)
+}
+
+func TestLogChannelCancelRemovesOutfeed(t *testing.T) {
+	tracker := newTestTracker("exec sleep 60 2>&1")
+	userCtx := AddUserToContext(context.Background(), "eve")
+	adminCtx := AddAdminToContext(userCtx)
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sleep"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tracker.Stop(userCtx, id, true /* cleanup */) })
+
+	ctx, cancel := context.WithCancel(userCtx)
+	ch, err := tracker.GetLogChannel(id, true /* follow */, 0, ctx)
+	require.NoError(t, err)
+	stats, err := tracker.FeederStats(adminCtx, id)
+	require.NoError(t, err)
+	require.Len(t, stats.Outfeeds, 1)
+
+	// The follower is detached as soon as its context is cancelled, not
+	// when the job next outputs a line or completes.
+	cancel()
+	select {
+	case _, ok := <-ch:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("log channel not closed on cancel")
+	}
+	require.Eventually(t, func() bool {
+		stats, err := tracker.FeederStats(adminCtx, id)
+		return err == nil && len(stats.Outfeeds) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStopDeliversFinalLogs(t *testing.T) {
+	script := `exec 2>&1
+trap 'echo shutting down; exit 0' TERM
+echo started
+while :; do sleep 0.1; done`
+	tracker := newTestTracker(script, WithStopGracePeriod(5*time.Second))
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+
+	ch, err := tracker.GetLogChannel(id, true /* follow */, 0, userCtx)
+	require.NoError(t, err)
+	require.Equal(t, "started\n", string((<-ch).Line))
+
+	// Stop and clean up the job while following it. The job's final log
+	// line written after SIGTERM must reach the follower before its
+	// stream is closed by the cleanup.
+	stopped := make(chan error)
+	go func() { stopped <- tracker.Stop(userCtx, id, true /* cleanup */) }()
+
+	var lines []string
+	for l := range ch {
+		lines = append(lines, string(l.Line))
+	}
+	require.NoError(t, <-stopped)
+	require.Equal(t, []string{"shutting down\n"}, lines)
+}
+
+func TestStopDoesNotBlockTracker(t *testing.T) {
+	// The job ignores SIGTERM, so stopping it takes its whole grace period.
+	const gracePeriod = time.Second
+	tracker := newTestTracker("trap '' TERM; exec sleep 60 2>&1", WithStopGracePeriod(gracePeriod))
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sleep"})
+	require.NoError(t, err)
+
+	start := time.Now()
+	stopped := make(chan error)
+	go func() { stopped <- tracker.Stop(userCtx, id, true /* cleanup */) }()
+
+	var slowest time.Duration
+	var lists int
+	for done := false; !done; {
+		select {
+		case err := <-stopped:
+			require.NoError(t, err)
+			done = true
+		default:
+			listStart := time.Now()
+			tracker.List(userCtx, true /* completed */, false /* all */)
+			_, err := tracker.Get(userCtx, id)
+			if err != nil {
+				// The job has been cleaned up.
+				require.ErrorIs(t, err, ErrUnknown)
+			}
+			if d := time.Since(listStart); d > slowest {
+				slowest = d
+			}
+			lists++
+		}
+	}
+	require.GreaterOrEqual(t, time.Since(start), gracePeriod)
+	require.Greater(t, lists, 1)
+	require.Less(t, slowest, gracePeriod/2, "listing blocked while stopping")
+
+	_, err = tracker.Get(userCtx, id)
+	require.ErrorIs(t, err, ErrUnknown)
+}
+
+func TestCheckCgroups(t *testing.T) {
+	const allControllers = "cpuset cpu io memory pids\n"
+	tests := map[string]struct {
+		subtreeControl string // not created if empty
+		writeErr       error
+		err            string
+	}{
+		"healthy": {
+			subtreeControl: allControllers,
+		},
+		"no cgroup": {
+			err: "cgroups unavailable: open ",
+		},
+		"controller missing": {
+			subtreeControl: "cpu io memory pids\n",
+			err:            "cgroups unavailable: cpuset controller not enabled",
+		},
+		"read-only": {
+			subtreeControl: allControllers,
+			writeErr:       syscall.EROFS,
+			err:            "read-only file system",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := fakeCgroupRoot(t)
+			if tc.subtreeControl != "" {
+				filename := filepath.Join(root, "cgroup.subtree_control")
+				require.NoError(t, os.WriteFile(filename, []byte(tc.subtreeControl), 0644))
+			}
+			if tc.writeErr != nil {
+				failingWriteFile(t, 1, tc.writeErr)
+			}
+
+			tracker := newTestTracker("exec 2>&1")
+			userCtx := AddUserToContext(context.Background(), "eve")
+			err := tracker.CheckCgroups()
+			id, _, startErr := tracker.Start(userCtx, JobSpec{Command: "/bin/true"})
+			if tc.err == "" {
+				require.NoError(t, err)
+				require.NoError(t, startErr)
+				// Don't leave the job using the fake cgroup root.
+				_, err = tracker.Wait(userCtx, id)
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrCgroupsUnavailable)
+			require.ErrorContains(t, err, tc.err)
+			require.ErrorIs(t, startErr, ErrCgroupsUnavailable)
+		})
+	}
+}
+
+func TestTrackerLogsPage(t *testing.T) {
+	tracker := newTestTracker("exec 2>&1; seq 1 5")
+	userCtx := AddUserToContext(context.Background(), "eve")
+	otherCtx := AddUserToContext(context.Background(), "mallory")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	_, err = tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+
+	page, err := tracker.LogsPage(userCtx, id, 1, 3)
+	require.NoError(t, err)
+	require.Equal(t, 5, page.Total)
+	require.Len(t, page.Logs, 2)
+	require.Equal(t, "2\n", string(page.Logs[0].Line))
+	require.Equal(t, "3\n", string(page.Logs[1].Line))
+	require.Equal(t, 3, page.End)
+
+	page, err = tracker.LogsPage(userCtx, id, 4, 0)
+	require.NoError(t, err)
+	require.Len(t, page.Logs, 1)
+	require.Equal(t, 5, page.End)
+
+	_, err = tracker.LogsPage(otherCtx, id, 0, 0)
+	require.ErrorIs(t, err, ErrUnauthorized)
+	_, err = tracker.LogsPage(userCtx, "no-such-job", 0, 0)
+	require.ErrorIs(t, err, ErrUnknown)
+}
+
+func TestTrackerLogsPageLimits(t *testing.T) {
+	userCtx := AddUserToContext(context.Background(), "eve")
+	run := func(script string) (*Tracker, string) {
+		tracker := newTestTracker(script)
+		id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+		require.NoError(t, err)
+		_, err = tracker.Wait(userCtx, id)
+		require.NoError(t, err)
+		return tracker, id
+	}
+
+	// A page is cut short after maxPageLines lines, and the next page
+	// starts from its end.
+	tracker, id := run("exec 2>&1; seq 1 12000")
+	page, err := tracker.LogsPage(userCtx, id, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, page.Logs, maxPageLines)
+	require.Equal(t, maxPageLines, page.End)
+	require.Equal(t, 12000, page.Total)
+	page, err = tracker.LogsPage(userCtx, id, page.End, 0)
+	require.NoError(t, err)
+	require.Len(t, page.Logs, 2000)
+	require.Equal(t, "10001\n", string(page.Logs[0].Line))
+	require.Equal(t, 12000, page.End)
+
+	// It is cut short after maxPageBytes too.
+	tracker, id = run("exec 2>&1; head -c 3000000 /dev/zero | tr '\\0' x | fold -w 500")
+	page, err = tracker.LogsPage(userCtx, id, 0, 0)
+	require.NoError(t, err)
+	size := 0
+	for _, l := range page.Logs {
+		size += len(l.Line)
+	}
+	require.LessOrEqual(t, size, maxPageBytes)
+	require.Greater(t, size, maxPageBytes-501)
+	require.Equal(t, len(page.Logs), page.End)
+	require.Less(t, page.End, page.Total)
+}
+
+// TestConcurrentListAndCompletion lists and gets jobs while they start and
+// complete, checking each description is a consistent snapshot. It is most
+// useful with the race detector.
+func TestConcurrentListAndCompletion(t *testing.T) {
+	tracker := newTestTracker("exec 2>&1; echo hello")
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	const numJobs = 20
+	ids := make(chan string, numJobs)
+	var wg sync.WaitGroup
+	for i := 0; i < numJobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+			if err == nil {
+				ids <- id
+			}
+		}()
+	}
+
+	// consistent returns an error if jd is not a snapshot that a job
+	// running the script could be in.
+	consistent := func(jd JobDescription) error {
+		switch {
+		case jd.Status.State != JobStateRunning && jd.Status.State != JobStateCompleted:
+			return fmt.Errorf("job %s in state %d", jd.ID, jd.Status.State)
+		case jd.Status.Pid == 0:
+			return fmt.Errorf("job %s has no pid", jd.ID)
+		case jd.Status.State == JobStateCompleted && jd.Status.ExitReason() != "exited with code 0":
+			return fmt.Errorf("job %s completed with %s", jd.ID, jd.Status.ExitReason())
+		}
+		return nil
+	}
+
+	stop := make(chan struct{})
+	listErr := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				listErr <- nil
+				return
+			default:
+			}
+			for _, jd := range tracker.List(userCtx, true /* completed */, false /* all */) {
+				if err := consistent(jd); err != nil {
+					listErr <- err
+					return
+				}
+				if got, err := tracker.Get(userCtx, jd.ID); err == nil {
+					if err := consistent(got); err != nil {
+						listErr <- err
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(ids)
+	require.Len(t, ids, numJobs)
+	for id := range ids {
+		jd, err := tracker.Wait(userCtx, id)
+		require.NoError(t, err)
+		require.NoError(t, consistent(jd))
+		require.Equal(t, JobState(JobStateCompleted), jd.Status.State)
+	}
+	close(stop)
+	require.NoError(t, <-listErr)
+}
+
+func TestUserIDPrefix(t *testing.T) {
+	tracker := newTestTracker("exec 2>&1; echo hello", WithUserIDPrefix(true))
+	userCtx := AddUserToContext(context.Background(), "eve")
+	otherCtx := AddUserToContext(context.Background(), "mallory")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	require.Regexp(t, `^eve/sh-[0-9a-f]+$`, id)
+	_, err = tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+
+	// The owner can use the ID with or without the namespace.
+	shortID := strings.TrimPrefix(id, "eve/")
+	for _, lookupID := range []string{id, shortID} {
+		jd, err := tracker.Get(userCtx, lookupID)
+		require.NoError(t, err)
+		require.Equal(t, id, jd.ID)
+	}
+
+	// Others resolve an ID without a namespace in their own namespace.
+	_, err = tracker.Get(otherCtx, shortID)
+	require.ErrorIs(t, err, ErrUnknown)
+	_, err = tracker.Get(otherCtx, id)
+	require.ErrorIs(t, err, ErrUnauthorized)
+	_, err = tracker.Get(adminCtx, id)
+	require.NoError(t, err)
+
+	require.NoError(t, tracker.Stop(userCtx, shortID, true /* cleanup */))
+	_, err = tracker.Get(userCtx, id)
+	require.ErrorIs(t, err, ErrUnknown)
+
+	// The slash in the ID does not nest the job's cgroup.
+	require.Equal(t, filepath.Join(cgroupRoot, "eve%2Fsh-1"), cgroupDir("eve/sh-1"))
+}
+
+func TestExists(t *testing.T) {
+	tracker := newTestTracker("exit 0")
+	userCtx := AddUserToContext(context.Background(), "eve")
+	otherCtx := AddUserToContext(context.Background(), "mallory")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh"})
+	require.NoError(t, err)
+	_, err = tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		ctx      context.Context
+		id       string
+		expected bool
+	}{
+		"owned":       {ctx: userCtx, id: id, expected: true},
+		"admin":       {ctx: adminCtx, id: id, expected: true},
+		"foreign":     {ctx: otherCtx, id: id, expected: false},
+		"nonexistent": {ctx: userCtx, id: "sh-nope", expected: false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			exists, err := tracker.Exists(tc.ctx, tc.id)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, exists)
+		})
+	}
+
+	_, err = tracker.Exists(context.Background(), id)
+	require.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestChown(t *testing.T) {
+	tracker := newTestTracker("exec sleep 60 2>&1")
+	userCtx := AddUserToContext(context.Background(), "eve")
+	newOwnerCtx := AddUserToContext(context.Background(), "mallory")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sleep"})
+	require.NoError(t, err)
+	require.ErrorIs(t, tracker.Stop(newOwnerCtx, id, false /* cleanup */), ErrUnauthorized)
+
+	// Only admins can change the owner.
+	_, err = tracker.Chown(userCtx, id, "mallory")
+	require.ErrorIs(t, err, ErrUnauthorized)
+	_, err = tracker.Chown(adminCtx, "no-such-job", "mallory")
+	require.ErrorIs(t, err, ErrUnknown)
+
+	prev, err := tracker.Chown(adminCtx, id, "mallory")
+	require.NoError(t, err)
+	require.Equal(t, "eve", prev)
+
+	// The new owner can manage the job, and the previous owner cannot.
+	_, err = tracker.Get(userCtx, id)
+	require.ErrorIs(t, err, ErrUnauthorized)
+	require.NoError(t, tracker.Stop(newOwnerCtx, id, false /* cleanup */))
+	jd, err := tracker.Get(newOwnerCtx, id)
+	require.NoError(t, err)
+	require.Equal(t, "mallory", jd.Status.Owner)
+	require.Equal(t, JobState(JobStateCompleted), jd.Status.State)
+}
+
+func TestTrackerSetupTimeout(t *testing.T) {
+	tracker := newTestTracker("exec 2>&1; exit 0", WithSetupTimeout(5*time.Second))
+	userCtx := AddUserToContext(context.Background(), "eve")
+
+	// The server's setup timeout replaces any the client gave.
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sh", SetupTimeout: time.Hour})
+	require.NoError(t, err)
+	jd, err := tracker.Wait(userCtx, id)
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Second, jd.Spec.SetupTimeout)
+}
+
+func TestMaxCompletedPerUser(t *testing.T) {
+	tracker := newTestTracker("exit 0", WithMaxCompletedPerUser(2))
+	eveCtx := AddUserToContext(context.Background(), "eve")
+	malloryCtx := AddUserToContext(context.Background(), "mallory")
+
+	run := func(ctx context.Context) string {
+		id, _, err := tracker.Start(ctx, JobSpec{Command: "/bin/true"})
+		require.NoError(t, err)
+		_, err = tracker.Wait(ctx, id)
+		require.NoError(t, err)
+		return id
+	}
+	malloryID := run(malloryCtx)
+	var ids []string
+	for i := 0; i < 4; i++ {
+		ids = append(ids, run(eveCtx))
+	}
+
+	// Jobs are evicted once they have been waited for, so wait for the
+	// eviction after the last job completes.
+	listed := func(ctx context.Context) []string {
+		var ids []string
+		for _, jd := range tracker.List(ctx, true /* completed */, false /* all */) {
+			ids = append(ids, jd.ID)
+		}
+		return ids
+	}
+	require.Eventually(t, func() bool { return len(listed(eveCtx)) == 2 }, time.Second, 10*time.Millisecond)
+	require.ElementsMatch(t, ids[2:], listed(eveCtx))
+	for _, id := range ids[:2] {
+		_, err := tracker.Get(eveCtx, id)
+		require.ErrorIs(t, err, ErrUnknown)
+	}
+
+	// Other users' jobs are not evicted.
+	require.Equal(t, []string{malloryID}, listed(malloryCtx))
+}
+
+func TestEvictionDoesNotBlockTracker(t *testing.T) {
+	tracker := newTestTracker("echo hello", WithMaxCompletedPerUser(1))
+	eveCtx := AddUserToContext(context.Background(), "eve")
+
+	id, _, err := tracker.Start(eveCtx, JobSpec{Command: "/bin/echo"})
+	require.NoError(t, err)
+	_, err = tracker.Wait(eveCtx, id)
+	require.NoError(t, err)
+	// A reader that never reads holds off the job's logs draining, so
+	// cleaning it up waits for the drain timeout.
+	_, err = tracker.GetLogChannel(id, false /* follow */, 0, eveCtx)
+	require.NoError(t, err)
+
+	id2, _, err := tracker.Start(eveCtx, JobSpec{Command: "/bin/echo"})
+	require.NoError(t, err)
+	_, err = tracker.Wait(eveCtx, id2)
+	require.NoError(t, err)
+
+	// The first job is evicted at once, and the tracker is not locked
+	// while it is cleaned up.
+	require.Eventually(t, func() bool {
+		jds := tracker.List(eveCtx, true /* completed */, false /* all */)
+		return len(jds) == 1 && jds[0].ID == id2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestShutdownReapsJobs(t *testing.T) {
+	tracker := newTestTracker("exec sleep 60 2>&1")
+	userCtx := AddUserToContext(context.Background(), "eve")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+
+	var jobs []*Job
+	for i := 0; i < 4; i++ {
+		id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sleep"})
+		require.NoError(t, err)
+		jobs = append(jobs, tracker.jobs[id])
+	}
+	completed, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sleep"})
+	require.NoError(t, err)
+	require.NoError(t, tracker.Stop(userCtx, completed, false /* cleanup */))
+
+	// Only the running jobs are stopped and counted.
+	count, err := tracker.Shutdown(adminCtx)
+	require.NoError(t, err)
+	require.Equal(t, len(jobs), count)
+	for _, j := range jobs {
+		jd := j.Description()
+		require.Equal(t, JobState(JobStateCompleted), jd.Status.State, j.ID)
+		require.Equal(t, syscall.SIGKILL, jd.Status.Signal, j.ID)
+	}
+}
+
+func TestShutdownContextCutsShortGracePeriod(t *testing.T) {
+	// The job ignores SIGTERM, so would take its whole grace period.
+	tracker := newTestTracker("trap '' TERM; exec sleep 60 2>&1", WithStopGracePeriod(time.Minute))
+	userCtx := AddUserToContext(context.Background(), "eve")
+	adminCtx := AddUserToContext(context.Background(), "admin")
+
+	id, _, err := tracker.Start(userCtx, JobSpec{Command: "/bin/sleep"})
+	require.NoError(t, err)
+	j := tracker.jobs[id]
+
+	ctx, cancel := context.WithTimeout(adminCtx, 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	count, err := tracker.Shutdown(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Less(t, time.Since(start), 10*time.Second)
+
+	jd := j.Wait(context.Background())
+	require.Equal(t, syscall.SIGKILL, jd.Status.Signal)
+}
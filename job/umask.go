@@ -0,0 +1,72 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var ErrInvalidUmask = errors.New("invalid umask")
+
+// maxUmask is the largest umask, masking all permission bits.
+const maxUmask = 0777
+
+// Umask is the file mode creation mask of a job. The zero value leaves the
+// job with the umask of the server, so a umask of 0 is set with Set.
+type Umask struct {
+	Mask uint32
+	Set  bool
+}
+
+// UnmarshalText unmarshals a string ([]byte) into a Umask. It is used by
+// kong to unmarshal the command line argument into a structured value.
+//
+// The format of the input string is an octal number, such as 022 or 0077,
+// as taken by umask(1).
+func (u *Umask) UnmarshalText(b []byte) error {
+	mask, err := strconv.ParseUint(string(b), 8, 32)
+	if err != nil {
+		return fmt.Errorf("%w: %q is not an octal number", ErrInvalidUmask, b)
+	}
+	*u = Umask{Mask: uint32(mask), Set: true}
+	return u.Validate()
+}
+
+// MarshalJSON marshals a Umask as a string in the format accepted by
+// UnmarshalText, or as null if it is not set.
+func (u Umask) MarshalJSON() ([]byte, error) {
+	if !u.Set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(u.String())
+}
+
+// UnmarshalJSON unmarshals a JSON string in the format accepted by
+// UnmarshalText into a Umask. Null leaves the umask unset.
+func (u *Umask) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*u = Umask{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: %s is not a string", ErrInvalidUmask, data)
+	}
+	return u.UnmarshalText([]byte(s))
+}
+
+func (u Umask) String() string {
+	if !u.Set {
+		return "none"
+	}
+	return fmt.Sprintf("%04o", u.Mask)
+}
+
+// Validate checks that the umask only masks permission bits.
+func (u Umask) Validate() error {
+	if u.Mask > maxUmask {
+		return fmt.Errorf("%w: %o has bits other than permission bits", ErrInvalidUmask, u.Mask)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUmaskUnmarshalText(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected Umask
+		err      bool
+	}{
+		"common":            {input: "022", expected: Umask{Mask: 022, Set: true}},
+		"leading zeros":     {input: "0077", expected: Umask{Mask: 077, Set: true}},
+		"no leading zero":   {input: "27", expected: Umask{Mask: 027, Set: true}},
+		"zero":              {input: "0", expected: Umask{Mask: 0, Set: true}},
+		"all":               {input: "777", expected: Umask{Mask: 0777, Set: true}},
+		"not octal":         {input: "089", err: true},
+		"not a number":      {input: "rwx", err: true},
+		"empty":             {input: "", err: true},
+		"negative":          {input: "-022", err: true},
+		"beyond permission": {input: "1022", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var u Umask
+			err := u.UnmarshalText([]byte(tc.input))
+			if tc.err {
+				require.ErrorIs(t, err, ErrInvalidUmask)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, u)
+		})
+	}
+}
+
+func TestUmaskString(t *testing.T) {
+	require.Equal(t, "none", Umask{}.String())
+	require.Equal(t, "0000", Umask{Set: true}.String())
+	require.Equal(t, "0022", Umask{Mask: 022, Set: true}.String())
+}
@@ -0,0 +1,122 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidQuantity = errors.New("invalid quantity")
+
+// ByteSize is a number of bytes, such as a memory limit.
+type ByteSize uint64
+
+// byteSizeUnits are the suffixes a ByteSize may be given with and their
+// multipliers. The binary units are listed first so "Mi" is not matched as
+// "M" followed by junk.
+var byteSizeUnits = []struct {
+	suffix     string
+	multiplier uint64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+	{"K", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+}
+
+// UnmarshalText unmarshals a string ([]byte) into a ByteSize. It is used by
+// kong to unmarshal the command line argument into a structured value.
+//
+// The format of the input string is a number of bytes, optionally followed
+// by a binary (Ki, Mi, Gi, Ti) or decimal (K, M, G, T) unit, such as 512Mi
+// or 1.5G. A number without a unit must be a whole number of bytes.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	s := string(text)
+	multiplier := uint64(1)
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, multiplier = strings.TrimSuffix(s, u.suffix), u.multiplier
+			break
+		}
+	}
+
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		if n > math.MaxUint64/multiplier {
+			return fmt.Errorf("%w: %s is too large", ErrInvalidQuantity, text)
+		}
+		*b = ByteSize(n * multiplier)
+		return nil
+	}
+	if multiplier == 1 {
+		return fmt.Errorf("%w: %q is not a number of bytes", ErrInvalidQuantity, text)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("%w: %q is not a number of bytes", ErrInvalidQuantity, text)
+	}
+	if f*float64(multiplier) >= math.MaxUint64 {
+		return fmt.Errorf("%w: %s is too large", ErrInvalidQuantity, text)
+	}
+	*b = ByteSize(f * float64(multiplier))
+	return nil
+}
+
+// UnmarshalJSON unmarshals a JSON number of bytes or a string in the format
+// accepted by UnmarshalText into a ByteSize.
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONQuantity(data, b.UnmarshalText)
+}
+
+// MilliCPU is an amount of CPU time in thousandths of a CPU.
+type MilliCPU uint32
+
+// UnmarshalText unmarshals a string ([]byte) into a MilliCPU. It is used by
+// kong to unmarshal the command line argument into a structured value.
+//
+// The format of the input string is either a whole number of milliCPUs,
+// optionally followed by "m" (500 or 500m), or a number of CPUs with a
+// decimal point (1.5 or 2.0). A whole number without a decimal point is
+// milliCPUs rather than CPUs for compatibility with earlier versions.
+func (c *MilliCPU) UnmarshalText(text []byte) error {
+	s := string(text)
+	if !strings.Contains(s, ".") {
+		n, err := strconv.ParseUint(strings.TrimSuffix(s, "m"), 10, 32)
+		if err != nil {
+			return fmt.Errorf("%w: %q is not a number of milliCPUs", ErrInvalidQuantity, text)
+		}
+		*c = MilliCPU(n)
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("%w: %q is not a number of CPUs", ErrInvalidQuantity, text)
+	}
+	milli := math.Round(f * 1000)
+	if milli > math.MaxUint32 {
+		return fmt.Errorf("%w: %s is too large", ErrInvalidQuantity, text)
+	}
+	*c = MilliCPU(milli)
+	return nil
+}
+
+// UnmarshalJSON unmarshals a JSON number of milliCPUs or a string in the
+// format accepted by UnmarshalText into a MilliCPU.
+func (c *MilliCPU) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONQuantity(data, c.UnmarshalText)
+}
+
+// unmarshalJSONQuantity unmarshals a JSON number or string with
+// unmarshalText. A number is passed on as its text.
+func unmarshalJSONQuantity(data []byte, unmarshalText func([]byte) error) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return unmarshalText([]byte(s))
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidQuantity, data)
+	}
+	return unmarshalText([]byte(n))
+}
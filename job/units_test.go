@@ -0,0 +1,90 @@
+package job
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteSizeUnmarshalText(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected ByteSize
+		err      bool
+	}{
+		"bare number":      {input: "1048576", expected: 1 << 20},
+		"zero":             {input: "0", expected: 0},
+		"kibibytes":        {input: "64Ki", expected: 64 << 10},
+		"mebibytes":        {input: "512Mi", expected: 512 << 20},
+		"gibibytes":        {input: "1Gi", expected: 1 << 30},
+		"tebibytes":        {input: "2Ti", expected: 2 << 40},
+		"megabytes":        {input: "100M", expected: 100e6},
+		"gigabytes":        {input: "2G", expected: 2e9},
+		"fractional":       {input: "1.5Gi", expected: 3 << 29},
+		"empty":            {input: "", err: true},
+		"unit only":        {input: "Mi", err: true},
+		"fraction no unit": {input: "1.5", err: true},
+		"negative":         {input: "-1Mi", err: true},
+		"unknown unit":     {input: "1MB", err: true},
+		"overflow":         {input: "20000000Ti", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var b ByteSize
+			err := b.UnmarshalText([]byte(tc.input))
+			if tc.err {
+				require.ErrorIs(t, err, ErrInvalidQuantity)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, b)
+		})
+	}
+}
+
+func TestMilliCPUUnmarshalText(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected MilliCPU
+		err      bool
+	}{
+		"bare number":    {input: "500", expected: 500},
+		"milli suffix":   {input: "250m", expected: 250},
+		"cores":          {input: "1.5", expected: 1500},
+		"whole cores":    {input: "2.0", expected: 2000},
+		"fraction cores": {input: "0.25", expected: 250},
+		"rounded":        {input: "0.0015", expected: 2},
+		"empty":          {input: "", err: true},
+		"negative":       {input: "-1.5", err: true},
+		"not a number":   {input: "lots", err: true},
+		"fraction milli": {input: "1.5m", err: true},
+		"overflow":       {input: "5000000.0", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var c MilliCPU
+			err := c.UnmarshalText([]byte(tc.input))
+			if tc.err {
+				require.ErrorIs(t, err, ErrInvalidQuantity)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, c)
+		})
+	}
+}
+
+func TestResourceLimitsUnmarshalJSON(t *testing.T) {
+	var r ResourceLimits
+	require.NoError(t, json.Unmarshal([]byte(`{"memory": "1Gi", "cpu": 1.5}`), &r))
+	require.Equal(t, ResourceLimits{Memory: 1 << 30, CPU: 1500}, r)
+
+	r = ResourceLimits{}
+	require.NoError(t, json.Unmarshal([]byte(`{"memory": 1048576, "cpu": 500}`), &r))
+	require.Equal(t, ResourceLimits{Memory: 1 << 20, CPU: 500}, r)
+
+	require.Error(t, json.Unmarshal([]byte(`{"memory": true}`), &r))
+}
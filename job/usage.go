@@ -0,0 +1,67 @@
+package job
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResourceUsage is the resources a job is using, or used, as read from its
+// cgroup. Each is zero if it could not be read, such as on kernels without
+// memory.peak.
+type ResourceUsage struct {
+	// Memory is the memory the job is using, in bytes. It is zero once
+	// the job has completed.
+	Memory uint64
+	// PeakMemory is the most memory the job has used at once, in bytes.
+	PeakMemory uint64
+	// CPUTime is the CPU time the job has used, in user and system mode.
+	CPUTime time.Duration
+}
+
+// ResourceUsage returns the resources the job is using, read from its
+// cgroup while it is running. Once it has completed and its cgroup has been
+// removed, it returns the usage last read when the job was reaped.
+func (j *Job) ResourceUsage() ResourceUsage {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.resourceUsage()
+}
+
+// resourceUsage is ResourceUsage with j.mu held.
+func (j *Job) resourceUsage() ResourceUsage {
+	if j.Status.State == JobStateRunning {
+		j.usage = readResourceUsage(j.ID)
+	}
+	return j.usage
+}
+
+// readResourceUsage reads the resource usage of the job identified by id
+// from its cgroup.
+func readResourceUsage(id string) ResourceUsage {
+	dir := cgroupDir(id)
+	usage := ResourceUsage{
+		Memory:     readCgroupUint(filepath.Join(dir, "memory.current")),
+		PeakMemory: readCgroupUint(filepath.Join(dir, "memory.peak")),
+	}
+	if usec, ok := readEvents(id, "cpu.stat")["usage_usec"]; ok {
+		usage.CPUTime = time.Duration(usec) * time.Microsecond
+	}
+	return usage
+}
+
+// readCgroupUint reads a cgroup file holding a single number, returning
+// zero if it cannot be read.
+func readCgroupUint(name string) uint64 {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return 0
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
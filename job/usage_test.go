@@ -0,0 +1,54 @@
+package job
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceUsage(t *testing.T) {
+	root := fakeCgroupRoot(t)
+	dir := filepath.Join(root, "test-1")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	writeFile := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	writeFile("memory.current", "4096\n")
+	writeFile("memory.peak", "8192\n")
+	writeFile("cpu.stat", "usage_usec 1500\nuser_usec 1000\nsystem_usec 500\n")
+
+	j := NewJob("test-1", JobSpec{Command: "/bin/sh"}, shellArgMaker("exec 2>&1; echo ready; exec sleep 10"))
+	j.noNamespaces = true
+	require.NoError(t, j.Start("owner"))
+	t.Cleanup(j.Cleanup)
+	<-j.AttachOutfeed(true /* follow */, 0, nil)
+
+	want := ResourceUsage{Memory: 4096, PeakMemory: 8192, CPUTime: 1500 * time.Microsecond}
+	require.Equal(t, want, j.ResourceUsage())
+
+	// The usage of a running job is read live.
+	writeFile("memory.current", "6144\n")
+	want.Memory = 6144
+	require.Equal(t, want, j.ResourceUsage())
+	require.Equal(t, want, j.Description().Usage)
+
+	// A completed job keeps the usage last read, without its current
+	// memory, after its cgroup is removed.
+	j.Stop(context.Background())
+	require.NoError(t, os.RemoveAll(dir))
+	want.Memory = 0
+	require.Equal(t, want, j.ResourceUsage())
+	require.Equal(t, want, j.Description().Usage)
+}
+
+func TestResourceUsageUnavailable(t *testing.T) {
+	fakeCgroupRoot(t)
+	j, _, err := runToCompletion(t, "exit 0")
+	require.NoError(t, err)
+	require.Equal(t, ResourceUsage{}, j.ResourceUsage())
+}
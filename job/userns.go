@@ -0,0 +1,107 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"syscall"
+)
+
+// IDMapping is the range of host user and group IDs that the IDs of a job
+// isolated in a user namespace are mapped to, from root (0) in the job.
+type IDMapping struct {
+	HostID uint32
+	Size   uint32
+}
+
+// DefaultIDMapping maps the IDs of jobs isolated in a user namespace unless
+// set with WithUserNamespaceIDs. It is the range conventionally given to
+// the first user in /etc/subuid.
+var DefaultIDMapping = IDMapping{HostID: 100000, Size: 65536}
+
+// Check returns an error if the mapping would give jobs host IDs they must
+// not have: root's, those of the server's own user uid and group gid, or
+// IDs beyond the largest there is.
+func (m IDMapping) Check(uid, gid uint32) error {
+	if m.HostID == 0 {
+		return errors.New("user namespace host ID cannot be 0, as root in jobs would be root on the host")
+	}
+	if m.Size == 0 {
+		return errors.New("user namespace size cannot be 0")
+	}
+	// The last ID, 2^32-1, is not a valid ID.
+	if uint64(m.HostID)+uint64(m.Size) > math.MaxUint32 {
+		return fmt.Errorf("user namespace IDs %d to %d exceed the largest ID", m.HostID, uint64(m.HostID)+uint64(m.Size)-1)
+	}
+	if m.contains(uid) {
+		return fmt.Errorf("user namespace IDs %d to %d include the server's user ID %d", m.HostID, m.HostID+m.Size-1, uid)
+	}
+	if m.contains(gid) {
+		return fmt.Errorf("user namespace IDs %d to %d include the server's group ID %d", m.HostID, m.HostID+m.Size-1, gid)
+	}
+	return nil
+}
+
+// contains returns whether id is one of the mapping's host IDs.
+func (m IDMapping) contains(id uint32) bool {
+	return id >= m.HostID && id-m.HostID < m.Size
+}
+
+// WithUserNamespaceIDs sets the host IDs the users and groups of jobs
+// isolated in a user namespace are mapped to. All such jobs share the
+// mapping, so the range should not be used by anything else on the host.
+func WithUserNamespaceIDs(m IDMapping) TrackerOption {
+	return func(t *Tracker) {
+		t.userNSIDs = m
+	}
+}
+
+// jobSyncFd is the file descriptor ExecPart1 passes a pipe to ExecPart2 on
+// for a job isolated in a user namespace, after the job's stderr. ExecPart1
+// closes the pipe once it has put the job in its cgroup.
+const jobSyncFd = 4
+
+// isolateUsers sets up attr to start the job's process in a new user
+// namespace with its IDs mapped to the job's host IDs. The other namespaces
+// created with it are owned by the user namespace, so root in the job can
+// still set them up.
+func (j *Job) isolateUsers(attr *syscall.SysProcAttr) {
+	m := j.userNSIDs
+	if m.Size == 0 {
+		m = DefaultIDMapping
+	}
+	attr.Cloneflags |= syscall.CLONE_NEWUSER
+	attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: int(m.HostID), Size: int(m.Size)}}
+	attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: int(m.HostID), Size: int(m.Size)}}
+	// The mappings are written by the privileged server, so the job can
+	// be allowed to set its supplementary groups.
+	attr.GidMappingsEnableSetgroups = true
+	// Without switching to root in the namespace, the process would keep
+	// the server's host IDs, which are not mapped into it.
+	attr.Credential = &syscall.Credential{Uid: 0, Gid: 0}
+}
+
+// setupCgroupFor creates the cgroup of the job identified by id with the
+// resource limits r and puts the process pid in it. ExecPart2 does this for
+// itself, except in a user namespace where it cannot write to the host's
+// cgroups, so ExecPart1 does it instead.
+func setupCgroupFor(id string, pid int, r ResourceLimits) error {
+	if err := newCgroup(id, pid); err != nil {
+		return err
+	}
+	return setCgroupLimits(id, r)
+}
+
+// waitForCgroup waits for ExecPart1 to close the pipe on jobSyncFd, once it
+// has put the job in its cgroup, so that the job's setup and command are
+// accounted to it and limited by it.
+func waitForCgroup() error {
+	f := os.NewFile(jobSyncFd, "sync")
+	defer f.Close()
+	if _, err := io.Copy(io.Discard, f); err != nil {
+		return fmt.Errorf("could not wait to be put in cgroup: %w", err)
+	}
+	return nil
+}
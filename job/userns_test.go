@@ -0,0 +1,71 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDMappingCheck(t *testing.T) {
+	require.NoError(t, DefaultIDMapping.Check(1000, 1000))
+	require.NoError(t, IDMapping{HostID: 1001, Size: 10}.Check(1000, 1000))
+	require.NoError(t, IDMapping{HostID: 990, Size: 10}.Check(1000, 1000))
+
+	for _, m := range []IDMapping{
+		{HostID: 0, Size: 65536},
+		{HostID: 100000, Size: 0},
+		{HostID: math.MaxUint32 - 10, Size: 11},
+		{HostID: 1000, Size: 1},
+		{HostID: 991, Size: 10},
+	} {
+		require.Error(t, m.Check(1000, 1000), "%+v", m)
+	}
+	// The server's group is outside the range too.
+	require.ErrorContains(t, IDMapping{HostID: 100000, Size: 10}.Check(0, 100009), "group ID 100009")
+}
+
+func TestIsolateUsers(t *testing.T) {
+	j := NewJob("id-1", JobSpec{Command: "/bin/true", IsolateUsers: true}, nil)
+	attr := &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWNS}
+	j.isolateUsers(attr)
+	require.Equal(t, uintptr(syscall.CLONE_NEWNS|syscall.CLONE_NEWUSER), attr.Cloneflags)
+	expected := []syscall.SysProcIDMap{{ContainerID: 0, HostID: 100000, Size: 65536}}
+	require.Equal(t, expected, attr.UidMappings)
+	require.Equal(t, expected, attr.GidMappings)
+	require.Equal(t, &syscall.Credential{Uid: 0, Gid: 0}, attr.Credential)
+
+	j.userNSIDs = IDMapping{HostID: 200000, Size: 1000}
+	j.isolateUsers(attr)
+	expected = []syscall.SysProcIDMap{{ContainerID: 0, HostID: 200000, Size: 1000}}
+	require.Equal(t, expected, attr.UidMappings)
+	require.Equal(t, expected, attr.GidMappings)
+
+	// A process started with it is root in the job, but the mapped user
+	// on the host.
+	attr = &syscall.SysProcAttr{}
+	j.isolateUsers(attr)
+	cmd := exec.Command("/bin/sleep", "60")
+	cmd.SysProcAttr = attr
+	err := cmd.Start()
+	if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EINVAL) {
+		t.Skip("user namespaces are not available")
+	}
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	uidMap, err := os.ReadFile(fmt.Sprintf("/proc/%d/uid_map", cmd.Process.Pid))
+	require.NoError(t, err)
+	require.Equal(t, []string{"0", "200000", "1000"}, strings.Fields(string(uidMap)))
+	status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", cmd.Process.Pid))
+	require.NoError(t, err)
+	require.Contains(t, string(status), "Uid:\t200000\t200000\t200000\t200000\n")
+}
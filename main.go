@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+
 	"github.com/alecthomas/kong"
 	"github.com/camh-/jobber/cli"
 )
@@ -14,17 +16,29 @@ type config struct {
 	Version kong.VersionFlag `short:"V" help:"Print version information"`
 
 	// Server commands
-	Serve    cli.CmdServe        `cmd:"" help:"Serve the JobExecutor gRPC service"`
-	Shutdown cli.CmdShutdown     `cmd:"" help:"kill all jobs and shutdown server"`
-	Rc       cli.CmdRunContainer `cmd:"" hidden:""`
-	Rj       cli.CmdRunJob       `cmd:"" hidden:""`
+	Serve       cli.CmdServe        `cmd:"" help:"Serve the JobExecutor gRPC service"`
+	Shutdown    cli.CmdShutdown     `cmd:"" help:"kill all jobs and shutdown server"`
+	Drain       cli.CmdDrain        `cmd:"" help:"Stop a server starting new jobs while its jobs run to completion, such as for maintenance (admin only)"`
+	DrainStatus cli.CmdDrainStatus  `cmd:"" name:"drain-status" help:"Show the progress of draining a server (admin only)"`
+	Chown       cli.CmdChown        `cmd:"" help:"Change the owner of a job (admin only)"`
+	Rc          cli.CmdRunContainer `cmd:"" hidden:""`
+	Rj          cli.CmdRunJob       `cmd:"" hidden:""`
+	Health      cli.CmdHealth       `cmd:"" hidden:"" help:"Check the health of a jobber server as a load balancer would"`
+	GenCerts    cli.CmdGenCerts     `cmd:"" name:"gen-certs" help:"Generate a CA, server cert and user certs for trying out jobber"`
 
 	// Client commands
-	Run    cli.CmdRun    `cmd:"" help:"Run a job on a remote jobber server"`
-	Stop   cli.CmdStop   `cmd:"" help:"Stop a job on a remote jobber server"`
-	Status cli.CmdStatus `cmd:"" help:"Get status of a job on a remote jobber server"`
-	List   cli.CmdList   `cmd:"" help:"List jobs on a remote jobber server"`
-	Logs   cli.CmdLogs   `cmd:"" help:"Get logs (output) of job on remote jobber server"`
+	Run          cli.CmdRun          `cmd:"" help:"Run a job on a remote jobber server"`
+	ExecSync     cli.CmdExecSync     `cmd:"" name:"exec-sync" help:"Run a short job on a remote jobber server and wait for its output and exit status"`
+	Stop         cli.CmdStop         `cmd:"" help:"Stop a job on a remote jobber server"`
+	Cancel       cli.CmdCancel       `cmd:"" help:"Cancel a queued job on a remote jobber server before it runs"`
+	Status       cli.CmdStatus       `cmd:"" help:"Get status of a job on a remote jobber server"`
+	Exists       cli.CmdExists       `cmd:"" help:"Exit with status 0 if a job exists on a remote jobber server, otherwise 1"`
+	Inspect      cli.CmdInspect      `cmd:"" help:"Show everything known about a job on a remote jobber server"`
+	StatsHistory cli.CmdStatsHistory `cmd:"" name:"stats-history" help:"Show the resource usage of a job over its run, for jobs run with --sample-resources"`
+	List         cli.CmdList         `cmd:"" help:"List jobs on a remote jobber server"`
+	Logs         cli.CmdLogs         `cmd:"" help:"Get logs (output) of job on remote jobber server"`
+	Watch        cli.CmdWatch        `cmd:"" help:"Follow a job's output interleaved with its starting, hitting limits and completing, in the order they happened"`
+	Debug        cli.CmdDebug        `cmd:"" help:"Show internal state of a remote jobber server (admin only)"`
 }
 
 func main() {
@@ -34,5 +48,18 @@ func main() {
 	// kctx.Run() will dispatch to the Run method of whichever subcommand
 	// is on the command line.
 	err := kctx.Run()
+	if code, ok := exitCode(err); ok {
+		// The command has already said all it has to say.
+		kctx.Exit(code)
+	}
 	kctx.FatalIfErrorf(err)
 }
+
+// exitCode returns the code to exit with if err is a cli.ExitCode.
+func exitCode(err error) (int, bool) {
+	var code cli.ExitCode
+	if errors.As(err, &code) {
+		return int(code), true
+	}
+	return 0, false
+}
@@ -20,10 +20,49 @@ const _ = grpc.SupportPackageIsVersion7
 type JobExecutorClient interface {
 	Run(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*RunResponse, error)
 	Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*StopResponse, error)
+	// Cancel removes a queued job from the queue without it ever running,
+	// leaving it in the JOBSTATE_CANCELLED state. It fails with
+	// FAILED_PRECONDITION for a job that is not queued, such as one that has
+	// already started, which must be stopped instead.
+	Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error)
 	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Exists returns whether a job exists that the caller may see, which is
+	// cheaper than Status for scripts polling for a job. A job of another
+	// user does not exist for a caller who is not an admin, so Exists does
+	// not reveal other users' jobs.
+	Exists(ctx context.Context, in *ExistsRequest, opts ...grpc.CallOption) (*ExistsResponse, error)
+	// ResourceHistory returns the resource usage of a job sampled over its
+	// run, for jobs run with sample_resources. It fails with
+	// FAILED_PRECONDITION for other jobs.
+	ResourceHistory(ctx context.Context, in *ResourceHistoryRequest, opts ...grpc.CallOption) (*ResourceHistoryResponse, error)
 	Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (JobExecutor_LogsClient, error)
+	// LogsPage returns a range of a job's output lines along with the number
+	// of lines output so far, for paging through the output of a job.
+	LogsPage(ctx context.Context, in *LogsPageRequest, opts ...grpc.CallOption) (*LogsPage, error)
+	// RunSync runs a job to completion and returns its exit status and output
+	// in a single response. It is for short commands. The job is removed from
+	// the server once it completes.
+	RunSync(ctx context.Context, in *RunSyncRequest, opts ...grpc.CallOption) (*RunSyncResponse, error)
 	Shutdown(ctx context.Context, in *ShutdownRequest, opts ...grpc.CallOption) (*ShutdownResponse, error)
+	// Drain stops the server starting new jobs while its running and queued
+	// jobs run to completion, such as before taking a node of a deployment
+	// out of service for maintenance. New jobs fail with UNAVAILABLE and the
+	// server reports itself as not serving to health checks. Drain returns at
+	// once with the jobs remaining; poll DrainStatus for progress until the
+	// server is drained and ready to shut down. It is only available to
+	// admins.
+	Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainResponse, error)
+	// DrainStatus returns the progress of draining the server. It is only
+	// available to admins.
+	DrainStatus(ctx context.Context, in *DrainStatusRequest, opts ...grpc.CallOption) (*DrainStatusResponse, error)
+	// Chown changes the owner of a job, such as when its owner has left, so
+	// that another user can manage it. It is only available to admins.
+	Chown(ctx context.Context, in *ChownRequest, opts ...grpc.CallOption) (*ChownResponse, error)
+	// DebugFeeder returns the internal state of a job's log feeder, which
+	// distributes the job's output to clients streaming its logs. It is only
+	// available to admins.
+	DebugFeeder(ctx context.Context, in *DebugFeederRequest, opts ...grpc.CallOption) (*DebugFeederResponse, error)
 }
 
 type jobExecutorClient struct {
@@ -52,6 +91,15 @@ func (c *jobExecutorClient) Stop(ctx context.Context, in *StopRequest, opts ...g
 	return out, nil
 }
 
+func (c *jobExecutorClient) Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error) {
+	out := new(CancelResponse)
+	err := c.cc.Invoke(ctx, "/JobExecutor/Cancel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *jobExecutorClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
 	out := new(ListResponse)
 	err := c.cc.Invoke(ctx, "/JobExecutor/List", in, out, opts...)
@@ -70,6 +118,24 @@ func (c *jobExecutorClient) Status(ctx context.Context, in *StatusRequest, opts
 	return out, nil
 }
 
+func (c *jobExecutorClient) Exists(ctx context.Context, in *ExistsRequest, opts ...grpc.CallOption) (*ExistsResponse, error) {
+	out := new(ExistsResponse)
+	err := c.cc.Invoke(ctx, "/JobExecutor/Exists", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobExecutorClient) ResourceHistory(ctx context.Context, in *ResourceHistoryRequest, opts ...grpc.CallOption) (*ResourceHistoryResponse, error) {
+	out := new(ResourceHistoryResponse)
+	err := c.cc.Invoke(ctx, "/JobExecutor/ResourceHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *jobExecutorClient) Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (JobExecutor_LogsClient, error) {
 	stream, err := c.cc.NewStream(ctx, &JobExecutor_ServiceDesc.Streams[0], "/JobExecutor/Logs", opts...)
 	if err != nil {
@@ -102,6 +168,24 @@ func (x *jobExecutorLogsClient) Recv() (*LogsResponse, error) {
 	return m, nil
 }
 
+func (c *jobExecutorClient) LogsPage(ctx context.Context, in *LogsPageRequest, opts ...grpc.CallOption) (*LogsPage, error) {
+	out := new(LogsPage)
+	err := c.cc.Invoke(ctx, "/JobExecutor/LogsPage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobExecutorClient) RunSync(ctx context.Context, in *RunSyncRequest, opts ...grpc.CallOption) (*RunSyncResponse, error) {
+	out := new(RunSyncResponse)
+	err := c.cc.Invoke(ctx, "/JobExecutor/RunSync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *jobExecutorClient) Shutdown(ctx context.Context, in *ShutdownRequest, opts ...grpc.CallOption) (*ShutdownResponse, error) {
 	out := new(ShutdownResponse)
 	err := c.cc.Invoke(ctx, "/JobExecutor/Shutdown", in, out, opts...)
@@ -111,16 +195,91 @@ func (c *jobExecutorClient) Shutdown(ctx context.Context, in *ShutdownRequest, o
 	return out, nil
 }
 
+func (c *jobExecutorClient) Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainResponse, error) {
+	out := new(DrainResponse)
+	err := c.cc.Invoke(ctx, "/JobExecutor/Drain", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobExecutorClient) DrainStatus(ctx context.Context, in *DrainStatusRequest, opts ...grpc.CallOption) (*DrainStatusResponse, error) {
+	out := new(DrainStatusResponse)
+	err := c.cc.Invoke(ctx, "/JobExecutor/DrainStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobExecutorClient) Chown(ctx context.Context, in *ChownRequest, opts ...grpc.CallOption) (*ChownResponse, error) {
+	out := new(ChownResponse)
+	err := c.cc.Invoke(ctx, "/JobExecutor/Chown", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobExecutorClient) DebugFeeder(ctx context.Context, in *DebugFeederRequest, opts ...grpc.CallOption) (*DebugFeederResponse, error) {
+	out := new(DebugFeederResponse)
+	err := c.cc.Invoke(ctx, "/JobExecutor/DebugFeeder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // JobExecutorServer is the server API for JobExecutor service.
 // All implementations must embed UnimplementedJobExecutorServer
 // for forward compatibility
 type JobExecutorServer interface {
 	Run(context.Context, *RunRequest) (*RunResponse, error)
 	Stop(context.Context, *StopRequest) (*StopResponse, error)
+	// Cancel removes a queued job from the queue without it ever running,
+	// leaving it in the JOBSTATE_CANCELLED state. It fails with
+	// FAILED_PRECONDITION for a job that is not queued, such as one that has
+	// already started, which must be stopped instead.
+	Cancel(context.Context, *CancelRequest) (*CancelResponse, error)
 	List(context.Context, *ListRequest) (*ListResponse, error)
 	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	// Exists returns whether a job exists that the caller may see, which is
+	// cheaper than Status for scripts polling for a job. A job of another
+	// user does not exist for a caller who is not an admin, so Exists does
+	// not reveal other users' jobs.
+	Exists(context.Context, *ExistsRequest) (*ExistsResponse, error)
+	// ResourceHistory returns the resource usage of a job sampled over its
+	// run, for jobs run with sample_resources. It fails with
+	// FAILED_PRECONDITION for other jobs.
+	ResourceHistory(context.Context, *ResourceHistoryRequest) (*ResourceHistoryResponse, error)
 	Logs(*LogsRequest, JobExecutor_LogsServer) error
+	// LogsPage returns a range of a job's output lines along with the number
+	// of lines output so far, for paging through the output of a job.
+	LogsPage(context.Context, *LogsPageRequest) (*LogsPage, error)
+	// RunSync runs a job to completion and returns its exit status and output
+	// in a single response. It is for short commands. The job is removed from
+	// the server once it completes.
+	RunSync(context.Context, *RunSyncRequest) (*RunSyncResponse, error)
 	Shutdown(context.Context, *ShutdownRequest) (*ShutdownResponse, error)
+	// Drain stops the server starting new jobs while its running and queued
+	// jobs run to completion, such as before taking a node of a deployment
+	// out of service for maintenance. New jobs fail with UNAVAILABLE and the
+	// server reports itself as not serving to health checks. Drain returns at
+	// once with the jobs remaining; poll DrainStatus for progress until the
+	// server is drained and ready to shut down. It is only available to
+	// admins.
+	Drain(context.Context, *DrainRequest) (*DrainResponse, error)
+	// DrainStatus returns the progress of draining the server. It is only
+	// available to admins.
+	DrainStatus(context.Context, *DrainStatusRequest) (*DrainStatusResponse, error)
+	// Chown changes the owner of a job, such as when its owner has left, so
+	// that another user can manage it. It is only available to admins.
+	Chown(context.Context, *ChownRequest) (*ChownResponse, error)
+	// DebugFeeder returns the internal state of a job's log feeder, which
+	// distributes the job's output to clients streaming its logs. It is only
+	// available to admins.
+	DebugFeeder(context.Context, *DebugFeederRequest) (*DebugFeederResponse, error)
 	mustEmbedUnimplementedJobExecutorServer()
 }
 
@@ -134,18 +293,45 @@ func (UnimplementedJobExecutorServer) Run(context.Context, *RunRequest) (*RunRes
 func (UnimplementedJobExecutorServer) Stop(context.Context, *StopRequest) (*StopResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
 }
+func (UnimplementedJobExecutorServer) Cancel(context.Context, *CancelRequest) (*CancelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
 func (UnimplementedJobExecutorServer) List(context.Context, *ListRequest) (*ListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
 }
 func (UnimplementedJobExecutorServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
 }
+func (UnimplementedJobExecutorServer) Exists(context.Context, *ExistsRequest) (*ExistsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Exists not implemented")
+}
+func (UnimplementedJobExecutorServer) ResourceHistory(context.Context, *ResourceHistoryRequest) (*ResourceHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResourceHistory not implemented")
+}
 func (UnimplementedJobExecutorServer) Logs(*LogsRequest, JobExecutor_LogsServer) error {
 	return status.Errorf(codes.Unimplemented, "method Logs not implemented")
 }
+func (UnimplementedJobExecutorServer) LogsPage(context.Context, *LogsPageRequest) (*LogsPage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LogsPage not implemented")
+}
+func (UnimplementedJobExecutorServer) RunSync(context.Context, *RunSyncRequest) (*RunSyncResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunSync not implemented")
+}
 func (UnimplementedJobExecutorServer) Shutdown(context.Context, *ShutdownRequest) (*ShutdownResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Shutdown not implemented")
 }
+func (UnimplementedJobExecutorServer) Drain(context.Context, *DrainRequest) (*DrainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Drain not implemented")
+}
+func (UnimplementedJobExecutorServer) DrainStatus(context.Context, *DrainStatusRequest) (*DrainStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DrainStatus not implemented")
+}
+func (UnimplementedJobExecutorServer) Chown(context.Context, *ChownRequest) (*ChownResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Chown not implemented")
+}
+func (UnimplementedJobExecutorServer) DebugFeeder(context.Context, *DebugFeederRequest) (*DebugFeederResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DebugFeeder not implemented")
+}
 func (UnimplementedJobExecutorServer) mustEmbedUnimplementedJobExecutorServer() {}
 
 // UnsafeJobExecutorServer may be embedded to opt out of forward compatibility for this service.
@@ -195,6 +381,24 @@ func _JobExecutor_Stop_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _JobExecutor_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobExecutorServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/JobExecutor/Cancel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobExecutorServer).Cancel(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _JobExecutor_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListRequest)
 	if err := dec(in); err != nil {
@@ -231,6 +435,42 @@ func _JobExecutor_Status_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _JobExecutor_Exists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobExecutorServer).Exists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/JobExecutor/Exists",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobExecutorServer).Exists(ctx, req.(*ExistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobExecutor_ResourceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobExecutorServer).ResourceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/JobExecutor/ResourceHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobExecutorServer).ResourceHistory(ctx, req.(*ResourceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _JobExecutor_Logs_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(LogsRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -252,6 +492,42 @@ func (x *jobExecutorLogsServer) Send(m *LogsResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _JobExecutor_LogsPage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogsPageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobExecutorServer).LogsPage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/JobExecutor/LogsPage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobExecutorServer).LogsPage(ctx, req.(*LogsPageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobExecutor_RunSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunSyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobExecutorServer).RunSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/JobExecutor/RunSync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobExecutorServer).RunSync(ctx, req.(*RunSyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _JobExecutor_Shutdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ShutdownRequest)
 	if err := dec(in); err != nil {
@@ -270,6 +546,78 @@ func _JobExecutor_Shutdown_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _JobExecutor_Drain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobExecutorServer).Drain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/JobExecutor/Drain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobExecutorServer).Drain(ctx, req.(*DrainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobExecutor_DrainStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobExecutorServer).DrainStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/JobExecutor/DrainStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobExecutorServer).DrainStatus(ctx, req.(*DrainStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobExecutor_Chown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChownRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobExecutorServer).Chown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/JobExecutor/Chown",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobExecutorServer).Chown(ctx, req.(*ChownRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobExecutor_DebugFeeder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DebugFeederRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobExecutorServer).DebugFeeder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/JobExecutor/DebugFeeder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobExecutorServer).DebugFeeder(ctx, req.(*DebugFeederRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // JobExecutor_ServiceDesc is the grpc.ServiceDesc for JobExecutor service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -285,6 +633,10 @@ var JobExecutor_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Stop",
 			Handler:    _JobExecutor_Stop_Handler,
 		},
+		{
+			MethodName: "Cancel",
+			Handler:    _JobExecutor_Cancel_Handler,
+		},
 		{
 			MethodName: "List",
 			Handler:    _JobExecutor_List_Handler,
@@ -293,10 +645,42 @@ var JobExecutor_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Status",
 			Handler:    _JobExecutor_Status_Handler,
 		},
+		{
+			MethodName: "Exists",
+			Handler:    _JobExecutor_Exists_Handler,
+		},
+		{
+			MethodName: "ResourceHistory",
+			Handler:    _JobExecutor_ResourceHistory_Handler,
+		},
+		{
+			MethodName: "LogsPage",
+			Handler:    _JobExecutor_LogsPage_Handler,
+		},
+		{
+			MethodName: "RunSync",
+			Handler:    _JobExecutor_RunSync_Handler,
+		},
 		{
 			MethodName: "Shutdown",
 			Handler:    _JobExecutor_Shutdown_Handler,
 		},
+		{
+			MethodName: "Drain",
+			Handler:    _JobExecutor_Drain_Handler,
+		},
+		{
+			MethodName: "DrainStatus",
+			Handler:    _JobExecutor_DrainStatus_Handler,
+		},
+		{
+			MethodName: "Chown",
+			Handler:    _JobExecutor_Chown_Handler,
+		},
+		{
+			MethodName: "DebugFeeder",
+			Handler:    _JobExecutor_DebugFeeder_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
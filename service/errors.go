@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/camh-/jobber/job"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorCodes maps errors from the job package to the gRPC status codes
+// returned for them. The first error that a returned error matches with
+// errors.Is gives its code.
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{job.ErrUnknown, codes.NotFound},
+	{job.ErrUnauthorized, codes.PermissionDenied},
+	{job.ErrAlreadyStarted, codes.FailedPrecondition},
+	{job.ErrNotSpooled, codes.FailedPrecondition},
+	{job.ErrReadReplica, codes.FailedPrecondition},
+	{job.ErrCgroupsUnavailable, codes.Unavailable},
+	{job.ErrShutdown, codes.Unavailable},
+	{job.ErrDraining, codes.Unavailable},
+	{job.ErrTooManyJobs, codes.ResourceExhausted},
+	{job.ErrNoCommand, codes.InvalidArgument},
+	{job.ErrMissingID, codes.InvalidArgument},
+	{job.ErrInvalidCommands, codes.InvalidArgument},
+	{job.ErrInvalidNice, codes.InvalidArgument},
+	{job.ErrInvalidIONice, codes.InvalidArgument},
+	{job.ErrInvalidUmask, codes.InvalidArgument},
+	{job.ErrInvalidWorkdir, codes.InvalidArgument},
+	{job.ErrInvalidRunAs, codes.InvalidArgument},
+	{job.ErrInvalidMount, codes.InvalidArgument},
+	{job.ErrInvalidTimeout, codes.InvalidArgument},
+	{job.ErrInvalidIOLimits, codes.InvalidArgument},
+	{job.ErrTooManyArgs, codes.InvalidArgument},
+	{job.ErrInvalidName, codes.InvalidArgument},
+	{job.ErrJobExists, codes.AlreadyExists},
+	{job.ErrNotSampled, codes.FailedPrecondition},
+	{job.ErrNotQueued, codes.FailedPrecondition},
+	{job.ErrInvalidCPUBurst, codes.InvalidArgument},
+	{job.ErrInvalidCPUList, codes.InvalidArgument},
+	{job.ErrInvalidQuantity, codes.InvalidArgument},
+}
+
+// statusError returns err as a gRPC status error with the code for the job
+// package error it wraps, so clients get a meaningful code rather than
+// Unknown. Errors that already have a status, and those with no code of
+// their own, are returned unchanged.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	for _, ec := range errorCodes {
+		if errors.Is(err, ec.err) {
+			return status.Error(ec.code, err.Error())
+		}
+	}
+	return err
+}
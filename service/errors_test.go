@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/camh-/jobber/job"
+	pb "github.com/camh-/jobber/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusError(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		code codes.Code
+	}{
+		"unknown job":     {err: fmt.Errorf("nope: %w", job.ErrUnknown), code: codes.NotFound},
+		"unauthorized":    {err: job.ErrUnauthorized, code: codes.PermissionDenied},
+		"no command":      {err: job.ErrNoCommand, code: codes.InvalidArgument},
+		"missing id":      {err: job.ErrMissingID, code: codes.InvalidArgument},
+		"invalid spec":    {err: fmt.Errorf("%w: 99 not in range", job.ErrInvalidNice), code: codes.InvalidArgument},
+		"too many args":   {err: fmt.Errorf("%w: 5000 given, the maximum is 4096", job.ErrTooManyArgs), code: codes.InvalidArgument},
+		"already started": {err: fmt.Errorf("job-1: %w", job.ErrAlreadyStarted), code: codes.FailedPrecondition},
+		"not queued":      {err: fmt.Errorf("job-1: %w", job.ErrNotQueued), code: codes.FailedPrecondition},
+		"too many jobs":   {err: job.ErrTooManyJobs, code: codes.ResourceExhausted},
+		"job exists":      {err: fmt.Errorf("nightly: %w", job.ErrJobExists), code: codes.AlreadyExists},
+		"no cgroups":      {err: job.ErrCgroupsUnavailable, code: codes.Unavailable},
+		"has status":      {err: status.Error(codes.Aborted, "aborted"), code: codes.Aborted},
+		"other":           {err: errors.New("something else"), code: codes.Unknown},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := statusError(tc.err)
+			require.Equal(t, tc.code, status.Code(err))
+			require.Equal(t, status.Convert(tc.err).Message(), status.Convert(err).Message())
+		})
+	}
+	require.NoError(t, statusError(nil))
+}
+
+// fakeStore is a job.Store holding jobs for a tracker to restore, so a
+// JobExecutor can have jobs without running any.
+type fakeStore struct {
+	jobs []job.StoredJob
+}
+
+func (s *fakeStore) Save(jd job.JobDescription, logs []job.Log) error { return nil }
+func (s *fakeStore) Load() ([]job.StoredJob, error)                   { return s.jobs, nil }
+func (s *fakeStore) Remove(id string) error                           { return nil }
+
+func TestServiceErrorCodes(t *testing.T) {
+	store := &fakeStore{jobs: []job.StoredJob{{Description: job.JobDescription{
+		ID:     "sleep-01234567",
+		Spec:   job.JobSpec{Command: "/bin/sleep"},
+		Status: job.JobStatus{State: job.JobStateCompleted, Owner: "mallory", StartTime: time.Now()},
+	}}}}
+	svc := NewJobExecutor(nil, nil, []string{"admin"}, job.WithStore(store))
+	ctx := job.AddUserToContext(context.Background(), "eve")
+	unknown := []byte("no-such-job")
+	others := []byte("sleep-01234567")
+
+	code := func(_ interface{}, err error) codes.Code { return status.Code(err) }
+	stream := &logsStream{ctx: ctx}
+
+	tests := map[string]struct {
+		code     codes.Code
+		expected codes.Code
+	}{
+		"status unknown":     {code(svc.Status(ctx, &pb.StatusRequest{JobId: unknown})), codes.NotFound},
+		"status of others":   {code(svc.Status(ctx, &pb.StatusRequest{JobId: others})), codes.PermissionDenied},
+		"stop unknown":       {code(svc.Stop(ctx, &pb.StopRequest{JobId: unknown})), codes.NotFound},
+		"stop others":        {code(svc.Stop(ctx, &pb.StopRequest{JobId: others})), codes.PermissionDenied},
+		"chown not admin":    {code(svc.Chown(ctx, &pb.ChownRequest{JobId: others, Owner: "eve"})), codes.PermissionDenied},
+		"logs unknown":       {status.Code(svc.Logs(&pb.LogsRequest{JobId: unknown}, stream)), codes.NotFound},
+		"logs of others":     {status.Code(svc.Logs(&pb.LogsRequest{JobId: others}, stream)), codes.PermissionDenied},
+		"logs page unknown":  {code(svc.LogsPage(ctx, &pb.LogsPageRequest{JobId: unknown})), codes.NotFound},
+		"feeder not admin":   {code(svc.DebugFeeder(ctx, &pb.DebugFeederRequest{JobId: others})), codes.PermissionDenied},
+		"shutdown not admin": {code(svc.Shutdown(ctx, &pb.ShutdownRequest{})), codes.PermissionDenied},
+		"no user":            {code(svc.Status(context.Background(), &pb.StatusRequest{JobId: others})), codes.PermissionDenied},
+		"run no command":     {code(svc.Run(ctx, &pb.RunRequest{Spec: &pb.JobSpec{}})), codes.InvalidArgument},
+		"run invalid spec":   {code(svc.Run(ctx, &pb.RunRequest{Spec: &pb.JobSpec{Command: "/bin/true", Nice: 99}})), codes.InvalidArgument},
+	}
+	for name, tc := range tests {
+		require.Equal(t, tc.expected, tc.code, name)
+	}
+}
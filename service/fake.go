@@ -6,15 +6,33 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/camh-/jobber/job"
 	pb "github.com/camh-/jobber/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type fakeJob struct {
 	status *pb.JobStatus
 	logs   []string
+	// stderr holds the indexes of the logs that are on stderr.
+	stderr map[int]bool
+	// history is the job's resource history, if it samples it.
+	history *pb.ResourceHistoryResponse
+	// dropped is the number of the first logs dropped from the job's log
+	// buffer, which Logs skips.
+	dropped int
+}
+
+func (j fakeJob) stream(i int) pb.Stream {
+	if j.stderr[i] {
+		return pb.Stream_STREAM_STDERR
+	}
+	return pb.Stream_STREAM_STDOUT
 }
 
 var fakeJobs = map[string]fakeJob{
@@ -25,17 +43,40 @@ var fakeJobs = map[string]fakeJob{
 			StartTime: &timestamppb.Timestamp{Seconds: 1653654244},
 			User:      "eve",
 		},
-		logs: []string{"Hello world\n", "Goodbye world\n"},
+		logs:   []string{"Hello world\n", "Goodbye world\n"},
+		stderr: map[int]bool{1: true},
 	},
 	"jack-01234568": {
 		status: &pb.JobStatus{
-			JobId:     []byte("jack-01234568"),
-			State:     pb.JobStatus_JOBSTATE_COMPLETED,
-			ExitCode:  1,
-			StartTime: &timestamppb.Timestamp{Seconds: 1653654245},
-			User:      "mallory",
+			JobId:           []byte("jack-01234568"),
+			State:           pb.JobStatus_JOBSTATE_COMPLETED,
+			ExitCode:        1,
+			StartTime:       &timestamppb.Timestamp{Seconds: 1653654245},
+			User:            "mallory",
+			Pid:             4242,
+			Exit:            &pb.ExitStatus{ExitCode: 1, Reason: "exited with code 1"},
+			CompletionTime:  &timestamppb.Timestamp{Seconds: 1653654305},
+			PeakProcesses:   3,
+			PeakMemoryBytes: 768 << 10,
+			CpuTimeUs:       1500000,
+			Client:          "192.0.2.7:51234",
+			Spec: &pb.JobSpec{
+				Command:   "jack",
+				Arguments: []string{"beanstalk"},
+				RootDir:   "/srv/giant",
+				Nice:      5,
+				Resources: &pb.Resources{MilliCpu: 500, Memory: 1 << 20},
+			},
 		},
 		logs: []string{"fee\n", "fi\n", "fo\n", "fum\n"},
+		history: &pb.ResourceHistoryResponse{
+			Samples: []*pb.ResourceSample{
+				{Time: &timestamppb.Timestamp{Seconds: 1653654255}, MemoryBytes: 256 << 10, CpuTimeUs: 200000, Processes: 1},
+				{Time: &timestamppb.Timestamp{Seconds: 1653654265}, MemoryBytes: 768 << 10, CpuTimeUs: 900000, Processes: 3},
+				{Time: &timestamppb.Timestamp{Seconds: 1653654275}, MemoryBytes: 512 << 10, CpuTimeUs: 1500000, Processes: 2},
+			},
+			Dropped: 1,
+		},
 	},
 	"red-01234569": {
 		status: &pb.JobStatus{
@@ -43,8 +84,10 @@ var fakeJobs = map[string]fakeJob{
 			State:     pb.JobStatus_JOBSTATE_RUNNING,
 			StartTime: &timestamppb.Timestamp{Seconds: 1653654246},
 			User:      "mallory",
+			Spec:      &pb.JobSpec{Command: "/usr/bin/red"},
 		},
-		logs: []string{"too hot\n", "too cold\n", "just right\n"},
+		logs:    []string{"too hot\n", "too cold\n", "just right\n"},
+		dropped: 1,
 	},
 }
 
@@ -61,6 +104,17 @@ func (svc *FakeJobExecutor) RegisterWith(gs grpc.ServiceRegistrar) {
 }
 
 func (svc *FakeJobExecutor) Run(ctx context.Context, req *pb.RunRequest) (*pb.RunResponse, error) {
+	if req.GetExplainCgroup() {
+		spec, warnings, err := newJobSpec(req.GetSpec())
+		if err != nil {
+			return nil, err
+		}
+		resp := &pb.RunResponse{Warnings: warnings}
+		for _, w := range job.CgroupWrites(spec.Resources) {
+			resp.CgroupWrites = append(resp.CgroupWrites, &pb.CgroupWrite{File: w.File, Value: w.Value})
+		}
+		return resp, nil
+	}
 	argv := append([]string{req.Spec.GetCommand()}, req.Spec.GetArguments()...)
 	switch strings.Join(argv, " ") {
 	case "greeting":
@@ -69,27 +123,95 @@ func (svc *FakeJobExecutor) Run(ctx context.Context, req *pb.RunRequest) (*pb.Ru
 		return &pb.RunResponse{JobId: []byte("jack-01234568")}, nil
 	case "red riding hood":
 		return &pb.RunResponse{JobId: []byte("red-01234569")}, nil
+	case "greedy":
+		return &pb.RunResponse{
+			JobId:    []byte("greedy-0123456a"),
+			Warnings: []string{"cpu limit of 64000 mCPU exceeds the 8 CPUs available; clamped to 8000 mCPU"},
+		}, nil
 	default:
 		return nil, fmt.Errorf("no such file or directory: %s", req.Spec.GetCommand())
 	}
 }
 
+func (svc *FakeJobExecutor) RunSync(ctx context.Context, req *pb.RunSyncRequest) (*pb.RunSyncResponse, error) {
+	resp, err := svc.Run(ctx, &pb.RunRequest{Spec: req.GetSpec()})
+	if err != nil {
+		return nil, err
+	}
+	j := fakeJobs[string(resp.GetJobId())]
+	exit := j.status.GetExit()
+	if exit == nil {
+		exit = &pb.ExitStatus{Reason: "exited with code 0"}
+	}
+	return &pb.RunSyncResponse{
+		JobId:    resp.GetJobId(),
+		Exit:     exit,
+		Output:   []byte(strings.Join(j.logs, "")),
+		Warnings: resp.GetWarnings(),
+	}, nil
+}
+
 func (svc *FakeJobExecutor) Stop(ctx context.Context, req *pb.StopRequest) (*pb.StopResponse, error) {
 	_, ok := fakeJobs[string(req.GetJobId())]
 	if !ok {
-		return nil, fmt.Errorf("no such job: %s", req.GetJobId())
+		return nil, status.Errorf(codes.NotFound, "%s: %v", req.GetJobId(), job.ErrUnknown)
 	}
 	return &pb.StopResponse{}, nil
 }
 
+// Cancel fails for every fake job, as none of them are queued.
+func (svc *FakeJobExecutor) Cancel(ctx context.Context, req *pb.CancelRequest) (*pb.CancelResponse, error) {
+	if _, ok := fakeJobs[string(req.GetJobId())]; !ok {
+		return nil, status.Errorf(codes.NotFound, "%s: %v", req.GetJobId(), job.ErrUnknown)
+	}
+	return nil, status.Errorf(codes.FailedPrecondition, "%s: %v", req.GetJobId(), job.ErrNotQueued)
+}
+
+func (svc *FakeJobExecutor) Chown(ctx context.Context, req *pb.ChownRequest) (*pb.ChownResponse, error) {
+	j, ok := fakeJobs[string(req.GetJobId())]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%s: %v", req.GetJobId(), job.ErrUnknown)
+	}
+	// Don't change the fake job, so each test sees the same jobs.
+	return &pb.ChownResponse{PreviousOwner: j.status.GetUser()}, nil
+}
+
+func (svc *FakeJobExecutor) Drain(ctx context.Context, req *pb.DrainRequest) (*pb.DrainResponse, error) {
+	// The greeting job is still running.
+	return &pb.DrainResponse{Progress: &pb.DrainProgress{Draining: true, RunningJobs: 1}}, nil
+}
+
+func (svc *FakeJobExecutor) DrainStatus(ctx context.Context, req *pb.DrainStatusRequest) (*pb.DrainStatusResponse, error) {
+	// Simulate the server having drained since it was asked to, without
+	// changing the fake jobs, so each test sees the same jobs.
+	return &pb.DrainStatusResponse{Progress: &pb.DrainProgress{Draining: true, Drained: true}}, nil
+}
+
 func (svc *FakeJobExecutor) Status(ctx context.Context, req *pb.StatusRequest) (*pb.StatusResponse, error) {
 	j, ok := fakeJobs[string(req.GetJobId())]
 	if !ok {
-		return nil, fmt.Errorf("no such job: %s", req.GetJobId())
+		return nil, status.Errorf(codes.NotFound, "%s: %v", req.GetJobId(), job.ErrUnknown)
 	}
 	return &pb.StatusResponse{Status: j.status}, nil
 }
 
+func (svc *FakeJobExecutor) Exists(ctx context.Context, req *pb.ExistsRequest) (*pb.ExistsResponse, error) {
+	const user = "eve" // simulates authed user making request
+	j, ok := fakeJobs[string(req.GetJobId())]
+	return &pb.ExistsResponse{Exists: ok && j.status.GetUser() == user}, nil
+}
+
+func (svc *FakeJobExecutor) ResourceHistory(ctx context.Context, req *pb.ResourceHistoryRequest) (*pb.ResourceHistoryResponse, error) {
+	j, ok := fakeJobs[string(req.GetJobId())]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%s: %v", req.GetJobId(), job.ErrUnknown)
+	}
+	if j.history == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s: %v", req.GetJobId(), job.ErrNotSampled)
+	}
+	return j.history, nil
+}
+
 func (svc *FakeJobExecutor) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
 	const user = "eve" // simulates authed user making request
 	resp := &pb.ListResponse{}
@@ -97,7 +219,13 @@ func (svc *FakeJobExecutor) List(ctx context.Context, req *pb.ListRequest) (*pb.
 		if j.status.GetUser() != user && !req.AllJobs {
 			continue
 		}
-		if j.status.GetState() == pb.JobStatus_JOBSTATE_COMPLETED && !req.Completed {
+		if j.status.GetState() == pb.JobStatus_JOBSTATE_COMPLETED && !req.Completed && req.RecentlyFailed == nil {
+			continue
+		}
+		if req.GetCommand() != "" && j.status.GetSpec().GetCommand() != req.GetCommand() {
+			continue
+		}
+		if req.RecentlyFailed != nil && !fakeFailedWithin(j.status, req.RecentlyFailed.AsDuration()) {
 			continue
 		}
 		resp.Jobs = append(resp.Jobs, j.status)
@@ -114,20 +242,95 @@ func (svc *FakeJobExecutor) List(ctx context.Context, req *pb.ListRequest) (*pb.
 	return resp, nil
 }
 
+func fakeFailedWithin(status *pb.JobStatus, window time.Duration) bool {
+	if status.GetState() != pb.JobStatus_JOBSTATE_COMPLETED || status.GetExitCode() == 0 {
+		return false
+	}
+	return time.Since(status.GetCompletionTime().AsTime()) <= window
+}
+
 func (svc *FakeJobExecutor) Logs(req *pb.LogsRequest, stream pb.JobExecutor_LogsServer) error {
 	j, ok := fakeJobs[string(req.GetJobId())]
 	if !ok {
-		return fmt.Errorf("no such job: %s", req.GetJobId())
+		return status.Errorf(codes.NotFound, "%s: %v", req.GetJobId(), job.ErrUnknown)
 	}
 
-	for _, line := range j.logs {
+	// Space the log lines closely and deterministically after the job's
+	// start time so clients can test timestamp output.
+	start := j.status.GetStartTime().AsTime()
+	first := req.GetStartLine()
+	if tail := int64(req.GetTailLines()); tail > 0 && int64(len(j.logs)) > tail {
+		first = int64(len(j.logs)) - tail
+	}
+	for i, line := range j.logs {
+		if int64(i) < first || i < j.dropped {
+			continue
+		}
+		ts := start.Add(time.Duration(i) * 250 * time.Microsecond)
+		if req.FromTime != nil && ts.Before(req.FromTime.AsTime()) {
+			continue
+		}
+		if req.ToTime != nil && ts.After(req.ToTime.AsTime()) {
+			continue
+		}
 		resp := pb.LogsResponse{
 			Line:      []byte(line),
-			Timestamp: timestamppb.Now(),
+			Timestamp: timestamppb.New(ts),
+			Index:     int64(i),
+			Stream:    j.stream(i),
+		}
+		if i == j.dropped && int64(i) > first {
+			resp.Dropped = int64(i) - first
 		}
 		if err := stream.Send(&resp); err != nil {
 			return err
 		}
 	}
+
+	if exit := j.status.GetExit(); exit != nil {
+		return stream.Send(&pb.LogsResponse{Exit: exit})
+	}
 	return nil
 }
+
+func (svc *FakeJobExecutor) LogsPage(ctx context.Context, req *pb.LogsPageRequest) (*pb.LogsPage, error) {
+	j, ok := fakeJobs[string(req.GetJobId())]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%s: %v", req.GetJobId(), job.ErrUnknown)
+	}
+
+	// Timestamps are as for Logs.
+	start := j.status.GetStartTime().AsTime()
+	end := req.GetEndLine()
+	if end == 0 || end > int64(len(j.logs)) {
+		end = int64(len(j.logs))
+	}
+	resp := &pb.LogsPage{TotalLines: int64(len(j.logs)), EndLine: end}
+	for i := req.GetStartLine(); i < end; i++ {
+		resp.Lines = append(resp.Lines, &pb.LogsResponse{
+			Line:      []byte(j.logs[i]),
+			Timestamp: timestamppb.New(start.Add(time.Duration(i) * 250 * time.Microsecond)),
+			Index:     i,
+			Stream:    j.stream(int(i)),
+		})
+	}
+	return resp, nil
+}
+
+func (svc *FakeJobExecutor) DebugFeeder(ctx context.Context, req *pb.DebugFeederRequest) (*pb.DebugFeederResponse, error) {
+	j, ok := fakeJobs[string(req.GetJobId())]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%s: %v", req.GetJobId(), job.ErrUnknown)
+	}
+
+	// Simulate one client that has read all the logs and is following,
+	// and one client part way through the logs.
+	n := int64(len(j.logs))
+	return &pb.DebugFeederResponse{
+		BufferLen: n,
+		Outfeeds: []*pb.DebugFeederResponse_Outfeed{
+			{Position: n, Lag: 0, Follow: true, Waiting: true},
+			{Position: 1, Lag: n - 1},
+		},
+	}, nil
+}
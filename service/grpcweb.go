@@ -0,0 +1,173 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// gRPC-Web content types. The text variants are base64 encoded, which
+// browser clients use to read server streams as they arrive.
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+)
+
+// grpcWebTrailerFlag marks a frame of a gRPC-Web response as the trailers
+// rather than a message.
+const grpcWebTrailerFlag = 0x80
+
+// grpcStatusHeaders are the headers gRPC sends the status of a call in,
+// which are always trailers in a gRPC-Web response.
+var grpcStatusHeaders = map[string]bool{
+	"Grpc-Status":             true,
+	"Grpc-Message":            true,
+	"Grpc-Status-Details-Bin": true,
+}
+
+// GRPCWebHandler returns an HTTP handler serving the gRPC-Web protocol so
+// that browsers can call the services of gs directly, without a proxy. Each
+// request is translated to a gRPC request served by gs, so the same
+// interceptors, including authentication from the request's client
+// certificate, apply. Server streams are supported, but not client
+// streams, as browsers cannot send them.
+//
+// Cross-origin requests are allowed only from allowedOrigins, such as
+// "https://jobber.example.com". There is no wildcard: browsers send the
+// user's client certificate with cross-origin requests, so any page the
+// user visits could otherwise act as them.
+func GRPCWebHandler(gs *grpc.Server, allowedOrigins []string) http.Handler {
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, o := range allowedOrigins {
+		origins[o] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if !origins[origin] {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			}
+			h := w.Header()
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Credentials", "true")
+			h.Add("Vary", "Origin")
+			if r.Method == http.MethodOptions {
+				h.Set("Access-Control-Allow-Methods", "POST")
+				h.Set("Access-Control-Allow-Headers", "Content-Type, X-Grpc-Web, X-User-Agent, Grpc-Timeout")
+				h.Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			h.Set("Access-Control-Expose-Headers", "Grpc-Status, Grpc-Message")
+		}
+
+		contentType := r.Header.Get("Content-Type")
+		text := strings.HasPrefix(contentType, grpcWebTextContentType)
+		if !text && !strings.HasPrefix(contentType, grpcWebContentType) {
+			http.Error(w, "not a gRPC-Web request", http.StatusUnsupportedMediaType)
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "gRPC-Web requests must be POST", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Make the request look like gRPC over HTTP/2, as gs expects.
+		gr := r.Clone(r.Context())
+		gr.ProtoMajor, gr.ProtoMinor, gr.Proto = 2, 0, "HTTP/2.0"
+		gr.Header.Set("Content-Type", "application/grpc+proto")
+		if text {
+			gr.Body = io.NopCloser(base64.NewDecoder(base64.StdEncoding, r.Body))
+		}
+		gw := &grpcWebResponseWriter{w: w, header: http.Header{}, text: text}
+		gw.contentType = grpcWebContentType + "+proto"
+		if text {
+			gw.contentType = grpcWebTextContentType + "+proto"
+		}
+		gs.ServeHTTP(gw, gr)
+		gw.writeTrailers()
+	})
+}
+
+// grpcWebResponseWriter translates the gRPC response written to it to a
+// gRPC-Web response written to w: the trailers gRPC sends after the body
+// are sent as a final frame of the body instead, as browsers cannot read
+// HTTP trailers.
+type grpcWebResponseWriter struct {
+	w           http.ResponseWriter
+	header      http.Header
+	text        bool
+	contentType string
+	// sent is the header keys sent as headers. Those set later are
+	// trailers.
+	sent map[string]bool
+}
+
+func (gw *grpcWebResponseWriter) Header() http.Header { return gw.header }
+
+func (gw *grpcWebResponseWriter) WriteHeader(code int) {
+	if gw.sent != nil {
+		return
+	}
+	gw.sent = map[string]bool{}
+	h := gw.w.Header()
+	for k, vv := range gw.header {
+		if k == "Trailer" || grpcStatusHeaders[k] || strings.HasPrefix(k, http.TrailerPrefix) {
+			continue
+		}
+		gw.sent[k] = true
+		h[k] = vv
+	}
+	h.Set("Content-Type", gw.contentType)
+	gw.w.WriteHeader(code)
+}
+
+func (gw *grpcWebResponseWriter) Write(b []byte) (int, error) {
+	gw.WriteHeader(http.StatusOK)
+	if !gw.text {
+		return gw.w.Write(b)
+	}
+	// Each write is encoded on its own, padding included, so the client
+	// can decode the frames as they arrive.
+	if _, err := io.WriteString(gw.w, base64.StdEncoding.EncodeToString(b)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (gw *grpcWebResponseWriter) Flush() {
+	gw.WriteHeader(http.StatusOK)
+	if f, ok := gw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// writeTrailers writes the trailers of the gRPC response as the final
+// frame of the gRPC-Web response, as lower case "key: value" lines.
+func (gw *grpcWebResponseWriter) writeTrailers() {
+	var lines []string
+	for k, vv := range gw.header {
+		if k == "Trailer" || gw.sent[k] {
+			continue
+		}
+		k = strings.ToLower(strings.TrimPrefix(k, http.TrailerPrefix))
+		for _, v := range vv {
+			lines = append(lines, k+": "+v+"\r\n")
+		}
+	}
+	sort.Strings(lines)
+	trailers := strings.Join(lines, "")
+
+	var frame bytes.Buffer
+	frame.WriteByte(grpcWebTrailerFlag)
+	_ = binary.Write(&frame, binary.BigEndian, uint32(len(trailers)))
+	frame.WriteString(trailers)
+	_, _ = gw.Write(frame.Bytes())
+	gw.Flush()
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/camh-/jobber/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+func newGRPCWebServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gs := grpc.NewServer()
+	NewFake().RegisterWith(gs)
+	srv := httptest.NewServer(GRPCWebHandler(gs, []string{"https://ui.example.com"}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// grpcWebFrame returns msg as a gRPC-Web message frame.
+func grpcWebFrame(t *testing.T, msg proto.Message) []byte {
+	t.Helper()
+	b, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	frame := []byte{0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(b)))
+	return append(frame, b...)
+}
+
+// readGRPCWebFrames returns the messages and trailers of a gRPC-Web
+// response body.
+func readGRPCWebFrames(t *testing.T, body []byte) ([][]byte, string) {
+	t.Helper()
+	var messages [][]byte
+	for len(body) > 0 {
+		require.GreaterOrEqual(t, len(body), 5)
+		flag, n := body[0], binary.BigEndian.Uint32(body[1:5])
+		data := body[5 : 5+n]
+		body = body[5+n:]
+		if flag == grpcWebTrailerFlag {
+			require.Empty(t, body, "data after trailers")
+			return messages, string(data)
+		}
+		messages = append(messages, data)
+	}
+	t.Fatal("no trailers")
+	return nil, ""
+}
+
+func postGRPCWeb(t *testing.T, url, contentType string, body []byte) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Grpc-Web", "1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestGRPCWebStatus(t *testing.T) {
+	srv := newGRPCWebServer(t)
+	url := srv.URL + "/JobExecutor/Status"
+
+	body := grpcWebFrame(t, &pb.StatusRequest{JobId: []byte("greeting-01234567")})
+	resp := postGRPCWeb(t, url, "application/grpc-web+proto", body)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/grpc-web+proto", resp.Header.Get("Content-Type"))
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	messages, trailers := readGRPCWebFrames(t, respBody)
+	require.Len(t, messages, 1)
+	var status pb.StatusResponse
+	require.NoError(t, proto.Unmarshal(messages[0], &status))
+	require.Equal(t, "greeting-01234567", string(status.GetStatus().GetJobId()))
+	require.Equal(t, "eve", status.GetStatus().GetUser())
+	require.Equal(t, "grpc-status: 0\r\n", trailers)
+
+	// Errors are in the trailers.
+	body = grpcWebFrame(t, &pb.StatusRequest{JobId: []byte("invalid-job-id")})
+	resp = postGRPCWeb(t, url, "application/grpc-web+proto", body)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	respBody, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	messages, trailers = readGRPCWebFrames(t, respBody)
+	require.Empty(t, messages)
+	require.Equal(t, "grpc-message: invalid-job-id: unknown job\r\ngrpc-status: 5\r\n", trailers)
+}
+
+func TestGRPCWebTextLogs(t *testing.T) {
+	srv := newGRPCWebServer(t)
+
+	body := grpcWebFrame(t, &pb.LogsRequest{JobId: []byte("jack-01234568")})
+	text := base64.StdEncoding.EncodeToString(body)
+	resp := postGRPCWeb(t, srv.URL+"/JobExecutor/Logs", "application/grpc-web-text", []byte(text))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/grpc-web-text+proto", resp.Header.Get("Content-Type"))
+	respText, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	// Each frame is encoded on its own, padding included, so decode the
+	// groups of four characters separately.
+	require.Zero(t, len(respText)%4)
+	var respBody []byte
+	for i := 0; i < len(respText); i += 4 {
+		b, err := base64.StdEncoding.DecodeString(string(respText[i : i+4]))
+		require.NoError(t, err)
+		respBody = append(respBody, b...)
+	}
+
+	messages, trailers := readGRPCWebFrames(t, respBody)
+	var lines []string
+	for _, m := range messages {
+		var l pb.LogsResponse
+		require.NoError(t, proto.Unmarshal(m, &l))
+		if l.GetExit() != nil {
+			require.Equal(t, uint32(1), l.GetExit().GetExitCode())
+			continue
+		}
+		lines = append(lines, string(l.GetLine()))
+	}
+	require.Equal(t, []string{"fee\n", "fi\n", "fo\n", "fum\n"}, lines)
+	require.Equal(t, "grpc-status: 0\r\n", trailers)
+}
+
+func TestGRPCWebCORS(t *testing.T) {
+	srv := newGRPCWebServer(t)
+	url := srv.URL + "/JobExecutor/Status"
+
+	preflight := func(origin string) *http.Response {
+		req, err := http.NewRequest(http.MethodOptions, url, nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+	resp := preflight("https://ui.example.com")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "https://ui.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "POST", resp.Header.Get("Access-Control-Allow-Methods"))
+	require.Contains(t, resp.Header.Get("Access-Control-Allow-Headers"), "X-Grpc-Web")
+
+	resp = preflight("https://evil.example.com")
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+
+	// Not gRPC-Web.
+	resp = postGRPCWeb(t, url, "application/json", []byte("{}"))
+	require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+}
+
+func TestGRPCWebUnlistedOrigin(t *testing.T) {
+	gs := grpc.NewServer()
+	NewFake().RegisterWith(gs)
+	// "*" is not a wildcard, so it allows no origin.
+	srv := httptest.NewServer(GRPCWebHandler(gs, []string{"https://ui.example.com", "*"}))
+	t.Cleanup(srv.Close)
+
+	body := grpcWebFrame(t, &pb.StatusRequest{JobId: []byte("greeting-01234567")})
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/JobExecutor/Status", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	req.Header.Set("Origin", "https://evil.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Credentials"))
+}
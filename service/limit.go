@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// healthServicePrefix is the method prefix of the gRPC health service. Its
+// checks are not limited so that the server is not reported unhealthy just
+// because it is busy.
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// ConcurrencyLimiter limits the number of unary RPCs the server handles at
+// once, across all users, rejecting those over the limit with
+// ResourceExhausted rather than queuing them. Streaming RPCs such as Logs
+// are not counted, as they are limited separately by
+// JobExecutor.SetMaxLogStreams.
+type ConcurrencyLimiter struct {
+	sem chan struct{}
+}
+
+// NewConcurrencyLimiter returns a ConcurrencyLimiter that allows max unary
+// RPCs in flight at once. Zero is no limit.
+func NewConcurrencyLimiter(max int) *ConcurrencyLimiter {
+	l := &ConcurrencyLimiter{}
+	if max > 0 {
+		l.sem = make(chan struct{}, max)
+	}
+	return l
+}
+
+// InFlight returns the number of unary RPCs being handled that count
+// against the limit.
+func (l *ConcurrencyLimiter) InFlight() int {
+	return len(l.sem)
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// enforces the limit.
+func (l *ConcurrencyLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if l.sem == nil || strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(ctx, req)
+		}
+		select {
+		case l.sem <- struct{}{}:
+		default:
+			return nil, status.Errorf(codes.ResourceExhausted, "server is handling its maximum of %d requests at once", cap(l.sem))
+		}
+		defer func() { <-l.sem }()
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	limiter := NewConcurrencyLimiter(2)
+	intercept := limiter.UnaryServerInterceptor()
+	runInfo := &grpc.UnaryServerInfo{FullMethod: "/jobber.JobExecutor/Run"}
+	healthInfo := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+
+	// Saturate the limit with handlers that block until released.
+	entered, release := make(chan struct{}), make(chan struct{})
+	blocking := func(ctx context.Context, req interface{}) (interface{}, error) {
+		entered <- struct{}{}
+		<-release
+		return "done", nil
+	}
+	results := make(chan error)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := intercept(context.Background(), nil, runInfo, blocking)
+			results <- err
+		}()
+		<-entered
+	}
+	require.Equal(t, 2, limiter.InFlight())
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	_, err := intercept(context.Background(), nil, runInfo, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Health checks are not limited.
+	resp, err := intercept(context.Background(), nil, healthInfo, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	// Once a request completes, there is room for another.
+	release <- struct{}{}
+	require.NoError(t, <-results)
+	resp, err = intercept(context.Background(), nil, runInfo, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	release <- struct{}{}
+	require.NoError(t, <-results)
+	require.Equal(t, 0, limiter.InFlight())
+}
+
+func TestConcurrencyLimiterUnlimited(t *testing.T) {
+	intercept := NewConcurrencyLimiter(0).UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/jobber.JobExecutor/Run"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	resp, err := intercept(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// metrics are gauges of the state of a server, served in the Prometheus
+// text format. The zero value has no gauges.
+type metrics struct {
+	mu     sync.Mutex
+	gauges []gauge
+}
+
+// gauge is a metric whose value is read when it is scraped.
+type gauge struct {
+	name, help string
+	value      func() float64
+}
+
+// register adds a gauge named name whose value is read by calling value,
+// replacing any gauge registered with the name before.
+func (m *metrics) register(name, help string, value func() float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, g := range m.gauges {
+		if g.name == name {
+			m.gauges[i] = gauge{name, help, value}
+			return
+		}
+	}
+	m.gauges = append(m.gauges, gauge{name, help, value})
+}
+
+// ServeHTTP writes the current value of each gauge in the order they were
+// registered.
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	gauges := append([]gauge(nil), m.gauges...)
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	for _, g := range gauges {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n",
+			g.name, g.help, g.name, g.name, strconv.FormatFloat(g.value(), 'g', -1, 64))
+	}
+}
+
+// MetricsHandler returns an http.Handler serving the server's metrics in
+// the Prometheus text format, such as the number of log streams open.
+func (svc *JobExecutor) MetricsHandler() http.Handler {
+	return &svc.metrics
+}
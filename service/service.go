@@ -3,11 +3,21 @@ package service
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"os"
 	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/camh-/jobber/job"
 	pb "github.com/camh-/jobber/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -16,52 +26,262 @@ type JobExecutor struct {
 
 	tracker *job.Tracker
 	done    chan<- struct{}
+	// doneOnce closes done once, as the server may be shut down by both
+	// a Shutdown request and a signal.
+	doneOnce sync.Once
+
+	// logStreams is the number of Logs streams currently open, and
+	// maxLogStreams is the most that may be open at once. Zero is no
+	// limit. logStreams is accessed atomically.
+	logStreams    int64
+	maxLogStreams int64
+
+	// metrics are served by MetricsHandler.
+	metrics metrics
+	// cgroupsHealthy is 1 if the last cgroup health check of
+	// MonitorCgroups passed, otherwise 0. It is accessed atomically.
+	cgroupsHealthy int32
 }
 
-func NewJobExecutor(done chan<- struct{}, argMaker job.ArgMaker, admins []string) *JobExecutor {
-	return &JobExecutor{
-		tracker: job.NewTracker(argMaker, admins),
+func NewJobExecutor(done chan<- struct{}, argMaker job.ArgMaker, admins []string, opts ...job.TrackerOption) *JobExecutor {
+	svc := &JobExecutor{
+		tracker: job.NewTracker(argMaker, admins, opts...),
 		done:    done,
 	}
+	svc.metrics.register("jobber_active_log_streams", "Number of log streams open across all jobs.", func() float64 {
+		return float64(svc.ActiveLogStreams())
+	})
+	return svc
 }
 
 func (svc *JobExecutor) RegisterWith(gs grpc.ServiceRegistrar) {
 	pb.RegisterJobExecutorServer(gs, svc)
 }
 
+// SetMaxLogStreams sets the maximum number of Logs streams open at once
+// across all jobs and clients. Further streams are rejected with
+// ResourceExhausted until one closes. Zero is no limit.
+func (svc *JobExecutor) SetMaxLogStreams(n int) {
+	atomic.StoreInt64(&svc.maxLogStreams, int64(n))
+}
+
+// ActiveLogStreams returns the number of Logs streams currently open.
+func (svc *JobExecutor) ActiveLogStreams() int64 {
+	return atomic.LoadInt64(&svc.logStreams)
+}
+
+// MonitorCgroups checks the health of the cgroups jobs are run in every
+// interval until done is closed, setting the serving status of the server
+// and the JobExecutor service in hs to match. It checks once before
+// returning and then continues checking in a new goroutine, every interval
+// if it is positive, and as soon as the tracker starts draining. Once
+// draining, the server is not serving whatever the health of its cgroups,
+// so that load balancers send new jobs to other servers. The result of the
+// last check is also the jobber_cgroups_healthy metric.
+func (svc *JobExecutor) MonitorCgroups(done <-chan struct{}, interval time.Duration, hs *health.Server) {
+	svc.metrics.register("jobber_cgroups_healthy", "Whether the last health check of the cgroups jobs are run in passed (1) or failed (0).", func() float64 {
+		return float64(atomic.LoadInt32(&svc.cgroupsHealthy))
+	})
+	var lastErr error
+	check := func() {
+		err := svc.tracker.CheckCgroups()
+		healthy := int32(1)
+		if err != nil {
+			healthy = 0
+		}
+		atomic.StoreInt32(&svc.cgroupsHealthy, healthy)
+		st := healthpb.HealthCheckResponse_SERVING
+		if err != nil || draining(svc.tracker) {
+			st = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		hs.SetServingStatus("", st)
+		hs.SetServingStatus(pb.JobExecutor_ServiceDesc.ServiceName, st)
+
+		// XXX Should log, but no logger yet
+		switch {
+		case err != nil && (lastErr == nil || err.Error() != lastErr.Error()):
+			fmt.Fprintf(os.Stderr, "cgroup health check failed: %v\n", err)
+		case err == nil && lastErr != nil:
+			fmt.Fprintln(os.Stderr, "cgroup health check recovered")
+		}
+		lastErr = err
+	}
+
+	check()
+	go func() {
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		drain := svc.tracker.Draining()
+		for {
+			select {
+			case <-tick:
+				check()
+			case <-drain:
+				// Stop being served new jobs at once.
+				drain = nil
+				check()
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// draining returns true if t has started draining.
+func draining(t *job.Tracker) bool {
+	select {
+	case <-t.Draining():
+		return true
+	default:
+		return false
+	}
+}
+
 func (svc *JobExecutor) Run(ctx context.Context, req *pb.RunRequest) (*pb.RunResponse, error) {
-	spec, err := newJobSpec(req.GetSpec())
+	spec, warnings, err := newJobSpec(req.GetSpec())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if req.GetExplainCgroup() {
+		return svc.explainCgroup(ctx, spec, warnings)
+	}
+	id, startWarnings, err := svc.tracker.Start(withClient(ctx), spec)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	warnings = append(warnings, startWarnings...)
+	return &pb.RunResponse{JobId: []byte(id), Warnings: warnings}, nil
+}
+
+// withClient adds the address of the gRPC client of ctx to ctx, for the
+// tracker to record on the jobs it starts.
+func withClient(ctx context.Context) context.Context {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return job.AddClientToContext(ctx, p.Addr.String())
+	}
+	return ctx
+}
+
+func (svc *JobExecutor) explainCgroup(ctx context.Context, spec job.JobSpec, warnings []string) (*pb.RunResponse, error) {
+	writes, explainWarnings, err := svc.tracker.ExplainCgroup(ctx, spec)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	resp := &pb.RunResponse{Warnings: append(warnings, explainWarnings...)}
+	for _, w := range writes {
+		resp.CgroupWrites = append(resp.CgroupWrites, &pb.CgroupWrite{File: w.File, Value: w.Value})
+	}
+	return resp, nil
+}
+
+func (svc *JobExecutor) RunSync(ctx context.Context, req *pb.RunSyncRequest) (*pb.RunSyncResponse, error) {
+	spec, warnings, err := newJobSpec(req.GetSpec())
 	if err != nil {
-		return nil, err
+		return nil, statusError(err)
 	}
-	id, err := svc.tracker.Start(ctx, spec)
+	result, runWarnings, err := svc.tracker.RunSync(withClient(ctx), spec, req.GetTimeout().AsDuration())
 	if err != nil {
-		// XXX do gRPC status/errors properly
-		return nil, err
+		return nil, statusError(err)
 	}
-	return &pb.RunResponse{JobId: []byte(id)}, nil
+	return &pb.RunSyncResponse{
+		JobId:            []byte(result.ID),
+		Exit:             newExitStatusPB(result.Status),
+		Output:           result.Output,
+		OutputTruncated:  result.OutputTruncated,
+		TimedOut:         result.TimedOut,
+		DeadlineExceeded: result.DeadlineExceeded,
+		Warnings:         append(warnings, runWarnings...),
+	}, nil
 }
 
 func (svc *JobExecutor) Stop(ctx context.Context, req *pb.StopRequest) (*pb.StopResponse, error) {
 	if err := svc.tracker.Stop(ctx, string(req.GetJobId()), req.GetCleanup()); err != nil {
-		// XXX do gRPC status/errors properly
-		return nil, err
+		return nil, statusError(err)
 	}
 	return &pb.StopResponse{}, nil
 }
 
+func (svc *JobExecutor) Cancel(ctx context.Context, req *pb.CancelRequest) (*pb.CancelResponse, error) {
+	if err := svc.tracker.Cancel(ctx, string(req.GetJobId())); err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.CancelResponse{}, nil
+}
+
+func (svc *JobExecutor) Chown(ctx context.Context, req *pb.ChownRequest) (*pb.ChownResponse, error) {
+	id, owner := string(req.GetJobId()), req.GetOwner()
+	prev, err := svc.tracker.Chown(ctx, id, owner)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	// Changing who can manage a job should leave a record of who did it,
+	// even without an event log, which the tracker also records it in.
+	// XXX Should log, but no logger yet
+	admin, _ := job.GetUserFromContext(ctx)
+	fmt.Fprintf(os.Stderr, "job %s owner changed from %q to %q by %s\n", id, prev, owner, admin)
+
+	return &pb.ChownResponse{PreviousOwner: prev}, nil
+}
+
 func (svc *JobExecutor) Status(ctx context.Context, req *pb.StatusRequest) (*pb.StatusResponse, error) {
 	jd, err := svc.tracker.Get(ctx, string(req.GetJobId()))
 	if err != nil {
-		// XXX do gRPC status/errors properly
-		return nil, err
+		return nil, statusError(err)
 	}
 	return &pb.StatusResponse{Status: newJobStatusPB(jd)}, nil
 }
 
+func (svc *JobExecutor) Exists(ctx context.Context, req *pb.ExistsRequest) (*pb.ExistsResponse, error) {
+	exists, err := svc.tracker.Exists(ctx, string(req.GetJobId()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ExistsResponse{Exists: exists}, nil
+}
+
+func (svc *JobExecutor) ResourceHistory(ctx context.Context, req *pb.ResourceHistoryRequest) (*pb.ResourceHistoryResponse, error) {
+	history, err := svc.tracker.ResourceHistory(ctx, string(req.GetJobId()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	resp := &pb.ResourceHistoryResponse{Dropped: uint64(history.Dropped)}
+	for _, s := range history.Samples {
+		resp.Samples = append(resp.Samples, &pb.ResourceSample{
+			Time:        timestamppb.New(s.Time),
+			MemoryBytes: s.Memory,
+			CpuTimeUs:   uint64(s.CPUTime / time.Microsecond),
+			Processes:   s.Processes,
+		})
+	}
+	return resp, nil
+}
+
 func (svc *JobExecutor) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	var window time.Duration
+	if req.RecentlyFailed != nil {
+		if err := req.RecentlyFailed.CheckValid(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid recently failed window: %v", err)
+		}
+		window = req.RecentlyFailed.AsDuration()
+		if window <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid recently failed window: %v", window)
+		}
+	}
+	completed := req.GetCompleted() || window > 0
+	now := time.Now()
 	resp := &pb.ListResponse{}
-	for _, jd := range svc.tracker.List(ctx, req.GetCompleted(), req.GetAllJobs()) {
+	for _, jd := range svc.tracker.List(ctx, completed, req.GetAllJobs()) {
+		if req.GetCommand() != "" && jd.Spec.FirstCommand() != req.GetCommand() {
+			continue
+		}
+		if window > 0 && !failedWithin(jd.Status, window, now) {
+			continue
+		}
 		resp.Jobs = append(resp.Jobs, newJobStatusPB(jd))
 	}
 
@@ -76,41 +296,255 @@ func (svc *JobExecutor) List(ctx context.Context, req *pb.ListRequest) (*pb.List
 	return resp, nil
 }
 
+// failedWithin returns whether a job failed and completed within window
+// before now.
+func failedWithin(s job.JobStatus, window time.Duration, now time.Time) bool {
+	return s.Failed() && !s.CompletionTime.Before(now.Add(-window))
+}
+
 func (svc *JobExecutor) Logs(req *pb.LogsRequest, stream pb.JobExecutor_LogsServer) error {
 	id, follow, ctx := string(req.GetJobId()), req.GetFollow(), stream.Context()
-	ch, err := svc.tracker.GetLogChannel(id, follow, ctx)
+	if req.GetStartLine() < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid start line: %d", req.GetStartLine())
+	}
+	windowed := req.FromTime != nil || req.ToTime != nil
+	start, tail := int(req.GetStartLine()), int(req.GetTailLines())
+	if tail > 0 {
+		if start > 0 || windowed {
+			return status.Errorf(codes.InvalidArgument, "cannot tail logs from a start line or in a time window")
+		}
+		// A negative start counts back from the end of the logs.
+		start = -tail
+	}
+	var from, to time.Time
+	if req.GetStopJobOnDisconnect() && !follow {
+		return status.Errorf(codes.InvalidArgument, "cannot stop a job on disconnect without following its logs")
+	}
+	if windowed {
+		if follow {
+			return status.Errorf(codes.InvalidArgument, "cannot follow logs in a time window")
+		}
+		if req.FromTime != nil {
+			from = req.FromTime.AsTime()
+		}
+		if req.ToTime != nil {
+			to = req.ToTime.AsTime()
+		}
+		if !from.IsZero() && !to.IsZero() && to.Before(from) {
+			return status.Errorf(codes.InvalidArgument, "invalid time window: %v to %v", from, to)
+		}
+	}
+	n := atomic.AddInt64(&svc.logStreams, 1)
+	defer atomic.AddInt64(&svc.logStreams, -1)
+	if max := atomic.LoadInt64(&svc.maxLogStreams); max > 0 && n > max {
+		return status.Errorf(codes.ResourceExhausted, "server has its maximum of %d log streams open", max)
+	}
+	if windowed {
+		return svc.logsWindow(ctx, id, start, from, to, stream)
+	}
+	var ch <-chan job.Log
+	var err error
+	if req.GetStopJobOnDisconnect() {
+		ch, err = svc.tracker.FollowRunLogs(id, start, ctx)
+	} else {
+		ch, err = svc.tracker.GetLogChannel(id, follow, start, ctx)
+	}
 	if err != nil {
-		return err
+		return statusError(err)
 	}
 
 	for l := range ch {
 		resp := pb.LogsResponse{
 			Line:      []byte(l.Line),
 			Timestamp: timestamppb.New(l.Timestamp),
+			Index:     int64(l.Index),
+			Stream:    pb.Stream(l.Stream),
+			Partial:   l.Partial,
+			Dropped:   int64(l.Dropped),
+		}
+		if err := stream.Send(&resp); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		// The client has gone away, so there is no one to send the
+		// exit status to.
+		return nil
+	}
+
+	// Send the exit status of the job as a final message if it has
+	// completed. A follower has reached the end of the logs because the
+	// job's output has closed, but the job may not have been reaped yet
+	// so wait for that.
+	var jd job.JobDescription
+	if follow {
+		jd, err = svc.tracker.Wait(ctx, id)
+	} else {
+		jd, err = svc.tracker.Get(ctx, id)
+	}
+	if err != nil || !jd.Status.Ended() {
+		// The job may have been cleaned up while we were streaming, in
+		// which case there is no exit status to send.
+		return nil
+	}
+	return stream.Send(&pb.LogsResponse{Exit: newExitStatusPB(jd.Status)})
+}
+
+// logsWindow sends the logs of the job identified by id from line start
+// that are timestamped in [from, to], followed by the job's exit status if
+// it has completed. A window with no logs in it is not an error.
+func (svc *JobExecutor) logsWindow(ctx context.Context, id string, start int, from, to time.Time, stream pb.JobExecutor_LogsServer) error {
+	page, err := svc.tracker.LogsWindow(ctx, id, from, to)
+	if err != nil {
+		return statusError(err)
+	}
+	for _, l := range page.Logs {
+		if l.Index < start {
+			continue
+		}
+		resp := pb.LogsResponse{
+			Line:      l.Line,
+			Timestamp: timestamppb.New(l.Timestamp),
+			Index:     int64(l.Index),
+			Stream:    pb.Stream(l.Stream),
+			Partial:   l.Partial,
+			Dropped:   int64(l.Dropped),
 		}
 		if err := stream.Send(&resp); err != nil {
 			return err
 		}
 	}
-	return nil
+
+	jd, err := svc.tracker.Get(ctx, id)
+	if err != nil || !jd.Status.Ended() {
+		return nil
+	}
+	return stream.Send(&pb.LogsResponse{Exit: newExitStatusPB(jd.Status)})
 }
 
 func (svc *JobExecutor) Shutdown(ctx context.Context, req *pb.ShutdownRequest) (*pb.ShutdownResponse, error) {
+	count, err := svc.shutdown(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ShutdownResponse{NumJobsStopped: int32(count)}, nil
+}
+
+// serverUser is the user the server acts as when it shuts itself down.
+const serverUser = "jobber"
+
+// ShutdownServer stops all running jobs and then closes the done channel
+// the service was created with, as a Shutdown request from an admin does.
+// It is for the server shutting itself down, such as on SIGTERM. It returns
+// the number of jobs stopped.
+func (svc *JobExecutor) ShutdownServer() (int, error) {
+	ctx := job.AddAdminToContext(job.AddUserToContext(context.Background(), serverUser))
+	return svc.shutdown(ctx)
+}
+
+// shutdown stops all running jobs, then closes done so that the server
+// stops once in-flight requests complete.
+func (svc *JobExecutor) shutdown(ctx context.Context) (int, error) {
 	count, err := svc.tracker.Shutdown(ctx)
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+	svc.doneOnce.Do(func() {
+		if svc.done != nil {
+			close(svc.done)
+		}
+	})
+	return count, nil
+}
+
+func (svc *JobExecutor) Drain(ctx context.Context, req *pb.DrainRequest) (*pb.DrainResponse, error) {
+	st, err := svc.tracker.Drain(ctx)
+	if err != nil {
+		return nil, statusError(err)
 	}
 
-	close(svc.done)
+	// XXX Should log, but no logger yet
+	admin, _ := job.GetUserFromContext(ctx)
+	fmt.Fprintf(os.Stderr, "draining, asked by %s: %d running and %d queued jobs remaining\n", admin, st.Running, st.Queued)
 
-	return &pb.ShutdownResponse{NumJobsStopped: int32(count)}, nil
+	return &pb.DrainResponse{Progress: newDrainProgressPB(st)}, nil
+}
+
+func (svc *JobExecutor) DrainStatus(ctx context.Context, req *pb.DrainStatusRequest) (*pb.DrainStatusResponse, error) {
+	st, err := svc.tracker.DrainStatus(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.DrainStatusResponse{Progress: newDrainProgressPB(st)}, nil
+}
+
+func newDrainProgressPB(st job.DrainStatus) *pb.DrainProgress {
+	return &pb.DrainProgress{
+		Draining:    st.Draining,
+		RunningJobs: int32(st.Running),
+		QueuedJobs:  int32(st.Queued),
+		Drained:     st.Drained(),
+	}
 }
 
-// Convert a protobuf JobSpec to a job.JobSpec
-func newJobSpec(pbspec *pb.JobSpec) (job.JobSpec, error) {
+func (svc *JobExecutor) LogsPage(ctx context.Context, req *pb.LogsPageRequest) (*pb.LogsPage, error) {
+	start, end := req.GetStartLine(), req.GetEndLine()
+	if start < 0 || end < 0 || (end > 0 && end < start) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid line range: %d to %d", start, end)
+	}
+	page, err := svc.tracker.LogsPage(ctx, string(req.GetJobId()), int(start), int(end))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &pb.LogsPage{TotalLines: int64(page.Total), EndLine: int64(page.End)}
+	for _, l := range page.Logs {
+		resp.Lines = append(resp.Lines, &pb.LogsResponse{
+			Line:      l.Line,
+			Timestamp: timestamppb.New(l.Timestamp),
+			Index:     int64(l.Index),
+			Stream:    pb.Stream(l.Stream),
+			Partial:   l.Partial,
+			Dropped:   int64(l.Dropped),
+		})
+	}
+	return resp, nil
+}
+
+func (svc *JobExecutor) DebugFeeder(ctx context.Context, req *pb.DebugFeederRequest) (*pb.DebugFeederResponse, error) {
+	stats, err := svc.tracker.FeederStats(ctx, string(req.GetJobId()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &pb.DebugFeederResponse{
+		BufferLen:    int64(stats.BufferLen),
+		InfeedClosed: stats.InfeedClosed,
+		Dropped:      int64(stats.Dropped),
+	}
+	for _, o := range stats.Outfeeds {
+		resp.Outfeeds = append(resp.Outfeeds, &pb.DebugFeederResponse_Outfeed{
+			Position: int64(o.Pos),
+			Lag:      int64(o.Lag),
+			Follow:   o.Follow,
+			Waiting:  o.Waiting,
+		})
+	}
+	return resp, nil
+}
+
+// Convert a protobuf JobSpec to a job.JobSpec. Any parts of the protobuf
+// JobSpec that are ignored are returned as warnings.
+func newJobSpec(pbspec *pb.JobSpec) (job.JobSpec, []string, error) {
 	pbresources := pbspec.GetResources()
 	var iolimits []job.DiskIOLimits
+	var warnings []string
 	for _, pblim := range pbresources.GetIoLimits() {
+		if pblim.ReadBps == 0 && pblim.WriteBps == 0 && pblim.ReadIops == 0 && pblim.WriteIops == 0 {
+			warnings = append(warnings, fmt.Sprintf("io limit for %s has no limits set; ignored", pblim.Device))
+			continue
+		}
 		iolim := job.DiskIOLimits{
 			Device:    pblim.Device,
 			ReadBPS:   pblim.ReadBps,
@@ -119,23 +553,127 @@ func newJobSpec(pbspec *pb.JobSpec) (job.JobSpec, error) {
 			WriteIOPS: pblim.ReadIops,
 		}
 		if err := iolim.ResolveDevice(); err != nil {
-			return job.JobSpec{}, err
+			return job.JobSpec{}, nil, err
 		}
 		iolimits = append(iolimits, iolim)
 	}
 
+	var umask job.Umask
+	if pbumask := pbspec.GetUmask(); pbumask != nil {
+		umask = job.Umask{Mask: pbumask.GetMask(), Set: true}
+	}
+
+	var commands [][]string
+	for _, pbcommand := range pbspec.GetCommands() {
+		commands = append(commands, pbcommand.GetArgv())
+	}
+
+	var mounts []job.BindMount
+	for _, pbmount := range pbspec.GetMounts() {
+		mounts = append(mounts, job.BindMount{
+			Source:   pbmount.GetSource(),
+			Target:   pbmount.GetTarget(),
+			ReadOnly: pbmount.GetReadOnly(),
+		})
+	}
+
 	return job.JobSpec{
 		Command:        pbspec.GetCommand(),
 		Args:           pbspec.GetArguments(),
+		Commands:       commands,
 		Root:           pbspec.GetRootDir(),
 		IsolateNetwork: pbspec.GetIsolateNetwork(),
+		IsolateUsers:   pbspec.GetIsolateUsers(),
+		MountDev:       pbspec.GetMountDev(),
+		Nice:           int(pbspec.GetNice()),
+		IONice: job.IONice{
+			Class:    job.IOClass(pbspec.GetIoClass()),
+			Priority: int(pbspec.GetIoPriority()),
+		},
+		Umask:                  umask,
+		Workdir:                pbspec.GetWorkdir(),
+		Mounts:                 mounts,
+		RunAsUser:              pbspec.GetRunAsUser(),
+		RunAsGroup:             pbspec.GetRunAsGroup(),
+		Name:                   pbspec.GetName(),
+		TraceSyscalls:          pbspec.GetTraceSyscalls(),
+		SampleResources:        pbspec.GetSampleResources(),
+		StopOnClientDisconnect: pbspec.GetStopOnClientDisconnect(),
+		MaxLogLinesPerSec:      pbspec.GetMaxLogLinesPerSec(),
+		Queue:                  pbspec.GetQueue(),
+		Timeout:                time.Duration(pbspec.GetTimeoutSeconds()) * time.Second,
 		Resources: job.ResourceLimits{
 			MaxProcesses: pbresources.GetMaxProcesses(),
-			Memory:       pbresources.GetMemory(),
-			CPU:          pbresources.GetMilliCpu(),
+			Memory:       job.ByteSize(pbresources.GetMemory()),
+			CPU:          job.MilliCPU(pbresources.GetMilliCpu()),
+			CpuBurst:     pbresources.GetCpuBurstUs(),
 			IO:           iolimits,
+			CpuSetMems:   pbresources.GetCpusetMems(),
 		},
-	}, nil
+	}, warnings, nil
+}
+
+// NewJobSpecPB converts a job.JobSpec to a protobuf JobSpec.
+func NewJobSpecPB(spec job.JobSpec) *pb.JobSpec {
+	var iolims []*pb.DiskIOLimit
+	for _, iolim := range spec.Resources.IO {
+		pblim := &pb.DiskIOLimit{
+			Device:    iolim.Device,
+			ReadBps:   iolim.ReadBPS,
+			WriteBps:  iolim.WriteBPS,
+			ReadIops:  iolim.ReadIOPS,
+			WriteIops: iolim.WriteIOPS,
+		}
+		iolims = append(iolims, pblim)
+	}
+
+	var mounts []*pb.BindMount
+	for _, m := range spec.Mounts {
+		mounts = append(mounts, &pb.BindMount{Source: m.Source, Target: m.Target, ReadOnly: m.ReadOnly})
+	}
+
+	var commands []*pb.CommandLine
+	for _, argv := range spec.Commands {
+		commands = append(commands, &pb.CommandLine{Argv: argv})
+	}
+
+	var umask *pb.Umask
+	if spec.Umask.Set {
+		umask = &pb.Umask{Mask: spec.Umask.Mask}
+	}
+
+	return &pb.JobSpec{
+		Command:                spec.Command,
+		Arguments:              spec.Args,
+		Commands:               commands,
+		RootDir:                spec.Root,
+		IsolateNetwork:         spec.IsolateNetwork,
+		IsolateUsers:           spec.IsolateUsers,
+		MountDev:               spec.MountDev,
+		Nice:                   int32(spec.Nice),
+		IoClass:                pb.IOClass(spec.IONice.Class),
+		IoPriority:             uint32(spec.IONice.Priority),
+		Umask:                  umask,
+		Workdir:                spec.Workdir,
+		Mounts:                 mounts,
+		RunAsUser:              spec.RunAsUser,
+		RunAsGroup:             spec.RunAsGroup,
+		TraceSyscalls:          spec.TraceSyscalls,
+		Name:                   spec.Name,
+		StopOnClientDisconnect: spec.StopOnClientDisconnect,
+		SampleResources:        spec.SampleResources,
+		MaxLogLinesPerSec:      spec.MaxLogLinesPerSec,
+		Queue:                  spec.Queue,
+		TimeoutSeconds:         uint32(spec.Timeout / time.Second),
+		Resources: &pb.Resources{
+			MaxProcesses: spec.Resources.MaxProcesses,
+			MilliCpu:     uint32(spec.Resources.CPU),
+			CpuBurstUs:   spec.Resources.CpuBurst,
+			Memory:       uint64(spec.Resources.Memory),
+			IoLimits:     iolims,
+			CpusetMems:   spec.Resources.CpuSetMems,
+		},
+	}
 }
 
 // Create a protobuf JobStatus from a job.Job
@@ -149,16 +687,45 @@ func newJobStatusPB(jd job.JobDescription) *pb.JobStatus {
 		state = pb.JobStatus_JOBSTATE_RUNNING
 	case job.JobStateCompleted:
 		state = pb.JobStatus_JOBSTATE_COMPLETED
+	case job.JobStateQueued:
+		state = pb.JobStatus_JOBSTATE_QUEUED
+	case job.JobStateCancelled:
+		state = pb.JobStatus_JOBSTATE_CANCELLED
 	default:
 		// leave as invalid
 	}
 
+	var exit *pb.ExitStatus
+	var completionTime *timestamppb.Timestamp
+	if jd.Status.Ended() {
+		exit = newExitStatusPB(jd.Status)
+		completionTime = timestamppb.New(jd.Status.CompletionTime)
+	}
+
 	return &pb.JobStatus{
-		JobId:     []byte(jd.ID),
-		StartTime: timestamppb.New(jd.Status.StartTime),
-		User:      jd.Status.Owner,
-		State:     state,
-		ExitCode:  jd.Status.ExitCode,
-		Spec:      nil, // XXX todo. nothing uses it yet
+		JobId:           []byte(jd.ID),
+		StartTime:       timestamppb.New(jd.Status.StartTime),
+		User:            jd.Status.Owner,
+		State:           state,
+		ExitCode:        jd.Status.ExitCode,
+		Spec:            NewJobSpecPB(jd.Spec),
+		Pid:             uint32(jd.Status.Pid),
+		Exit:            exit,
+		PeakProcesses:   jd.Status.PeakProcesses,
+		CompletionTime:  completionTime,
+		MemoryBytes:     jd.Usage.Memory,
+		PeakMemoryBytes: jd.Usage.PeakMemory,
+		CpuTimeUs:       uint64(jd.Usage.CPUTime / time.Microsecond),
+		Client:          jd.Status.Client,
+	}
+}
+
+// Create a protobuf ExitStatus from a completed job.JobStatus
+func newExitStatusPB(status job.JobStatus) *pb.ExitStatus {
+	return &pb.ExitStatus{
+		ExitCode: status.ExitCode,
+		Signal:   uint32(status.Signal),
+		Reason:   status.ExitReason(),
+		TimedOut: status.TimedOut(),
 	}
 }
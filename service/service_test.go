@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/camh-/jobber/job"
+	pb "github.com/camh-/jobber/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// logsStream is a pb.JobExecutor_LogsServer for calling Logs directly.
+type logsStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*pb.LogsResponse
+}
+
+func (s *logsStream) Context() context.Context { return s.ctx }
+
+func (s *logsStream) Send(resp *pb.LogsResponse) error {
+	s.sent = append(s.sent, resp)
+	return nil
+}
+
+func TestMaxLogStreams(t *testing.T) {
+	svc := NewJobExecutor(nil, nil, nil)
+	svc.SetMaxLogStreams(2)
+	stream := &logsStream{ctx: job.AddUserToContext(context.Background(), "eve")}
+	req := &pb.LogsRequest{JobId: []byte("no-such-job")}
+
+	// Streams that are open hold their place until they return.
+	svc.logStreams = 2
+	err := svc.Logs(req, stream)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Contains(t, scrapeMetrics(t, svc), "\njobber_active_log_streams 2\n")
+
+	// Once one closes, there is room for another.
+	svc.logStreams = 1
+	err = svc.Logs(req, stream)
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Contains(t, scrapeMetrics(t, svc), "\njobber_active_log_streams 1\n")
+}
+
+// scrapeMetrics returns the metrics served by svc's MetricsHandler.
+func scrapeMetrics(t *testing.T, svc *JobExecutor) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	svc.MetricsHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+func TestMonitorCgroupsMetric(t *testing.T) {
+	svc := NewJobExecutor(nil, nil, nil)
+	done := make(chan struct{})
+	defer close(done)
+	hs := health.NewServer()
+	svc.MonitorCgroups(done, 0, hs)
+
+	// Whether the cgroups are healthy depends on the host, but the
+	// metric must agree with the health service.
+	resp, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	expected := "0"
+	if resp.GetStatus() == healthpb.HealthCheckResponse_SERVING {
+		expected = "1"
+	}
+	require.Contains(t, scrapeMetrics(t, svc), "\njobber_cgroups_healthy "+expected+"\n")
+}
+
+func TestLogsInvalidTimeWindow(t *testing.T) {
+	svc := NewJobExecutor(nil, nil, nil)
+	stream := &logsStream{ctx: job.AddUserToContext(context.Background(), "eve")}
+	now := time.Now()
+
+	req := &pb.LogsRequest{JobId: []byte("no-such-job"), Follow: true, FromTime: timestamppb.New(now)}
+	require.Equal(t, codes.InvalidArgument, status.Code(svc.Logs(req, stream)))
+
+	req = &pb.LogsRequest{JobId: []byte("no-such-job"), FromTime: timestamppb.New(now), ToTime: timestamppb.New(now.Add(-time.Second))}
+	require.Equal(t, codes.InvalidArgument, status.Code(svc.Logs(req, stream)))
+
+	req = &pb.LogsRequest{JobId: []byte("no-such-job"), ToTime: timestamppb.New(now)}
+	require.Equal(t, codes.NotFound, status.Code(svc.Logs(req, stream)))
+
+	// A tail is always of the latest logs.
+	req = &pb.LogsRequest{JobId: []byte("no-such-job"), TailLines: 10, ToTime: timestamppb.New(now)}
+	require.Equal(t, codes.InvalidArgument, status.Code(svc.Logs(req, stream)))
+	req = &pb.LogsRequest{JobId: []byte("no-such-job"), TailLines: 10, StartLine: 3}
+	require.Equal(t, codes.InvalidArgument, status.Code(svc.Logs(req, stream)))
+}
+
+func TestFailedWithin(t *testing.T) {
+	now := time.Date(2022, 5, 27, 12, 0, 0, 0, time.UTC)
+	completed := func(ago time.Duration, exitCode uint32) job.JobStatus {
+		return job.JobStatus{State: job.JobStateCompleted, ExitCode: exitCode, CompletionTime: now.Add(-ago)}
+	}
+	tests := map[string]struct {
+		status job.JobStatus
+		want   bool
+	}{
+		"failed just now":           {status: completed(0, 1), want: true},
+		"failed within window":      {status: completed(59*time.Minute, 2), want: true},
+		"failed at edge of window":  {status: completed(time.Hour, 1), want: true},
+		"failed before window":      {status: completed(61*time.Minute, 1), want: false},
+		"succeeded within window":   {status: completed(time.Minute, 0), want: false},
+		"killed within window":      {status: job.JobStatus{State: job.JobStateCompleted, ExitCode: 137, Signal: 9, CompletionTime: now}, want: true},
+		"running":                   {status: job.JobStatus{State: job.JobStateRunning}, want: false},
+		"not started within window": {status: completed(time.Second, 127), want: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, failedWithin(tc.status, time.Hour, now))
+		})
+	}
+}
+
+func TestListInvalidRecentlyFailed(t *testing.T) {
+	svc := NewJobExecutor(nil, nil, nil)
+	ctx := job.AddUserToContext(context.Background(), "eve")
+	_, err := svc.List(ctx, &pb.ListRequest{RecentlyFailed: durationpb.New(-time.Hour)})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err := svc.List(ctx, &pb.ListRequest{RecentlyFailed: durationpb.New(time.Hour)})
+	require.NoError(t, err)
+	require.Empty(t, resp.Jobs)
+}
+
+func TestJobStatusCompletionTime(t *testing.T) {
+	start := time.Date(2022, 5, 27, 12, 0, 0, 0, time.UTC)
+	jd := job.JobDescription{ID: "test-1", Status: job.JobStatus{State: job.JobStateRunning, StartTime: start}}
+	require.Nil(t, newJobStatusPB(jd).CompletionTime)
+
+	jd.Status.State = job.JobStateCompleted
+	jd.Status.CompletionTime = start.Add(time.Minute)
+	status := newJobStatusPB(jd)
+	require.Equal(t, start.Add(time.Minute), status.GetCompletionTime().AsTime())
+	require.Equal(t, start, status.GetStartTime().AsTime())
+}
+
+func TestReadReplicaService(t *testing.T) {
+	store := &fakeStore{jobs: []job.StoredJob{{
+		Description: job.JobDescription{
+			ID:     "sleep-01234567",
+			Spec:   job.JobSpec{Command: "/bin/sleep", Args: []string{"60"}},
+			Status: job.JobStatus{State: job.JobStateRunning, Owner: "eve", Pid: 4242, StartTime: time.Now()},
+		},
+	}}}
+	svc := NewJobExecutor(nil, nil, nil, job.WithReadReplica(store, 0))
+	ctx := job.AddUserToContext(context.Background(), "eve")
+
+	resp, err := svc.Status(ctx, &pb.StatusRequest{JobId: []byte("sleep-01234567")})
+	require.NoError(t, err)
+	require.Equal(t, pb.JobStatus_JOBSTATE_RUNNING, resp.GetStatus().GetState())
+	require.Equal(t, uint32(4242), resp.GetStatus().GetPid())
+
+	list, err := svc.List(ctx, &pb.ListRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetJobs(), 1)
+
+	_, err = svc.Run(ctx, &pb.RunRequest{Spec: &pb.JobSpec{Command: "/bin/true"}})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = svc.Stop(ctx, &pb.StopRequest{JobId: []byte("sleep-01234567")})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestViewerRole(t *testing.T) {
+	store := &fakeStore{jobs: []job.StoredJob{{Description: job.JobDescription{
+		ID:     "sleep-01234567",
+		Spec:   job.JobSpec{Command: "/bin/sleep"},
+		Status: job.JobStatus{State: job.JobStateCompleted, Owner: "mallory", StartTime: time.Now()},
+	}}}}
+	svc := NewJobExecutor(nil, nil, nil, job.WithStore(store), job.WithRoles(map[string]job.Role{"vic": job.RoleViewer}))
+	ctx := job.AddUserToContext(context.Background(), "vic")
+
+	resp, err := svc.Status(ctx, &pb.StatusRequest{JobId: []byte("sleep-01234567")})
+	require.NoError(t, err)
+	require.Equal(t, "mallory", resp.GetStatus().GetUser())
+
+	_, err = svc.Stop(ctx, &pb.StopRequest{JobId: []byte("sleep-01234567")})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = svc.Run(ctx, &pb.RunRequest{Spec: &pb.JobSpec{Command: "/bin/true"}})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestClientAddress(t *testing.T) {
+	ctx := job.AddUserToContext(context.Background(), "eve")
+	_, ok := job.GetClientFromContext(withClient(ctx))
+	require.False(t, ok)
+
+	addr := &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 51234}
+	ctx = withClient(peer.NewContext(ctx, &peer.Peer{Addr: addr}))
+	client, ok := job.GetClientFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "192.0.2.1:51234", client)
+
+	status := newJobStatusPB(job.JobDescription{Status: job.JobStatus{State: job.JobStateRunning, Client: client}})
+	require.Equal(t, "192.0.2.1:51234", status.GetClient())
+}
+
+func TestShutdownServer(t *testing.T) {
+	done := make(chan struct{})
+	svc := NewJobExecutor(done, nil, []string{"admin"})
+
+	n, err := svc.ShutdownServer()
+	require.NoError(t, err)
+	require.Zero(t, n)
+	select {
+	case <-done:
+	default:
+		t.Fatal("done not closed")
+	}
+
+	// A Shutdown request racing a signal does not close done again.
+	ctx := job.AddUserToContext(context.Background(), "admin")
+	_, err = svc.Shutdown(ctx, &pb.ShutdownRequest{})
+	require.NoError(t, err)
+	_, err = svc.ShutdownServer()
+	require.NoError(t, err)
+
+	// No jobs are started once the server is shutting down.
+	_, err = svc.Run(ctx, &pb.RunRequest{Spec: &pb.JobSpec{Command: "/bin/true"}})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
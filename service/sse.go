@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/camh-/jobber/job"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sseLog is the data of a log event streamed by LogsHandler.
+type sseLog struct {
+	Timestamp time.Time `json:"timestamp"`
+	Stream    string    `json:"stream"`
+	Line      string    `json:"line"`
+	Partial   bool      `json:"partial,omitempty"`
+	Dropped   int       `json:"dropped,omitempty"`
+}
+
+// sseExit is the data of the exit event streamed by LogsHandler once the
+// logs of a completed job have all been sent.
+type sseExit struct {
+	ExitCode uint32 `json:"exit_code"`
+	Signal   int    `json:"signal,omitempty"`
+	Reason   string `json:"reason"`
+	TimedOut bool   `json:"timed_out,omitempty"`
+}
+
+// httpStatusCodes maps the gRPC status codes of errors to the HTTP status
+// returned for them by LogsHandler. Other codes are an internal error.
+var httpStatusCodes = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.NotFound:           http.StatusNotFound,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.FailedPrecondition: http.StatusConflict,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+}
+
+// LogsHandler returns an HTTP handler that streams the logs of the job
+// identified by <id> at /jobs/<id>/logs as Server-Sent Events, for web
+// clients that cannot use gRPC. auth authenticates the user of each request,
+// returning the request's context with the user added as the gRPC auth
+// func does.
+//
+// Each line is a "log" event with the line's index as its event ID and its
+// timestamp, stream and text as JSON data. The query parameters follow and
+// tail are as for the Logs RPC. A client reconnecting with a Last-Event-ID
+// header resumes after that line. If the job has completed, the stream
+// ends with an "exit" event with the job's exit status, on which a client
+// should close the stream rather than reconnect.
+//
+// Log streams count towards the server's maximum set with
+// SetMaxLogStreams.
+func (svc *JobExecutor) LogsHandler(auth func(*http.Request) (context.Context, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := svc.serveSSELogs(w, r, auth); err != nil {
+			code := http.StatusInternalServerError
+			if c, ok := httpStatusCodes[status.Code(err)]; ok {
+				code = c
+			}
+			http.Error(w, status.Convert(err).Message(), code)
+		}
+	})
+}
+
+// serveSSELogs streams the logs requested by r to w as Server-Sent Events.
+// An error is only returned before the stream has started, for the caller
+// to respond with.
+func (svc *JobExecutor) serveSSELogs(w http.ResponseWriter, r *http.Request, auth func(*http.Request) (context.Context, error)) error {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil
+	}
+	path := r.URL.Path
+	if !strings.HasPrefix(path, "/jobs/") || !strings.HasSuffix(path, "/logs") || len(path) <= len("/jobs//logs") {
+		http.NotFound(w, r)
+		return nil
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(path, "/jobs/"), "/logs")
+
+	ctx, err := auth(r)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	follow, start, err := sseLogsParams(r)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return status.Error(codes.Internal, "streaming unsupported")
+	}
+
+	n := atomic.AddInt64(&svc.logStreams, 1)
+	defer atomic.AddInt64(&svc.logStreams, -1)
+	if max := atomic.LoadInt64(&svc.maxLogStreams); max > 0 && n > max {
+		return status.Errorf(codes.ResourceExhausted, "server has its maximum of %d log streams open", max)
+	}
+	ch, err := svc.tracker.GetLogChannel(id, follow, start, ctx)
+	if err != nil {
+		return statusError(err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for l := range ch {
+		data := sseLog{
+			Timestamp: l.Timestamp,
+			Stream:    l.Stream.String(),
+			Line:      string(l.Line),
+			Partial:   l.Partial,
+			Dropped:   l.Dropped,
+		}
+		if err := writeSSE(w, strconv.Itoa(l.Index), "log", data); err != nil {
+			return nil
+		}
+		flusher.Flush()
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	// As for the Logs RPC, a follower may reach the end of the logs
+	// before the job is reaped.
+	var jd job.JobDescription
+	if follow {
+		jd, err = svc.tracker.Wait(ctx, id)
+	} else {
+		jd, err = svc.tracker.Get(ctx, id)
+	}
+	if err != nil || !jd.Status.Ended() {
+		return nil
+	}
+	data := sseExit{
+		ExitCode: jd.Status.ExitCode,
+		Signal:   int(jd.Status.Signal),
+		Reason:   jd.Status.ExitReason(),
+		TimedOut: jd.Status.TimedOut(),
+	}
+	if err := writeSSE(w, "", "exit", data); err == nil {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// sseLogsParams returns whether to follow the logs requested by r and the
+// line to start from, as passed to Tracker.GetLogChannel.
+func sseLogsParams(r *http.Request) (bool, int, error) {
+	var follow bool
+	if s := r.URL.Query().Get("follow"); s != "" {
+		var err error
+		if follow, err = strconv.ParseBool(s); err != nil {
+			return false, 0, fmt.Errorf("invalid follow: %q", s)
+		}
+	}
+	if s := r.Header.Get("Last-Event-ID"); s != "" {
+		last, err := strconv.Atoi(s)
+		if err != nil || last < 0 {
+			return false, 0, fmt.Errorf("invalid Last-Event-ID: %q", s)
+		}
+		return follow, last + 1, nil
+	}
+	if s := r.URL.Query().Get("tail"); s != "" {
+		tail, err := strconv.ParseUint(s, 10, 31)
+		if err != nil {
+			return false, 0, fmt.Errorf("invalid tail: %q", s)
+		}
+		// A negative start counts back from the end of the logs.
+		return follow, -int(tail), nil
+	}
+	return follow, 0, nil
+}
+
+// writeSSE writes a Server-Sent Event of the given type with data as JSON
+// to w, with the given event ID unless it is empty.
+func writeSSE(w http.ResponseWriter, id, event string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+	return err
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/camh-/jobber/job"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogsHandler(t *testing.T) {
+	start := time.Date(2022, 5, 27, 12, 24, 4, 0, time.UTC)
+	store := &fakeStore{jobs: []job.StoredJob{{
+		Description: job.JobDescription{
+			ID:     "greeting-01234567",
+			Spec:   job.JobSpec{Command: "greeting"},
+			Status: job.JobStatus{State: job.JobStateCompleted, Owner: "eve", StartTime: start, CompletionTime: start.Add(time.Second)},
+		},
+		Logs: []job.Log{
+			{Timestamp: start, Line: []byte("Hello world\n"), Index: 0},
+			{Timestamp: start.Add(time.Millisecond), Line: []byte("Goodbye world\n"), Index: 1, Stream: job.StreamStderr},
+		},
+	}}}
+	svc := NewJobExecutor(nil, nil, nil, job.WithStore(store))
+	auth := func(r *http.Request) (context.Context, error) {
+		user := r.Header.Get("X-Test-User")
+		if user == "" {
+			return nil, job.ErrUnauthorized
+		}
+		return job.AddUserToContext(r.Context(), user), nil
+	}
+	srv := httptest.NewServer(svc.LogsHandler(auth))
+	defer srv.Close()
+
+	get := func(path, user string, header ...string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		if resp.StatusCode == http.StatusOK {
+			require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		}
+		return resp.StatusCode, string(b)
+	}
+
+	hello := "id: 0\nevent: log\ndata: {\"timestamp\":\"2022-05-27T12:24:04Z\",\"stream\":\"stdout\",\"line\":\"Hello world\\n\"}\n\n"
+	goodbye := "id: 1\nevent: log\ndata: {\"timestamp\":\"2022-05-27T12:24:04.001Z\",\"stream\":\"stderr\",\"line\":\"Goodbye world\\n\"}\n\n"
+	exit := "event: exit\ndata: {\"exit_code\":0,\"reason\":\"exited with code 0\"}\n\n"
+
+	code, body := get("/jobs/greeting-01234567/logs", "eve")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, hello+goodbye+exit, body)
+
+	// A completed job's logs end the same when followed.
+	code, body = get("/jobs/greeting-01234567/logs?follow=true", "eve")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, hello+goodbye+exit, body)
+
+	code, body = get("/jobs/greeting-01234567/logs?tail=1", "eve")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, goodbye+exit, body)
+
+	// A reconnecting client resumes after the last event it received.
+	code, body = get("/jobs/greeting-01234567/logs", "eve", "Last-Event-ID", "0")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, goodbye+exit, body)
+
+	tests := map[string]struct {
+		path, user string
+		expected   int
+	}{
+		"unauthenticated": {"/jobs/greeting-01234567/logs", "", http.StatusUnauthorized},
+		"unknown job":     {"/jobs/no-such-job/logs", "eve", http.StatusNotFound},
+		"others job":      {"/jobs/greeting-01234567/logs", "mallory", http.StatusForbidden},
+		"bad follow":      {"/jobs/greeting-01234567/logs?follow=maybe", "eve", http.StatusBadRequest},
+		"bad tail":        {"/jobs/greeting-01234567/logs?tail=-1", "eve", http.StatusBadRequest},
+		"not logs":        {"/jobs/greeting-01234567", "eve", http.StatusNotFound},
+		"no job id":       {"/jobs//logs", "eve", http.StatusNotFound},
+	}
+	for name, tc := range tests {
+		code, _ := get(tc.path, tc.user)
+		require.Equal(t, tc.expected, code, name)
+	}
+}